@@ -32,10 +32,13 @@ import (
 
 	_ "github.com/KNLopez/restaurant-api/docs"
 	"github.com/KNLopez/restaurant-api/internal/config"
+	"github.com/KNLopez/restaurant-api/internal/geocode"
 	"github.com/KNLopez/restaurant-api/internal/handler"
+	"github.com/KNLopez/restaurant-api/internal/payment"
 	"github.com/KNLopez/restaurant-api/internal/repository/postgres"
 	"github.com/KNLopez/restaurant-api/internal/router"
 	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/KNLopez/restaurant-api/internal/tenant"
 	"github.com/KNLopez/restaurant-api/internal/utils"
 	_ "github.com/lib/pq"
 )
@@ -60,13 +63,81 @@ func main() {
 	menuRepo := postgres.NewMenuRepository(db)
 	orderRepo := postgres.NewOrderRepository(db)
 	tableRepo := postgres.NewTableRepository(db)
+	checkRepo := postgres.NewCheckRepository(db)
+	paymentRepo := postgres.NewPaymentRepository(db)
+	adjustmentRepo := postgres.NewAdjustmentRepository(db)
+	pricingRuleRepo := postgres.NewPricingRuleRepository(db)
+	giftCardRepo := postgres.NewGiftCardRepository(db)
+	loyaltyRepo := postgres.NewLoyaltyRepository(db)
+	favoriteRepo := postgres.NewFavoriteRepository(db)
+	reviewRepo := postgres.NewReviewRepository(db)
+	customizationRepo := postgres.NewCustomizationRepository(db)
+	inventoryRepo := postgres.NewInventoryRepository(db)
+	supplierRepo := postgres.NewSupplierRepository(db)
+	purchaseOrderRepo := postgres.NewPurchaseOrderRepository(db)
+	menuScheduleRepo := postgres.NewMenuScheduleRepository(db)
+	allergyProfileRepo := postgres.NewAllergyProfileRepository(db)
+	variantRepo := postgres.NewVariantRepository(db)
+	bundleRepo := postgres.NewBundleRepository(db)
+	categoryRepo := postgres.NewCategoryRepository(db)
+	brandRepo := postgres.NewBrandRepository(db)
+	menuVersionRepo := postgres.NewMenuVersionRepository(db)
+	hoursRepo := postgres.NewHoursRepository(db)
+	settingsRepo := postgres.NewSettingsRepository(db)
+	organizationRepo := postgres.NewOrganizationRepository(db)
+
+	// Initialize the geocoder
+	var geocoder geocode.Geocoder
+	switch cfg.Geocoder.Provider {
+	case "stub":
+		center := geocode.Point{Latitude: cfg.Geocoder.StubLatitude, Longitude: cfg.Geocoder.StubLongitude}
+		geocoder = geocode.NewStubGeocoder(center, cfg.Geocoder.StubRadiusKm)
+	default:
+		log.Fatalf("unknown geocoder %q", cfg.Geocoder.Provider)
+	}
 
 	// Initialize services
 	userService := service.NewUserService(userRepo)
-	restaurantService := service.NewRestaurantService(restaurantRepo)
-	menuService := service.NewMenuService(menuRepo)
-	orderService := service.NewOrderService(orderRepo)
+	menuScheduleService := service.NewMenuScheduleService(menuScheduleRepo, restaurantRepo, menuRepo)
+	hoursService := service.NewHoursService(hoursRepo, restaurantRepo)
+	settingsService := service.NewSettingsService(settingsRepo, restaurantRepo, cfg.Settings.CacheTTL)
+	restaurantService := service.NewRestaurantService(restaurantRepo, geocoder, hoursService, organizationRepo)
+	dietaryService := service.NewDietaryService(allergyProfileRepo, menuRepo)
+	menuVersionService := service.NewMenuVersionService(menuVersionRepo, menuRepo, categoryRepo)
+	menuSearchService := service.NewMenuSearchService(menuRepo, categoryRepo)
+	menuService := service.NewMenuService(menuRepo, reviewRepo, customizationRepo, variantRepo, bundleRepo, menuScheduleService)
+	pricingService := service.NewPricingService(pricingRuleRepo, menuRepo, customizationRepo, variantRepo, bundleRepo, restaurantRepo)
+	inventoryService := service.NewInventoryService(inventoryRepo, menuRepo, customizationRepo)
+	loyaltyService := service.NewLoyaltyService(loyaltyRepo, userRepo)
+	orderService := service.NewOrderService(orderRepo, checkRepo, menuRepo, variantRepo, pricingService, loyaltyService, inventoryService, menuScheduleService, dietaryService, menuVersionService, hoursService, settingsService)
 	tableService := service.NewTableService(tableRepo)
+	checkService := service.NewCheckService(checkRepo, orderRepo, tableRepo, orderService)
+	giftCardService := service.NewGiftCardService(giftCardRepo, orderRepo, restaurantRepo)
+	favoriteService := service.NewFavoriteService(favoriteRepo, menuRepo)
+	reviewService := service.NewReviewService(reviewRepo, orderRepo, restaurantRepo, organizationRepo)
+	purchasingService := service.NewPurchasingService(supplierRepo, purchaseOrderRepo, inventoryRepo)
+	kitchenService := service.NewKitchenService(orderRepo, menuRepo, customizationRepo)
+	reportService := service.NewReportService(orderRepo)
+	categoryService := service.NewCategoryService(categoryRepo)
+	translationService := service.NewTranslationService(restaurantRepo, categoryRepo, menuRepo)
+	menuImportService := service.NewMenuImportService(menuRepo, categoryRepo, customizationRepo)
+	organizationService := service.NewOrganizationService(organizationRepo, restaurantRepo, userRepo)
+	brandService := service.NewBrandService(brandRepo, restaurantRepo, menuRepo, categoryRepo, organizationRepo)
+
+	// Initialize payment providers
+	paymentProviders := payment.NewRegistry(
+		payment.NewManualProvider(payment.ProviderCash),
+		payment.NewManualProvider(payment.ProviderManualCard),
+		payment.NewGiftCardProvider(giftCardService),
+	)
+	paymentService := service.NewPaymentService(paymentRepo, orderRepo, checkService, paymentProviders)
+	if cfg.Payment.FakeEnabled {
+		log.Println("warning: the fake payment provider is enabled and approves any token")
+		fakeProvider := payment.NewFakeProvider(cfg.Payment.WebhookSecret, cfg.Payment.FakeWebhookDelay)
+		fakeProvider.SetWebhookSink(paymentService.HandleWebhook)
+		paymentProviders.Register(fakeProvider)
+	}
+	adjustmentService := service.NewAdjustmentService(adjustmentRepo, orderRepo, restaurantRepo, organizationRepo, orderService, paymentService, loyaltyService, cfg.Refund.ApprovalThreshold)
 
 	// Initialize Cloudinary
 	cloudinary, err := utils.NewCloudinaryService(
@@ -84,9 +155,63 @@ func main() {
 	menuHandler := handler.NewMenuHandler(menuService, cloudinary)
 	orderHandler := handler.NewOrderHandler(orderService)
 	tableHandler := handler.NewTableHandler(tableService, cfg)
+	checkHandler := handler.NewCheckHandler(checkService)
+	paymentHandler := handler.NewPaymentHandler(paymentService)
+	adjustmentHandler := handler.NewAdjustmentHandler(adjustmentService)
+	pricingRuleHandler := handler.NewPricingRuleHandler(pricingService)
+	giftCardHandler := handler.NewGiftCardHandler(giftCardService)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyService)
+	favoriteHandler := handler.NewFavoriteHandler(favoriteService)
+	reviewHandler := handler.NewReviewHandler(reviewService)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService)
+	purchasingHandler := handler.NewPurchasingHandler(purchasingService)
+	menuScheduleHandler := handler.NewMenuScheduleHandler(menuScheduleService)
+	allergyProfileHandler := handler.NewAllergyProfileHandler(dietaryService)
+	kitchenHandler := handler.NewKitchenHandler(kitchenService)
+	reportHandler := handler.NewReportHandler(reportService)
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+	translationHandler := handler.NewTranslationHandler(translationService)
+	menuImportHandler := handler.NewMenuImportHandler(menuImportService)
+	brandHandler := handler.NewBrandHandler(brandService)
+	menuVersionHandler := handler.NewMenuVersionHandler(menuVersionService)
+	menuSearchHandler := handler.NewMenuSearchHandler(menuSearchService)
+	hoursHandler := handler.NewHoursHandler(hoursService)
+	settingsHandler := handler.NewSettingsHandler(settingsService)
+	organizationHandler := handler.NewOrganizationHandler(organizationService)
 
 	// Setup router
-	router := router.NewRouter(userHandler, restaurantHandler, menuHandler, orderHandler, tableHandler)
+	router := router.NewRouter(
+		cfg.Auth.JWTSecret,
+		organizationService.Memberships,
+		userHandler,
+		restaurantHandler,
+		menuHandler,
+		orderHandler,
+		tableHandler,
+		checkHandler,
+		paymentHandler,
+		adjustmentHandler,
+		pricingRuleHandler,
+		giftCardHandler,
+		loyaltyHandler,
+		favoriteHandler,
+		reviewHandler,
+		inventoryHandler,
+		purchasingHandler,
+		menuScheduleHandler,
+		allergyProfileHandler,
+		kitchenHandler,
+		reportHandler,
+		categoryHandler,
+		translationHandler,
+		menuImportHandler,
+		brandHandler,
+		menuVersionHandler,
+		menuSearchHandler,
+		hoursHandler,
+		settingsHandler,
+		organizationHandler,
+	)
 
 	// Create server
 	srv := &http.Server{
@@ -105,6 +230,11 @@ func main() {
 		}
 	}()
 
+	// Publish scheduled menu versions in the background, for every
+	// organization
+	schedulerCtx, stopScheduler := context.WithCancel(tenant.Unscoped(context.Background()))
+	go menuVersionService.Run(schedulerCtx, cfg.Menu.PublishInterval)
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -112,6 +242,7 @@ func main() {
 
 	// Graceful shutdown
 	log.Println("Server is shutting down...")
+	stopScheduler()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
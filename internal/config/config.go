@@ -3,12 +3,20 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Server     ServerConfig
 	Database   DatabaseConfig
 	Auth       AuthConfig
+	Payment    PaymentConfig
+	Refund     RefundConfig
+	Menu       MenuConfig
+	Geocoder   GeocoderConfig
+	Settings   SettingsConfig
 	BaseURL    string `env:"BASE_URL" envDefault:"http://localhost:8080"`
 	Cloudinary struct {
 		CloudName string `env:"CLOUDINARY_CLOUD_NAME"`
@@ -38,10 +46,112 @@ type AuthConfig struct {
 	FacebookClientKey string
 }
 
+// PaymentConfig configures the payment providers. The fake provider
+// approves any token, so it is only registered when explicitly enabled.
+type PaymentConfig struct {
+	WebhookSecret    string
+	FakeEnabled      bool
+	FakeWebhookDelay time.Duration
+}
+
+type RefundConfig struct {
+	// ApprovalThreshold is the refund amount above which a manager has to
+	// approve the refund.
+	ApprovalThreshold float64
+}
+
+type MenuConfig struct {
+	// PublishInterval is how often scheduled menu versions are checked
+	// and published.
+	PublishInterval time.Duration
+}
+
+// GeocoderConfig selects how restaurant addresses are located. The stub
+// provider places them around a center point without any network calls.
+type GeocoderConfig struct {
+	Provider      string
+	StubLatitude  float64
+	StubLongitude float64
+	StubRadiusKm  float64
+}
+
+type SettingsConfig struct {
+	// CacheTTL is how long services keep a restaurant's settings before
+	// reading them again.
+	CacheTTL time.Duration
+}
+
+// defaultJWTSecret is the placeholder the example environment used to
+// ship. Anyone could sign an admin token with it.
+const defaultJWTSecret = "your-secret-key"
+
+// defaultWebhookSecret is the placeholder shipped in the example
+// environment. Webhooks signed with it could be forged by anyone.
+const defaultWebhookSecret = "your-webhook-secret"
+
 func Load() (*Config, error) {
 	dbPort := 5432     // default postgres port
 	serverPort := 8080 // default server port
 
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" || jwtSecret == defaultJWTSecret {
+		return nil, fmt.Errorf("JWT_SECRET must be set to a secret of your own")
+	}
+
+	fakePayments, err := strconv.ParseBool(getEnvOrDefault("FAKE_PAYMENTS_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FAKE_PAYMENTS_ENABLED: %w", err)
+	}
+
+	// Only the fake provider sends webhooks; cash, manual card and gift
+	// card payments settle immediately.
+	webhookSecret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if fakePayments && (webhookSecret == "" || webhookSecret == defaultWebhookSecret) {
+		return nil, fmt.Errorf("PAYMENT_WEBHOOK_SECRET must be set to a secret of your own when FAKE_PAYMENTS_ENABLED is true")
+	}
+
+	webhookDelay, err := time.ParseDuration(getEnvOrDefault("FAKE_PAYMENT_WEBHOOK_DELAY", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FAKE_PAYMENT_WEBHOOK_DELAY: %w", err)
+	}
+
+	refundThreshold, err := strconv.ParseFloat(getEnvOrDefault("REFUND_APPROVAL_THRESHOLD", "50"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REFUND_APPROVAL_THRESHOLD: %w", err)
+	}
+
+	publishInterval, err := time.ParseDuration(getEnvOrDefault("MENU_PUBLISH_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MENU_PUBLISH_INTERVAL: %w", err)
+	}
+	if publishInterval <= 0 {
+		return nil, fmt.Errorf("invalid MENU_PUBLISH_INTERVAL: must be positive")
+	}
+
+	settingsCacheTTL, err := time.ParseDuration(getEnvOrDefault("SETTINGS_CACHE_TTL", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SETTINGS_CACHE_TTL: %w", err)
+	}
+	if settingsCacheTTL < 0 {
+		return nil, fmt.Errorf("invalid SETTINGS_CACHE_TTL: must not be negative")
+	}
+
+	geocoder := GeocoderConfig{Provider: getEnvOrDefault("GEOCODER", "stub")}
+	stubCenter := getEnvOrDefault("GEOCODER_STUB_CENTER", "40.7128,-74.0060")
+	lat, lng, ok := strings.Cut(stubCenter, ",")
+	if !ok {
+		return nil, fmt.Errorf("invalid GEOCODER_STUB_CENTER: expected lat,lng")
+	}
+	if geocoder.StubLatitude, err = strconv.ParseFloat(strings.TrimSpace(lat), 64); err != nil {
+		return nil, fmt.Errorf("invalid GEOCODER_STUB_CENTER: %w", err)
+	}
+	if geocoder.StubLongitude, err = strconv.ParseFloat(strings.TrimSpace(lng), 64); err != nil {
+		return nil, fmt.Errorf("invalid GEOCODER_STUB_CENTER: %w", err)
+	}
+	if geocoder.StubRadiusKm, err = strconv.ParseFloat(getEnvOrDefault("GEOCODER_STUB_RADIUS_KM", "10"), 64); err != nil {
+		return nil, fmt.Errorf("invalid GEOCODER_STUB_RADIUS_KM: %w", err)
+	}
+
 	return &Config{
 		Server: ServerConfig{
 			Port: serverPort,
@@ -55,12 +165,27 @@ func Load() (*Config, error) {
 			SSLMode:  getEnvOrDefault("DB_SSLMODE", "disable"),
 		},
 		Auth: AuthConfig{
-			JWTSecret:         getEnvOrDefault("JWT_SECRET", "your-secret-key"),
+			JWTSecret:         jwtSecret,
 			GoogleClientID:    os.Getenv("GOOGLE_CLIENT_ID"),
 			GoogleClientKey:   os.Getenv("GOOGLE_CLIENT_SECRET"),
 			FacebookClientID:  os.Getenv("FACEBOOK_CLIENT_ID"),
 			FacebookClientKey: os.Getenv("FACEBOOK_CLIENT_SECRET"),
 		},
+		Payment: PaymentConfig{
+			WebhookSecret:    webhookSecret,
+			FakeEnabled:      fakePayments,
+			FakeWebhookDelay: webhookDelay,
+		},
+		Refund: RefundConfig{
+			ApprovalThreshold: refundThreshold,
+		},
+		Menu: MenuConfig{
+			PublishInterval: publishInterval,
+		},
+		Geocoder: geocoder,
+		Settings: SettingsConfig{
+			CacheTTL: settingsCacheTTL,
+		},
 	}, nil
 }
 
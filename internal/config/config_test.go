@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestLoadJWTSecret(t *testing.T) {
+	tests := []struct {
+		name      string
+		jwtSecret string
+		wantErr   bool
+	}{
+		{"missing", "", true},
+		{"placeholder", defaultJWTSecret, true},
+		{"set", "a-secret-of-our-own", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SECRET", tt.jwtSecret)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadWebhookSecret(t *testing.T) {
+	tests := []struct {
+		name          string
+		fakePayments  string
+		webhookSecret string
+		wantErr       bool
+	}{
+		{"not needed without the fake provider", "false", "", false},
+		{"fake provider needs a secret", "true", "", true},
+		{"fake provider rejects the placeholder", "true", defaultWebhookSecret, true},
+		{"fake provider with a secret", "true", "a-secret-of-our-own", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_SECRET", "a-secret-of-our-own")
+			t.Setenv("FAKE_PAYMENTS_ENABLED", tt.fakePayments)
+			t.Setenv("PAYMENT_WEBHOOK_SECRET", tt.webhookSecret)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -6,8 +6,17 @@ const (
 
 // Route groups
 const (
-	UsersRoute       = BaseURL + "/users"
-	RestaurantsRoute = BaseURL + "/restaurants"
-	OrdersRoute      = BaseURL + "/orders"
-	TablesRoute      = BaseURL + "/tables"
+	UsersRoute         = BaseURL + "/users"
+	RestaurantsRoute   = BaseURL + "/restaurants"
+	OrdersRoute        = BaseURL + "/orders"
+	TablesRoute        = BaseURL + "/tables"
+	ChecksRoute        = BaseURL + "/checks"
+	PaymentsRoute      = BaseURL + "/payments"
+	WebhooksRoute      = BaseURL + "/webhooks"
+	AdjustmentsRoute   = BaseURL + "/adjustments"
+	GiftCardsRoute     = BaseURL + "/gift-cards"
+	LoyaltyRoute       = BaseURL + "/loyalty"
+	ReviewsRoute       = BaseURL + "/reviews"
+	BrandsRoute        = BaseURL + "/brands"
+	OrganizationsRoute = BaseURL + "/organizations"
 )
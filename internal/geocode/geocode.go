@@ -0,0 +1,30 @@
+// Package geocode turns street addresses into coordinates.
+package geocode
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when an address cannot be located.
+var ErrNotFound = errors.New("address not found")
+
+// earthRadiusKm is the mean radius of the Earth.
+const earthRadiusKm = 6371.0
+
+// Point is a position in decimal degrees.
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Geocoder is implemented by every geocoding service the API can look
+// addresses up with.
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (Point, error)
+}
+
+// Valid reports whether the point is on the globe.
+func (p Point) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
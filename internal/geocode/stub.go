@@ -0,0 +1,44 @@
+package geocode
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+)
+
+// StubGeocoder places addresses without calling out to a service, for
+// development and tests. Every address gets a stable point within
+// RadiusKm of Center, derived from a hash of the normalized address, so
+// the same address always lands in the same place.
+type StubGeocoder struct {
+	Center   Point
+	RadiusKm float64
+}
+
+func NewStubGeocoder(center Point, radiusKm float64) *StubGeocoder {
+	return &StubGeocoder{Center: center, RadiusKm: radiusKm}
+}
+
+func (g *StubGeocoder) Geocode(ctx context.Context, address string) (Point, error) {
+	address = strings.Join(strings.Fields(strings.ToLower(address)), " ")
+	if address == "" {
+		return Point{}, ErrNotFound
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(address))
+	sum := h.Sum64()
+
+	// Spread points evenly over the disc around the center
+	angle := float64(sum&0xffffffff) / float64(1<<32) * 2 * math.Pi
+	distance := math.Sqrt(float64(sum>>32)/float64(1<<32)) * g.RadiusKm
+
+	dLat := distance / earthRadiusKm * 180 / math.Pi
+	dLng := dLat / math.Cos(g.Center.Latitude*math.Pi/180)
+
+	return Point{
+		Latitude:  g.Center.Latitude + dLat*math.Sin(angle),
+		Longitude: g.Center.Longitude + dLng*math.Cos(angle),
+	}, nil
+}
@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type AdjustmentHandler struct {
+	adjustmentService *service.AdjustmentService
+}
+
+func NewAdjustmentHandler(adjustmentService *service.AdjustmentService) *AdjustmentHandler {
+	return &AdjustmentHandler{
+		adjustmentService: adjustmentService,
+	}
+}
+
+// Void godoc
+// @Summary Void order item
+// @Description Void some or all of an order item that has not been sent to the kitchen
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Param void body models.AdjustmentRequest true "Void request"
+// @Success 201 {object} models.OrderAdjustment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/{id}/voids [post]
+func (h *AdjustmentHandler) Void(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	orderID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.AdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	adjustment, err := h.adjustmentService.Void(r.Context(), orderID, req, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(adjustment)
+}
+
+// Refund godoc
+// @Summary Refund order
+// @Description Refund paid items or part of the order; large refunds wait for manager approval
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Param refund body models.AdjustmentRequest true "Refund request"
+// @Success 201 {object} models.OrderAdjustment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/{id}/refunds [post]
+func (h *AdjustmentHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	orderID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.AdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	adjustment, err := h.adjustmentService.RequestRefund(r.Context(), orderID, req, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(adjustment)
+}
+
+// ListByOrder godoc
+// @Summary List order adjustments
+// @Description List voids and refunds of an order
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Success 200 {array} models.OrderAdjustment
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/{id}/adjustments [get]
+func (h *AdjustmentHandler) ListByOrder(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	orderID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	adjustments, err := h.adjustmentService.ListByOrder(r.Context(), orderID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adjustments)
+}
+
+// Approve godoc
+// @Summary Approve refund
+// @Description Approve and execute a refund waiting for manager approval
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Adjustment ID"
+// @Success 200 {object} models.OrderAdjustment
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/adjustments/{id}/approve [post]
+func (h *AdjustmentHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid adjustment ID", http.StatusBadRequest)
+		return
+	}
+
+	adjustment, err := h.adjustmentService.Approve(r.Context(), id, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adjustment)
+}
+
+// Retry godoc
+// @Summary Retry refund
+// @Description Refund what is left of a refund that failed part way; the refunds already made are kept
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Adjustment ID"
+// @Success 200 {object} models.OrderAdjustment
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/adjustments/{id}/retry [post]
+func (h *AdjustmentHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid adjustment ID", http.StatusBadRequest)
+		return
+	}
+
+	adjustment, err := h.adjustmentService.Retry(r.Context(), id, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adjustment)
+}
+
+// Reject godoc
+// @Summary Reject refund
+// @Description Reject a refund waiting for manager approval
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Adjustment ID"
+// @Success 200 {object} models.OrderAdjustment
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/adjustments/{id}/reject [post]
+func (h *AdjustmentHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid adjustment ID", http.StatusBadRequest)
+		return
+	}
+
+	adjustment, err := h.adjustmentService.Reject(r.Context(), id, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adjustment)
+}
+
+// Report godoc
+// @Summary Adjustments report
+// @Description Summarize voids and refunds of a restaurant by type and reason code
+// @Tags adjustments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param from query string false "Start (RFC 3339 or YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "End (RFC 3339 or YYYY-MM-DD), defaults to now"
+// @Success 200 {object} models.AdjustmentReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/reports/adjustments [get]
+func (h *AdjustmentHandler) Report(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.adjustmentService.Report(r.Context(), restaurantID, from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
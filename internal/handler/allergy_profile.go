@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+)
+
+type AllergyProfileHandler struct {
+	dietaryService *service.DietaryService
+}
+
+func NewAllergyProfileHandler(dietaryService *service.DietaryService) *AllergyProfileHandler {
+	return &AllergyProfileHandler{
+		dietaryService: dietaryService,
+	}
+}
+
+// Get godoc
+// @Summary My allergy profile
+// @Description Get the caller's allergens and dietary requirements
+// @Tags dietary
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} models.AllergyProfile
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/users/me/allergy-profile [get]
+func (h *AllergyProfileHandler) Get(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.dietaryService.GetProfile(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// Save godoc
+// @Summary Save my allergy profile
+// @Description Replace the caller's allergens and dietary requirements. Orders with items that contain one of the allergens or lack one of the dietary tags come back with warnings.
+// @Tags dietary
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param profile body models.AllergyProfile true "Allergy profile"
+// @Success 200 {object} models.AllergyProfile
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/users/me/allergy-profile [put]
+func (h *AllergyProfileHandler) Save(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var profile models.AllergyProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	profile.UserID = user.ID
+
+	if err := h.dietaryService.SaveProfile(r.Context(), &profile); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/middleware"
+	"github.com/KNLopez/restaurant-api/internal/models"
+)
+
+// requireUser returns the authenticated caller or writes 401.
+func requireUser(w http.ResponseWriter, r *http.Request) (*models.AuthUser, bool) {
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
+
+// requireStaff returns the authenticated caller if they are restaurant
+// staff, otherwise it writes 401 or 403.
+func requireStaff(w http.ResponseWriter, r *http.Request) (*models.AuthUser, bool) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return nil, false
+	}
+	if !user.IsStaff() {
+		http.Error(w, "Staff access required", http.StatusForbidden)
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,636 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type BrandHandler struct {
+	brandService *service.BrandService
+}
+
+func NewBrandHandler(brandService *service.BrandService) *BrandHandler {
+	return &BrandHandler{
+		brandService: brandService,
+	}
+}
+
+// Create godoc
+// @Summary Create brand
+// @Description Create a restaurant chain owned by the calling manager in one of their organizations. Its locations, which must be restaurants of that organization, share a master menu.
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param brand body models.Brand true "Brand"
+// @Success 201 {object} models.Brand
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands [post]
+func (h *BrandHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	var brand models.Brand
+	if err := json.NewDecoder(r.Body).Decode(&brand); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.brandService.Create(r.Context(), user, &brand); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(brand)
+}
+
+// List godoc
+// @Summary List brands
+// @Description List the brands the caller owns or that belong to their organizations
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.Brand
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands [get]
+func (h *BrandHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	brands, err := h.brandService.List(r.Context(), user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(brands)
+}
+
+// Get godoc
+// @Summary Get brand
+// @Description Get a brand by ID
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path string true "Brand ID"
+// @Success 200 {object} models.Brand
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id} [get]
+func (h *BrandHandler) Get(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid brand ID", http.StatusBadRequest)
+		return
+	}
+
+	brand, err := h.brandService.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(brand)
+}
+
+// Update godoc
+// @Summary Update brand
+// @Description Rename a brand
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Brand ID"
+// @Param brand body models.Brand true "Brand"
+// @Success 200 {object} models.Brand
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id} [put]
+func (h *BrandHandler) Update(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid brand ID", http.StatusBadRequest)
+		return
+	}
+
+	var brand models.Brand
+	if err := json.NewDecoder(r.Body).Decode(&brand); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	brand.ID = id
+
+	if err := h.brandService.Update(r.Context(), user, &brand); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(brand)
+}
+
+// ListLocations godoc
+// @Summary List brand locations
+// @Description List the restaurants that are locations of a brand
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path string true "Brand ID"
+// @Success 200 {array} models.Restaurant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id}/restaurants [get]
+func (h *BrandHandler) ListLocations(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(r.URL.Path, "/")
+	brandID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid brand ID", http.StatusBadRequest)
+		return
+	}
+
+	restaurants, err := h.brandService.ListLocations(r.Context(), brandID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(restaurants)
+}
+
+// AddLocation godoc
+// @Summary Add brand location
+// @Description Make a restaurant the caller manages a location of the brand. Its menu follows the master menu from the next publish on; existing items with the same SKU as a master item are taken over.
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Brand ID"
+// @Param restaurant_id path string true "Restaurant ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id}/restaurants/{restaurant_id} [put]
+func (h *BrandHandler) AddLocation(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	brandID, restaurantID, ok := parseBrandSubPath(w, r, "restaurant")
+	if !ok {
+		return
+	}
+
+	if err := h.brandService.AddLocation(r.Context(), user, brandID, restaurantID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveLocation godoc
+// @Summary Remove brand location
+// @Description Make a location an independent restaurant again. It keeps its menu items, which stop following the master menu.
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Brand ID"
+// @Param restaurant_id path string true "Restaurant ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id}/restaurants/{restaurant_id} [delete]
+func (h *BrandHandler) RemoveLocation(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	brandID, restaurantID, ok := parseBrandSubPath(w, r, "restaurant")
+	if !ok {
+		return
+	}
+
+	if err := h.brandService.RemoveLocation(r.Context(), user, brandID, restaurantID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListItems godoc
+// @Summary List master menu
+// @Description List the items of a brand's master menu
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Brand ID"
+// @Success 200 {array} models.BrandMenuItem
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id}/menu-items [get]
+func (h *BrandHandler) ListItems(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	brandID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid brand ID", http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.brandService.ListItems(r.Context(), brandID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// CreateItem godoc
+// @Summary Create master menu item
+// @Description Add an item to a brand's master menu. Locations get it when the master menu is next published.
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Brand ID"
+// @Param item body models.BrandMenuItem true "Master menu item"
+// @Success 201 {object} models.BrandMenuItem
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id}/menu-items [post]
+func (h *BrandHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	brandID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid brand ID", http.StatusBadRequest)
+		return
+	}
+
+	var item models.BrandMenuItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	item.BrandID = brandID
+	item.DeletedAt = nil
+
+	if err := h.brandService.CreateItem(r.Context(), user, &item); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+// UpdateItem godoc
+// @Summary Update master menu item
+// @Description Replace a master menu item. Locations get the change when the master menu is next published.
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Brand ID"
+// @Param item_id path string true "Master menu item ID"
+// @Param item body models.BrandMenuItem true "Master menu item"
+// @Success 200 {object} models.BrandMenuItem
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id}/menu-items/{item_id} [put]
+func (h *BrandHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	brandID, itemID, ok := parseBrandSubPath(w, r, "menu item")
+	if !ok {
+		return
+	}
+
+	var item models.BrandMenuItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	item.ID = itemID
+	item.BrandID = brandID
+	item.DeletedAt = nil
+
+	if err := h.brandService.UpdateItem(r.Context(), user, &item); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// DeleteItem godoc
+// @Summary Delete master menu item
+// @Description Take an item off a brand's master menu. Locations keep it until the master menu is next published.
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Brand ID"
+// @Param item_id path string true "Master menu item ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id}/menu-items/{item_id} [delete]
+func (h *BrandHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	brandID, itemID, ok := parseBrandSubPath(w, r, "menu item")
+	if !ok {
+		return
+	}
+
+	if err := h.brandService.DeleteItem(r.Context(), user, brandID, itemID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Preview godoc
+// @Summary Preview master menu publish
+// @Description Show the items publishing the master menu would create, update or remove at each location, without changing anything
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Brand ID"
+// @Success 200 {object} models.BrandMenuDiff
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id}/publish [get]
+func (h *BrandHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	h.publish(w, r, h.brandService.Preview)
+}
+
+// Publish godoc
+// @Summary Publish master menu
+// @Description Copy the master menu to every location in one transaction, keeping each location's price and availability overrides, and return what changed. Removed items that have been ordered are taken off sale instead of deleted.
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Brand ID"
+// @Success 200 {object} models.BrandMenuDiff
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/brands/{id}/publish [post]
+func (h *BrandHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	h.publish(w, r, h.brandService.Publish)
+}
+
+func (h *BrandHandler) publish(w http.ResponseWriter, r *http.Request, run func(ctx context.Context, user *models.AuthUser, brandID uuid.UUID) (*models.BrandMenuDiff, error)) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	brandID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid brand ID", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := run(r.Context(), user, brandID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// ListOverrides godoc
+// @Summary List brand menu overrides
+// @Description List a location's own prices and availability for master menu items
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Success 200 {array} models.BrandMenuOverride
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/brand-overrides [get]
+func (h *BrandHandler) ListOverrides(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	overrides, err := h.brandService.ListOverrides(r.Context(), restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(overrides)
+}
+
+// SetOverride godoc
+// @Summary Set brand menu override
+// @Description Give a location its own price or availability for a master menu item. It applies right away and is kept by later publishes.
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param brand_item_id path string true "Master menu item ID"
+// @Param override body models.BrandMenuOverride true "Override"
+// @Success 200 {object} models.BrandMenuOverride
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/brand-overrides/{brand_item_id} [put]
+func (h *BrandHandler) SetOverride(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	restaurantID, brandItemID, ok := parseOverridePath(w, r)
+	if !ok {
+		return
+	}
+
+	var override models.BrandMenuOverride
+	if err := json.NewDecoder(r.Body).Decode(&override); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	override.RestaurantID = restaurantID
+	override.BrandItemID = brandItemID
+
+	if err := h.brandService.SetOverride(r.Context(), user, &override); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(override)
+}
+
+// DeleteOverride godoc
+// @Summary Delete brand menu override
+// @Description Make a location follow the master menu price again
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param brand_item_id path string true "Master menu item ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/brand-overrides/{brand_item_id} [delete]
+func (h *BrandHandler) DeleteOverride(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	restaurantID, brandItemID, ok := parseOverridePath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.brandService.DeleteOverride(r.Context(), user, restaurantID, brandItemID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseBrandSubPath reads the brand ID and the ID of a nested resource
+// from /brands/{id}/{resource}/{sub_id}.
+func parseBrandSubPath(w http.ResponseWriter, r *http.Request, resource string) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	brandID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid brand ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid "+resource+" ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return brandID, id, true
+}
+
+// parseOverridePath reads the restaurant and master item IDs from
+// /restaurants/{id}/brand-overrides/{brand_item_id}.
+func parseOverridePath(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	brandItemID, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid brand item ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return restaurantID, brandItemID, true
+}
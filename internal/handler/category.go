@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type CategoryHandler struct {
+	categoryService *service.CategoryService
+}
+
+func NewCategoryHandler(categoryService *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// Create godoc
+// @Summary Create category
+// @Description Create a menu category with optional translations of its name and description keyed by locale
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param category body models.Category true "Category"
+// @Success 201 {object} models.Category
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/categories [post]
+func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	var category models.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	category.RestaurantID = restaurantID
+
+	if err := h.categoryService.Create(r.Context(), &category); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(category)
+}
+
+// List godoc
+// @Summary List categories
+// @Description List the menu categories of a restaurant in the requested locale
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param lang query string false "Locale to show names and descriptions in, overriding Accept-Language"
+// @Success 200 {array} models.Category
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/categories [get]
+func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	categories, err := h.categoryService.List(r.Context(), restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	locales := requestLocales(r)
+	for _, category := range categories {
+		category.Localize(locales)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(categories)
+}
+
+// Get godoc
+// @Summary Get category
+// @Description Get a menu category of a restaurant in the requested locale
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param category_id path string true "Category ID"
+// @Param lang query string false "Locale to show the name and description in, overriding Accept-Language"
+// @Success 200 {object} models.Category
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/categories/{category_id} [get]
+func (h *CategoryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	restaurantID, categoryID, ok := parseCategoryPath(w, r)
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryService.Get(r.Context(), restaurantID, categoryID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	category.Localize(requestLocales(r))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+// Update godoc
+// @Summary Update category
+// @Description Replace a category's name, description and translations
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param category_id path string true "Category ID"
+// @Param category body models.Category true "Category"
+// @Success 200 {object} models.Category
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/categories/{category_id} [put]
+func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, categoryID, ok := parseCategoryPath(w, r)
+	if !ok {
+		return
+	}
+
+	var category models.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	category.ID = categoryID
+	category.RestaurantID = restaurantID
+
+	if err := h.categoryService.Update(r.Context(), &category); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+// parseCategoryPath reads the restaurant and category IDs from
+// /restaurants/{id}/categories/{category_id}.
+func parseCategoryPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	categoryID, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return restaurantID, categoryID, true
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type CheckHandler struct {
+	checkService *service.CheckService
+}
+
+func NewCheckHandler(checkService *service.CheckService) *CheckHandler {
+	return &CheckHandler{
+		checkService: checkService,
+	}
+}
+
+// SplitOrder godoc
+// @Summary Split order
+// @Description Split an order into checks by item, by seat, evenly or by custom amounts. Checks are settled by paying them through the payments API.
+// @Tags checks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Param split body models.SplitRequest true "Split definition"
+// @Success 201 {array} models.Check
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/{id}/split [post]
+func (h *CheckHandler) SplitOrder(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	orderID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.SplitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	checks, err := h.checkService.SplitOrder(r.Context(), orderID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(checks)
+}
+
+// SplitTable godoc
+// @Summary Split table bill
+// @Description Split all open orders of a table into checks
+// @Tags checks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param table_id path string true "Table ID"
+// @Param split body models.SplitRequest true "Split definition"
+// @Success 201 {array} models.Check
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/tables/{table_id}/split [post]
+func (h *CheckHandler) SplitTable(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-4])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+	tableID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid table ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.SplitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	checks, err := h.checkService.SplitTable(r.Context(), restaurantID, tableID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(checks)
+}
+
+// ListByOrder godoc
+// @Summary List order checks
+// @Description List the checks an order has been split into
+// @Tags checks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Success 200 {array} models.Check
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/{id}/checks [get]
+func (h *CheckHandler) ListByOrder(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireUser(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	orderID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	checks, err := h.checkService.ListByOrder(r.Context(), orderID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(checks)
+}
+
+// Get godoc
+// @Summary Get check
+// @Description Get check details by ID
+// @Tags checks
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Check ID"
+// @Success 200 {object} models.Check
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/checks/{id} [get]
+func (h *CheckHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireUser(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid check ID", http.StatusBadRequest)
+		return
+	}
+
+	check, err := h.checkService.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if check == nil {
+		http.Error(w, "Check not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(check)
+}
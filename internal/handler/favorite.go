@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type FavoriteHandler struct {
+	favoriteService *service.FavoriteService
+}
+
+func NewFavoriteHandler(favoriteService *service.FavoriteService) *FavoriteHandler {
+	return &FavoriteHandler{
+		favoriteService: favoriteService,
+	}
+}
+
+// List godoc
+// @Summary My favorites
+// @Description List the caller's favorite menu items
+// @Tags favorites
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.Favorite
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/users/me/favorites [get]
+func (h *FavoriteHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	favorites, err := h.favoriteService.List(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if favorites == nil {
+		favorites = []*models.Favorite{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(favorites)
+}
+
+// Add godoc
+// @Summary Add favorite
+// @Description Save a menu item as a favorite
+// @Tags favorites
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param favorite body models.Favorite true "Favorite with menu_item_id"
+// @Success 201 {object} models.Favorite
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/users/me/favorites [post]
+func (h *FavoriteHandler) Add(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.Favorite
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	favorite, err := h.favoriteService.Add(r.Context(), user.ID, req.MenuItemID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(favorite)
+}
+
+// Remove godoc
+// @Summary Remove favorite
+// @Description Remove a menu item from the caller's favorites
+// @Tags favorites
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param menu_item_id path string true "Menu item ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/users/me/favorites/{menu_item_id} [delete]
+func (h *FavoriteHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	menuItemID, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid menu item ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.favoriteService.Remove(r.Context(), user.ID, menuItemID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
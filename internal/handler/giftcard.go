@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+)
+
+type GiftCardHandler struct {
+	giftCardService *service.GiftCardService
+}
+
+func NewGiftCardHandler(giftCardService *service.GiftCardService) *GiftCardHandler {
+	return &GiftCardHandler{
+		giftCardService: giftCardService,
+	}
+}
+
+// Issue godoc
+// @Summary Issue gift card
+// @Description Sell a new gift card with a generated code, redeemable within the selling restaurant's organization or brand, or anywhere if it has no restaurant
+// @Tags gift-cards
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param card body models.GiftCardRequest true "Initial amount and selling restaurant"
+// @Success 201 {object} models.GiftCard
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/gift-cards [post]
+func (h *GiftCardHandler) Issue(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.GiftCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	card, err := h.giftCardService.Issue(r.Context(), req, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(card)
+}
+
+// Balance godoc
+// @Summary Gift card balance
+// @Description Look up the remaining balance of a gift card
+// @Tags gift-cards
+// @Accept json
+// @Produce json
+// @Param code path string true "Gift card code"
+// @Success 200 {object} models.GiftCardBalance
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/gift-cards/{code}/balance [get]
+func (h *GiftCardHandler) Balance(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(r.URL.Path, "/")
+	code := path[len(path)-2]
+
+	balance, err := h.giftCardService.Balance(r.Context(), code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
+
+// Statement godoc
+// @Summary Gift card statement
+// @Description Get a gift card with its full transaction ledger and reconciliation against the balance
+// @Tags gift-cards
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param code path string true "Gift card code"
+// @Success 200 {object} models.GiftCardStatement
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/gift-cards/{code} [get]
+func (h *GiftCardHandler) Statement(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	code := path[len(path)-1]
+
+	statement, err := h.giftCardService.Statement(r.Context(), code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statement)
+}
+
+// Load godoc
+// @Summary Load gift card
+// @Description Add value to an existing gift card
+// @Tags gift-cards
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param code path string true "Gift card code"
+// @Param load body models.GiftCardRequest true "Amount to add"
+// @Success 201 {object} models.GiftCardTransaction
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/gift-cards/{code}/load [post]
+func (h *GiftCardHandler) Load(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	code := path[len(path)-2]
+
+	var req models.GiftCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.giftCardService.Load(r.Context(), code, req, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type HoursHandler struct {
+	hoursService *service.HoursService
+}
+
+func NewHoursHandler(hoursService *service.HoursService) *HoursHandler {
+	return &HoursHandler{
+		hoursService: hoursService,
+	}
+}
+
+// Get godoc
+// @Summary Get opening hours
+// @Description Get the weekly opening hours of a restaurant and its holiday and special-closure exceptions, in the restaurant's timezone. A restaurant without hours is always open.
+// @Tags hours
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Success 200 {object} models.OpeningHours
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/hours [get]
+func (h *HoursHandler) Get(w http.ResponseWriter, r *http.Request) {
+	restaurantID, ok := parseHoursPath(w, r)
+	if !ok {
+		return
+	}
+
+	hours, err := h.hoursService.Get(r.Context(), restaurantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hours)
+}
+
+// Update godoc
+// @Summary Set opening hours
+// @Description Replace the opening hours of a restaurant. A day may have several intervals; one that closes at or before it opens, such as 18:00-02:00, runs past midnight. An exception replaces the weekly hours on its date, and one without intervals closes the restaurant for the day.
+// @Tags hours
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param hours body models.OpeningHours true "Opening hours"
+// @Success 200 {object} models.OpeningHours
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/hours [put]
+func (h *HoursHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, ok := parseHoursPath(w, r)
+	if !ok {
+		return
+	}
+
+	var hours models.OpeningHours
+	if err := json.NewDecoder(r.Body).Decode(&hours); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	hours.RestaurantID = restaurantID
+
+	if err := h.hoursService.Save(r.Context(), &hours); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hours)
+}
+
+func parseHoursPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return restaurantID, true
+}
@@ -0,0 +1,403 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type InventoryHandler struct {
+	inventoryService *service.InventoryService
+}
+
+func NewInventoryHandler(inventoryService *service.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+// CreateIngredient godoc
+// @Summary Create ingredient
+// @Description Add an ingredient to a restaurant's inventory; any starting stock is recorded as an opening count
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param ingredient body models.Ingredient true "Ingredient"
+// @Success 201 {object} models.Ingredient
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/ingredients [post]
+func (h *InventoryHandler) CreateIngredient(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	var ingredient models.Ingredient
+	if err := json.NewDecoder(r.Body).Decode(&ingredient); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ingredient.RestaurantID = restaurantID
+
+	if err := h.inventoryService.CreateIngredient(r.Context(), &ingredient, user); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ingredient)
+}
+
+// ListIngredients godoc
+// @Summary List ingredients
+// @Description List the ingredients of a restaurant with their current stock
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param low_stock query bool false "Only ingredients at or below their low stock threshold"
+// @Success 200 {array} models.Ingredient
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/ingredients [get]
+func (h *InventoryHandler) ListIngredients(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	ingredients, err := h.inventoryService.ListIngredients(r.Context(), restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("low_stock") == "true" {
+		low := []*models.Ingredient{}
+		for _, ingredient := range ingredients {
+			if ingredient.LowStock() {
+				low = append(low, ingredient)
+			}
+		}
+		ingredients = low
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ingredients)
+}
+
+// GetIngredient godoc
+// @Summary Get ingredient
+// @Description Get an ingredient with its current stock
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param ingredient_id path string true "Ingredient ID"
+// @Success 200 {object} models.Ingredient
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/ingredients/{ingredient_id} [get]
+func (h *InventoryHandler) GetIngredient(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, ingredientID, ok := parseIngredientPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	ingredient, err := h.inventoryService.GetIngredient(r.Context(), restaurantID, ingredientID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ingredient)
+}
+
+// UpdateIngredient godoc
+// @Summary Update ingredient
+// @Description Update the name, unit and low stock threshold of an ingredient; stock changes go through adjustments
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param ingredient_id path string true "Ingredient ID"
+// @Param ingredient body models.Ingredient true "Ingredient"
+// @Success 200 {object} models.Ingredient
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/ingredients/{ingredient_id} [put]
+func (h *InventoryHandler) UpdateIngredient(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, ingredientID, ok := parseIngredientPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	var ingredient models.Ingredient
+	if err := json.NewDecoder(r.Body).Decode(&ingredient); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ingredient.ID = ingredientID
+	ingredient.RestaurantID = restaurantID
+
+	if err := h.inventoryService.UpdateIngredient(r.Context(), &ingredient); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ingredient)
+}
+
+// DeleteIngredient godoc
+// @Summary Delete ingredient
+// @Description Delete an ingredient together with its recipe lines and stock history
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param ingredient_id path string true "Ingredient ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/ingredients/{ingredient_id} [delete]
+func (h *InventoryHandler) DeleteIngredient(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, ingredientID, ok := parseIngredientPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	if err := h.inventoryService.DeleteIngredient(r.Context(), restaurantID, ingredientID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListMovements godoc
+// @Summary List stock movements
+// @Description List the stock history of an ingredient, newest first
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param ingredient_id path string true "Ingredient ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of movements to skip"
+// @Success 200 {array} models.StockMovement
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/ingredients/{ingredient_id}/movements [get]
+func (h *InventoryHandler) ListMovements(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, ingredientID, ok := parseIngredientPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	movements, err := h.inventoryService.Movements(r.Context(), restaurantID, ingredientID, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if movements == nil {
+		movements = []*models.StockMovement{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movements)
+}
+
+// Adjust godoc
+// @Summary Adjust stock
+// @Description Record a delivery, waste or stock count for an ingredient. Deliveries and waste take the quantity moved, a count takes the quantity on the shelf.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param ingredient_id path string true "Ingredient ID"
+// @Param adjustment body models.StockAdjustmentRequest true "Stock adjustment"
+// @Success 201 {object} models.StockMovement
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/ingredients/{ingredient_id}/adjustments [post]
+func (h *InventoryHandler) Adjust(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	restaurantID, ingredientID, ok := parseIngredientPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	movement, err := h.inventoryService.Adjust(r.Context(), restaurantID, ingredientID, req, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
+
+// GetRecipe godoc
+// @Summary Get recipe
+// @Description Get the ingredients used by one portion of a menu item
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param item_id path string true "Menu item ID"
+// @Success 200 {object} models.Recipe
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-items/{item_id}/recipe [get]
+func (h *InventoryHandler) GetRecipe(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	recipe, err := h.inventoryService.GetRecipe(r.Context(), restaurantID, menuItemID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recipe)
+}
+
+// SetRecipe godoc
+// @Summary Set recipe
+// @Description Replace the recipe of a menu item. Lines with a customization only apply when the guest picks that option.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param item_id path string true "Menu item ID"
+// @Param recipe body models.Recipe true "Recipe"
+// @Success 200 {object} models.Recipe
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-items/{item_id}/recipe [put]
+func (h *InventoryHandler) SetRecipe(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	var recipe models.Recipe
+	if err := json.NewDecoder(r.Body).Decode(&recipe); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	recipe.MenuItemID = menuItemID
+
+	if err := h.inventoryService.SetRecipe(r.Context(), restaurantID, &recipe); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recipe)
+}
+
+// parseIngredientPath reads the restaurant and ingredient IDs from
+// /restaurants/{id}/ingredients/{ingredient_id}[/...], where the
+// ingredient ID is the given number of segments from the end.
+func parseIngredientPath(w http.ResponseWriter, r *http.Request, fromEnd int) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-fromEnd-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	ingredientID, err := uuid.Parse(path[len(path)-fromEnd])
+	if err != nil {
+		http.Error(w, "Invalid ingredient ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return restaurantID, ingredientID, true
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type LoyaltyHandler struct {
+	loyaltyService *service.LoyaltyService
+}
+
+func NewLoyaltyHandler(loyaltyService *service.LoyaltyService) *LoyaltyHandler {
+	return &LoyaltyHandler{
+		loyaltyService: loyaltyService,
+	}
+}
+
+// Account godoc
+// @Summary My loyalty account
+// @Description Get the caller's loyalty balance, tier and points about to expire
+// @Tags loyalty
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} models.LoyaltyAccount
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/users/me/loyalty [get]
+func (h *LoyaltyHandler) Account(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	account, err := h.loyaltyService.Account(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
+
+// History godoc
+// @Summary My loyalty history
+// @Description List the caller's loyalty transactions, newest first
+// @Tags loyalty
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of entries to skip"
+// @Success 200 {array} models.LoyaltyTransaction
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/users/me/loyalty/transactions [get]
+func (h *LoyaltyHandler) History(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.loyaltyService.History(r.Context(), user.ID, limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// GetProgram godoc
+// @Summary Get restaurant loyalty program
+// @Description Get the loyalty program in effect at a restaurant, falling back to the chain-wide program
+// @Tags loyalty
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Success 200 {object} models.LoyaltyProgram
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/loyalty-program [get]
+func (h *LoyaltyHandler) GetProgram(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	program, err := h.loyaltyService.Program(r.Context(), restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(program)
+}
+
+// SaveProgram godoc
+// @Summary Configure restaurant loyalty program
+// @Description Set the earning rate, point value and expiry for a restaurant
+// @Tags loyalty
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param program body models.LoyaltyProgram true "Loyalty program"
+// @Success 200 {object} models.LoyaltyProgram
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/loyalty-program [put]
+func (h *LoyaltyHandler) SaveProgram(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	var program models.LoyaltyProgram
+	if err := json.NewDecoder(r.Body).Decode(&program); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	program.RestaurantID = &restaurantID
+
+	if err := h.loyaltyService.SaveProgram(r.Context(), &program); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(program)
+}
+
+// GetChainProgram godoc
+// @Summary Get chain loyalty program
+// @Description Get the chain-wide loyalty program used by restaurants without their own
+// @Tags loyalty
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.LoyaltyProgram
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/loyalty/program [get]
+func (h *LoyaltyHandler) GetChainProgram(w http.ResponseWriter, r *http.Request) {
+	program, err := h.loyaltyService.ChainProgram(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(program)
+}
+
+// SaveChainProgram godoc
+// @Summary Configure chain loyalty program
+// @Description Set the chain-wide earning rate, point value and expiry
+// @Tags loyalty
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param program body models.LoyaltyProgram true "Loyalty program"
+// @Success 200 {object} models.LoyaltyProgram
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/loyalty/program [put]
+func (h *LoyaltyHandler) SaveChainProgram(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	if user.Role != models.RoleAdmin {
+		http.Error(w, "Admin access required", http.StatusForbidden)
+		return
+	}
+
+	var program models.LoyaltyProgram
+	if err := json.NewDecoder(r.Body).Decode(&program); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	program.RestaurantID = nil
+
+	if err := h.loyaltyService.SaveProgram(r.Context(), &program); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(program)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/KNLopez/restaurant-api/internal/models"
 	"github.com/KNLopez/restaurant-api/internal/service"
@@ -29,13 +30,20 @@ func NewMenuHandler(menuService *service.MenuService, cloudinary *utils.Cloudina
 // @Tags menu
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param restaurant_id path string true "Restaurant ID"
 // @Param item body models.MenuItem true "Menu item object"
 // @Success 201 {object} models.MenuItem
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants/{restaurant_id}/menu-items [post]
 func (h *MenuHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
 	// Parse multipart form
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
 		http.Error(w, "Failed to parse form", http.StatusBadRequest)
@@ -78,7 +86,7 @@ func (h *MenuHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.menuService.Create(r.Context(), &item); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -95,6 +103,7 @@ func (h *MenuHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param restaurant_id path string true "Restaurant ID"
 // @Param id path string true "Menu Item ID"
+// @Param lang query string false "Locale to show the name and description in, overriding Accept-Language"
 // @Success 200 {object} models.MenuItem
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
@@ -116,6 +125,7 @@ func (h *MenuHandler) Get(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Menu item not found", http.StatusNotFound)
 		return
 	}
+	item.Localize(requestLocales(r))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(item)
@@ -123,12 +133,17 @@ func (h *MenuHandler) Get(w http.ResponseWriter, r *http.Request) {
 
 // List godoc
 // @Summary List menu items
-// @Description List all menu items for a restaurant
+// @Description List the menu items of a restaurant that are on a menu being served at the given time; sold-out items are included with available set to false
 // @Tags menu
 // @Accept json
 // @Produce json
 // @Param restaurant_id path string true "Restaurant ID"
+// @Param at query string false "Preview the menu at this time (RFC 3339), defaults to now"
+// @Param dietary query string false "Comma-separated dietary tags every item must carry, e.g. vegan,gluten_free"
+// @Param exclude_allergens query string false "Comma-separated allergens to leave out, e.g. peanuts,milk"
+// @Param lang query string false "Locale to show names and descriptions in, overriding Accept-Language"
 // @Success 200 {array} models.MenuItem
+// @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants/{restaurant_id}/menu-items [get]
@@ -140,11 +155,29 @@ func (h *MenuHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	items, err := h.menuService.List(r.Context(), restaurantID)
+	at, err := parseTimeParam(r, "at")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	filter := models.MenuFilter{
+		DietaryTags:      splitParam[models.DietaryTag](r, "dietary"),
+		ExcludeAllergens: splitParam[models.Allergen](r, "exclude_allergens"),
+	}
+
+	items, err := h.menuService.ListOrderable(r.Context(), restaurantID, filter, at)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	locales := requestLocales(r)
+	for _, item := range items {
+		item.Localize(locales)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(items)
@@ -156,15 +189,22 @@ func (h *MenuHandler) List(w http.ResponseWriter, r *http.Request) {
 // @Tags menu
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param restaurant_id path string true "Restaurant ID"
 // @Param id path string true "Menu Item ID"
 // @Param item body models.MenuItem true "Menu item object"
 // @Success 200 {object} models.MenuItem
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id} [put]
 func (h *MenuHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-1])
 	if err != nil {
@@ -188,7 +228,7 @@ func (h *MenuHandler) Update(w http.ResponseWriter, r *http.Request) {
 	item.RestaurantID = restaurantID
 
 	if err := h.menuService.Update(r.Context(), &item); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -202,13 +242,20 @@ func (h *MenuHandler) Update(w http.ResponseWriter, r *http.Request) {
 // @Tags menu
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param restaurant_id path string true "Restaurant ID"
 // @Param id path string true "Menu Item ID"
 // @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id} [delete]
 func (h *MenuHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-1])
 	if err != nil {
@@ -223,3 +270,420 @@ func (h *MenuHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// SetAvailability godoc
+// @Summary Set menu item availability
+// @Description Mark a menu item as sold out or available again, optionally with a number of portions left. The item is marked sold out automatically when its portions run out.
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param id path string true "Menu Item ID"
+// @Param availability body models.MenuItemAvailability true "Availability"
+// @Success 200 {object} models.MenuItem
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id}/availability [put]
+func (h *MenuHandler) SetAvailability(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	var req models.MenuItemAvailability
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.menuService.SetAvailability(r.Context(), restaurantID, menuItemID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// ListCustomizations godoc
+// @Summary List customizations
+// @Description List the customizations guests can choose for a menu item
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param id path string true "Menu Item ID"
+// @Success 200 {array} models.MenuItemCustomization
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id}/customizations [get]
+func (h *MenuHandler) ListCustomizations(w http.ResponseWriter, r *http.Request) {
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	customizations, err := h.menuService.ListCustomizations(r.Context(), restaurantID, menuItemID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if customizations == nil {
+		customizations = []*models.MenuItemCustomization{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customizations)
+}
+
+// CreateCustomization godoc
+// @Summary Create customization
+// @Description Add a customization to a menu item. Select options may carry a price that is added to the item price.
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param id path string true "Menu Item ID"
+// @Param customization body models.MenuItemCustomization true "Customization"
+// @Success 201 {object} models.MenuItemCustomization
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id}/customizations [post]
+func (h *MenuHandler) CreateCustomization(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	var customization models.MenuItemCustomization
+	if err := json.NewDecoder(r.Body).Decode(&customization); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	customization.MenuItemID = menuItemID
+
+	if err := h.menuService.CreateCustomization(r.Context(), restaurantID, &customization); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(customization)
+}
+
+// DeleteCustomization godoc
+// @Summary Delete customization
+// @Description Delete a customization of a menu item together with its recipe lines
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param id path string true "Menu Item ID"
+// @Param customization_id path string true "Customization ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id}/customizations/{customization_id} [delete]
+func (h *MenuHandler) DeleteCustomization(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 3)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	customizationID, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid customization ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.menuService.DeleteCustomization(r.Context(), restaurantID, menuItemID, customizationID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListVariants godoc
+// @Summary List variants
+// @Description List the variants of a menu item, such as its sizes
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param id path string true "Menu Item ID"
+// @Success 200 {array} models.MenuItemVariant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id}/variants [get]
+func (h *MenuHandler) ListVariants(w http.ResponseWriter, r *http.Request) {
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	variants, err := h.menuService.ListVariants(r.Context(), restaurantID, menuItemID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if variants == nil {
+		variants = []*models.MenuItemVariant{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(variants)
+}
+
+// CreateVariant godoc
+// @Summary Create variant
+// @Description Add a variant with its own price, SKU and availability to a menu item. Once an item has variants, orders must choose one.
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param id path string true "Menu Item ID"
+// @Param variant body models.MenuItemVariant true "Variant"
+// @Success 201 {object} models.MenuItemVariant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id}/variants [post]
+func (h *MenuHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	var variant models.MenuItemVariant
+	if err := json.NewDecoder(r.Body).Decode(&variant); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	variant.MenuItemID = menuItemID
+
+	if err := h.menuService.CreateVariant(r.Context(), restaurantID, &variant); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(variant)
+}
+
+// UpdateVariant godoc
+// @Summary Update variant
+// @Description Update the name, price, SKU, availability or position of a variant
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param id path string true "Menu Item ID"
+// @Param variant_id path string true "Variant ID"
+// @Param variant body models.MenuItemVariant true "Variant"
+// @Success 200 {object} models.MenuItemVariant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id}/variants/{variant_id} [put]
+func (h *MenuHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuItemID, variantID, ok := parseVariantPath(w, r)
+	if !ok {
+		return
+	}
+
+	var variant models.MenuItemVariant
+	if err := json.NewDecoder(r.Body).Decode(&variant); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	variant.ID = variantID
+	variant.MenuItemID = menuItemID
+
+	if err := h.menuService.UpdateVariant(r.Context(), restaurantID, &variant); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(variant)
+}
+
+// DeleteVariant godoc
+// @Summary Delete variant
+// @Description Remove a variant from a menu item; past orders keep the variant name
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param id path string true "Menu Item ID"
+// @Param variant_id path string true "Variant ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id}/variants/{variant_id} [delete]
+func (h *MenuHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuItemID, variantID, ok := parseVariantPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.menuService.DeleteVariant(r.Context(), restaurantID, menuItemID, variantID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBundle godoc
+// @Summary Get bundle
+// @Description Get the choice groups of a combo menu item; plain items have no groups
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param id path string true "Menu Item ID"
+// @Success 200 {object} models.Bundle
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id}/bundle [get]
+func (h *MenuHandler) GetBundle(w http.ResponseWriter, r *http.Request) {
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	bundle, err := h.menuService.GetBundle(r.Context(), restaurantID, menuItemID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bundle)
+}
+
+// SetBundle godoc
+// @Summary Set bundle
+// @Description Replace the choice groups of a menu item, making it a combo sold at its menu price plus the upcharges of the chosen components. Send no groups to make it a plain item again.
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param id path string true "Menu Item ID"
+// @Param bundle body models.Bundle true "Bundle"
+// @Success 200 {object} models.Bundle
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/{id}/bundle [put]
+func (h *MenuHandler) SetBundle(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	var bundle models.Bundle
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bundle.MenuItemID = menuItemID
+
+	if err := h.menuService.SetBundle(r.Context(), restaurantID, &bundle); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bundle)
+}
+
+// parseVariantPath reads the restaurant, menu item and variant IDs from
+// /restaurants/{id}/menu-items/{item_id}/variants/{variant_id}.
+func parseVariantPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	restaurantID, menuItemID, ok := parseMenuItemSubPath(w, r, 3)
+	if !ok {
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	variantID, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid variant ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	return restaurantID, menuItemID, variantID, true
+}
+
+// parseMenuItemSubPath reads the restaurant and menu item IDs from
+// /restaurants/{id}/menu-items/{item_id}/..., where the menu item ID is
+// the given number of segments from the end.
+func parseMenuItemSubPath(w http.ResponseWriter, r *http.Request, fromEnd int) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-fromEnd-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	menuItemID, err := uuid.Parse(path[len(path)-fromEnd])
+	if err != nil {
+		http.Error(w, "Invalid menu item ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return restaurantID, menuItemID, true
+}
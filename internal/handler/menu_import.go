@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+const maxImportSize = 10 << 20 // 10 MB
+
+type MenuImportHandler struct {
+	importService *service.MenuImportService
+}
+
+func NewMenuImportHandler(importService *service.MenuImportService) *MenuImportHandler {
+	return &MenuImportHandler{
+		importService: importService,
+	}
+}
+
+// Import godoc
+// @Summary Import menu
+// @Description Create or update categories, menu items, prices and customizations in bulk from CSV or JSON. Items are matched by SKU and categories by name. Every row is validated first and nothing is changed if any row is invalid. CSV columns are sku, category, name, description, price, allergens and dietary_tags separated by "|", and customizations as a JSON array; an empty customizations cell leaves them unchanged.
+// @Tags menu
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param format query string false "csv or json, defaults to the Content-Type"
+// @Param dry_run query bool false "Validate and report what would change without changing anything"
+// @Param menu body models.MenuImport true "Menu"
+// @Success 200 {object} models.MenuImportResult
+// @Failure 400 {object} models.MenuImportResult
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-items/import [post]
+func (h *MenuImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "Invalid dry_run", http.StatusBadRequest)
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	var result *models.MenuImportResult
+	switch menuFormat(r) {
+	case "csv":
+		result, err = h.importService.ImportCSV(r.Context(), restaurantID, body, dryRun)
+	case "json":
+		var menu models.MenuImport
+		if err := json.NewDecoder(body).Decode(&menu); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err = h.importService.ImportJSON(r.Context(), restaurantID, &menu, dryRun)
+	default:
+		http.Error(w, "Unsupported format: expected csv or json", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(result.Errors) > 0 && !dryRun {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// Export godoc
+// @Summary Export menu
+// @Description Export the categories, menu items, prices and customizations of a restaurant as CSV or JSON in the import format, to edit in a spreadsheet or copy to another restaurant
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Produce text/csv
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param format query string false "csv or json, defaults to json"
+// @Success 200 {object} models.MenuImport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-items/export [get]
+func (h *MenuImportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "csv" && format != "json" {
+		http.Error(w, "Unsupported format: expected csv or json", http.StatusBadRequest)
+		return
+	}
+
+	menu, err := h.importService.Export(r.Context(), restaurantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="menu.csv"`)
+		service.WriteMenuCSV(w, menu)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(menu)
+}
+
+// menuFormat returns the format of an import from the format query
+// parameter or else the Content-Type, defaulting to JSON.
+func menuFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		return "csv"
+	}
+	return "json"
+}
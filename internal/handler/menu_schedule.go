@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type MenuScheduleHandler struct {
+	scheduleService *service.MenuScheduleService
+}
+
+func NewMenuScheduleHandler(scheduleService *service.MenuScheduleService) *MenuScheduleHandler {
+	return &MenuScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// Create godoc
+// @Summary Create menu
+// @Description Create a named menu such as breakfast or dinner with the day and time windows it is served in, in the restaurant's timezone. Windows may run past midnight. Items attached to a menu, directly or through their category, can only be ordered while one of their menus is served.
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param menu body models.Menu true "Menu"
+// @Success 201 {object} models.Menu
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menus [post]
+func (h *MenuScheduleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	var menu models.Menu
+	if err := json.NewDecoder(r.Body).Decode(&menu); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	menu.RestaurantID = restaurantID
+
+	if err := h.scheduleService.Create(r.Context(), &menu); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(menu)
+}
+
+// List godoc
+// @Summary List menus
+// @Description List the menus of a restaurant with their serving windows
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Success 200 {array} models.Menu
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menus [get]
+func (h *MenuScheduleHandler) List(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	menus, err := h.scheduleService.List(r.Context(), restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(menus)
+}
+
+// Get godoc
+// @Summary Get menu
+// @Description Get a menu of a restaurant
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param menu_id path string true "Menu ID"
+// @Success 200 {object} models.Menu
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menus/{menu_id} [get]
+func (h *MenuScheduleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	restaurantID, menuID, ok := parseMenuPath(w, r)
+	if !ok {
+		return
+	}
+
+	menu, err := h.scheduleService.Get(r.Context(), restaurantID, menuID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(menu)
+}
+
+// Update godoc
+// @Summary Update menu
+// @Description Replace a menu's name, serving windows and attached categories and items
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param menu_id path string true "Menu ID"
+// @Param menu body models.Menu true "Menu"
+// @Success 200 {object} models.Menu
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menus/{menu_id} [put]
+func (h *MenuScheduleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuID, ok := parseMenuPath(w, r)
+	if !ok {
+		return
+	}
+
+	var menu models.Menu
+	if err := json.NewDecoder(r.Body).Decode(&menu); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	menu.ID = menuID
+	menu.RestaurantID = restaurantID
+
+	if err := h.scheduleService.Update(r.Context(), &menu); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(menu)
+}
+
+// Delete godoc
+// @Summary Delete menu
+// @Description Delete a menu. Its items become orderable at all times unless they are on another menu.
+// @Tags menus
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param menu_id path string true "Menu ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menus/{menu_id} [delete]
+func (h *MenuScheduleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, menuID, ok := parseMenuPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.scheduleService.Delete(r.Context(), restaurantID, menuID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseMenuPath reads the restaurant and menu IDs from
+// /restaurants/{id}/menus/{menu_id}.
+func parseMenuPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	menuID, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid menu ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return restaurantID, menuID, true
+}
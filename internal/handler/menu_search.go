@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type MenuSearchHandler struct {
+	searchService *service.MenuSearchService
+}
+
+func NewMenuSearchHandler(searchService *service.MenuSearchService) *MenuSearchHandler {
+	return &MenuSearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search godoc
+// @Summary Search menu items
+// @Description Full-text search over the names, dietary tags and descriptions of a restaurant's menu items, best match first, with matched words wrapped in <mark> tags. If no word matches, items with a similar spelling are returned and fuzzy is set. Facets count every hit before the category, price and dietary filters are applied.
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param restaurant_id path string true "Restaurant ID"
+// @Param q query string true "Search text"
+// @Param category query string false "Comma-separated category IDs"
+// @Param min_price query number false "Lowest price"
+// @Param max_price query number false "Highest price"
+// @Param dietary query string false "Comma-separated dietary tags every item must carry, e.g. vegan,gluten_free"
+// @Param limit query int false "Page size, up to 100"
+// @Param offset query int false "Hits to skip"
+// @Param lang query string false "Locale to show names and descriptions in, overriding Accept-Language"
+// @Success 200 {object} models.MenuSearchResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{restaurant_id}/menu-items/search [get]
+func (h *MenuSearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	query := models.MenuSearchQuery{
+		Text:        r.URL.Query().Get("q"),
+		DietaryTags: splitParam[models.DietaryTag](r, "dietary"),
+	}
+	for _, value := range splitParam[string](r, "category") {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			http.Error(w, "Invalid category ID", http.StatusBadRequest)
+			return
+		}
+		query.CategoryIDs = append(query.CategoryIDs, id)
+	}
+	if query.MinPrice, err = parsePriceParam(r, "min_price"); err != nil {
+		http.Error(w, "Invalid min_price", http.StatusBadRequest)
+		return
+	}
+	if query.MaxPrice, err = parsePriceParam(r, "max_price"); err != nil {
+		http.Error(w, "Invalid max_price", http.StatusBadRequest)
+		return
+	}
+	if query.Limit, query.Offset, err = parsePagination(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.searchService.Search(r.Context(), restaurantID, query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	locales := requestLocales(r)
+	for _, hit := range result.Hits {
+		hit.Item.Localize(locales)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// parsePriceParam reads an optional non-negative price query parameter.
+func parsePriceParam(r *http.Request, name string) (*float64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || price < 0 {
+		return nil, strconv.ErrSyntax
+	}
+	return &price, nil
+}
@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type MenuVersionHandler struct {
+	versionService *service.MenuVersionService
+}
+
+func NewMenuVersionHandler(versionService *service.MenuVersionService) *MenuVersionHandler {
+	return &MenuVersionHandler{
+		versionService: versionService,
+	}
+}
+
+// Create godoc
+// @Summary Create menu draft
+// @Description Open a draft of the menu. Without items the draft starts as a copy of the live menu. A restaurant has one open draft at a time.
+// @Tags menu-versions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param version body models.MenuVersion false "Note and optional items"
+// @Success 201 {object} models.MenuVersion
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-versions [post]
+func (h *MenuVersionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	restaurantID, _, ok := parseMenuVersionPath(w, r, false)
+	if !ok {
+		return
+	}
+
+	var version models.MenuVersion
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&version); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	version.RestaurantID = restaurantID
+
+	if err := h.versionService.CreateDraft(r.Context(), user, &version); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(version)
+}
+
+// List godoc
+// @Summary List menu versions
+// @Description List the menu versions of a restaurant, newest first, without their items
+// @Tags menu-versions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Success 200 {array} models.MenuVersion
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-versions [get]
+func (h *MenuVersionHandler) List(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, _, ok := parseMenuVersionPath(w, r, false)
+	if !ok {
+		return
+	}
+
+	versions, err := h.versionService.List(r.Context(), restaurantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if versions == nil {
+		versions = []*models.MenuVersion{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// Get godoc
+// @Summary Get menu version
+// @Description Get a menu version with its items
+// @Tags menu-versions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param version_id path string true "Menu version ID"
+// @Success 200 {object} models.MenuVersion
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-versions/{version_id} [get]
+func (h *MenuVersionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, versionID, ok := parseMenuVersionPath(w, r, true)
+	if !ok {
+		return
+	}
+
+	version, err := h.versionService.Get(r.Context(), restaurantID, versionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(version)
+}
+
+// Update godoc
+// @Summary Update menu draft
+// @Description Replace the note and items of a draft or scheduled version. The live menu is not affected until the version is published.
+// @Tags menu-versions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param version_id path string true "Menu version ID"
+// @Param version body models.MenuVersion true "Note and items"
+// @Success 200 {object} models.MenuVersion
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-versions/{version_id} [put]
+func (h *MenuVersionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, versionID, ok := parseMenuVersionPath(w, r, true)
+	if !ok {
+		return
+	}
+
+	var version models.MenuVersion
+	if err := json.NewDecoder(r.Body).Decode(&version); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	version.ID = versionID
+	version.RestaurantID = restaurantID
+
+	updated, err := h.versionService.UpdateDraft(r.Context(), &version)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// Delete godoc
+// @Summary Discard menu draft
+// @Description Discard a draft or scheduled version
+// @Tags menu-versions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param version_id path string true "Menu version ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-versions/{version_id} [delete]
+func (h *MenuVersionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, versionID, ok := parseMenuVersionPath(w, r, true)
+	if !ok {
+		return
+	}
+
+	if err := h.versionService.Discard(r.Context(), restaurantID, versionID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Preview godoc
+// @Summary Preview menu version
+// @Description Show the items publishing a version would create, update or remove on the live menu, without changing anything
+// @Tags menu-versions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param version_id path string true "Menu version ID"
+// @Success 200 {object} models.MenuVersionPreview
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-versions/{version_id}/preview [get]
+func (h *MenuVersionHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, versionID, ok := parseMenuVersionPath(w, r, true)
+	if !ok {
+		return
+	}
+
+	preview, err := h.versionService.Preview(r.Context(), restaurantID, versionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// Publish godoc
+// @Summary Publish menu version
+// @Description Publish a draft to the live menu in one step, or schedule it by giving a future publish_at. The previously live version is archived.
+// @Tags menu-versions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param version_id path string true "Menu version ID"
+// @Param publish body models.MenuVersionPublish false "When to publish"
+// @Success 200 {object} models.MenuVersion
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-versions/{version_id}/publish [post]
+func (h *MenuVersionHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, versionID, ok := parseMenuVersionPath(w, r, true)
+	if !ok {
+		return
+	}
+
+	var req models.MenuVersionPublish
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	version, err := h.versionService.Publish(r.Context(), restaurantID, versionID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(version)
+}
+
+// Rollback godoc
+// @Summary Roll back menu
+// @Description Publish a copy of an archived version as the new live menu
+// @Tags menu-versions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param version_id path string true "ID of the archived version to roll back to"
+// @Success 201 {object} models.MenuVersion
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/menu-versions/{version_id}/rollback [post]
+func (h *MenuVersionHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	restaurantID, versionID, ok := parseMenuVersionPath(w, r, true)
+	if !ok {
+		return
+	}
+
+	version, err := h.versionService.Rollback(r.Context(), user, restaurantID, versionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(version)
+}
+
+// parseMenuVersionPath reads the restaurant ID, and the version ID if
+// withVersion is set, from the segments around "menu-versions".
+func parseMenuVersionPath(w http.ResponseWriter, r *http.Request, withVersion bool) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	i := 0
+	for i < len(path) && path[i] != "menu-versions" {
+		i++
+	}
+	if i == 0 || i == len(path) {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	restaurantID, err := uuid.Parse(path[i-1])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	if !withVersion {
+		return restaurantID, uuid.Nil, true
+	}
+
+	if i+1 >= len(path) {
+		http.Error(w, "Invalid menu version ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	versionID, err := uuid.Parse(path[i+1])
+	if err != nil {
+		http.Error(w, "Invalid menu version ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return restaurantID, versionID, true
+}
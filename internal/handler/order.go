@@ -22,42 +22,40 @@ func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
 
 // Create godoc
 // @Summary Create order
-// @Description Create a new order with multiple menu items
+// @Description Create a new order with multiple menu items. Prices come from the menu and active pricing rules; an optional promo code and loyalty points are applied at checkout. The type defaults to dine_in; pickup orders need pickup_at and customer_name, delivery orders need delivery_address and contact_phone and are charged the restaurant's delivery fee.
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param order body models.Order true "Order object with items array"
 // @Success 201 {object} models.Order
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/orders [post]
 func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
 	var order models.Order
 	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	// Validate order items
-	if len(order.Items) == 0 {
-		http.Error(w, "Order must contain at least one item", http.StatusBadRequest)
-		return
+	// Staff may place orders for a guest; customers only for themselves
+	if !user.IsStaff() {
+		order.UserID = user.ID
 	}
 
-	// Calculate total amount
-	var total float64
-	for _, item := range order.Items {
-		if item.Quantity <= 0 {
-			http.Error(w, "Item quantity must be greater than 0", http.StatusBadRequest)
-			return
-		}
-		total += item.Price * float64(item.Quantity)
+	if !canRedeemPoints(w, r, &order) {
+		return
 	}
-	order.TotalAmount = total
-	order.Status = models.OrderStatusPending
 
 	if err := h.orderService.Create(r.Context(), &order); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -66,18 +64,55 @@ func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(order)
 }
 
+// Quote godoc
+// @Summary Quote order
+// @Description Price an order with its discounts without placing it
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param order body models.Order true "Order object with items array"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/quote [post]
+func (h *OrderHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	var order models.Order
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !canRedeemPoints(w, r, &order) {
+		return
+	}
+
+	if err := h.orderService.Quote(r.Context(), &order); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
 // Get godoc
 // @Summary Get order by ID
-// @Description Get order details by ID
+// @Description Get order details by ID. Customers only see their own orders.
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param id path string true "Order ID"
 // @Success 200 {object} models.Order
+// @Failure 401 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/orders/{id} [get]
 func (h *OrderHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireUser(w, r); !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-1])
 	if err != nil {
@@ -99,20 +134,132 @@ func (h *OrderHandler) Get(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(order)
 }
 
+// ListMine godoc
+// @Summary My orders
+// @Description List the caller's orders, newest first
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param status query string false "Order status"
+// @Param type query string false "Order type (dine_in, pickup or delivery)"
+// @Param restaurant_id query string false "Restaurant ID"
+// @Param from query string false "Placed at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Placed before (RFC 3339 or YYYY-MM-DD)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of orders to skip"
+// @Success 200 {array} models.Order
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/users/me/orders [get]
+func (h *OrderHandler) ListMine(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var (
+		filter models.OrderFilter
+		err    error
+	)
+	filter.Limit, filter.Offset, err = parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.From, err = parseTimeParam(r, "from"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTimeParam(r, "to"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Status = models.OrderStatus(r.URL.Query().Get("status"))
+	filter.Type = models.OrderType(r.URL.Query().Get("type"))
+	if value := r.URL.Query().Get("restaurant_id"); value != "" {
+		restaurantID, err := uuid.Parse(value)
+		if err != nil {
+			http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+			return
+		}
+		filter.RestaurantID = &restaurantID
+	}
+
+	orders, err := h.orderService.ListByUser(r.Context(), user.ID, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if orders == nil {
+		orders = []*models.Order{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+// Reorder godoc
+// @Summary Reorder
+// @Description Place a new order with the items of an earlier one at current prices, with warnings for items that changed or are gone
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Success 201 {object} models.ReorderResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/{id}/reorder [post]
+func (h *OrderHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.orderService.Reorder(r.Context(), id, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
 // Update godoc
 // @Summary Update order
-// @Description Update order details
+// @Description Update order details. Customers can only update their own orders.
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param id path string true "Order ID"
 // @Param order body models.Order true "Order object"
 // @Success 200 {object} models.Order
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/orders/{id} [put]
 func (h *OrderHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireUser(w, r); !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-1])
 	if err != nil {
@@ -129,7 +276,7 @@ func (h *OrderHandler) Update(w http.ResponseWriter, r *http.Request) {
 	order.ID = id
 
 	if err := h.orderService.Update(r.Context(), &order); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -139,18 +286,26 @@ func (h *OrderHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 // UpdateStatus godoc
 // @Summary Update order status
-// @Description Update order status
+// @Description Move an order along its status flow: pending, accepted, ready, then complete, with delivery orders going out_for_delivery before complete. Orders can be canceled until they are complete.
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param id path string true "Order ID"
 // @Param status body models.OrderStatus true "New status"
 // @Success 200 {object} models.Order
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/orders/{id}/status [put]
 func (h *OrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-2])
 	if err != nil {
@@ -167,7 +322,7 @@ func (h *OrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.orderService.UpdateStatus(r.Context(), id, status.Status); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -176,16 +331,24 @@ func (h *OrderHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 
 // Delete godoc
 // @Summary Delete order
-// @Description Delete an order
+// @Description Cancel an order. Orders are kept for their payments and history; this is the same as setting its status to canceled
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param id path string true "Order ID"
 // @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/orders/{id} [delete]
 func (h *OrderHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-1])
 	if err != nil {
@@ -194,9 +357,27 @@ func (h *OrderHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.orderService.Delete(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// canRedeemPoints checks that loyalty points on an order are redeemed by
+// their owner or by staff on the guest's behalf.
+func canRedeemPoints(w http.ResponseWriter, r *http.Request, order *models.Order) bool {
+	if order.LoyaltyPoints == 0 {
+		return true
+	}
+
+	user, ok := requireUser(w, r)
+	if !ok {
+		return false
+	}
+	if !user.IsStaff() && user.ID != order.UserID {
+		http.Error(w, "Loyalty points can only be redeemed by their owner", http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,413 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type OrganizationHandler struct {
+	organizationService *service.OrganizationService
+}
+
+func NewOrganizationHandler(organizationService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+	}
+}
+
+// Create godoc
+// @Summary Create organization
+// @Description Create an organization owned by the calling manager. Its restaurants, brands and their data are only visible to its members.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param organization body models.Organization true "Organization"
+// @Success 201 {object} models.Organization
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/organizations [post]
+func (h *OrganizationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	var organization models.Organization
+	if err := json.NewDecoder(r.Body).Decode(&organization); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.organizationService.Create(r.Context(), user, &organization); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(organization)
+}
+
+// List godoc
+// @Summary List organizations
+// @Description List the organizations the caller is a member of with their role in each; admins see every organization
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} models.Organization
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/organizations [get]
+func (h *OrganizationHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	organizations, err := h.organizationService.List(r.Context(), user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(organizations)
+}
+
+// Get godoc
+// @Summary Get organization
+// @Description Get an organization the caller is a member of
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Organization ID"
+// @Success 200 {object} models.Organization
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id} [get]
+func (h *OrganizationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOrganizationPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	organization, err := h.organizationService.Get(r.Context(), user, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(organization)
+}
+
+// Update godoc
+// @Summary Update organization
+// @Description Rename an organization. Only its owners can.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Organization ID"
+// @Param organization body models.Organization true "Organization"
+// @Success 200 {object} models.Organization
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id} [put]
+func (h *OrganizationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOrganizationPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	var organization models.Organization
+	if err := json.NewDecoder(r.Body).Decode(&organization); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	organization.ID = id
+
+	if err := h.organizationService.Update(r.Context(), user, &organization); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(organization)
+}
+
+// Delete godoc
+// @Summary Delete organization
+// @Description Delete an organization that no longer runs any restaurants. Only its owners can.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Organization ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id} [delete]
+func (h *OrganizationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOrganizationPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.Delete(r.Context(), user, id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListMembers godoc
+// @Summary List organization members
+// @Description List the members of an organization the caller is a member of, with their roles
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Organization ID"
+// @Success 200 {array} models.OrganizationMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id}/members [get]
+func (h *OrganizationHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOrganizationPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(r.Context(), user, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// SaveMember godoc
+// @Summary Add or update organization member
+// @Description Add a staff user to an organization or change their role: owner, manager or staff. Only owners can, and the last owner cannot be demoted.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Param member body models.OrganizationMember true "Member"
+// @Success 200 {object} models.OrganizationMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id}/members/{user_id} [put]
+func (h *OrganizationHandler) SaveMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	organizationID, userID, ok := parseOrganizationSubPath(w, r, "user")
+	if !ok {
+		return
+	}
+
+	var member models.OrganizationMember
+	if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	member.OrganizationID = organizationID
+	member.UserID = userID
+
+	if err := h.organizationService.SaveMember(r.Context(), user, &member); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(member)
+}
+
+// RemoveMember godoc
+// @Summary Remove organization member
+// @Description Remove a user from an organization. Only owners can, and the last owner cannot be removed.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id}/members/{user_id} [delete]
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	organizationID, userID, ok := parseOrganizationSubPath(w, r, "user")
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(r.Context(), user, organizationID, userID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRestaurants godoc
+// @Summary List organization restaurants
+// @Description List the restaurants an organization the caller is a member of runs
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Organization ID"
+// @Success 200 {array} models.Restaurant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id}/restaurants [get]
+func (h *OrganizationHandler) ListRestaurants(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := parseOrganizationPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	restaurants, err := h.organizationService.ListRestaurants(r.Context(), user, id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(restaurants)
+}
+
+// AssignRestaurant godoc
+// @Summary Assign restaurant to organization
+// @Description Move a restaurant into an organization. The caller must own both the organization and the one the restaurant is in; restaurants in no organization are assigned by admins.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Organization ID"
+// @Param restaurant_id path string true "Restaurant ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id}/restaurants/{restaurant_id} [put]
+func (h *OrganizationHandler) AssignRestaurant(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	organizationID, restaurantID, ok := parseOrganizationSubPath(w, r, "restaurant")
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.AssignRestaurant(r.Context(), user, organizationID, restaurantID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseOrganizationPath reads the organization ID that is fromEnd segments
+// from the end of the path.
+func parseOrganizationPath(w http.ResponseWriter, r *http.Request, fromEnd int) (uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-fromEnd])
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseOrganizationSubPath reads the organization ID and the ID of a nested
+// resource from /organizations/{id}/{resource}/{sub_id}.
+func parseOrganizationSubPath(w http.ResponseWriter, r *http.Request, resource string) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	organizationID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid organization ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid "+resource+" ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return organizationID, id, true
+}
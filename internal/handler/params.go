@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTimeParam reads a query parameter formatted as RFC 3339 or as a
+// plain date (YYYY-MM-DD). It returns the zero time if the parameter is
+// absent.
+func parseTimeParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s: expected RFC 3339 timestamp or YYYY-MM-DD", name)
+}
+
+// parseTimeRange reads the from/to query parameters, defaulting to the last
+// 30 days. A plain "to" date includes that whole day.
+func parseTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	from, err := parseTimeParam(r, "from")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseTimeParam(r, "to")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	} else if _, err := time.Parse(time.DateOnly, r.URL.Query().Get("to")); err == nil {
+		to = to.AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must be before to")
+	}
+
+	return from, to, nil
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// parsePagination reads the limit/offset query parameters.
+func parsePagination(r *http.Request) (int, int, error) {
+	limit, offset := defaultPageSize, 0
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > maxPageSize {
+			return 0, 0, fmt.Errorf("invalid limit: expected 1 to %d", maxPageSize)
+		}
+		limit = n
+	}
+	if value := r.URL.Query().Get("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid offset")
+		}
+		offset = n
+	}
+
+	return limit, offset, nil
+}
+
+// splitParam reads a comma-separated query parameter.
+func splitParam[T ~string](r *http.Request, name string) []T {
+	var values []T
+	for _, value := range strings.Split(r.URL.Query().Get(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, T(value))
+		}
+	}
+	return values
+}
+
+// requestLocales returns the locales the client prefers, most preferred
+// first: the lang query parameter if given, otherwise the languages of the
+// Accept-Language header by quality. Content without a translation in any
+// of them is shown in its base text.
+func requestLocales(r *http.Request) []string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return []string{strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))}
+	}
+
+	type preference struct {
+		locale  string
+		quality float64
+	}
+	var preferences []preference
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			preferences = append(preferences, preference{tag, quality})
+		}
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	locales := make([]string, len(preferences))
+	for i, p := range preferences {
+		locales[i] = p.locale
+	}
+	return locales
+}
@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+	}
+}
+
+type paymentAmountRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+// Create godoc
+// @Summary Create payment
+// @Description Pay an order, or one of its checks, through a payment provider. Guests pay their own orders; only staff can record cash and manual_card payments.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Param payment body models.PaymentRequest true "Payment request"
+// @Success 201 {object} models.Payment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/{id}/payments [post]
+func (h *PaymentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	orderID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.paymentService.Create(r.Context(), orderID, req, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payment)
+}
+
+// ListByOrder godoc
+// @Summary List order payments
+// @Description List all payments made against one of the caller's orders, or any order for staff
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Success 200 {array} models.Payment
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/{id}/payments [get]
+func (h *PaymentHandler) ListByOrder(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	orderID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	payments, err := h.paymentService.ListByOrder(r.Context(), orderID, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payments)
+}
+
+// Get godoc
+// @Summary Get payment
+// @Description Get the details of a payment on one of the caller's orders, or any order for staff
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Payment ID"
+// @Success 200 {object} models.Payment
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/payments/{id} [get]
+func (h *PaymentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid payment ID", http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.paymentService.GetByID(r.Context(), id, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if payment == nil {
+		http.Error(w, "Payment not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
+
+// Capture godoc
+// @Summary Capture payment
+// @Description Capture an authorized payment; omit amount to capture in full
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Payment ID"
+// @Param body body paymentAmountRequest false "Amount to capture"
+// @Success 200 {object} models.Payment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/payments/{id}/capture [post]
+func (h *PaymentHandler) Capture(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid payment ID", http.StatusBadRequest)
+		return
+	}
+
+	var req paymentAmountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.paymentService.Capture(r.Context(), id, req.Amount)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
+
+// Void godoc
+// @Summary Void payment
+// @Description Void a payment that has not been captured
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Payment ID"
+// @Success 200 {object} models.Payment
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/payments/{id}/void [post]
+func (h *PaymentHandler) Void(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid payment ID", http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.paymentService.Void(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
+
+// Webhook godoc
+// @Summary Payment provider webhook
+// @Description Receive a signed asynchronous status update from a payment provider
+// @Tags payments
+// @Accept json
+// @Param provider path string true "Provider name"
+// @Param X-Webhook-Signature header string true "Hex HMAC-SHA256 of the body"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/webhooks/payments/{provider} [post]
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(r.URL.Path, "/")
+	provider := path[len(path)-1]
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.paymentService.HandleWebhook(r.Context(), provider, payload, r.Header.Get("X-Webhook-Signature")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
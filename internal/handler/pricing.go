@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type PricingRuleHandler struct {
+	pricingService *service.PricingService
+}
+
+func NewPricingRuleHandler(pricingService *service.PricingService) *PricingRuleHandler {
+	return &PricingRuleHandler{
+		pricingService: pricingService,
+	}
+}
+
+// Create godoc
+// @Summary Create pricing rule
+// @Description Create a discount, promo code or time-based pricing rule for a restaurant
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param rule body models.PricingRule true "Pricing rule"
+// @Success 201 {object} models.PricingRule
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/pricing-rules [post]
+func (h *PricingRuleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	var rule models.PricingRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rule.RestaurantID = restaurantID
+
+	if err := h.pricingService.CreateRule(r.Context(), &rule); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// List godoc
+// @Summary List pricing rules
+// @Description List the pricing rules of a restaurant
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Success 200 {array} models.PricingRule
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/pricing-rules [get]
+func (h *PricingRuleHandler) List(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	rules, err := h.pricingService.ListRules(r.Context(), restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// Get godoc
+// @Summary Get pricing rule
+// @Description Get a pricing rule of a restaurant
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param rule_id path string true "Pricing rule ID"
+// @Success 200 {object} models.PricingRule
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/pricing-rules/{rule_id} [get]
+func (h *PricingRuleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, ruleID, ok := parseRulePath(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.pricingService.GetRule(r.Context(), restaurantID, ruleID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// Update godoc
+// @Summary Update pricing rule
+// @Description Update a pricing rule of a restaurant
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param rule_id path string true "Pricing rule ID"
+// @Param rule body models.PricingRule true "Pricing rule"
+// @Success 200 {object} models.PricingRule
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/pricing-rules/{rule_id} [put]
+func (h *PricingRuleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, ruleID, ok := parseRulePath(w, r)
+	if !ok {
+		return
+	}
+
+	var rule models.PricingRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rule.ID = ruleID
+	rule.RestaurantID = restaurantID
+
+	if err := h.pricingService.UpdateRule(r.Context(), &rule); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// Delete godoc
+// @Summary Delete pricing rule
+// @Description Delete a pricing rule; discounts already applied to orders are kept
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param rule_id path string true "Pricing rule ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/pricing-rules/{rule_id} [delete]
+func (h *PricingRuleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, ruleID, ok := parseRulePath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.pricingService.DeleteRule(r.Context(), restaurantID, ruleID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseRulePath reads the restaurant and rule IDs from
+// /restaurants/{id}/pricing-rules/{rule_id}.
+func parseRulePath(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	ruleID, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid pricing rule ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return restaurantID, ruleID, true
+}
@@ -0,0 +1,726 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type PurchasingHandler struct {
+	purchasingService *service.PurchasingService
+}
+
+func NewPurchasingHandler(purchasingService *service.PurchasingService) *PurchasingHandler {
+	return &PurchasingHandler{
+		purchasingService: purchasingService,
+	}
+}
+
+// CreateSupplier godoc
+// @Summary Create supplier
+// @Description Add a supplier to a restaurant
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param supplier body models.Supplier true "Supplier"
+// @Success 201 {object} models.Supplier
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/suppliers [post]
+func (h *PurchasingHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	var supplier models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	supplier.RestaurantID = restaurantID
+
+	if err := h.purchasingService.CreateSupplier(r.Context(), &supplier); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(supplier)
+}
+
+// ListSuppliers godoc
+// @Summary List suppliers
+// @Description List the suppliers of a restaurant
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Success 200 {array} models.Supplier
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/suppliers [get]
+func (h *PurchasingHandler) ListSuppliers(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	suppliers, err := h.purchasingService.ListSuppliers(r.Context(), restaurantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if suppliers == nil {
+		suppliers = []*models.Supplier{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suppliers)
+}
+
+// GetSupplier godoc
+// @Summary Get supplier
+// @Description Get a supplier of a restaurant
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param supplier_id path string true "Supplier ID"
+// @Success 200 {object} models.Supplier
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/suppliers/{supplier_id} [get]
+func (h *PurchasingHandler) GetSupplier(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, supplierID, ok := parseSupplierPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	supplier, err := h.purchasingService.GetSupplier(r.Context(), restaurantID, supplierID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(supplier)
+}
+
+// UpdateSupplier godoc
+// @Summary Update supplier
+// @Description Update the details of a supplier
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param supplier_id path string true "Supplier ID"
+// @Param supplier body models.Supplier true "Supplier"
+// @Success 200 {object} models.Supplier
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/suppliers/{supplier_id} [put]
+func (h *PurchasingHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, supplierID, ok := parseSupplierPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	var supplier models.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	supplier.ID = supplierID
+	supplier.RestaurantID = restaurantID
+
+	if err := h.purchasingService.UpdateSupplier(r.Context(), &supplier); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(supplier)
+}
+
+// DeleteSupplier godoc
+// @Summary Delete supplier
+// @Description Delete a supplier and its catalog; suppliers with purchase orders cannot be deleted
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param supplier_id path string true "Supplier ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/suppliers/{supplier_id} [delete]
+func (h *PurchasingHandler) DeleteSupplier(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, supplierID, ok := parseSupplierPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	if err := h.purchasingService.DeleteSupplier(r.Context(), restaurantID, supplierID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCatalog godoc
+// @Summary List supplier catalog
+// @Description List the ingredients a supplier sells, with their cost and current on-hand quantity
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param supplier_id path string true "Supplier ID"
+// @Success 200 {array} models.SupplierItem
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/suppliers/{supplier_id}/items [get]
+func (h *PurchasingHandler) ListCatalog(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, supplierID, ok := parseSupplierPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	items, err := h.purchasingService.ListCatalog(r.Context(), restaurantID, supplierID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if items == nil {
+		items = []*models.SupplierItem{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// SaveCatalogItem godoc
+// @Summary Save supplier catalog item
+// @Description Add an ingredient to a supplier's catalog, or update its SKU and unit cost if it is already listed
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param supplier_id path string true "Supplier ID"
+// @Param item body models.SupplierItem true "Catalog item"
+// @Success 200 {object} models.SupplierItem
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/suppliers/{supplier_id}/items [post]
+func (h *PurchasingHandler) SaveCatalogItem(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, supplierID, ok := parseSupplierPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	var item models.SupplierItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	item.SupplierID = supplierID
+
+	if err := h.purchasingService.SaveCatalogItem(r.Context(), restaurantID, &item); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// DeleteCatalogItem godoc
+// @Summary Delete supplier catalog item
+// @Description Remove an ingredient from a supplier's catalog
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param supplier_id path string true "Supplier ID"
+// @Param item_id path string true "Catalog item ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/suppliers/{supplier_id}/items/{item_id} [delete]
+func (h *PurchasingHandler) DeleteCatalogItem(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, supplierID, ok := parseSupplierPath(w, r, 3)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	itemID, err := uuid.Parse(path[len(path)-1])
+	if err != nil {
+		http.Error(w, "Invalid catalog item ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.purchasingService.DeleteCatalogItem(r.Context(), restaurantID, supplierID, itemID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreatePurchaseOrder godoc
+// @Summary Create purchase order
+// @Description Create a draft purchase order. Lines without a unit cost use the supplier's catalog cost.
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param order body models.PurchaseOrder true "Purchase order"
+// @Success 201 {object} models.PurchaseOrder
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/purchase-orders [post]
+func (h *PurchasingHandler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	var order models.PurchaseOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	order.RestaurantID = restaurantID
+
+	if err := h.purchasingService.CreatePurchaseOrder(r.Context(), &order, user); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(order)
+}
+
+// ListPurchaseOrders godoc
+// @Summary List purchase orders
+// @Description List the purchase orders of a restaurant, newest first
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param status query string false "Filter by status (draft, sent, partially_received, received)"
+// @Param supplier_id query string false "Filter by supplier"
+// @Success 200 {array} models.PurchaseOrder
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/purchase-orders [get]
+func (h *PurchasingHandler) ListPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.PurchaseOrderFilter{
+		Status: models.PurchaseOrderStatus(query.Get("status")),
+	}
+	if value := query.Get("supplier_id"); value != "" {
+		supplierID, err := uuid.Parse(value)
+		if err != nil {
+			http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+			return
+		}
+		filter.SupplierID = &supplierID
+	}
+
+	orders, err := h.purchasingService.ListPurchaseOrders(r.Context(), restaurantID, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if orders == nil {
+		orders = []*models.PurchaseOrder{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+// GetPurchaseOrder godoc
+// @Summary Get purchase order
+// @Description Get a purchase order with its lines
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param po_id path string true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/purchase-orders/{po_id} [get]
+func (h *PurchasingHandler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, orderID, ok := parsePurchaseOrderPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	order, err := h.purchasingService.GetPurchaseOrder(r.Context(), restaurantID, orderID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// UpdatePurchaseOrder godoc
+// @Summary Update purchase order
+// @Description Replace the lines and details of a draft purchase order
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param po_id path string true "Purchase order ID"
+// @Param order body models.PurchaseOrder true "Purchase order"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/purchase-orders/{po_id} [put]
+func (h *PurchasingHandler) UpdatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, orderID, ok := parsePurchaseOrderPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	var order models.PurchaseOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	order.ID = orderID
+	order.RestaurantID = restaurantID
+
+	if err := h.purchasingService.UpdatePurchaseOrder(r.Context(), &order); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// DeletePurchaseOrder godoc
+// @Summary Delete purchase order
+// @Description Delete a draft purchase order
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param po_id path string true "Purchase order ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/purchase-orders/{po_id} [delete]
+func (h *PurchasingHandler) DeletePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, orderID, ok := parsePurchaseOrderPath(w, r, 1)
+	if !ok {
+		return
+	}
+
+	if err := h.purchasingService.DeletePurchaseOrder(r.Context(), restaurantID, orderID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendPurchaseOrder godoc
+// @Summary Send purchase order
+// @Description Mark a draft purchase order as sent to the supplier; it can no longer be edited
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param po_id path string true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/purchase-orders/{po_id}/send [post]
+func (h *PurchasingHandler) SendPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, orderID, ok := parsePurchaseOrderPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	order, err := h.purchasingService.SendPurchaseOrder(r.Context(), restaurantID, orderID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// ReceivePurchaseOrder godoc
+// @Summary Receive purchase order
+// @Description Record a delivery against a sent purchase order. Received quantities are added to stock; the order is received once every line is delivered in full.
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param po_id path string true "Purchase order ID"
+// @Param delivery body models.ReceiveRequest true "Received lines"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/purchase-orders/{po_id}/receive [post]
+func (h *PurchasingHandler) ReceivePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	restaurantID, orderID, ok := parsePurchaseOrderPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	var req models.ReceiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.purchasingService.ReceivePurchaseOrder(r.Context(), restaurantID, orderID, req, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+// SuggestPurchaseOrders godoc
+// @Summary Suggest purchase orders
+// @Description Propose purchase orders for ingredients at or below their low stock threshold, topping stock up to the par level and counting what is already on order
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Success 200 {array} models.SuggestedPurchaseOrder
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/purchase-orders/suggestions [get]
+func (h *PurchasingHandler) SuggestPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	suggestions, err := h.purchasingService.SuggestPurchaseOrders(r.Context(), restaurantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if suggestions == nil {
+		suggestions = []*models.SuggestedPurchaseOrder{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+// CreateSuggestedPurchaseOrders godoc
+// @Summary Create suggested purchase orders
+// @Description Create draft purchase orders from the current suggestions; ingredients that no supplier sells are skipped
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Success 201 {array} models.PurchaseOrder
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/purchase-orders/suggestions [post]
+func (h *PurchasingHandler) CreateSuggestedPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-3])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	orders, err := h.purchasingService.CreateSuggestedPurchaseOrders(r.Context(), restaurantID, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(orders)
+}
+
+// parseSupplierPath reads the restaurant and supplier IDs from
+// /restaurants/{id}/suppliers/{supplier_id}[/...], where the supplier ID
+// is the given number of segments from the end.
+func parseSupplierPath(w http.ResponseWriter, r *http.Request, fromEnd int) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-fromEnd-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	supplierID, err := uuid.Parse(path[len(path)-fromEnd])
+	if err != nil {
+		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return restaurantID, supplierID, true
+}
+
+// parsePurchaseOrderPath reads the restaurant and purchase order IDs from
+// /restaurants/{id}/purchase-orders/{po_id}[/...], where the purchase
+// order ID is the given number of segments from the end.
+func parsePurchaseOrderPath(w http.ResponseWriter, r *http.Request, fromEnd int) (uuid.UUID, uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-fromEnd-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	orderID, err := uuid.Parse(path[len(path)-fromEnd])
+	if err != nil {
+		http.Error(w, "Invalid purchase order ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return restaurantID, orderID, true
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/KNLopez/restaurant-api/internal/models"
@@ -25,16 +26,24 @@ func NewRestaurantHandler(restaurantService *service.RestaurantService, cloudina
 
 // Create godoc
 // @Summary Create restaurant
-// @Description Create a new restaurant
+// @Description Create a new restaurant. Everyone but admins must open it in an organization they own or manage.
 // @Tags restaurants
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param restaurant body models.Restaurant true "Restaurant object"
 // @Success 201 {object} models.Restaurant
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants [post]
 func (h *RestaurantHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
 	// Parse multipart form
 	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10 MB max
 		http.Error(w, "Failed to parse form", http.StatusBadRequest)
@@ -59,8 +68,8 @@ func (h *RestaurantHandler) Create(w http.ResponseWriter, r *http.Request) {
 		restaurant.LogoURL = logoURL
 	}
 
-	if err := h.restaurantService.Create(r.Context(), &restaurant); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.restaurantService.Create(r.Context(), user, &restaurant); err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -76,6 +85,7 @@ func (h *RestaurantHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Restaurant ID"
+// @Param lang query string false "Locale to show the description in, overriding Accept-Language"
 // @Success 200 {object} models.Restaurant
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
@@ -97,25 +107,116 @@ func (h *RestaurantHandler) Get(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Restaurant not found", http.StatusNotFound)
 		return
 	}
+	restaurant.Localize(requestLocales(r))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(restaurant)
 }
 
+// List godoc
+// @Summary List restaurants
+// @Description Browse restaurants. With near, only restaurants within the radius are listed, nearest first, with their distance; otherwise they are sorted by name. Each restaurant carries its rating.
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param near query string false "Position to search around as lat,lng"
+// @Param radius query number false "Search radius in km around near, default 5, up to 50"
+// @Param cuisine query string false "Comma-separated cuisine tags, any of which must match"
+// @Param open_now query bool false "Only restaurants that are open right now"
+// @Param min_rating query number false "Lowest average rating, from 1 to 5"
+// @Param limit query int false "Page size, up to 100"
+// @Param offset query int false "Restaurants to skip"
+// @Param lang query string false "Locale to show descriptions in, overriding Accept-Language"
+// @Success 200 {array} models.Restaurant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants [get]
+func (h *RestaurantHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.RestaurantFilter{
+		CuisineTags: splitParam[string](r, "cuisine"),
+	}
+
+	if near := query.Get("near"); near != "" {
+		lat, lng, ok := strings.Cut(near, ",")
+		latitude, latErr := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		longitude, lngErr := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+		if !ok || latErr != nil || lngErr != nil {
+			http.Error(w, "Invalid near: expected lat,lng", http.StatusBadRequest)
+			return
+		}
+		filter.Latitude, filter.Longitude = &latitude, &longitude
+	}
+	if value := query.Get("radius"); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, "Invalid radius", http.StatusBadRequest)
+			return
+		}
+		filter.RadiusKm = radius
+	}
+	if value := query.Get("open_now"); value != "" {
+		openNow, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid open_now", http.StatusBadRequest)
+			return
+		}
+		filter.OpenNow = openNow
+	}
+	if value := query.Get("min_rating"); value != "" {
+		rating, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, "Invalid min_rating", http.StatusBadRequest)
+			return
+		}
+		filter.MinRating = rating
+	}
+
+	var err error
+	if filter.Limit, filter.Offset, err = parsePagination(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	restaurants, err := h.restaurantService.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if restaurants == nil {
+		restaurants = []*models.Restaurant{}
+	}
+	locales := requestLocales(r)
+	for _, restaurant := range restaurants {
+		restaurant.Localize(locales)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(restaurants)
+}
+
 // Update godoc
 // @Summary Update restaurant
-// @Description Update restaurant details
+// @Description Update restaurant details. Only owners and managers of the restaurant's organization can.
 // @Tags restaurants
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param id path string true "Restaurant ID"
 // @Param restaurant body models.Restaurant true "Restaurant object"
 // @Success 200 {object} models.Restaurant
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants/{id} [put]
 func (h *RestaurantHandler) Update(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-1])
 	if err != nil {
@@ -131,8 +232,8 @@ func (h *RestaurantHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	restaurant.ID = id
 
-	if err := h.restaurantService.Update(r.Context(), &restaurant); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.restaurantService.Update(r.Context(), user, &restaurant); err != nil {
+		writeError(w, err)
 		return
 	}
 
@@ -142,16 +243,24 @@ func (h *RestaurantHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 // Delete godoc
 // @Summary Delete restaurant
-// @Description Delete a restaurant
+// @Description Delete a restaurant. Only owners and managers of the restaurant's organization can.
 // @Tags restaurants
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param id path string true "Restaurant ID"
 // @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants/{id} [delete]
 func (h *RestaurantHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-1])
 	if err != nil {
@@ -159,8 +268,8 @@ func (h *RestaurantHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.restaurantService.Delete(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.restaurantService.Delete(r.Context(), user, id); err != nil {
+		writeError(w, err)
 		return
 	}
 
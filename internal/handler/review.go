@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/middleware"
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+// recentReviewWindow is how far back filter=recent looks.
+const recentReviewWindow = 30 * 24 * time.Hour
+
+type ReviewHandler struct {
+	reviewService *service.ReviewService
+}
+
+func NewReviewHandler(reviewService *service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// Create godoc
+// @Summary Review order
+// @Description Rate a completed order and, optionally, its menu items
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Order ID"
+// @Param review body models.Review true "Overall rating, comment and item ratings"
+// @Success 201 {object} models.Review
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/{id}/review [post]
+func (h *ReviewHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	orderID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	var review models.Review
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.reviewService.Create(r.Context(), orderID, &review, user); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(review)
+}
+
+// GetByOrder godoc
+// @Summary Get order review
+// @Description Get the review of an order
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.Review
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/orders/{id}/review [get]
+func (h *ReviewHandler) GetByOrder(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(r.URL.Path, "/")
+	orderID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.reviewService.GetByOrder(r.Context(), orderID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// ListByRestaurant godoc
+// @Summary List restaurant reviews
+// @Description List the reviews of a restaurant, newest first. filter=recent limits to the last 30 days and filter=low to scores of 2 or less.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Restaurant ID"
+// @Param filter query string false "recent or low"
+// @Param max_rating query int false "Only reviews rated at most this"
+// @Param since query string false "Only reviews since (RFC 3339 or YYYY-MM-DD)"
+// @Param include_hidden query bool false "Include hidden reviews (staff only)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Number of reviews to skip"
+// @Success 200 {array} models.Review
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/reviews [get]
+func (h *ReviewHandler) ListByRestaurant(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return
+	}
+
+	var filter models.ReviewFilter
+	filter.Limit, filter.Offset, err = parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Since, err = parseTimeParam(r, "since"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	switch query.Get("filter") {
+	case "":
+	case "recent":
+		if filter.Since.IsZero() {
+			filter.Since = time.Now().Add(-recentReviewWindow)
+		}
+	case "low":
+		filter.MaxRating = models.LowRating
+	default:
+		http.Error(w, "Invalid filter: expected recent or low", http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("max_rating"); value != "" {
+		filter.MaxRating, err = strconv.Atoi(value)
+		if err != nil || filter.MaxRating < models.MinRating || filter.MaxRating > models.MaxRating {
+			http.Error(w, "Invalid max_rating", http.StatusBadRequest)
+			return
+		}
+	}
+	if query.Get("include_hidden") == "true" {
+		user, ok := middleware.UserFromContext(r.Context())
+		filter.IncludeHidden = ok && user.IsStaff()
+	}
+
+	reviews, err := h.reviewService.ListByRestaurant(r.Context(), restaurantID, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if reviews == nil {
+		reviews = []*models.Review{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reviews)
+}
+
+// Reply godoc
+// @Summary Reply to review
+// @Description Post the restaurant's public reply to a review
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Review ID"
+// @Param reply body object true "Reply text as {\"reply\": \"...\"}"
+// @Success 200 {object} models.Review
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/reviews/{id}/reply [post]
+func (h *ReviewHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Reply string `json:"reply"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.reviewService.Reply(r.Context(), id, req.Reply, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// Hide godoc
+// @Summary Hide review
+// @Description Hide a review from public listings and ratings
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Review ID"
+// @Success 200 {object} models.Review
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/reviews/{id}/hide [post]
+func (h *ReviewHandler) Hide(w http.ResponseWriter, r *http.Request) {
+	h.setHidden(w, r, true)
+}
+
+// Unhide godoc
+// @Summary Unhide review
+// @Description Show a hidden review again
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Review ID"
+// @Success 200 {object} models.Review
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/reviews/{id}/unhide [post]
+func (h *ReviewHandler) Unhide(w http.ResponseWriter, r *http.Request) {
+	h.setHidden(w, r, false)
+}
+
+func (h *ReviewHandler) setHidden(w http.ResponseWriter, r *http.Request, hidden bool) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Split(r.URL.Path, "/")
+	id, err := uuid.Parse(path[len(path)-2])
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	review, err := h.reviewService.SetHidden(r.Context(), id, hidden, user)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/service"
+	"github.com/google/uuid"
+)
+
+type SettingsHandler struct {
+	settingsService *service.SettingsService
+}
+
+func NewSettingsHandler(settingsService *service.SettingsService) *SettingsHandler {
+	return &SettingsHandler{
+		settingsService: settingsService,
+	}
+}
+
+// Get godoc
+// @Summary Get restaurant settings
+// @Description Get the current settings of a restaurant: currency, timezone, tax mode and rate, service charge rate, whether orders are accepted automatically and the receipt footer. A restaurant that has never saved its settings gets the defaults at version 0.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Success 200 {object} models.RestaurantSettings
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/settings [get]
+func (h *SettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, ok := parseSettingsPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	settings, err := h.settingsService.Get(r.Context(), restaurantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// Update godoc
+// @Summary Update restaurant settings
+// @Description Save the settings of a restaurant as a new version. The body must carry the version it was based on; if the settings have been saved since, the request fails with 409 and must be retried on the current version. Rates are percentages.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Param settings body models.RestaurantSettings true "Settings"
+// @Success 200 {object} models.RestaurantSettings
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/settings [put]
+func (h *SettingsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireStaff(w, r)
+	if !ok {
+		return
+	}
+
+	restaurantID, ok := parseSettingsPath(w, r, 2)
+	if !ok {
+		return
+	}
+
+	var settings models.RestaurantSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	settings.RestaurantID = restaurantID
+
+	if err := h.settingsService.Save(r.Context(), user, &settings); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// ListVersions godoc
+// @Summary List restaurant settings versions
+// @Description List every saved version of a restaurant's settings, newest first
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Restaurant ID"
+// @Success 200 {array} models.RestaurantSettings
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/restaurants/{id}/settings/versions [get]
+func (h *SettingsHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
+	restaurantID, ok := parseSettingsPath(w, r, 3)
+	if !ok {
+		return
+	}
+
+	versions, err := h.settingsService.Versions(r.Context(), restaurantID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// parseSettingsPath reads the restaurant ID that is fromEnd segments from
+// the end of the path.
+func parseSettingsPath(w http.ResponseWriter, r *http.Request, fromEnd int) (uuid.UUID, bool) {
+	path := strings.Split(r.URL.Path, "/")
+	restaurantID, err := uuid.Parse(path[len(path)-fromEnd])
+	if err != nil {
+		http.Error(w, "Invalid restaurant ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return restaurantID, true
+}
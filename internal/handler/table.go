@@ -32,13 +32,20 @@ func NewTableHandler(tableService *service.TableService, config *config.Config)
 // @Tags tables
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param restaurant_id path string true "Restaurant ID"
 // @Param table body models.Table true "Table object"
 // @Success 201 {object} models.Table
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants/{restaurant_id}/tables [post]
 func (h *TableHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
 	// Extract restaurant ID from URL
 	path := strings.Split(r.URL.Path, "/")
 	restaurantID, err := uuid.Parse(path[len(path)-2])
@@ -90,13 +97,20 @@ func (h *TableHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Tags tables
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param restaurant_id path string true "Restaurant ID"
 // @Param id path string true "Table ID"
 // @Success 200 {object} models.Table
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants/{restaurant_id}/tables/{id} [get]
 func (h *TableHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-1])
 	if err != nil {
@@ -153,15 +167,22 @@ func (h *TableHandler) GetByQR(w http.ResponseWriter, r *http.Request) {
 // @Tags tables
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param restaurant_id path string true "Restaurant ID"
 // @Param id path string true "Table ID"
 // @Param status body models.TableStatus true "New status"
 // @Success 200 {object} models.Table
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants/{restaurant_id}/tables/{id}/status [put]
 func (h *TableHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-2])
 	if err != nil {
@@ -191,13 +212,20 @@ func (h *TableHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 // @Tags tables
 // @Accept json
 // @Produce json
+// @Security Bearer
 // @Param restaurant_id path string true "Restaurant ID"
 // @Param id path string true "Table ID"
 // @Success 204 "No Content"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/restaurants/{restaurant_id}/tables/{id} [delete]
 func (h *TableHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireStaff(w, r); !ok {
+		return
+	}
+
 	path := strings.Split(r.URL.Path, "/")
 	id, err := uuid.Parse(path[len(path)-1])
 	if err != nil {
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+type contextKey string
+
+const authUserKey contextKey = "auth_user"
+
+type tokenClaims struct {
+	Subject   string          `json:"sub"`
+	Role      models.UserRole `json:"role"`
+	ExpiresAt int64           `json:"exp"`
+}
+
+// Authenticate verifies HS256 bearer tokens signed with secret, which must
+// carry an expiry, and stores the caller in the request context. Requests
+// without an Authorization header continue anonymously; handlers decide
+// whether a user is required.
+func Authenticate(secret string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				http.Error(w, "Invalid authorization header", http.StatusUnauthorized)
+				return
+			}
+
+			user, err := parseToken(token, []byte(secret))
+			if err != nil {
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authUserKey, user)))
+		})
+	}
+}
+
+// UserFromContext returns the authenticated caller, if any.
+func UserFromContext(ctx context.Context) (*models.AuthUser, bool) {
+	user, ok := ctx.Value(authUserKey).(*models.AuthUser)
+	return user, ok
+}
+
+func parseToken(token string, secret []byte) (*models.AuthUser, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &h); err != nil || h.Alg != "HS256" {
+		return nil, errors.New("unsupported token algorithm")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("invalid signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, err
+	}
+	if claims.ExpiresAt == 0 {
+		return nil, errors.New("token does not expire")
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, errors.New("token expired")
+	}
+
+	id, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthUser{ID: id, Role: claims.Role}, nil
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+func signToken(t *testing.T, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseToken(t *testing.T) {
+	const secret = "test-secret"
+	userID := uuid.New()
+	future := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid", signToken(t, secret, map[string]interface{}{"sub": userID.String(), "role": models.RoleManager, "exp": future}), false},
+		{"no expiry", signToken(t, secret, map[string]interface{}{"sub": userID.String(), "role": models.RoleAdmin}), true},
+		{"expired", signToken(t, secret, map[string]interface{}{"sub": userID.String(), "role": models.RoleManager, "exp": time.Now().Add(-time.Minute).Unix()}), true},
+		{"other secret", signToken(t, "another-secret", map[string]interface{}{"sub": userID.String(), "role": models.RoleAdmin, "exp": future}), true},
+		{"bad subject", signToken(t, secret, map[string]interface{}{"sub": "admin", "role": models.RoleAdmin, "exp": future}), true},
+		{"malformed", "not-a-token", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := parseToken(tt.token, []byte(secret))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseToken() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (user.ID != userID || user.Role != models.RoleManager) {
+				t.Errorf("parseToken() = %+v", user)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/tenant"
+	"github.com/google/uuid"
+)
+
+// MembershipLookup returns the organizations a user is a member of.
+type MembershipLookup func(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+
+// ScopeTenant limits requests of managers and employees to the data of the
+// organizations they are members of, and those of customers to their own
+// orders. Only admins are unscoped; anonymous requests are left as guests.
+// It must run after Authenticate.
+func ScopeTenant(memberships MembershipLookup) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := UserFromContext(r.Context())
+			switch {
+			case !ok:
+				next.ServeHTTP(w, r)
+				return
+			case user.Role == models.RoleAdmin:
+				next.ServeHTTP(w, r.WithContext(tenant.Unscoped(r.Context())))
+				return
+			case !user.IsStaff():
+				next.ServeHTTP(w, r.WithContext(tenant.WithCustomer(r.Context(), user.ID)))
+				return
+			}
+
+			// Memberships are looked up before the request is scoped.
+			organizationIDs, err := memberships(tenant.Unscoped(r.Context()), user.ID)
+			if err != nil {
+				log.Printf("tenant: memberships of %s: %v", user.ID, err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(tenant.WithOrganizations(r.Context(), organizationIDs)))
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AdjustmentType string
+
+const (
+	// AdjustmentVoid removes items that have not been sent to the kitchen.
+	AdjustmentVoid AdjustmentType = "void"
+	// AdjustmentRefund returns money for items that have been paid for.
+	AdjustmentRefund AdjustmentType = "refund"
+)
+
+type AdjustmentStatus string
+
+// A refund waits in pending_approval until it is approved, is processing
+// while its payments are refunded and ends up completed. If a payment
+// cannot be refunded it is failed, with what was refunded so far, and can
+// be retried.
+const (
+	AdjustmentPendingApproval AdjustmentStatus = "pending_approval"
+	AdjustmentProcessing      AdjustmentStatus = "processing"
+	AdjustmentFailed          AdjustmentStatus = "failed"
+	AdjustmentCompleted       AdjustmentStatus = "completed"
+	AdjustmentRejected        AdjustmentStatus = "rejected"
+)
+
+// Outstanding reports whether a refund in this status may still move money,
+// so its unrefunded amount is held back from other refunds.
+func (s AdjustmentStatus) Outstanding() bool {
+	return s == AdjustmentPendingApproval || s == AdjustmentProcessing || s == AdjustmentFailed
+}
+
+type ReasonCode string
+
+const (
+	ReasonSentBack       ReasonCode = "sent_back"
+	ReasonWrongItem      ReasonCode = "wrong_item"
+	ReasonQuality        ReasonCode = "quality"
+	ReasonChangedMind    ReasonCode = "changed_mind"
+	ReasonLongWait       ReasonCode = "long_wait"
+	ReasonComp           ReasonCode = "comp"
+	ReasonEnteredInError ReasonCode = "entered_in_error"
+	ReasonOther          ReasonCode = "other"
+)
+
+func (c ReasonCode) Valid() bool {
+	switch c {
+	case ReasonSentBack, ReasonWrongItem, ReasonQuality, ReasonChangedMind,
+		ReasonLongWait, ReasonComp, ReasonEnteredInError, ReasonOther:
+		return true
+	}
+	return false
+}
+
+// OrderAdjustment records a void or refund against an order. Item-level
+// adjustments reference the order item; order-level refunds do not.
+type OrderAdjustment struct {
+	ID           uuid.UUID      `json:"id" db:"id"`
+	OrderID      uuid.UUID      `json:"order_id" db:"order_id"`
+	RestaurantID uuid.UUID      `json:"restaurant_id" db:"restaurant_id"`
+	OrderItemID  *uuid.UUID     `json:"order_item_id,omitempty" db:"order_item_id"`
+	Type         AdjustmentType `json:"type" db:"type"`
+	Quantity     int            `json:"quantity" db:"quantity"`
+	Amount       float64        `json:"amount" db:"amount"`
+	// RefundedAmount is how much of a refund has been paid back so far.
+	RefundedAmount float64          `json:"refunded_amount" db:"refunded_amount"`
+	ReasonCode     ReasonCode       `json:"reason_code" db:"reason_code"`
+	Note           string           `json:"note,omitempty" db:"note"`
+	Status         AdjustmentStatus `json:"status" db:"status"`
+	RequestedBy    uuid.UUID        `json:"requested_by" db:"requested_by"`
+	ApprovedBy     *uuid.UUID       `json:"approved_by,omitempty" db:"approved_by"`
+	ApprovedAt     *time.Time       `json:"approved_at,omitempty" db:"approved_at"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at" db:"updated_at"`
+	// Refunds are the attempts to refund the order's payments, when the
+	// adjustment is fetched on its own.
+	Refunds []RefundAttempt `json:"refunds,omitempty"`
+}
+
+// RefundAttempt is the result of refunding one payment for a refund
+// adjustment.
+type RefundAttempt struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	AdjustmentID uuid.UUID `json:"adjustment_id" db:"adjustment_id"`
+	PaymentID    uuid.UUID `json:"payment_id" db:"payment_id"`
+	Amount       float64   `json:"amount" db:"amount"`
+	Succeeded    bool      `json:"succeeded" db:"succeeded"`
+	Error        string    `json:"error,omitempty" db:"error"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// AdjustmentRequest asks for a void or refund. For refunds without an
+// order item, Amount is required; otherwise it defaults to the item price
+// times Quantity, and Quantity defaults to everything still eligible.
+type AdjustmentRequest struct {
+	OrderItemID *uuid.UUID `json:"order_item_id,omitempty"`
+	Quantity    int        `json:"quantity,omitempty"`
+	Amount      float64    `json:"amount,omitempty"`
+	ReasonCode  ReasonCode `json:"reason_code"`
+	Note        string     `json:"note,omitempty"`
+}
+
+type AdjustmentSummary struct {
+	Type       AdjustmentType `json:"type"`
+	ReasonCode ReasonCode     `json:"reason_code"`
+	Count      int            `json:"count"`
+	Quantity   int            `json:"quantity"`
+	Amount     float64        `json:"amount"`
+}
+
+// AdjustmentReport summarizes completed voids and refunds of a restaurant
+// over a period, along with refunds still waiting for approval.
+type AdjustmentReport struct {
+	RestaurantID uuid.UUID           `json:"restaurant_id"`
+	From         time.Time           `json:"from"`
+	To           time.Time           `json:"to"`
+	Summary      []AdjustmentSummary `json:"summary"`
+	Pending      []*OrderAdjustment  `json:"pending"`
+	Adjustments  []*OrderAdjustment  `json:"adjustments"`
+}
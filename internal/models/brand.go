@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Brand is a restaurant chain that owns a master menu shared by its
+// locations.
+type Brand struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	Name           string     `json:"name" db:"name"`
+	OwnerID        uuid.UUID  `json:"owner_id" db:"owner_id"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty" db:"organization_id"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// BrandMenuItem is an item of a brand's master menu. Locations get a copy
+// of it on their own menu when the master menu is published. Category is
+// the name of the category it is listed under at every location.
+type BrandMenuItem struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	BrandID      uuid.UUID    `json:"brand_id" db:"brand_id"`
+	SKU          string       `json:"sku,omitempty" db:"sku"`
+	Category     string       `json:"category" db:"category"`
+	Name         string       `json:"name" db:"name"`
+	Description  string       `json:"description" db:"description"`
+	Translations Translations `json:"translations,omitempty" db:"translations"`
+	Price        float64      `json:"price" db:"price"`
+	Allergens    []Allergen   `json:"allergens" db:"allergens"`
+	DietaryTags  []DietaryTag `json:"dietary_tags" db:"dietary_tags"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
+	DeletedAt    *time.Time   `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// BrandMenuOverride is a location's own price or availability for a master
+// menu item. Nil fields follow the master menu.
+type BrandMenuOverride struct {
+	RestaurantID uuid.UUID `json:"restaurant_id" db:"restaurant_id"`
+	BrandItemID  uuid.UUID `json:"brand_item_id" db:"brand_item_id"`
+	Price        *float64  `json:"price,omitempty" db:"price"`
+	Available    *bool     `json:"available,omitempty" db:"available"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type MenuChangeAction string
+
+const (
+	MenuChangeCreate MenuChangeAction = "create"
+	MenuChangeUpdate MenuChangeAction = "update"
+	MenuChangeRemove MenuChangeAction = "remove"
+)
+
+// MenuItemChange is a change publishing makes to one item on a
+// restaurant's menu. Fields names what an update changes.
+type MenuItemChange struct {
+	Action      MenuChangeAction `json:"action"`
+	BrandItemID *uuid.UUID       `json:"brand_item_id,omitempty"`
+	MenuItemID  *uuid.UUID       `json:"menu_item_id,omitempty"`
+	Name        string           `json:"name"`
+	Fields      []string         `json:"fields,omitempty"`
+	OldPrice    *float64         `json:"old_price,omitempty"`
+	NewPrice    *float64         `json:"new_price,omitempty"`
+}
+
+// LocationMenuDiff lists the changes publishing makes to one location.
+type LocationMenuDiff struct {
+	RestaurantID   uuid.UUID        `json:"restaurant_id"`
+	RestaurantName string           `json:"restaurant_name"`
+	Changes        []MenuItemChange `json:"changes"`
+}
+
+// BrandMenuDiff is what publishing a brand's master menu changes, or
+// changed, at each of its locations.
+type BrandMenuDiff struct {
+	BrandID       uuid.UUID          `json:"brand_id"`
+	Published     bool               `json:"published"`
+	ItemsAffected int                `json:"items_affected"`
+	Locations     []LocationMenuDiff `json:"locations"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "github.com/google/uuid"
+
+// BundleGroup is a choice a guest makes when ordering a bundle, such as
+// "pick a side". The guest picks between MinSelections and MaxSelections
+// components from Choices.
+type BundleGroup struct {
+	ID            uuid.UUID      `json:"id" db:"id"`
+	MenuItemID    uuid.UUID      `json:"menu_item_id" db:"menu_item_id"`
+	Name          string         `json:"name" db:"name"`
+	MinSelections int            `json:"min_selections" db:"min_selections"`
+	MaxSelections int            `json:"max_selections" db:"max_selections"`
+	Position      int            `json:"position" db:"position"`
+	Choices       []BundleChoice `json:"choices"`
+}
+
+// BundleChoice is an item allowed in a bundle group. A choice with a
+// variant only allows that variant, such as large fries. The upcharge is
+// added to the bundle price when the choice is picked.
+type BundleChoice struct {
+	MenuItemID uuid.UUID  `json:"menu_item_id" db:"menu_item_id"`
+	VariantID  *uuid.UUID `json:"variant_id,omitempty" db:"variant_id"`
+	Upcharge   float64    `json:"upcharge" db:"upcharge"`
+}
+
+// Choice returns the choice that allows the given item and variant.
+func (g *BundleGroup) Choice(menuItemID uuid.UUID, variantID *uuid.UUID) (BundleChoice, bool) {
+	for _, choice := range g.Choices {
+		if choice.MenuItemID != menuItemID {
+			continue
+		}
+		if choice.VariantID == nil || variantID == nil || *choice.VariantID == *variantID {
+			return choice, true
+		}
+	}
+	return BundleChoice{}, false
+}
+
+// Bundle is the set of choice groups that turns a menu item into a combo.
+// The menu item's price is the bundle price.
+type Bundle struct {
+	MenuItemID uuid.UUID     `json:"menu_item_id"`
+	Groups     []BundleGroup `json:"groups"`
+}
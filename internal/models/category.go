@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category groups the items of a restaurant's menu, such as starters or
+// desserts.
+type Category struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	RestaurantID uuid.UUID    `json:"restaurant_id" db:"restaurant_id"`
+	Name         string       `json:"name" db:"name"`
+	Description  string       `json:"description" db:"description"`
+	Translations Translations `json:"translations,omitempty" db:"translations"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CheckStatus string
+
+const (
+	CheckStatusOpen CheckStatus = "open"
+	CheckStatusPaid CheckStatus = "paid"
+)
+
+type SplitType string
+
+const (
+	SplitByItem   SplitType = "item"
+	SplitBySeat   SplitType = "seat"
+	SplitEvenly   SplitType = "even"
+	SplitByAmount SplitType = "amount"
+)
+
+// Check is one of the bills an order, or all open orders of a table, is
+// split into. Each check is paid separately.
+type Check struct {
+	ID           uuid.UUID   `json:"id" db:"id"`
+	RestaurantID uuid.UUID   `json:"restaurant_id" db:"restaurant_id"`
+	TableID      *uuid.UUID  `json:"table_id,omitempty" db:"table_id"`
+	SplitType    SplitType   `json:"split_type" db:"split_type"`
+	Label        string      `json:"label" db:"label"`
+	Amount       float64     `json:"amount" db:"amount"`
+	Status       CheckStatus `json:"status" db:"status"`
+	OrderIDs     []uuid.UUID `json:"order_ids"`
+	Items        []CheckItem `json:"items,omitempty"`
+	PaidAt       *time.Time  `json:"paid_at,omitempty" db:"paid_at"`
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// CheckItem assigns some or all of an order item's quantity to a check.
+type CheckItem struct {
+	CheckID     uuid.UUID `json:"check_id" db:"check_id"`
+	OrderItemID uuid.UUID `json:"order_item_id" db:"order_item_id"`
+	Quantity    int       `json:"quantity" db:"quantity"`
+	Amount      float64   `json:"amount" db:"amount"`
+}
+
+// SplitRequest describes how to split a bill.
+//   - item:   Checks lists which order items (and quantities) go on each check
+//   - seat:   one check per OrderItem.Seat, unseated items go on a shared check
+//   - even:   Parts equal checks
+//   - amount: one check per entry in Amounts, which must add up to the total
+type SplitRequest struct {
+	Type    SplitType          `json:"type"`
+	Parts   int                `json:"parts,omitempty"`
+	Amounts []float64          `json:"amounts,omitempty"`
+	Checks  []SplitCheckDetail `json:"checks,omitempty"`
+}
+
+type SplitCheckDetail struct {
+	Label string            `json:"label"`
+	Items []SplitItemDetail `json:"items"`
+}
+
+type SplitItemDetail struct {
+	OrderItemID uuid.UUID `json:"order_item_id"`
+	Quantity    int       `json:"quantity,omitempty"` // defaults to the full item quantity
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Allergen is one of the 14 allergens EU food law requires restaurants to
+// declare.
+type Allergen string
+
+const (
+	AllergenGluten      Allergen = "gluten"
+	AllergenCrustaceans Allergen = "crustaceans"
+	AllergenEggs        Allergen = "eggs"
+	AllergenFish        Allergen = "fish"
+	AllergenPeanuts     Allergen = "peanuts"
+	AllergenSoybeans    Allergen = "soybeans"
+	AllergenMilk        Allergen = "milk"
+	AllergenNuts        Allergen = "nuts"
+	AllergenCelery      Allergen = "celery"
+	AllergenMustard     Allergen = "mustard"
+	AllergenSesame      Allergen = "sesame"
+	AllergenSulphites   Allergen = "sulphites"
+	AllergenLupin       Allergen = "lupin"
+	AllergenMolluscs    Allergen = "molluscs"
+)
+
+var allergens = map[Allergen]bool{
+	AllergenGluten: true, AllergenCrustaceans: true, AllergenEggs: true,
+	AllergenFish: true, AllergenPeanuts: true, AllergenSoybeans: true,
+	AllergenMilk: true, AllergenNuts: true, AllergenCelery: true,
+	AllergenMustard: true, AllergenSesame: true, AllergenSulphites: true,
+	AllergenLupin: true, AllergenMolluscs: true,
+}
+
+func ValidAllergen(a Allergen) bool {
+	return allergens[a]
+}
+
+type DietaryTag string
+
+const (
+	DietaryVegan       DietaryTag = "vegan"
+	DietaryVegetarian  DietaryTag = "vegetarian"
+	DietaryGlutenFree  DietaryTag = "gluten_free"
+	DietaryDairyFree   DietaryTag = "dairy_free"
+	DietaryHalal       DietaryTag = "halal"
+	DietaryKosher      DietaryTag = "kosher"
+	DietaryPescatarian DietaryTag = "pescatarian"
+)
+
+func ValidDietaryTag(t DietaryTag) bool {
+	switch t {
+	case DietaryVegan, DietaryVegetarian, DietaryGlutenFree, DietaryDairyFree,
+		DietaryHalal, DietaryKosher, DietaryPescatarian:
+		return true
+	}
+	return false
+}
+
+// NutritionFacts are per serving; weights are in grams.
+type NutritionFacts struct {
+	Calories      int     `json:"calories"`
+	Fat           float64 `json:"fat"`
+	SaturatedFat  float64 `json:"saturated_fat"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Sugars        float64 `json:"sugars"`
+	Protein       float64 `json:"protein"`
+	Salt          float64 `json:"salt"`
+}
+
+// MenuFilter narrows a menu listing to items carrying every dietary tag
+// and none of the excluded allergens.
+type MenuFilter struct {
+	DietaryTags      []DietaryTag
+	ExcludeAllergens []Allergen
+}
+
+// AllergyProfile is what a guest cannot or will not eat. Ordering an item
+// that contains one of the allergens or lacks one of the dietary tags
+// produces a warning.
+type AllergyProfile struct {
+	UserID      uuid.UUID    `json:"user_id" db:"user_id"`
+	Allergens   []Allergen   `json:"allergens" db:"allergens"`
+	DietaryTags []DietaryTag `json:"dietary_tags" db:"dietary_tags"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+}
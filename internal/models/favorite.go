@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Favorite is a menu item a guest saved for later.
+type Favorite struct {
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	MenuItemID uuid.UUID `json:"menu_item_id" db:"menu_item_id"`
+	MenuItem   *MenuItem `json:"menu_item,omitempty"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GiftCardTransactionType string
+
+const (
+	GiftCardIssue  GiftCardTransactionType = "issue"
+	GiftCardLoad   GiftCardTransactionType = "load"
+	GiftCardRedeem GiftCardTransactionType = "redeem"
+	GiftCardRefund GiftCardTransactionType = "refund"
+)
+
+// GiftCard is a stored-value card. A card sold by a restaurant can be
+// redeemed at the restaurants of the same organization or brand; a card
+// without a restaurant is chain-wide and can be redeemed at any of them.
+// Its balance always equals the sum of its ledger transactions.
+type GiftCard struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	Code           string     `json:"code" db:"code"`
+	InitialBalance float64    `json:"initial_balance" db:"initial_balance"`
+	Balance        float64    `json:"balance" db:"balance"`
+	RestaurantID   *uuid.UUID `json:"restaurant_id,omitempty" db:"restaurant_id"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// GiftCardTransaction is an append-only ledger entry. Amount is positive
+// for value added to the card and negative for value taken off it.
+type GiftCardTransaction struct {
+	ID           uuid.UUID               `json:"id" db:"id"`
+	GiftCardID   uuid.UUID               `json:"gift_card_id" db:"gift_card_id"`
+	Type         GiftCardTransactionType `json:"type" db:"type"`
+	Amount       float64                 `json:"amount" db:"amount"`
+	BalanceAfter float64                 `json:"balance_after" db:"balance_after"`
+	OrderID      *uuid.UUID              `json:"order_id,omitempty" db:"order_id"`
+	PaymentID    *uuid.UUID              `json:"payment_id,omitempty" db:"payment_id"`
+	RestaurantID *uuid.UUID              `json:"restaurant_id,omitempty" db:"restaurant_id"`
+	Note         string                  `json:"note,omitempty" db:"note"`
+	CreatedBy    *uuid.UUID              `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time               `json:"created_at" db:"created_at"`
+}
+
+type GiftCardRequest struct {
+	Amount       float64    `json:"amount"`
+	RestaurantID *uuid.UUID `json:"restaurant_id,omitempty"`
+	Note         string     `json:"note,omitempty"`
+}
+
+// GiftCardBalance is the public answer to a balance lookup.
+type GiftCardBalance struct {
+	Code    string  `json:"code"`
+	Balance float64 `json:"balance"`
+}
+
+// GiftCardStatement is a card with its full ledger. Reconciled reports
+// whether the ledger sums to the card balance.
+type GiftCardStatement struct {
+	Card          *GiftCard              `json:"card"`
+	Transactions  []*GiftCardTransaction `json:"transactions"`
+	LedgerBalance float64                `json:"ledger_balance"`
+	Reconciled    bool                   `json:"reconciled"`
+}
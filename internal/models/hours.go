@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OpeningHours is a restaurant's weekly timetable with exceptions for
+// holidays and special closures, in the restaurant's timezone. A day can
+// have several intervals. An interval that closes at or before it opens,
+// such as 18:00-02:00, runs past midnight and belongs to the day it opens
+// on; 00:00-00:00 is open all day.
+type OpeningHours struct {
+	RestaurantID uuid.UUID         `json:"restaurant_id" db:"restaurant_id"`
+	Weekly       []OpeningInterval `json:"weekly" db:"weekly"`
+	Exceptions   []HoursException  `json:"exceptions" db:"exceptions"`
+	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// OpeningInterval is a time range on a day of the week, numbered from
+// Sunday (0).
+type OpeningInterval struct {
+	DayOfWeek int `json:"day_of_week"`
+	TimeRange
+}
+
+// TimeRange is an opening and closing time as HH:MM.
+type TimeRange struct {
+	Opens  string `json:"opens"`
+	Closes string `json:"closes"`
+}
+
+// HoursException replaces the weekly hours on a date (YYYY-MM-DD). An
+// exception without intervals closes the restaurant for the day.
+type HoursException struct {
+	Date      string      `json:"date"`
+	Name      string      `json:"name,omitempty"`
+	Intervals []TimeRange `json:"intervals"`
+}
+
+// OpenStatus is whether a restaurant is open at a time and, if it is
+// closed, when it next opens. NextOpenAt is nil if it does not open again
+// within the lookahead.
+type OpenStatus struct {
+	IsOpen     bool       `json:"is_open"`
+	NextOpenAt *time.Time `json:"next_open_at,omitempty"`
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IngredientUnit string
+
+const (
+	UnitGram       IngredientUnit = "g"
+	UnitKilogram   IngredientUnit = "kg"
+	UnitMilliliter IngredientUnit = "ml"
+	UnitLiter      IngredientUnit = "l"
+	UnitPiece      IngredientUnit = "pcs"
+)
+
+// ValidIngredientUnit reports whether unit is one of the supported units.
+func ValidIngredientUnit(unit IngredientUnit) bool {
+	switch unit {
+	case UnitGram, UnitKilogram, UnitMilliliter, UnitLiter, UnitPiece:
+		return true
+	}
+	return false
+}
+
+type StockMovementType string
+
+const (
+	StockDelivery     StockMovementType = "delivery"
+	StockWaste        StockMovementType = "waste"
+	StockCount        StockMovementType = "count"
+	StockSale         StockMovementType = "sale"
+	StockSaleReversal StockMovementType = "sale_reversal"
+)
+
+// Ingredient is a raw ingredient a restaurant keeps in stock. Stock may go
+// negative when sales are recorded for stock that was never booked in.
+// ParLevel is the stock to reorder up to once it runs low.
+type Ingredient struct {
+	ID                uuid.UUID      `json:"id" db:"id"`
+	RestaurantID      uuid.UUID      `json:"restaurant_id" db:"restaurant_id"`
+	Name              string         `json:"name" db:"name"`
+	Unit              IngredientUnit `json:"unit" db:"unit"`
+	Stock             float64        `json:"stock" db:"stock"`
+	LowStockThreshold float64        `json:"low_stock_threshold" db:"low_stock_threshold"`
+	ParLevel          float64        `json:"par_level" db:"par_level"`
+	CreatedAt         time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// ReorderTarget is the stock level a purchase order should bring the
+// ingredient back to, twice the threshold when no par level is set.
+func (i *Ingredient) ReorderTarget() float64 {
+	if i.ParLevel > i.LowStockThreshold {
+		return i.ParLevel
+	}
+	return 2 * i.LowStockThreshold
+}
+
+// LowStock reports whether the stock is at or below the threshold.
+func (i *Ingredient) LowStock() bool {
+	return i.LowStockThreshold > 0 && i.Stock <= i.LowStockThreshold
+}
+
+// StockMovement is an entry in an ingredient's stock history. Quantity is
+// positive for stock coming in and negative for stock going out.
+type StockMovement struct {
+	ID           uuid.UUID         `json:"id" db:"id"`
+	IngredientID uuid.UUID         `json:"ingredient_id" db:"ingredient_id"`
+	RestaurantID uuid.UUID         `json:"restaurant_id" db:"restaurant_id"`
+	Type         StockMovementType `json:"type" db:"type"`
+	Quantity     float64           `json:"quantity" db:"quantity"`
+	StockAfter   float64           `json:"stock_after" db:"stock_after"`
+	OrderID      *uuid.UUID        `json:"order_id,omitempty" db:"order_id"`
+	Note         string            `json:"note,omitempty" db:"note"`
+	CreatedBy    *uuid.UUID        `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+}
+
+// StockAdjustmentRequest is a manual stock change. Deliveries and waste
+// give the quantity moved; a count gives the quantity found on the shelf.
+type StockAdjustmentRequest struct {
+	Type     StockMovementType `json:"type"`
+	Quantity float64           `json:"quantity"`
+	Note     string            `json:"note,omitempty"`
+}
+
+// RecipeLine is the quantity of an ingredient used by one portion of a
+// menu item. Lines with a customization only apply when the guest picks
+// Option for it.
+type RecipeLine struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	MenuItemID      uuid.UUID  `json:"menu_item_id" db:"menu_item_id"`
+	IngredientID    uuid.UUID  `json:"ingredient_id" db:"ingredient_id"`
+	Quantity        float64    `json:"quantity" db:"quantity"`
+	CustomizationID *uuid.UUID `json:"customization_id,omitempty" db:"customization_id"`
+	Option          string     `json:"option,omitempty" db:"option"`
+}
+
+// AppliesTo reports whether the line is used for an item ordered with the
+// given options.
+func (l RecipeLine) AppliesTo(options []OrderItemOption) bool {
+	if l.CustomizationID == nil {
+		return true
+	}
+	for _, option := range options {
+		if option.CustomizationID == *l.CustomizationID && option.Value == l.Option {
+			return true
+		}
+	}
+	return false
+}
+
+type Recipe struct {
+	MenuItemID uuid.UUID    `json:"menu_item_id"`
+	Lines      []RecipeLine `json:"lines"`
+}
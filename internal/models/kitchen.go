@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KitchenTicket is an order as the kitchen sees it: what to cook, without
+// prices. Label says how the order leaves the kitchen, such as "DINE IN",
+// "PICKUP: Sam" or "DELIVERY", for displays to show prominently.
+type KitchenTicket struct {
+	OrderID      uuid.UUID           `json:"order_id"`
+	Type         OrderType           `json:"type"`
+	Label        string              `json:"label"`
+	TableID      *uuid.UUID          `json:"table_id,omitempty"`
+	PickupAt     *time.Time          `json:"pickup_at,omitempty"`
+	CustomerName string              `json:"customer_name,omitempty"`
+	Status       OrderStatus         `json:"status"`
+	Items        []KitchenTicketItem `json:"items"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+// KitchenTicketItem is a line on a kitchen ticket. Options are the chosen
+// customizations in readable form, such as "Milk: Oat". A bundle lists its
+// components, with quantities for the whole line.
+type KitchenTicketItem struct {
+	OrderItemID uuid.UUID           `json:"order_item_id"`
+	Name        string              `json:"name"`
+	Variant     string              `json:"variant,omitempty"`
+	Quantity    int                 `json:"quantity"`
+	Seat        *int                `json:"seat,omitempty"`
+	Options     []string            `json:"options,omitempty"`
+	SentAt      *time.Time          `json:"sent_at,omitempty"`
+	Components  []KitchenTicketItem `json:"components,omitempty"`
+}
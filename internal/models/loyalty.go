@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LoyaltyTransactionType string
+
+const (
+	LoyaltyEarn   LoyaltyTransactionType = "earn"
+	LoyaltyRedeem LoyaltyTransactionType = "redeem"
+	// LoyaltyRestore gives back points redeemed on an order that was
+	// canceled.
+	LoyaltyRestore LoyaltyTransactionType = "restore"
+	LoyaltyExpire  LoyaltyTransactionType = "expire"
+	// LoyaltyReverse takes back the share of the points earned on an order
+	// that a refund paid back.
+	LoyaltyReverse LoyaltyTransactionType = "reverse"
+)
+
+type LoyaltyTier string
+
+const (
+	TierBronze LoyaltyTier = "bronze"
+	TierSilver LoyaltyTier = "silver"
+	TierGold   LoyaltyTier = "gold"
+)
+
+// TierLevel is reached once a member has earned MinPoints in total. Points
+// earned at a level are multiplied by Multiplier.
+type TierLevel struct {
+	Tier       LoyaltyTier `json:"tier"`
+	MinPoints  int         `json:"min_points"`
+	Multiplier float64     `json:"multiplier"`
+}
+
+// LoyaltyTiers are ordered from lowest to highest.
+var LoyaltyTiers = []TierLevel{
+	{Tier: TierBronze, MinPoints: 0, Multiplier: 1},
+	{Tier: TierSilver, MinPoints: 1000, Multiplier: 1.25},
+	{Tier: TierGold, MinPoints: 5000, Multiplier: 1.5},
+}
+
+// TierFor returns the level reached with the given lifetime points.
+func TierFor(lifetimePoints int) TierLevel {
+	level := LoyaltyTiers[0]
+	for _, candidate := range LoyaltyTiers {
+		if lifetimePoints >= candidate.MinPoints {
+			level = candidate
+		}
+	}
+	return level
+}
+
+// LoyaltyProgram configures earning and redemption. A program without a
+// restaurant applies chain-wide to restaurants without their own.
+type LoyaltyProgram struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	RestaurantID *uuid.UUID `json:"restaurant_id,omitempty" db:"restaurant_id"`
+	// PointsPerUnit is earned per currency unit spent on items, after
+	// discounts.
+	PointsPerUnit float64 `json:"points_per_unit" db:"points_per_unit"`
+	// PointValue is the discount one point is worth at checkout.
+	PointValue float64 `json:"point_value" db:"point_value"`
+	// ExpiryDays is how long earned points stay valid; 0 means forever.
+	ExpiryDays int       `json:"expiry_days" db:"expiry_days"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultLoyaltyProgram applies when no program has been configured.
+func DefaultLoyaltyProgram() *LoyaltyProgram {
+	return &LoyaltyProgram{
+		PointsPerUnit: 1,
+		PointValue:    0.01,
+		ExpiryDays:    365,
+		Active:        true,
+	}
+}
+
+// ExpiryFrom returns when points credited at the given time expire, or nil
+// if they never do.
+func (p *LoyaltyProgram) ExpiryFrom(t time.Time) *time.Time {
+	if p.ExpiryDays == 0 {
+		return nil
+	}
+	expiry := t.AddDate(0, 0, p.ExpiryDays)
+	return &expiry
+}
+
+type LoyaltyAccount struct {
+	UserID         uuid.UUID   `json:"user_id" db:"user_id"`
+	Balance        int         `json:"balance" db:"balance"`
+	LifetimePoints int         `json:"lifetime_points" db:"lifetime_points"`
+	Tier           LoyaltyTier `json:"tier"`
+	NextTier       LoyaltyTier `json:"next_tier,omitempty"`
+	PointsToNext   int         `json:"points_to_next_tier,omitempty"`
+	// ExpiringPoints expire at NextExpiryAt unless they are redeemed first.
+	ExpiringPoints int        `json:"expiring_points,omitempty"`
+	NextExpiryAt   *time.Time `json:"next_expiry_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// LoyaltyTransaction is a ledger entry. Points are positive when credited
+// and negative when redeemed or expired.
+type LoyaltyTransaction struct {
+	ID           uuid.UUID              `json:"id" db:"id"`
+	UserID       uuid.UUID              `json:"user_id" db:"user_id"`
+	RestaurantID *uuid.UUID             `json:"restaurant_id,omitempty" db:"restaurant_id"`
+	OrderID      *uuid.UUID             `json:"order_id,omitempty" db:"order_id"`
+	AdjustmentID *uuid.UUID             `json:"adjustment_id,omitempty" db:"adjustment_id"`
+	Type         LoyaltyTransactionType `json:"type" db:"type"`
+	Points       int                    `json:"points" db:"points"`
+	// Remaining is how much of a credit has not been redeemed or expired.
+	Remaining int        `json:"-" db:"remaining"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
@@ -6,14 +6,116 @@ import (
 	"github.com/google/uuid"
 )
 
+// MenuItem is a dish on a restaurant's menu. Items that are not available
+// stay on the menu and are shown as sold out. PortionsRemaining counts down
+// as orders come in and is nil when the kitchen does not count portions.
+// Nutrition is nil when the restaurant has not published nutrition facts.
+// An item with variants is ordered as one of its variants, and Price is
+// then only the price shown on the menu. An item with bundle groups is a
+// combo sold at Price plus the upcharges of the chosen components.
+// Translations hold the name and description in other locales. SKU is the
+// restaurant's own code for the item, used to match it in bulk imports.
+// BrandItemID links an item to the brand master menu item it was published
+// from; publishing overwrites it.
 type MenuItem struct {
-	ID           uuid.UUID `json:"id" db:"id"`
-	RestaurantID uuid.UUID `json:"restaurant_id" db:"restaurant_id"`
-	CategoryID   uuid.UUID `json:"category_id" db:"category_id"`
-	Name         string    `json:"name" db:"name"`
-	Description  string    `json:"description" db:"description"`
-	Price        float64   `json:"price" db:"price"`
-	ImageURLs    []string  `json:"image_urls" db:"image_urls"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID                uuid.UUID         `json:"id" db:"id"`
+	RestaurantID      uuid.UUID         `json:"restaurant_id" db:"restaurant_id"`
+	SKU               string            `json:"sku,omitempty" db:"sku"`
+	BrandItemID       *uuid.UUID        `json:"brand_item_id,omitempty" db:"brand_item_id"`
+	CategoryID        uuid.UUID         `json:"category_id" db:"category_id"`
+	Name              string            `json:"name" db:"name"`
+	Description       string            `json:"description" db:"description"`
+	Translations      Translations      `json:"translations,omitempty" db:"translations"`
+	Price             float64           `json:"price" db:"price"`
+	ImageURLs         []string          `json:"image_urls" db:"image_urls"`
+	Available         bool              `json:"available" db:"available"`
+	PortionsRemaining *int              `json:"portions_remaining,omitempty" db:"portions_remaining"`
+	Allergens         []Allergen        `json:"allergens" db:"allergens"`
+	DietaryTags       []DietaryTag      `json:"dietary_tags" db:"dietary_tags"`
+	Nutrition         *NutritionFacts   `json:"nutrition,omitempty" db:"nutrition"`
+	Variants          []MenuItemVariant `json:"variants,omitempty"`
+	BundleGroups      []BundleGroup     `json:"bundle_groups,omitempty"`
+	Rating            *RatingSummary    `json:"rating,omitempty"`
+	CreatedAt         time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// MenuItemVariant is a size or version of a menu item with its own price,
+// such as a small or large coffee.
+type MenuItemVariant struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	MenuItemID uuid.UUID `json:"menu_item_id" db:"menu_item_id"`
+	Name       string    `json:"name" db:"name"`
+	SKU        string    `json:"sku,omitempty" db:"sku"`
+	Price      float64   `json:"price" db:"price"`
+	Available  bool      `json:"available" db:"available"`
+	Position   int       `json:"position" db:"position"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Variant returns the variant with the given ID.
+func (i *MenuItem) Variant(id uuid.UUID) (*MenuItemVariant, bool) {
+	for j := range i.Variants {
+		if i.Variants[j].ID == id {
+			return &i.Variants[j], true
+		}
+	}
+	return nil, false
+}
+
+// MenuChangeSet is the write set of a menu publish, applied in one
+// transaction. New categories and items carry their new IDs. Availability
+// is set after the items are written, by menu item ID. Removed items are
+// deleted, or retired if they have been ordered; updating a retired item
+// brings it back.
+type MenuChangeSet struct {
+	Categories   []*Category
+	Create       []*MenuItem
+	Update       []*MenuItem
+	Availability map[uuid.UUID]bool
+	Remove       []uuid.UUID
+}
+
+// MenuItemAvailability is what staff send to 86 an item or bring it back.
+type MenuItemAvailability struct {
+	Available         bool `json:"available"`
+	PortionsRemaining *int `json:"portions_remaining,omitempty"`
+}
+
+type CustomizationFieldType string
+
+const (
+	CustomizationBoolean      CustomizationFieldType = "boolean"
+	CustomizationText         CustomizationFieldType = "text"
+	CustomizationSingleSelect CustomizationFieldType = "single_select"
+	CustomizationMultiSelect  CustomizationFieldType = "multi_select"
+)
+
+// MenuItemCustomization is a modifier guests can pick when ordering an
+// item, such as a size or extra toppings.
+type MenuItemCustomization struct {
+	ID         uuid.UUID              `json:"id" db:"id"`
+	MenuItemID uuid.UUID              `json:"menu_item_id" db:"menu_item_id"`
+	Name       string                 `json:"name" db:"name"`
+	FieldType  CustomizationFieldType `json:"field_type" db:"field_type"`
+	Options    []CustomizationOption  `json:"options" db:"options"`
+	Required   bool                   `json:"required" db:"required"`
+}
+
+// CustomizationOption is a choice of a select customization. Its price is
+// added to the item price when chosen.
+type CustomizationOption struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+// Option returns the option with the given name.
+func (c *MenuItemCustomization) Option(name string) (CustomizationOption, bool) {
+	for _, option := range c.Options {
+		if option.Name == name {
+			return option, true
+		}
+	}
+	return CustomizationOption{}, false
 }
@@ -0,0 +1,74 @@
+package models
+
+import "github.com/google/uuid"
+
+// MenuImport is a restaurant's menu in the bulk import and export format.
+// Items are matched to existing menu items by SKU and categories by name.
+type MenuImport struct {
+	Categories []MenuImportCategory `json:"categories,omitempty"`
+	Items      []MenuImportRow      `json:"items"`
+}
+
+// MenuImportCategory sets the description of a category in an import.
+// Categories named by items but not listed here are created without one.
+type MenuImportCategory struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// MenuImportRow is one menu item in an import. Row is the CSV line or the
+// position in the JSON items array and is used to report errors. Nil
+// Customizations leave an existing item's customizations as they are; an
+// empty list removes them.
+type MenuImportRow struct {
+	Row            int                       `json:"-"`
+	SKU            string                    `json:"sku"`
+	Category       string                    `json:"category"`
+	Name           string                    `json:"name"`
+	Description    string                    `json:"description,omitempty"`
+	Price          float64                   `json:"price"`
+	Allergens      []Allergen                `json:"allergens,omitempty"`
+	DietaryTags    []DietaryTag              `json:"dietary_tags,omitempty"`
+	Customizations []MenuImportCustomization `json:"customizations,omitempty"`
+}
+
+// MenuImportCustomization is a customization of an imported item. It is
+// matched to the item's existing customizations by name.
+type MenuImportCustomization struct {
+	Name      string                 `json:"name"`
+	FieldType CustomizationFieldType `json:"field_type"`
+	Options   []CustomizationOption  `json:"options,omitempty"`
+	Required  bool                   `json:"required,omitempty"`
+}
+
+// MenuImportItem is a menu item to create, when its ID is unset, or update
+// during an import, along with its category, which may be created by the
+// same import, and the customizations to replace its own with.
+type MenuImportItem struct {
+	Item           *MenuItem
+	Category       *Category
+	Customizations []MenuItemCustomization
+}
+
+// MenuImportError is a problem with one row of an import. Row is 0 for
+// problems with the file as a whole.
+type MenuImportError struct {
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// MenuImportResult reports what an import changed, or would change on a
+// dry run. Nothing is changed when there are errors.
+type MenuImportResult struct {
+	RestaurantID      uuid.UUID         `json:"restaurant_id"`
+	DryRun            bool              `json:"dry_run"`
+	Applied           bool              `json:"applied"`
+	Rows              int               `json:"rows"`
+	ItemsCreated      int               `json:"items_created"`
+	ItemsUpdated      int               `json:"items_updated"`
+	CategoriesCreated int               `json:"categories_created"`
+	CategoriesUpdated int               `json:"categories_updated"`
+	Errors            []MenuImportError `json:"errors"`
+}
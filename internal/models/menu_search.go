@@ -0,0 +1,65 @@
+package models
+
+import "github.com/google/uuid"
+
+// MenuSearchQuery is a search of a restaurant's menu. The filters narrow
+// the hits but not the facets, so a guest can see what other choices they
+// have.
+type MenuSearchQuery struct {
+	Text        string
+	CategoryIDs []uuid.UUID
+	MinPrice    *float64
+	MaxPrice    *float64
+	DietaryTags []DietaryTag
+	Limit       int
+	Offset      int
+}
+
+// MenuSearchHit is a matching item with its relevance and the matched
+// words of its name and description wrapped in <mark> tags.
+type MenuSearchHit struct {
+	Item       *MenuItem            `json:"item"`
+	Rank       float64              `json:"rank"`
+	Highlights MenuSearchHighlights `json:"highlights"`
+}
+
+type MenuSearchHighlights struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// MenuSearchResult is a page of hits, best first. Fuzzy is set when
+// nothing matched the words of the query and the hits are close spellings
+// instead.
+type MenuSearchResult struct {
+	Query  string           `json:"query"`
+	Fuzzy  bool             `json:"fuzzy"`
+	Total  int              `json:"total"`
+	Hits   []MenuSearchHit  `json:"hits"`
+	Facets MenuSearchFacets `json:"facets"`
+}
+
+type MenuSearchFacets struct {
+	Categories  []CategoryFacet   `json:"categories"`
+	PriceRanges []PriceRangeFacet `json:"price_ranges"`
+	DietaryTags []DietaryTagFacet `json:"dietary_tags"`
+}
+
+type CategoryFacet struct {
+	CategoryID uuid.UUID `json:"category_id"`
+	Name       string    `json:"name"`
+	Count      int       `json:"count"`
+}
+
+// PriceRangeFacet counts the hits priced from Min up to but not including
+// Max. The last range has no Max.
+type PriceRangeFacet struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int      `json:"count"`
+}
+
+type DietaryTagFacet struct {
+	Tag   DietaryTag `json:"tag"`
+	Count int        `json:"count"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MenuVersionStatus string
+
+const (
+	MenuVersionDraft     MenuVersionStatus = "draft"
+	MenuVersionScheduled MenuVersionStatus = "scheduled"
+	MenuVersionPublished MenuVersionStatus = "published"
+	MenuVersionArchived  MenuVersionStatus = "archived"
+)
+
+// MenuVersion is a numbered snapshot of a restaurant's menu items. Drafts
+// are edited without affecting the live menu and published atomically,
+// right away or at PublishAt. The published version is the live menu;
+// earlier ones are archived and can be rolled back to.
+type MenuVersion struct {
+	ID           uuid.UUID         `json:"id" db:"id"`
+	RestaurantID uuid.UUID         `json:"restaurant_id" db:"restaurant_id"`
+	Number       int               `json:"number" db:"number"`
+	Status       MenuVersionStatus `json:"status" db:"status"`
+	Note         string            `json:"note,omitempty" db:"note"`
+	Items        []MenuVersionItem `json:"items,omitempty" db:"items"`
+	PublishAt    *time.Time        `json:"publish_at,omitempty" db:"publish_at"`
+	PublishedAt  *time.Time        `json:"published_at,omitempty" db:"published_at"`
+	CreatedBy    uuid.UUID         `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// MenuVersionItem is a menu item as it is in a version. MenuItemID is the
+// live item it updates and is nil for items the version adds. Stock and
+// availability are not versioned.
+type MenuVersionItem struct {
+	MenuItemID   *uuid.UUID      `json:"menu_item_id,omitempty"`
+	SKU          string          `json:"sku,omitempty"`
+	CategoryID   uuid.UUID       `json:"category_id"`
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	Translations Translations    `json:"translations,omitempty"`
+	Price        float64         `json:"price"`
+	Allergens    []Allergen      `json:"allergens"`
+	DietaryTags  []DietaryTag    `json:"dietary_tags"`
+	Nutrition    *NutritionFacts `json:"nutrition,omitempty"`
+}
+
+// MenuVersionPublish is the request to publish a version. A nil or past
+// PublishAt publishes right away.
+type MenuVersionPublish struct {
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+}
+
+// MenuVersionPreview is what publishing a version would change on the
+// live menu.
+type MenuVersionPreview struct {
+	VersionID uuid.UUID        `json:"version_id"`
+	Changes   []MenuItemChange `json:"changes"`
+}
@@ -14,24 +14,118 @@ const (
 	OrderStatusReady    OrderStatus = "ready"
 	OrderStatusComplete OrderStatus = "complete"
 	OrderStatusCanceled OrderStatus = "canceled"
+	// OrderStatusOutForDelivery is a delivery order that has left the
+	// restaurant. Only delivery orders pass through it.
+	OrderStatusOutForDelivery OrderStatus = "out_for_delivery"
 )
 
+// OrderType is how an order reaches the guest.
+type OrderType string
+
+const (
+	// OrderTypeDineIn is eaten at the restaurant, usually at a table.
+	OrderTypeDineIn OrderType = "dine_in"
+	// OrderTypePickup is collected by the guest at a set time.
+	OrderTypePickup OrderType = "pickup"
+	// OrderTypeDelivery is taken to the guest's address.
+	OrderTypeDelivery OrderType = "delivery"
+)
+
+// Order is a guest's order. Which of the type's fields apply depends on
+// Type: a table for dine-in, a pickup time and name for pickup, and an
+// address, phone number and fee for delivery.
 type Order struct {
-	ID           uuid.UUID   `json:"id" db:"id"`
-	UserID       uuid.UUID   `json:"user_id" db:"user_id"`
-	RestaurantID uuid.UUID   `json:"restaurant_id" db:"restaurant_id"`
-	TableID      uuid.UUID   `json:"table_id" db:"table_id"`
-	Status       OrderStatus `json:"status" db:"status"`
-	TotalAmount  float64     `json:"total_amount" db:"total_amount"`
-	Items        []OrderItem `json:"items"`
-	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at"`
+	ID              uuid.UUID          `json:"id" db:"id"`
+	UserID          uuid.UUID          `json:"user_id" db:"user_id"`
+	RestaurantID    uuid.UUID          `json:"restaurant_id" db:"restaurant_id"`
+	Type            OrderType          `json:"type" db:"order_type"`
+	TableID         uuid.UUID          `json:"table_id" db:"table_id"`
+	PickupAt        *time.Time         `json:"pickup_at,omitempty" db:"pickup_at"`
+	CustomerName    string             `json:"customer_name,omitempty" db:"customer_name"`
+	DeliveryAddress string             `json:"delivery_address,omitempty" db:"delivery_address"`
+	ContactPhone    string             `json:"contact_phone,omitempty" db:"contact_phone"`
+	DeliveryFee     float64            `json:"delivery_fee,omitempty" db:"delivery_fee"`
+	Status          OrderStatus        `json:"status" db:"status"`
+	PaymentStatus   OrderPaymentStatus `json:"payment_status" db:"payment_status"`
+	Subtotal        float64            `json:"subtotal" db:"subtotal"`
+	DiscountTotal   float64            `json:"discount_total" db:"discount_total"`
+	ServiceCharge   float64            `json:"service_charge" db:"service_charge"`
+	TaxTotal        float64            `json:"tax_total" db:"tax_total"`
+	TotalAmount     float64            `json:"total_amount" db:"total_amount"`
+	Currency        string             `json:"currency" db:"currency"`
+	PromoCode       string             `json:"promo_code,omitempty" db:"promo_code"`
+	LoyaltyPoints   int                `json:"loyalty_points,omitempty" db:"loyalty_points"`
+	MenuVersionID   *uuid.UUID         `json:"menu_version_id,omitempty" db:"menu_version_id"`
+	ScheduledFor    *time.Time         `json:"scheduled_for,omitempty" db:"scheduled_for"`
+	Items           []OrderItem        `json:"items"`
+	Discounts       []OrderDiscount    `json:"discounts,omitempty"`
+	Warnings        []OrderWarning     `json:"warnings,omitempty"`
+	CreatedAt       time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" db:"updated_at"`
 }
 
+// OrderItem is a line on an order. A bundle line carries its chosen
+// components, each with the bundle group it was picked for; component
+// quantities and prices are per bundle, the price being the upcharge.
 type OrderItem struct {
-	ID         uuid.UUID `json:"id" db:"id"`
-	OrderID    uuid.UUID `json:"order_id" db:"order_id"`
-	MenuItemID uuid.UUID `json:"menu_item_id" db:"menu_item_id"`
-	Quantity   int       `json:"quantity" db:"quantity"`
-	Price      float64   `json:"price" db:"price"`
+	ID               uuid.UUID         `json:"id" db:"id"`
+	OrderID          uuid.UUID         `json:"order_id" db:"order_id"`
+	MenuItemID       uuid.UUID         `json:"menu_item_id" db:"menu_item_id"`
+	VariantID        *uuid.UUID        `json:"variant_id,omitempty" db:"variant_id"`
+	VariantName      string            `json:"variant_name,omitempty" db:"variant_name"`
+	Quantity         int               `json:"quantity" db:"quantity"`
+	Price            float64           `json:"price" db:"price"`
+	Seat             *int              `json:"seat,omitempty" db:"seat"`
+	Options          []OrderItemOption `json:"options,omitempty" db:"options"`
+	BundleGroupID    *uuid.UUID        `json:"bundle_group_id,omitempty" db:"bundle_group_id"`
+	Components       []OrderItem       `json:"components,omitempty"`
+	SentAt           *time.Time        `json:"sent_at,omitempty" db:"sent_at"`
+	VoidedQuantity   int               `json:"voided_quantity" db:"voided_quantity"`
+	RefundedQuantity int               `json:"refunded_quantity" db:"refunded_quantity"`
+}
+
+// OrderItemOption is a customization chosen for an order item. Value is
+// the option name for select customizations, "true" for a checked boolean
+// and free text otherwise.
+type OrderItemOption struct {
+	CustomizationID uuid.UUID `json:"customization_id"`
+	Value           string    `json:"value"`
+}
+
+// ActiveQuantity is the quantity still on the bill after voids.
+func (i OrderItem) ActiveQuantity() int {
+	return i.Quantity - i.VoidedQuantity
+}
+
+// OrderStatusChange moves an order from one status to another together
+// with the stock movements and loyalty entry the change books.
+type OrderStatusChange struct {
+	From           OrderStatus
+	To             OrderStatus
+	StockMovements []*StockMovement
+	LoyaltyEntry   *LoyaltyTransaction
+}
+
+// OrderFilter narrows an order listing. Zero values are ignored.
+type OrderFilter struct {
+	Status       OrderStatus
+	Type         OrderType
+	RestaurantID *uuid.UUID
+	From         time.Time
+	To           time.Time
+	Limit        int
+	Offset       int
+}
+
+// OrderWarning tells the guest about something that changed or may be a
+// problem with an item they ordered, or with the order as a whole when it
+// has no menu item.
+type OrderWarning struct {
+	MenuItemID *uuid.UUID `json:"menu_item_id,omitempty"`
+	Message    string     `json:"message"`
+}
+
+type ReorderResult struct {
+	Order    *Order         `json:"order"`
+	Warnings []OrderWarning `json:"warnings"`
 }
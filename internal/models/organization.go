@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationRole is what a member may do in an organization. Owners
+// manage its members and restaurants; managers run its restaurants; staff
+// work in them.
+type OrganizationRole string
+
+const (
+	OrganizationRoleOwner   OrganizationRole = "owner"
+	OrganizationRoleManager OrganizationRole = "manager"
+	OrganizationRoleStaff   OrganizationRole = "staff"
+)
+
+// Organization is a company that runs one or more restaurants. Role is the
+// caller's role in it when listed for a member.
+type Organization struct {
+	ID        uuid.UUID        `json:"id" db:"id"`
+	Name      string           `json:"name" db:"name"`
+	CreatedBy uuid.UUID        `json:"created_by" db:"created_by"`
+	Role      OrganizationRole `json:"role,omitempty"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// OrganizationMember is a user's membership of an organization.
+type OrganizationMember struct {
+	OrganizationID uuid.UUID        `json:"organization_id" db:"organization_id"`
+	UserID         uuid.UUID        `json:"user_id" db:"user_id"`
+	Email          string           `json:"email,omitempty"`
+	Role           OrganizationRole `json:"role" db:"role"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+}
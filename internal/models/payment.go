@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "pending"
+	PaymentStatusAuthorized        PaymentStatus = "authorized"
+	PaymentStatusCaptured          PaymentStatus = "captured"
+	PaymentStatusDeclined          PaymentStatus = "declined"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
+	PaymentStatusVoided            PaymentStatus = "voided"
+)
+
+// OrderPaymentStatus summarizes the payments made against an order.
+type OrderPaymentStatus string
+
+const (
+	OrderPaymentUnpaid        OrderPaymentStatus = "unpaid"
+	OrderPaymentPartiallyPaid OrderPaymentStatus = "partially_paid"
+	OrderPaymentPaid          OrderPaymentStatus = "paid"
+	OrderPaymentRefunded      OrderPaymentStatus = "refunded"
+)
+
+type Payment struct {
+	ID             uuid.UUID     `json:"id" db:"id"`
+	OrderID        uuid.UUID     `json:"order_id" db:"order_id"`
+	CheckID        *uuid.UUID    `json:"check_id,omitempty" db:"check_id"`
+	Provider       string        `json:"provider" db:"provider"`
+	ProviderRef    string        `json:"provider_ref" db:"provider_ref"`
+	Amount         float64       `json:"amount" db:"amount"`
+	CapturedAmount float64       `json:"captured_amount" db:"captured_amount"`
+	RefundedAmount float64       `json:"refunded_amount" db:"refunded_amount"`
+	Status         PaymentStatus `json:"status" db:"status"`
+	FailureReason  string        `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt      time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// PaymentRequest starts a payment for an order or one of its checks. Amount
+// defaults to the check amount or the order's outstanding balance. Token is
+// passed through to the provider (card token, gift card code, ...).
+type PaymentRequest struct {
+	CheckID  *uuid.UUID `json:"check_id,omitempty"`
+	Provider string     `json:"provider"`
+	Amount   float64    `json:"amount,omitempty"`
+	Token    string     `json:"token,omitempty"`
+	Capture  bool       `json:"capture"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PricingRuleType string
+
+const (
+	// PricingPercentOff takes Value percent off the scoped items or order.
+	PricingPercentOff PricingRuleType = "percent_off"
+	// PricingAmountOff takes Value off each scoped item, or off the order.
+	PricingAmountOff PricingRuleType = "amount_off"
+	// PricingBuyXGetY discounts GetQuantity of every BuyQuantity+GetQuantity
+	// scoped items by Value percent (100 when unset), cheapest first.
+	PricingBuyXGetY PricingRuleType = "buy_x_get_y"
+	// PricingFixedPrice sells each scoped item for Value.
+	PricingFixedPrice PricingRuleType = "fixed_price"
+)
+
+type PricingScope string
+
+const (
+	PricingScopeOrder    PricingScope = "order"
+	PricingScopeCategory PricingScope = "category"
+	PricingScopeMenuItem PricingScope = "menu_item"
+)
+
+// PricingRule is a discount evaluated when an order is priced. Rules with a
+// PromoCode only apply when the order carries that code. Time windows are
+// evaluated in the restaurant's local time; StartTime and EndTime use
+// "HH:MM" and DaysOfWeek uses 0 for Sunday.
+type PricingRule struct {
+	ID           uuid.UUID       `json:"id" db:"id"`
+	RestaurantID uuid.UUID       `json:"restaurant_id" db:"restaurant_id"`
+	Name         string          `json:"name" db:"name"`
+	Type         PricingRuleType `json:"type" db:"type"`
+	Scope        PricingScope    `json:"scope" db:"scope"`
+	ScopeID      *uuid.UUID      `json:"scope_id,omitempty" db:"scope_id"`
+	Value        float64         `json:"value" db:"value"`
+	BuyQuantity  int             `json:"buy_quantity,omitempty" db:"buy_quantity"`
+	GetQuantity  int             `json:"get_quantity,omitempty" db:"get_quantity"`
+	PromoCode    *string         `json:"promo_code,omitempty" db:"promo_code"`
+	MinSubtotal  float64         `json:"min_subtotal" db:"min_subtotal"`
+	ValidFrom    *time.Time      `json:"valid_from,omitempty" db:"valid_from"`
+	ValidUntil   *time.Time      `json:"valid_until,omitempty" db:"valid_until"`
+	DaysOfWeek   []int           `json:"days_of_week,omitempty" db:"days_of_week"`
+	StartTime    string          `json:"start_time,omitempty" db:"start_time"`
+	EndTime      string          `json:"end_time,omitempty" db:"end_time"`
+	UsageLimit   *int            `json:"usage_limit,omitempty" db:"usage_limit"`
+	UsageCount   int             `json:"usage_count" db:"usage_count"`
+	Stackable    bool            `json:"stackable" db:"stackable"`
+	Priority     int             `json:"priority" db:"priority"`
+	Active       bool            `json:"active" db:"active"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// OrderDiscount is a discount applied to an order when it was priced.
+type OrderDiscount struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	OrderID     uuid.UUID  `json:"order_id" db:"order_id"`
+	RuleID      *uuid.UUID `json:"rule_id,omitempty" db:"rule_id"`
+	Code        string     `json:"code,omitempty" db:"code"`
+	Description string     `json:"description" db:"description"`
+	Amount      float64    `json:"amount" db:"amount"`
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderDraft             PurchaseOrderStatus = "draft"
+	PurchaseOrderSent              PurchaseOrderStatus = "sent"
+	PurchaseOrderPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived          PurchaseOrderStatus = "received"
+)
+
+type Supplier struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	RestaurantID uuid.UUID `json:"restaurant_id" db:"restaurant_id"`
+	Name         string    `json:"name" db:"name"`
+	ContactName  string    `json:"contact_name,omitempty" db:"contact_name"`
+	Email        string    `json:"email,omitempty" db:"email"`
+	Phone        string    `json:"phone,omitempty" db:"phone"`
+	Notes        string    `json:"notes,omitempty" db:"notes"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SupplierItem is an ingredient a supplier sells, at a cost per ingredient
+// unit. Name, Unit and OnHand come from the ingredient.
+type SupplierItem struct {
+	ID           uuid.UUID      `json:"id" db:"id"`
+	SupplierID   uuid.UUID      `json:"supplier_id" db:"supplier_id"`
+	IngredientID uuid.UUID      `json:"ingredient_id" db:"ingredient_id"`
+	SKU          string         `json:"sku,omitempty" db:"sku"`
+	UnitCost     float64        `json:"unit_cost" db:"unit_cost"`
+	Name         string         `json:"name"`
+	Unit         IngredientUnit `json:"unit"`
+	OnHand       float64        `json:"on_hand"`
+}
+
+type PurchaseOrder struct {
+	ID           uuid.UUID           `json:"id" db:"id"`
+	RestaurantID uuid.UUID           `json:"restaurant_id" db:"restaurant_id"`
+	SupplierID   uuid.UUID           `json:"supplier_id" db:"supplier_id"`
+	Status       PurchaseOrderStatus `json:"status" db:"status"`
+	ExpectedAt   *time.Time          `json:"expected_at,omitempty" db:"expected_at"`
+	Notes        string              `json:"notes,omitempty" db:"notes"`
+	Total        float64             `json:"total" db:"total"`
+	Lines        []PurchaseOrderLine `json:"lines"`
+	CreatedBy    *uuid.UUID          `json:"created_by,omitempty" db:"created_by"`
+	SentAt       *time.Time          `json:"sent_at,omitempty" db:"sent_at"`
+	CreatedAt    time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// Open reports whether the order still expects deliveries.
+func (o *PurchaseOrder) Open() bool {
+	return o.Status != PurchaseOrderReceived
+}
+
+type PurchaseOrderLine struct {
+	ID               uuid.UUID `json:"id" db:"id"`
+	PurchaseOrderID  uuid.UUID `json:"purchase_order_id" db:"purchase_order_id"`
+	IngredientID     uuid.UUID `json:"ingredient_id" db:"ingredient_id"`
+	Quantity         float64   `json:"quantity" db:"quantity"`
+	ReceivedQuantity float64   `json:"received_quantity" db:"received_quantity"`
+	UnitCost         float64   `json:"unit_cost" db:"unit_cost"`
+}
+
+// Outstanding is the quantity still to be delivered.
+func (l PurchaseOrderLine) Outstanding() float64 {
+	return max(l.Quantity-l.ReceivedQuantity, 0)
+}
+
+type PurchaseOrderFilter struct {
+	Status     PurchaseOrderStatus
+	SupplierID *uuid.UUID
+}
+
+// ReceiveRequest records a delivery against a purchase order. Lines left
+// out were not delivered this time.
+type ReceiveRequest struct {
+	Lines []ReceiveLine `json:"lines"`
+	Note  string        `json:"note,omitempty"`
+}
+
+type ReceiveLine struct {
+	LineID   uuid.UUID `json:"line_id"`
+	Quantity float64   `json:"quantity"`
+}
+
+// SuggestedPurchaseOrder groups the low-stock ingredients that should be
+// reordered from one supplier. SupplierID is nil for ingredients no
+// supplier sells.
+type SuggestedPurchaseOrder struct {
+	SupplierID   *uuid.UUID      `json:"supplier_id"`
+	SupplierName string          `json:"supplier_name,omitempty"`
+	Lines        []SuggestedLine `json:"lines"`
+	Total        float64         `json:"total"`
+}
+
+type SuggestedLine struct {
+	IngredientID      uuid.UUID      `json:"ingredient_id"`
+	Name              string         `json:"name"`
+	Unit              IngredientUnit `json:"unit"`
+	Stock             float64        `json:"stock"`
+	LowStockThreshold float64        `json:"low_stock_threshold"`
+	ParLevel          float64        `json:"par_level"`
+	OnOrder           float64        `json:"on_order"`
+	Quantity          float64        `json:"quantity"`
+	UnitCost          float64        `json:"unit_cost"`
+}
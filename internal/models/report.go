@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ItemSales is the quantity and revenue of one menu item, or one of its
+// variants, sold over a period. Revenue is at item prices, before
+// order-level discounts.
+type ItemSales struct {
+	MenuItemID  uuid.UUID  `json:"menu_item_id"`
+	Name        string     `json:"name"`
+	VariantID   *uuid.UUID `json:"variant_id,omitempty"`
+	VariantName string     `json:"variant_name,omitempty"`
+	Quantity    int        `json:"quantity"`
+	Revenue     float64    `json:"revenue"`
+}
+
+// SalesReport lists what a restaurant sold over a period, best sellers
+// first.
+type SalesReport struct {
+	RestaurantID uuid.UUID   `json:"restaurant_id"`
+	From         time.Time   `json:"from"`
+	To           time.Time   `json:"to"`
+	Quantity     int         `json:"quantity"`
+	Revenue      float64     `json:"revenue"`
+	Items        []ItemSales `json:"items"`
+}
@@ -6,14 +6,46 @@ import (
 	"github.com/google/uuid"
 )
 
+// Restaurant is a restaurant on the platform. Translations hold its
+// description in other locales. OrganizationID is the organization that
+// runs it, and BrandID is set for locations of a chain.
+// Latitude and Longitude are located from the address unless given.
+// IsOpen and NextOpenAt are worked out from the opening hours when the
+// restaurant is read; Rating and DistanceKm are only filled in by
+// discovery listings.
 type Restaurant struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	ManagerID   uuid.UUID `json:"manager_id" db:"manager_id"`
-	Address     string    `json:"address" db:"address"`
-	Phone       string    `json:"phone" db:"phone"`
-	LogoURL     string    `json:"logo_url" db:"logo_url"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID             uuid.UUID      `json:"id" db:"id"`
+	Name           string         `json:"name" db:"name"`
+	Description    string         `json:"description" db:"description"`
+	Translations   Translations   `json:"translations,omitempty" db:"translations"`
+	ManagerID      uuid.UUID      `json:"manager_id" db:"manager_id"`
+	OrganizationID *uuid.UUID     `json:"organization_id,omitempty" db:"organization_id"`
+	BrandID        *uuid.UUID     `json:"brand_id,omitempty" db:"brand_id"`
+	Address        string         `json:"address" db:"address"`
+	Latitude       *float64       `json:"latitude,omitempty" db:"latitude"`
+	Longitude      *float64       `json:"longitude,omitempty" db:"longitude"`
+	CuisineTags    []string       `json:"cuisine_tags" db:"cuisine_tags"`
+	Phone          string         `json:"phone" db:"phone"`
+	LogoURL        string         `json:"logo_url" db:"logo_url"`
+	Timezone       string         `json:"timezone" db:"timezone"`
+	IsOpen         *bool          `json:"is_open,omitempty"`
+	NextOpenAt     *time.Time     `json:"next_open_at,omitempty"`
+	Rating         *RatingSummary `json:"rating,omitempty"`
+	DistanceKm     *float64       `json:"distance_km,omitempty"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// RestaurantFilter narrows a restaurant listing. Zero values are ignored;
+// RadiusKm only applies together with a point to search around.
+type RestaurantFilter struct {
+	OrganizationID *uuid.UUID
+	Latitude       *float64
+	Longitude      *float64
+	RadiusKm       float64
+	CuisineTags    []string
+	MinRating      float64
+	OpenNow        bool
+	Limit          int
+	Offset         int
 }
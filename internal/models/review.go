@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MinRating = 1
+	MaxRating = 5
+	// LowRating is the highest score counted as a low score.
+	LowRating = 2
+)
+
+// Review is a guest's rating of a completed order, optionally with ratings
+// of the individual menu items.
+type Review struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	OrderID      uuid.UUID    `json:"order_id" db:"order_id"`
+	RestaurantID uuid.UUID    `json:"restaurant_id" db:"restaurant_id"`
+	UserID       uuid.UUID    `json:"user_id" db:"user_id"`
+	Rating       int          `json:"rating" db:"rating"`
+	Comment      string       `json:"comment,omitempty" db:"comment"`
+	Items        []ReviewItem `json:"items,omitempty"`
+	Reply        string       `json:"reply,omitempty" db:"reply"`
+	RepliedBy    *uuid.UUID   `json:"replied_by,omitempty" db:"replied_by"`
+	RepliedAt    *time.Time   `json:"replied_at,omitempty" db:"replied_at"`
+	Hidden       bool         `json:"hidden" db:"hidden"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+type ReviewItem struct {
+	ReviewID   uuid.UUID `json:"review_id" db:"review_id"`
+	MenuItemID uuid.UUID `json:"menu_item_id" db:"menu_item_id"`
+	Rating     int       `json:"rating" db:"rating"`
+	Comment    string    `json:"comment,omitempty" db:"comment"`
+}
+
+// RatingSummary aggregates the visible ratings of a menu item or restaurant.
+type RatingSummary struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+// ReviewFilter narrows a review listing. Zero values are ignored.
+type ReviewFilter struct {
+	MaxRating     int
+	Since         time.Time
+	IncludeHidden bool
+	Limit         int
+	Offset        int
+}
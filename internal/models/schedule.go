@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Menu is a named menu such as breakfast or dinner that makes its
+// categories and items orderable during its windows, in the restaurant's
+// timezone. A menu without windows is available at all times.
+type Menu struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	RestaurantID uuid.UUID    `json:"restaurant_id" db:"restaurant_id"`
+	Name         string       `json:"name" db:"name"`
+	Active       bool         `json:"active" db:"active"`
+	Windows      []MenuWindow `json:"windows"`
+	CategoryIDs  []uuid.UUID  `json:"category_ids"`
+	MenuItemIDs  []uuid.UUID  `json:"menu_item_ids"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// MenuWindow is a time of day on some days of the week, with days numbered
+// from Sunday (0). An empty DaysOfWeek means every day. A window that ends
+// before it starts, such as 22:00-02:00, runs past midnight and belongs to
+// the day it starts on.
+type MenuWindow struct {
+	DaysOfWeek []int  `json:"days_of_week"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaxMode is how tax applies to a restaurant's prices.
+type TaxMode string
+
+const (
+	// TaxModeNone charges no tax.
+	TaxModeNone TaxMode = "none"
+	// TaxModeExclusive adds tax on top of the menu prices.
+	TaxModeExclusive TaxMode = "exclusive"
+	// TaxModeInclusive treats the menu prices as already including tax.
+	TaxModeInclusive TaxMode = "inclusive"
+)
+
+// RestaurantSettings configure how a restaurant prices and handles orders.
+// Every save creates a new version, and a save must name the version it
+// was based on so that concurrent edits do not overwrite each other. A
+// restaurant that has never saved its settings has the defaults at
+// version 0. Rates are percentages; the delivery fee is a flat amount
+// charged on delivery orders.
+type RestaurantSettings struct {
+	RestaurantID      uuid.UUID  `json:"restaurant_id" db:"restaurant_id"`
+	Version           int        `json:"version" db:"version"`
+	Currency          string     `json:"currency" db:"currency"`
+	Timezone          string     `json:"timezone" db:"timezone"`
+	TaxMode           TaxMode    `json:"tax_mode" db:"tax_mode"`
+	TaxRate           float64    `json:"tax_rate" db:"tax_rate"`
+	ServiceChargeRate float64    `json:"service_charge_rate" db:"service_charge_rate"`
+	DeliveryFee       float64    `json:"delivery_fee" db:"delivery_fee"`
+	AutoAcceptOrders  bool       `json:"auto_accept_orders" db:"auto_accept_orders"`
+	ReceiptFooter     string     `json:"receipt_footer" db:"receipt_footer"`
+	UpdatedBy         *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
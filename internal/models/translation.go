@@ -0,0 +1,92 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Translation holds the translated text of a menu item, category or
+// restaurant in one locale. An empty field falls back to the base text.
+type Translation struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Translations maps lowercase locale tags such as "fr" or "pt-br" to the
+// translated text, stored alongside the base text it translates.
+type Translations map[string]Translation
+
+// Lookup returns the translation for the first of the preferred locales
+// that has one. A regional locale such as "fr-ca" falls back to its
+// language ("fr") before the next preference is tried.
+func (t Translations) Lookup(locales []string) (Translation, bool) {
+	for _, locale := range locales {
+		locale = strings.ToLower(locale)
+		if translation, ok := t[locale]; ok {
+			return translation, true
+		}
+		if language, _, found := strings.Cut(locale, "-"); found {
+			if translation, ok := t[language]; ok {
+				return translation, true
+			}
+		}
+	}
+	return Translation{}, false
+}
+
+// Localize replaces the name and description of the item with their
+// translation in the first preferred locale that has one.
+func (i *MenuItem) Localize(locales []string) {
+	if translation, ok := i.Translations.Lookup(locales); ok {
+		if translation.Name != "" {
+			i.Name = translation.Name
+		}
+		if translation.Description != "" {
+			i.Description = translation.Description
+		}
+	}
+}
+
+// Localize replaces the name and description of the category with their
+// translation in the first preferred locale that has one.
+func (c *Category) Localize(locales []string) {
+	if translation, ok := c.Translations.Lookup(locales); ok {
+		if translation.Name != "" {
+			c.Name = translation.Name
+		}
+		if translation.Description != "" {
+			c.Description = translation.Description
+		}
+	}
+}
+
+// Localize replaces the description of the restaurant with its translation
+// in the first preferred locale that has one. Restaurant names are not
+// translated.
+func (r *Restaurant) Localize(locales []string) {
+	if translation, ok := r.Translations.Lookup(locales); ok && translation.Description != "" {
+		r.Description = translation.Description
+	}
+}
+
+// MissingTranslation is a base string of a restaurant that has no
+// translation in a locale.
+type MissingTranslation struct {
+	EntityType string    `json:"entity_type"`
+	EntityID   uuid.UUID `json:"entity_id"`
+	Field      string    `json:"field"`
+	Locale     string    `json:"locale"`
+	BaseText   string    `json:"base_text"`
+}
+
+// TranslationReport lists the strings of a restaurant that are still
+// untranslated in each of the locales.
+type TranslationReport struct {
+	RestaurantID uuid.UUID            `json:"restaurant_id"`
+	Locales      []string             `json:"locales"`
+	Total        int                  `json:"total"`
+	Missing      []MissingTranslation `json:"missing"`
+	GeneratedAt  time.Time            `json:"generated_at"`
+}
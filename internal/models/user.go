@@ -25,3 +25,14 @@ type User struct {
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// AuthUser is the authenticated caller of a request.
+type AuthUser struct {
+	ID   uuid.UUID `json:"id"`
+	Role UserRole  `json:"role"`
+}
+
+// IsStaff reports whether the user works for a restaurant.
+func (u *AuthUser) IsStaff() bool {
+	return u.Role == RoleAdmin || u.Role == RoleManager || u.Role == RoleEmployee
+}
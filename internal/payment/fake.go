@@ -0,0 +1,181 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+const ProviderFake = "fake"
+
+// Test tokens understood by FakeProvider. Any other token is approved.
+const (
+	FakeTokenDecline = "tok_decline"
+	FakeTokenDelayed = "tok_delayed"
+)
+
+// FakeProvider simulates a card gateway for offline development. It keeps
+// its state in memory, declines FakeTokenDecline and, for FakeTokenDelayed,
+// leaves the payment pending and reports the outcome through a signed
+// webhook after a delay.
+type FakeProvider struct {
+	secret []byte
+	delay  time.Duration
+
+	mu       sync.Mutex
+	sink     WebhookSink
+	payments map[string]*fakePayment
+}
+
+type fakePayment struct {
+	authorized float64
+	captured   float64
+	refunded   float64
+	voided     bool
+}
+
+func NewFakeProvider(webhookSecret string, webhookDelay time.Duration) *FakeProvider {
+	return &FakeProvider{
+		secret:   []byte(webhookSecret),
+		delay:    webhookDelay,
+		payments: make(map[string]*fakePayment),
+	}
+}
+
+// SetWebhookSink sets where delayed webhooks are delivered.
+func (p *FakeProvider) SetWebhookSink(sink WebhookSink) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sink = sink
+}
+
+func (p *FakeProvider) Name() string {
+	return ProviderFake
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	ref := "fake_" + uuid.NewString()
+
+	if req.Token == FakeTokenDecline {
+		return &Result{Reference: ref, Status: models.PaymentStatusDeclined, Message: "card declined"}, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment := &fakePayment{authorized: req.Amount}
+	if req.Capture {
+		payment.captured = req.Amount
+	}
+	p.payments[ref] = payment
+
+	status := models.PaymentStatusAuthorized
+	if req.Capture {
+		status = models.PaymentStatusCaptured
+	}
+
+	if req.Token == FakeTokenDelayed {
+		p.scheduleWebhook(WebhookEvent{Reference: ref, Status: status, Amount: req.Amount})
+		return &Result{Reference: ref, Status: models.PaymentStatusPending}, nil
+	}
+
+	return &Result{Reference: ref, Status: status, Amount: req.Amount}, nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, ref string, amount float64) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[ref]
+	if !ok || payment.voided {
+		return nil, fmt.Errorf("fake: unknown or voided payment %s", ref)
+	}
+	if payment.captured+amount > payment.authorized+0.005 {
+		return nil, fmt.Errorf("fake: capture exceeds authorized amount")
+	}
+	payment.captured += amount
+
+	return &Result{Reference: ref, Status: models.PaymentStatusCaptured, Amount: amount}, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, ref string, amount float64) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[ref]
+	if !ok {
+		return nil, fmt.Errorf("fake: unknown payment %s", ref)
+	}
+	if payment.refunded+amount > payment.captured+0.005 {
+		return nil, fmt.Errorf("fake: refund exceeds captured amount")
+	}
+	payment.refunded += amount
+
+	return &Result{Reference: ref, Status: models.PaymentStatusRefunded, Amount: amount}, nil
+}
+
+func (p *FakeProvider) Void(ctx context.Context, ref string) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[ref]
+	if !ok {
+		return nil, fmt.Errorf("fake: unknown payment %s", ref)
+	}
+	if payment.captured > 0 {
+		return nil, fmt.Errorf("fake: captured payments must be refunded")
+	}
+	payment.voided = true
+
+	return &Result{Reference: ref, Status: models.PaymentStatusVoided}, nil
+}
+
+func (p *FakeProvider) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, p.sign(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (p *FakeProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// scheduleWebhook must be called with p.mu held.
+func (p *FakeProvider) scheduleWebhook(event WebhookEvent) {
+	sink := p.sink
+	if sink == nil {
+		log.Printf("fake payment provider: no webhook sink, dropping event for %s", event.Reference)
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("fake payment provider: %v", err)
+		return
+	}
+	signature := hex.EncodeToString(p.sign(payload))
+
+	time.AfterFunc(p.delay, func() {
+		if err := sink(context.Background(), ProviderFake, payload, signature); err != nil {
+			log.Printf("fake payment provider: webhook for %s failed: %v", event.Reference, err)
+		}
+	})
+}
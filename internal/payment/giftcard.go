@@ -0,0 +1,74 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+const ProviderGiftCard = "gift_card"
+
+// GiftCardLedger moves value on gift cards for payments.
+type GiftCardLedger interface {
+	// Redeem takes up to amount off the card and returns how much was taken.
+	Redeem(ctx context.Context, code string, amount float64, orderID, paymentID uuid.UUID) (float64, error)
+	// RefundPayment puts part of a gift card payment back on the card.
+	RefundPayment(ctx context.Context, paymentID uuid.UUID, amount float64) error
+}
+
+// GiftCardProvider pays with stored-value gift cards. The token is the card
+// code. Payments are captured immediately; when the card holds less than
+// the requested amount its whole balance is taken and the rest of the bill
+// stays open for another payment method.
+type GiftCardProvider struct {
+	ledger GiftCardLedger
+}
+
+func NewGiftCardProvider(ledger GiftCardLedger) *GiftCardProvider {
+	return &GiftCardProvider{ledger: ledger}
+}
+
+func (p *GiftCardProvider) Name() string {
+	return ProviderGiftCard
+}
+
+func (p *GiftCardProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	ref := req.PaymentID.String()
+	if req.Token == "" {
+		return &Result{Reference: ref, Status: models.PaymentStatusDeclined, Message: "gift card code is required"}, nil
+	}
+
+	redeemed, err := p.ledger.Redeem(ctx, req.Token, req.Amount, req.OrderID, req.PaymentID)
+	if err != nil {
+		return &Result{Reference: ref, Status: models.PaymentStatusDeclined, Message: err.Error()}, nil
+	}
+
+	return &Result{Reference: ref, Status: models.PaymentStatusCaptured, Amount: redeemed}, nil
+}
+
+func (p *GiftCardProvider) Capture(ctx context.Context, ref string, amount float64) (*Result, error) {
+	return nil, ErrUnsupported
+}
+
+func (p *GiftCardProvider) Refund(ctx context.Context, ref string, amount float64) (*Result, error) {
+	paymentID, err := uuid.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gift card payment reference %q", ref)
+	}
+
+	if err := p.ledger.RefundPayment(ctx, paymentID, amount); err != nil {
+		return nil, err
+	}
+
+	return &Result{Reference: ref, Status: models.PaymentStatusRefunded, Amount: amount}, nil
+}
+
+func (p *GiftCardProvider) Void(ctx context.Context, ref string) (*Result, error) {
+	return nil, ErrUnsupported
+}
+
+func (p *GiftCardProvider) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,57 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+)
+
+const (
+	ProviderCash       = "cash"
+	ProviderManualCard = "manual_card"
+)
+
+// ManualProvider records payments taken by staff outside the system, such as
+// cash or a card run on a standalone terminal. Every operation succeeds
+// immediately; the payment ID doubles as the provider reference.
+type ManualProvider struct {
+	name string
+}
+
+// IsManual reports whether a provider records payments staff took outside
+// the system rather than charging the guest.
+func IsManual(name string) bool {
+	return name == ProviderCash || name == ProviderManualCard
+}
+
+func NewManualProvider(name string) *ManualProvider {
+	return &ManualProvider{name: name}
+}
+
+func (p *ManualProvider) Name() string {
+	return p.name
+}
+
+func (p *ManualProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	status := models.PaymentStatusAuthorized
+	if req.Capture {
+		status = models.PaymentStatusCaptured
+	}
+	return &Result{Reference: req.PaymentID.String(), Status: status, Amount: req.Amount}, nil
+}
+
+func (p *ManualProvider) Capture(ctx context.Context, ref string, amount float64) (*Result, error) {
+	return &Result{Reference: ref, Status: models.PaymentStatusCaptured, Amount: amount}, nil
+}
+
+func (p *ManualProvider) Refund(ctx context.Context, ref string, amount float64) (*Result, error) {
+	return &Result{Reference: ref, Status: models.PaymentStatusRefunded, Amount: amount}, nil
+}
+
+func (p *ManualProvider) Void(ctx context.Context, ref string) (*Result, error) {
+	return &Result{Reference: ref, Status: models.PaymentStatusVoided}, nil
+}
+
+func (p *ManualProvider) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,89 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnsupported is returned for operations a provider cannot perform,
+	// such as webhooks for staff-recorded payments.
+	ErrUnsupported = errors.New("operation not supported by provider")
+	// ErrInvalidSignature is returned when a webhook fails verification.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// PaymentProvider is implemented by every payment gateway the API can charge
+// through. Amounts are in the order currency.
+type PaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, ref string, amount float64) (*Result, error)
+	Refund(ctx context.Context, ref string, amount float64) (*Result, error)
+	Void(ctx context.Context, ref string) (*Result, error)
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+type AuthorizeRequest struct {
+	PaymentID uuid.UUID
+	OrderID   uuid.UUID
+	Amount    float64
+	Token     string
+	// Capture requests authorization and capture in a single step.
+	Capture bool
+}
+
+// Result is the provider's answer to an operation. Amount is the amount the
+// provider approved, which may be lower than requested.
+type Result struct {
+	Reference string
+	Status    models.PaymentStatus
+	Amount    float64
+	Message   string
+}
+
+// WebhookEvent is a verified asynchronous status update from a provider.
+type WebhookEvent struct {
+	Reference string               `json:"reference"`
+	Status    models.PaymentStatus `json:"status"`
+	Amount    float64              `json:"amount"`
+	Message   string               `json:"message,omitempty"`
+}
+
+// WebhookSink receives webhooks emitted by in-process providers.
+type WebhookSink func(ctx context.Context, provider string, payload []byte, signature string) error
+
+// Registry holds the configured providers by name.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]PaymentProvider
+}
+
+func NewRegistry(providers ...PaymentProvider) *Registry {
+	r := &Registry{providers: make(map[string]PaymentProvider)}
+	for _, p := range providers {
+		r.Register(p)
+	}
+	return r
+}
+
+func (r *Registry) Register(p PaymentProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Name()] = p
+}
+
+func (r *Registry) Get(name string) (PaymentProvider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown payment provider %q", name)
+	}
+	return p, nil
+}
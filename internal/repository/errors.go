@@ -0,0 +1,24 @@
+package repository
+
+import "errors"
+
+var (
+	// ErrUsageLimitReached is returned when an order uses a pricing rule
+	// that has run out of uses in the meantime.
+	ErrUsageLimitReached = errors.New("promotion usage limit reached")
+	// ErrInsufficientBalance is returned when a debit would take a stored
+	// balance below zero.
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrCheckPaid is returned when a split would replace a check that is
+	// paid or has payments made against it.
+	ErrCheckPaid = errors.New("check already paid")
+	// ErrSoldOut is returned when an order takes a menu item that has been
+	// marked unavailable or has too few portions left.
+	ErrSoldOut = errors.New("menu item sold out")
+	// ErrStaleVersion is returned when a save is based on a version that
+	// has since been replaced.
+	ErrStaleVersion = errors.New("stale version")
+	// ErrOutsideTenant is returned when a write names a restaurant or brand
+	// of an organization the request is not scoped to.
+	ErrOutsideTenant = errors.New("not found")
+)
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/KNLopez/restaurant-api/internal/models"
 	"github.com/google/uuid"
@@ -19,26 +20,52 @@ type RestaurantRepository interface {
 	Create(ctx context.Context, restaurant *models.Restaurant) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Restaurant, error)
 	GetByManagerID(ctx context.Context, managerID uuid.UUID) ([]*models.Restaurant, error)
+	GetByBrandID(ctx context.Context, brandID uuid.UUID) ([]*models.Restaurant, error)
+	// List returns the restaurants matching the filter with their rating,
+	// nearest first when searching around a point.
+	List(ctx context.Context, filter models.RestaurantFilter) ([]*models.Restaurant, error)
 	Update(ctx context.Context, restaurant *models.Restaurant) error
+	SetBrand(ctx context.Context, id uuid.UUID, brandID *uuid.UUID) error
+	SetOrganization(ctx context.Context, id, organizationID uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type MenuRepository interface {
 	Create(ctx context.Context, item *models.MenuItem) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.MenuItem, error)
-	List(ctx context.Context, restaurantID uuid.UUID) ([]*models.MenuItem, error)
+	GetBySKU(ctx context.Context, restaurantID uuid.UUID, sku string) (*models.MenuItem, error)
+	List(ctx context.Context, restaurantID uuid.UUID, filter models.MenuFilter) ([]*models.MenuItem, error)
 	Update(ctx context.Context, item *models.MenuItem) error
+	SetAvailability(ctx context.Context, id uuid.UUID, available bool, portions *int) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// Import creates or updates categories, items and their customizations
+	// in one transaction.
+	Import(ctx context.Context, categories []*models.Category, items []models.MenuImportItem) error
+	// Search matches the words of the text against item names, dietary
+	// tags and descriptions; SearchFuzzy matches close spellings instead.
+	Search(ctx context.Context, restaurantID uuid.UUID, text string) ([]models.MenuSearchHit, error)
+	SearchFuzzy(ctx context.Context, restaurantID uuid.UUID, text string) ([]models.MenuSearchHit, error)
 }
 
 type OrderRepository interface {
 	Create(ctx context.Context, order *models.Order) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Order, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, filter models.OrderFilter) ([]*models.Order, error)
+	// ListByRestaurant returns a restaurant's orders, newest first, with
+	// their items. A zero limit returns every matching order.
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, filter models.OrderFilter) ([]*models.Order, error)
+	// SalesByItem totals the items sold on completed orders per menu item
+	// and variant, net of voids and refunds.
+	SalesByItem(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]models.ItemSales, error)
 	GetByRestaurantID(ctx context.Context, restaurantID uuid.UUID) ([]*models.Order, error)
+	GetOpenByTableID(ctx context.Context, tableID uuid.UUID) ([]*models.Order, error)
 	Update(ctx context.Context, order *models.Order) error
-	UpdateStatus(ctx context.Context, id uuid.UUID, status models.OrderStatus) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	// UpdateStatus moves an order from change.From to change.To together
+	// with what the change books, and returns sql.ErrNoRows if the order
+	// is no longer in the from status.
+	UpdateStatus(ctx context.Context, id uuid.UUID, change models.OrderStatusChange) error
+	UpdatePaymentStatus(ctx context.Context, id uuid.UUID, status models.OrderPaymentStatus) error
 }
 
 type TableRepository interface {
@@ -50,3 +77,278 @@ type TableRepository interface {
 	UpdateStatus(ctx context.Context, id uuid.UUID, status models.TableStatus) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
+
+type CheckRepository interface {
+	ReplaceForOrders(ctx context.Context, orderIDs []uuid.UUID, checks []*models.Check) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Check, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.Check, error)
+	MarkPaid(ctx context.Context, id uuid.UUID) error
+}
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *models.Payment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error)
+	GetByProviderRef(ctx context.Context, provider, ref string) (*models.Payment, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.Payment, error)
+	Update(ctx context.Context, payment *models.Payment, from models.PaymentStatus, refunded float64) error
+}
+
+type AdjustmentRepository interface {
+	Create(ctx context.Context, adjustment *models.OrderAdjustment) error
+	// CreateVoid records a void together with the order repriced without
+	// the voided quantity. It returns sql.ErrNoRows if the item can no
+	// longer be voided or the order changed since it was read.
+	CreateVoid(ctx context.Context, adjustment *models.OrderAdjustment, order *models.Order) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.OrderAdjustment, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.OrderAdjustment, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*models.OrderAdjustment, error)
+	ListPending(ctx context.Context, restaurantID uuid.UUID) ([]*models.OrderAdjustment, error)
+	Claim(ctx context.Context, adjustment *models.OrderAdjustment, from models.AdjustmentStatus) error
+	RecordRefund(ctx context.Context, refund *models.RefundAttempt) error
+	Fail(ctx context.Context, adjustment *models.OrderAdjustment) error
+	Complete(ctx context.Context, adjustment *models.OrderAdjustment) error
+	Reject(ctx context.Context, adjustment *models.OrderAdjustment) error
+}
+
+type PricingRuleRepository interface {
+	Create(ctx context.Context, rule *models.PricingRule) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.PricingRule, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.PricingRule, error)
+	Update(ctx context.Context, rule *models.PricingRule) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type GiftCardRepository interface {
+	// Create stores a new card together with its issuing transaction.
+	Create(ctx context.Context, card *models.GiftCard, issue *models.GiftCardTransaction) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.GiftCard, error)
+	GetByCode(ctx context.Context, code string) (*models.GiftCard, error)
+	// AppendTransaction adds a ledger entry and moves the card balance by
+	// the same amount. With allowPartial, a debit larger than the balance
+	// is reduced to the balance instead of failing.
+	AppendTransaction(ctx context.Context, entry *models.GiftCardTransaction, allowPartial bool) error
+	ListTransactions(ctx context.Context, cardID uuid.UUID) ([]*models.GiftCardTransaction, error)
+	ListTransactionsByPayment(ctx context.Context, paymentID uuid.UUID) ([]*models.GiftCardTransaction, error)
+}
+
+type LoyaltyRepository interface {
+	// GetProgram returns the program of a restaurant, or the chain-wide
+	// program when restaurantID is nil.
+	GetProgram(ctx context.Context, restaurantID *uuid.UUID) (*models.LoyaltyProgram, error)
+	SaveProgram(ctx context.Context, program *models.LoyaltyProgram) error
+	GetAccount(ctx context.Context, userID uuid.UUID) (*models.LoyaltyAccount, error)
+	// Credit adds points to an account. It reports false without changing
+	// anything when the order already has an entry of that type.
+	Credit(ctx context.Context, entry *models.LoyaltyTransaction) (bool, error)
+	// Reverse takes back points credited for an order, no more than the
+	// account holds. It reports false without changing anything when the
+	// entry's adjustment already has one or there is nothing to take.
+	Reverse(ctx context.Context, entry *models.LoyaltyTransaction) (bool, error)
+	// Expire writes off credits that expired before now and returns the
+	// number of points lost.
+	Expire(ctx context.Context, userID uuid.UUID, now time.Time) (int, error)
+	ListTransactions(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.LoyaltyTransaction, error)
+	ListOpenCredits(ctx context.Context, userID uuid.UUID) ([]*models.LoyaltyTransaction, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.LoyaltyTransaction, error)
+}
+
+type FavoriteRepository interface {
+	Add(ctx context.Context, favorite *models.Favorite) error
+	Remove(ctx context.Context, userID, menuItemID uuid.UUID) error
+	// ListByUser returns the favorites with their menu items.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Favorite, error)
+}
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review *models.Review) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Review, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.Review, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, filter models.ReviewFilter) ([]*models.Review, error)
+	Reply(ctx context.Context, review *models.Review) error
+	SetHidden(ctx context.Context, id uuid.UUID, hidden bool) error
+	// ItemRatings aggregates the visible item ratings of a restaurant by
+	// menu item.
+	ItemRatings(ctx context.Context, restaurantID uuid.UUID) (map[uuid.UUID]models.RatingSummary, error)
+	RestaurantRating(ctx context.Context, restaurantID uuid.UUID) (models.RatingSummary, error)
+}
+
+type CustomizationRepository interface {
+	Create(ctx context.Context, customization *models.MenuItemCustomization) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.MenuItemCustomization, error)
+	ListByMenuItem(ctx context.Context, menuItemID uuid.UUID) ([]*models.MenuItemCustomization, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type VariantRepository interface {
+	Create(ctx context.Context, variant *models.MenuItemVariant) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.MenuItemVariant, error)
+	ListByMenuItem(ctx context.Context, menuItemID uuid.UUID) ([]*models.MenuItemVariant, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.MenuItemVariant, error)
+	Update(ctx context.Context, variant *models.MenuItemVariant) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type BundleRepository interface {
+	ListByMenuItem(ctx context.Context, menuItemID uuid.UUID) ([]models.BundleGroup, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]models.BundleGroup, error)
+	// Replace swaps the choice groups of a menu item for the given ones,
+	// giving them new IDs. An empty list turns the bundle back into a
+	// plain item.
+	Replace(ctx context.Context, menuItemID uuid.UUID, groups []models.BundleGroup) error
+}
+
+type InventoryRepository interface {
+	CreateIngredient(ctx context.Context, ingredient *models.Ingredient) error
+	GetIngredient(ctx context.Context, id uuid.UUID) (*models.Ingredient, error)
+	ListIngredients(ctx context.Context, restaurantID uuid.UUID) ([]*models.Ingredient, error)
+	UpdateIngredient(ctx context.Context, ingredient *models.Ingredient) error
+	DeleteIngredient(ctx context.Context, id uuid.UUID) error
+	GetRecipe(ctx context.Context, menuItemID uuid.UUID) ([]models.RecipeLine, error)
+	ReplaceRecipe(ctx context.Context, menuItemID uuid.UUID, lines []models.RecipeLine) error
+	// RecordMovements applies the movements to ingredient stock in one
+	// transaction and fills in their resulting stock levels. A count
+	// movement carries the counted level in StockAfter and gets the
+	// difference to the current stock as its quantity.
+	RecordMovements(ctx context.Context, movements []*models.StockMovement) error
+	ListMovements(ctx context.Context, ingredientID uuid.UUID, limit, offset int) ([]*models.StockMovement, error)
+	ListMovementsByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.StockMovement, error)
+}
+
+type SupplierRepository interface {
+	Create(ctx context.Context, supplier *models.Supplier) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Supplier, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.Supplier, error)
+	Update(ctx context.Context, supplier *models.Supplier) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// SaveItem adds an ingredient to a supplier's catalog or updates its
+	// SKU and cost if it is already listed.
+	SaveItem(ctx context.Context, item *models.SupplierItem) error
+	GetItem(ctx context.Context, id uuid.UUID) (*models.SupplierItem, error)
+	ListItems(ctx context.Context, supplierID uuid.UUID) ([]*models.SupplierItem, error)
+	ListItemsByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.SupplierItem, error)
+	DeleteItem(ctx context.Context, id uuid.UUID) error
+}
+
+type PurchaseOrderRepository interface {
+	Create(ctx context.Context, order *models.PurchaseOrder) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.PurchaseOrder, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, filter models.PurchaseOrderFilter) ([]*models.PurchaseOrder, error)
+	// Update replaces the details and lines of a draft purchase order.
+	Update(ctx context.Context, order *models.PurchaseOrder) error
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	// Receive adds the received quantities to the lines, books the
+	// deliveries into stock and moves the order to partially received or
+	// received, all in one transaction. It returns sql.ErrNoRows if a line
+	// would be received beyond its ordered quantity.
+	Receive(ctx context.Context, order *models.PurchaseOrder, received map[uuid.UUID]float64, movements []*models.StockMovement) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type BrandRepository interface {
+	Create(ctx context.Context, brand *models.Brand) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Brand, error)
+	ListByMember(ctx context.Context, userID uuid.UUID) ([]*models.Brand, error)
+	Update(ctx context.Context, brand *models.Brand) error
+	CreateItem(ctx context.Context, item *models.BrandMenuItem) error
+	GetItem(ctx context.Context, id uuid.UUID) (*models.BrandMenuItem, error)
+	// ListItems returns the master menu, including deleted items that are
+	// yet to be published if includeDeleted is set.
+	ListItems(ctx context.Context, brandID uuid.UUID, includeDeleted bool) ([]*models.BrandMenuItem, error)
+	UpdateItem(ctx context.Context, item *models.BrandMenuItem) error
+	// DeleteItem marks a master item deleted; the next publish removes it.
+	DeleteItem(ctx context.Context, id uuid.UUID) error
+	ListOverrides(ctx context.Context, restaurantID uuid.UUID) ([]*models.BrandMenuOverride, error)
+	ListOverridesByBrand(ctx context.Context, brandID uuid.UUID) ([]*models.BrandMenuOverride, error)
+	SaveOverride(ctx context.Context, override *models.BrandMenuOverride) error
+	DeleteOverride(ctx context.Context, restaurantID, brandItemID uuid.UUID) error
+	// Publish applies the changes to every location in one transaction and
+	// purges the deleted master items.
+	Publish(ctx context.Context, brandID uuid.UUID, publish *models.MenuChangeSet) error
+}
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *models.Category) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.Category, error)
+	Update(ctx context.Context, category *models.Category) error
+}
+
+type MenuScheduleRepository interface {
+	Create(ctx context.Context, menu *models.Menu) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Menu, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.Menu, error)
+	// Update replaces the name, windows and attachments of a menu.
+	Update(ctx context.Context, menu *models.Menu) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// CountCategories returns how many of the categories belong to the
+	// restaurant.
+	CountCategories(ctx context.Context, restaurantID uuid.UUID, categoryIDs []uuid.UUID) (int, error)
+}
+
+type AllergyProfileRepository interface {
+	// Get returns nil if the user has not saved a profile.
+	Get(ctx context.Context, userID uuid.UUID) (*models.AllergyProfile, error)
+	Save(ctx context.Context, profile *models.AllergyProfile) error
+}
+
+type MenuVersionRepository interface {
+	Create(ctx context.Context, version *models.MenuVersion) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.MenuVersion, error)
+	// GetPublished returns nil if the restaurant has no live version.
+	GetPublished(ctx context.Context, restaurantID uuid.UUID) (*models.MenuVersion, error)
+	ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.MenuVersion, error)
+	// ListDue returns the scheduled versions due to be published by now.
+	ListDue(ctx context.Context, now time.Time) ([]*models.MenuVersion, error)
+	// Update and Delete only touch versions that are yet to be published.
+	Update(ctx context.Context, version *models.MenuVersion) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Publish archives the live version, publishes this one and applies
+	// the changes to the menu in one transaction.
+	Publish(ctx context.Context, version *models.MenuVersion, changes *models.MenuChangeSet) error
+}
+
+type HoursRepository interface {
+	// Get returns nil if the restaurant has not set its hours.
+	Get(ctx context.Context, restaurantID uuid.UUID) (*models.OpeningHours, error)
+	// ListByRestaurants returns the hours of those restaurants that have
+	// set them, by restaurant ID.
+	ListByRestaurants(ctx context.Context, restaurantIDs []uuid.UUID) (map[uuid.UUID]*models.OpeningHours, error)
+	// Save replaces the hours of a restaurant.
+	Save(ctx context.Context, hours *models.OpeningHours) error
+}
+
+type SettingsRepository interface {
+	// Get returns the current settings of a restaurant, or nil if it has
+	// never saved any.
+	Get(ctx context.Context, restaurantID uuid.UUID) (*models.RestaurantSettings, error)
+	// ListVersions returns every saved version, newest first.
+	ListVersions(ctx context.Context, restaurantID uuid.UUID) ([]*models.RestaurantSettings, error)
+	// Save stores the settings as the next version and mirrors the
+	// timezone on the restaurant. It returns ErrStaleVersion unless the
+	// settings are based on the current version.
+	Save(ctx context.Context, settings *models.RestaurantSettings) error
+}
+
+// OrganizationRepository reads and writes organizations and their members
+// within the tenant scope of the context.
+type OrganizationRepository interface {
+	// Create stores the organization with its creator as owner.
+	Create(ctx context.Context, organization *models.Organization) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+	List(ctx context.Context) ([]*models.Organization, error)
+	// ListByMember returns the user's organizations with their role in each.
+	ListByMember(ctx context.Context, userID uuid.UUID) ([]*models.Organization, error)
+	Update(ctx context.Context, organization *models.Organization) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	CountRestaurants(ctx context.Context, id uuid.UUID) (int, error)
+	ListMembers(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error)
+	// GetMember returns nil if the user is not a member.
+	GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*models.OrganizationMember, error)
+	// ListMemberships returns the IDs of the user's organizations whatever
+	// the scope of the context.
+	ListMemberships(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	// SaveMember adds the member or changes their role.
+	SaveMember(ctx context.Context, member *models.OrganizationMember) error
+	RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error
+	CountOwners(ctx context.Context, organizationID uuid.UUID) (int, error)
+}
@@ -0,0 +1,412 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+type AdjustmentRepository struct {
+	db *sql.DB
+}
+
+func NewAdjustmentRepository(db *sql.DB) *AdjustmentRepository {
+	return &AdjustmentRepository{db: db}
+}
+
+const adjustmentColumns = `
+	id, order_id, restaurant_id, order_item_id, type, quantity, amount,
+	refunded_amount, reason_code, note, status, requested_by, approved_by, approved_at,
+	created_at, updated_at
+`
+
+func (r *AdjustmentRepository) Create(ctx context.Context, adjustment *models.OrderAdjustment) error {
+	if err := checkTenant(ctx, r.db, adjustment.RestaurantID); err != nil {
+		return err
+	}
+	return r.insert(ctx, r.db, adjustment)
+}
+
+// CreateVoid removes the voided quantity from the order item, stores the
+// order as repriced without it, gives the voided portions back to the menu
+// and records the adjustment in one transaction. It returns sql.ErrNoRows
+// if the item has been sent or does not have enough quantity left, or if
+// the order changed since it was repriced.
+func (r *AdjustmentRepository) CreateVoid(ctx context.Context, adjustment *models.OrderAdjustment, order *models.Order) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTenant(ctx, tx, adjustment.RestaurantID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE order_items
+		SET voided_quantity = voided_quantity + $1
+		WHERE id = $2 AND order_id = $3 AND sent_at IS NULL
+		  AND quantity - voided_quantity - refunded_quantity >= $1
+		  AND `+staffOnly(ctx, inTenantOrder(ctx, "order_id")), adjustment.Quantity, adjustment.OrderItemID, adjustment.OrderID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	// The order must not have changed since it was repriced
+	now := time.Now()
+	result, err = tx.ExecContext(ctx, `
+		UPDATE orders
+		SET subtotal = $1,
+			discount_total = $2,
+			service_charge = $3,
+			delivery_fee = $4,
+			tax_total = $5,
+			total_amount = $6,
+			updated_at = $7
+		WHERE id = $8 AND updated_at = $9 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id")),
+		order.Subtotal,
+		order.DiscountTotal,
+		order.ServiceCharge,
+		order.DeliveryFee,
+		order.TaxTotal,
+		order.TotalAmount,
+		now,
+		order.ID,
+		order.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err = result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	order.UpdatedAt = now
+
+	for _, item := range order.Items {
+		if _, err := tx.ExecContext(ctx, `UPDATE order_items SET price = $1 WHERE id = $2`, item.Price, item.ID); err != nil {
+			return err
+		}
+	}
+	if err := swapRuleUses(ctx, tx, order.ID, order.Discounts); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM order_discounts WHERE order_id = $1", order.ID); err != nil {
+		return err
+	}
+	if err := insertOrderDiscounts(ctx, tx, order); err != nil {
+		return err
+	}
+
+	if err := releaseItemPortions(ctx, tx, *adjustment.OrderItemID, adjustment.Quantity); err != nil {
+		return err
+	}
+
+	if err := r.insert(ctx, tx, adjustment); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AdjustmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.OrderAdjustment, error) {
+	query := `SELECT ` + adjustmentColumns + ` FROM order_adjustments WHERE id = $1 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id"))
+
+	adjustments, err := r.query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(adjustments) == 0 {
+		return nil, nil
+	}
+
+	adjustment := adjustments[0]
+	if adjustment.Refunds, err = r.listRefunds(ctx, adjustment.ID); err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+func (r *AdjustmentRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.OrderAdjustment, error) {
+	query := `
+		SELECT ` + adjustmentColumns + `
+		FROM order_adjustments
+		WHERE order_id = $1 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id")) + `
+		ORDER BY created_at
+	`
+
+	return r.query(ctx, query, orderID)
+}
+
+func (r *AdjustmentRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]*models.OrderAdjustment, error) {
+	query := `
+		SELECT ` + adjustmentColumns + `
+		FROM order_adjustments
+		WHERE restaurant_id = $1 AND created_at >= $2 AND created_at < $3 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id")) + `
+		ORDER BY created_at
+	`
+
+	return r.query(ctx, query, restaurantID, from, to)
+}
+
+func (r *AdjustmentRepository) ListPending(ctx context.Context, restaurantID uuid.UUID) ([]*models.OrderAdjustment, error) {
+	query := `
+		SELECT ` + adjustmentColumns + `
+		FROM order_adjustments
+		WHERE restaurant_id = $1 AND status = $2 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id")) + `
+		ORDER BY created_at
+	`
+
+	return r.query(ctx, query, restaurantID, models.AdjustmentPendingApproval)
+}
+
+// Claim moves a refund from the given status to processing, recording who
+// approved it. Only one caller can claim a refund, so its money is only
+// moved once; the others get sql.ErrNoRows.
+func (r *AdjustmentRepository) Claim(ctx context.Context, adjustment *models.OrderAdjustment, from models.AdjustmentStatus) error {
+	return r.decide(ctx, r.db, adjustment, from, models.AdjustmentProcessing)
+}
+
+// RecordRefund stores the result of refunding one payment and adds a
+// successful refund to the adjustment's refunded amount.
+func (r *AdjustmentRepository) RecordRefund(ctx context.Context, refund *models.RefundAttempt) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	refund.ID = uuid.New()
+	refund.CreatedAt = time.Now()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO adjustment_refunds (id, adjustment_id, payment_id, amount, succeeded, error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, refund.ID, refund.AdjustmentID, refund.PaymentID, refund.Amount, refund.Succeeded, refund.Error, refund.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if refund.Succeeded {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE order_adjustments
+			SET refunded_amount = refunded_amount + $1,
+				updated_at = $2
+			WHERE id = $3 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id")), refund.Amount, refund.CreatedAt, refund.AdjustmentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Fail marks a processing refund as failed so it can be retried, keeping
+// what it has refunded so far.
+func (r *AdjustmentRepository) Fail(ctx context.Context, adjustment *models.OrderAdjustment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.decide(ctx, tx, adjustment, models.AdjustmentProcessing, models.AdjustmentFailed); err != nil {
+		return err
+	}
+
+	// A refund paid out but not recorded still counts, so that a retry
+	// does not pay it out again
+	_, err = tx.ExecContext(ctx, `
+		UPDATE order_adjustments
+		SET refunded_amount = GREATEST(refunded_amount, $1)
+		WHERE id = $2
+	`, adjustment.RefundedAmount, adjustment.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Complete marks a processing refund as completed and, for item refunds,
+// adds the refunded quantity to the order item.
+func (r *AdjustmentRepository) Complete(ctx context.Context, adjustment *models.OrderAdjustment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.decide(ctx, tx, adjustment, models.AdjustmentProcessing, models.AdjustmentCompleted); err != nil {
+		return err
+	}
+
+	if adjustment.OrderItemID != nil && adjustment.Quantity > 0 {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE order_items
+			SET refunded_quantity = refunded_quantity + $1
+			WHERE id = $2 AND `+staffOnly(ctx, inTenantOrder(ctx, "order_id")), adjustment.Quantity, adjustment.OrderItemID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *AdjustmentRepository) Reject(ctx context.Context, adjustment *models.OrderAdjustment) error {
+	return r.decide(ctx, r.db, adjustment, models.AdjustmentPendingApproval, models.AdjustmentRejected)
+}
+
+func (r *AdjustmentRepository) insert(ctx context.Context, db execer, adjustment *models.OrderAdjustment) error {
+	query := `
+		INSERT INTO order_adjustments (` + adjustmentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	now := time.Now()
+	adjustment.ID = uuid.New()
+	adjustment.CreatedAt = now
+	adjustment.UpdatedAt = now
+
+	_, err := db.ExecContext(ctx, query,
+		adjustment.ID,
+		adjustment.OrderID,
+		adjustment.RestaurantID,
+		adjustment.OrderItemID,
+		adjustment.Type,
+		adjustment.Quantity,
+		adjustment.Amount,
+		adjustment.RefundedAmount,
+		adjustment.ReasonCode,
+		adjustment.Note,
+		adjustment.Status,
+		adjustment.RequestedBy,
+		adjustment.ApprovedBy,
+		adjustment.ApprovedAt,
+		adjustment.CreatedAt,
+		adjustment.UpdatedAt,
+	)
+
+	return err
+}
+
+// decide moves an adjustment from one status to another, recording who
+// approved or rejected it the first time. It returns sql.ErrNoRows if the
+// adjustment is no longer in the from status.
+func (r *AdjustmentRepository) decide(ctx context.Context, db queryRower, adjustment *models.OrderAdjustment, from, status models.AdjustmentStatus) error {
+	query := `
+		UPDATE order_adjustments
+		SET status = $1,
+			approved_by = COALESCE(approved_by, $2),
+			approved_at = COALESCE(approved_at, $3),
+			updated_at = $3
+		WHERE id = $4 AND status = $5 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id")) + `
+		RETURNING approved_by, approved_at`
+
+	now := time.Now()
+	err := db.QueryRowContext(ctx, query,
+		status,
+		adjustment.ApprovedBy,
+		now,
+		adjustment.ID,
+		from,
+	).Scan(&adjustment.ApprovedBy, &adjustment.ApprovedAt)
+	if err != nil {
+		return err
+	}
+
+	adjustment.Status = status
+	adjustment.UpdatedAt = now
+	return nil
+}
+
+func (r *AdjustmentRepository) listRefunds(ctx context.Context, adjustmentID uuid.UUID) ([]models.RefundAttempt, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, adjustment_id, payment_id, amount, succeeded, error, created_at
+		FROM adjustment_refunds
+		WHERE adjustment_id = $1
+		ORDER BY created_at
+	`, adjustmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []models.RefundAttempt
+	for rows.Next() {
+		var refund models.RefundAttempt
+		if err := rows.Scan(
+			&refund.ID,
+			&refund.AdjustmentID,
+			&refund.PaymentID,
+			&refund.Amount,
+			&refund.Succeeded,
+			&refund.Error,
+			&refund.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+func (r *AdjustmentRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.OrderAdjustment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var adjustments []*models.OrderAdjustment
+	for rows.Next() {
+		adjustment := &models.OrderAdjustment{}
+		err := rows.Scan(
+			&adjustment.ID,
+			&adjustment.OrderID,
+			&adjustment.RestaurantID,
+			&adjustment.OrderItemID,
+			&adjustment.Type,
+			&adjustment.Quantity,
+			&adjustment.Amount,
+			&adjustment.RefundedAmount,
+			&adjustment.ReasonCode,
+			&adjustment.Note,
+			&adjustment.Status,
+			&adjustment.RequestedBy,
+			&adjustment.ApprovedBy,
+			&adjustment.ApprovedAt,
+			&adjustment.CreatedAt,
+			&adjustment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return adjustments, nil
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type AllergyProfileRepository struct {
+	db *sql.DB
+}
+
+func NewAllergyProfileRepository(db *sql.DB) *AllergyProfileRepository {
+	return &AllergyProfileRepository{db: db}
+}
+
+func (r *AllergyProfileRepository) Get(ctx context.Context, userID uuid.UUID) (*models.AllergyProfile, error) {
+	query := `
+		SELECT user_id, allergens, dietary_tags, updated_at
+		FROM allergy_profiles
+		WHERE user_id = $1
+	`
+
+	profile := &models.AllergyProfile{}
+	var allergens, dietaryTags []string
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&profile.UserID,
+		pq.Array(&allergens),
+		pq.Array(&dietaryTags),
+		&profile.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profile.Allergens = fromStrings[models.Allergen](allergens)
+	profile.DietaryTags = fromStrings[models.DietaryTag](dietaryTags)
+
+	return profile, nil
+}
+
+// Save creates or replaces the user's profile.
+func (r *AllergyProfileRepository) Save(ctx context.Context, profile *models.AllergyProfile) error {
+	query := `
+		INSERT INTO allergy_profiles (user_id, allergens, dietary_tags, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET allergens = EXCLUDED.allergens,
+			dietary_tags = EXCLUDED.dietary_tags,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	profile.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, query,
+		profile.UserID,
+		pq.Array(toStrings(profile.Allergens)),
+		pq.Array(toStrings(profile.DietaryTags)),
+		profile.UpdatedAt,
+	)
+
+	return err
+}
@@ -0,0 +1,390 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/KNLopez/restaurant-api/internal/tenant"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type BrandRepository struct {
+	db *sql.DB
+}
+
+func NewBrandRepository(db *sql.DB) *BrandRepository {
+	return &BrandRepository{db: db}
+}
+
+const brandColumns = `id, name, owner_id, organization_id, created_at, updated_at`
+
+const brandItemColumns = `id, brand_id, sku, category, name, description, translations,
+	price, allergens, dietary_tags, created_at, updated_at, deleted_at`
+
+func (r *BrandRepository) Create(ctx context.Context, brand *models.Brand) error {
+	query := `
+		INSERT INTO brands (` + brandColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	if !tenant.Allows(ctx, brand.OrganizationID) {
+		return repository.ErrOutsideTenant
+	}
+
+	now := time.Now()
+	brand.ID = uuid.New()
+	brand.CreatedAt = now
+	brand.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, query, brand.ID, brand.Name, brand.OwnerID, brand.OrganizationID, brand.CreatedAt, brand.UpdatedAt)
+	return err
+}
+
+func (r *BrandRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Brand, error) {
+	brands, err := r.queryBrands(ctx, `SELECT `+brandColumns+` FROM brands WHERE id = $1 AND `+inOrganizations(ctx, "organization_id"), id)
+	if err != nil || len(brands) == 0 {
+		return nil, err
+	}
+	return brands[0], nil
+}
+
+// ListByMember returns the brands the user owns or that belong to one of
+// their organizations.
+func (r *BrandRepository) ListByMember(ctx context.Context, userID uuid.UUID) ([]*models.Brand, error) {
+	query := `
+		SELECT ` + brandColumns + `
+		FROM brands
+		WHERE (owner_id = $1 OR organization_id IN (
+			SELECT organization_id FROM organization_members WHERE user_id = $1
+		)) AND ` + inOrganizations(ctx, "organization_id") + `
+		ORDER BY name
+	`
+	return r.queryBrands(ctx, query, userID)
+}
+
+func (r *BrandRepository) Update(ctx context.Context, brand *models.Brand) error {
+	brand.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE brands SET name = $1, updated_at = $2 WHERE id = $3 AND `+inOrganizations(ctx, "organization_id"), brand.Name, brand.UpdatedAt, brand.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *BrandRepository) CreateItem(ctx context.Context, item *models.BrandMenuItem) error {
+	query := `
+		INSERT INTO brand_menu_items (` + brandItemColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	if err := checkTenantBrand(ctx, r.db, item.BrandID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	item.ID = uuid.New()
+	item.CreatedAt = now
+	item.UpdatedAt = now
+
+	translations, err := marshalTranslations(item.Translations)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
+		item.ID,
+		item.BrandID,
+		item.SKU,
+		item.Category,
+		item.Name,
+		item.Description,
+		translations,
+		item.Price,
+		pq.Array(toStrings(item.Allergens)),
+		pq.Array(toStrings(item.DietaryTags)),
+		item.CreatedAt,
+		item.UpdatedAt,
+		item.DeletedAt,
+	)
+	return err
+}
+
+func (r *BrandRepository) GetItem(ctx context.Context, id uuid.UUID) (*models.BrandMenuItem, error) {
+	items, err := r.queryItems(ctx, `SELECT `+brandItemColumns+` FROM brand_menu_items WHERE id = $1 AND deleted_at IS NULL AND `+inTenantBrand(ctx, "brand_id"), id)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+func (r *BrandRepository) ListItems(ctx context.Context, brandID uuid.UUID, includeDeleted bool) ([]*models.BrandMenuItem, error) {
+	query := `SELECT ` + brandItemColumns + ` FROM brand_menu_items WHERE brand_id = $1 AND ` + inTenantBrand(ctx, "brand_id")
+	if !includeDeleted {
+		query += ` AND deleted_at IS NULL`
+	}
+	query += ` ORDER BY category, name`
+	return r.queryItems(ctx, query, brandID)
+}
+
+func (r *BrandRepository) UpdateItem(ctx context.Context, item *models.BrandMenuItem) error {
+	query := `
+		UPDATE brand_menu_items
+		SET sku = $1,
+			category = $2,
+			name = $3,
+			description = $4,
+			translations = $5,
+			price = $6,
+			allergens = $7,
+			dietary_tags = $8,
+			updated_at = $9
+		WHERE id = $10 AND brand_id = $11 AND deleted_at IS NULL AND ` + inTenantBrand(ctx, "brand_id")
+
+	item.UpdatedAt = time.Now()
+
+	translations, err := marshalTranslations(item.Translations)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, query,
+		item.SKU,
+		item.Category,
+		item.Name,
+		item.Description,
+		translations,
+		item.Price,
+		pq.Array(toStrings(item.Allergens)),
+		pq.Array(toStrings(item.DietaryTags)),
+		item.UpdatedAt,
+		item.ID,
+		item.BrandID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *BrandRepository) DeleteItem(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE brand_menu_items SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL AND `+inTenantBrand(ctx, "brand_id"), time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *BrandRepository) ListOverrides(ctx context.Context, restaurantID uuid.UUID) ([]*models.BrandMenuOverride, error) {
+	query := `
+		SELECT restaurant_id, brand_item_id, price, available, updated_at
+		FROM brand_menu_overrides
+		WHERE restaurant_id = $1 AND ` + inTenant(ctx, "restaurant_id")
+	return r.queryOverrides(ctx, query, restaurantID)
+}
+
+func (r *BrandRepository) ListOverridesByBrand(ctx context.Context, brandID uuid.UUID) ([]*models.BrandMenuOverride, error) {
+	query := `
+		SELECT o.restaurant_id, o.brand_item_id, o.price, o.available, o.updated_at
+		FROM brand_menu_overrides o
+		JOIN brand_menu_items i ON i.id = o.brand_item_id
+		WHERE i.brand_id = $1 AND ` + inTenant(ctx, "o.restaurant_id")
+	return r.queryOverrides(ctx, query, brandID)
+}
+
+func (r *BrandRepository) SaveOverride(ctx context.Context, override *models.BrandMenuOverride) error {
+	query := `
+		INSERT INTO brand_menu_overrides (restaurant_id, brand_item_id, price, available, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (restaurant_id, brand_item_id) DO UPDATE
+		SET price = EXCLUDED.price,
+			available = EXCLUDED.available,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if err := checkTenant(ctx, r.db, override.RestaurantID); err != nil {
+		return err
+	}
+
+	override.UpdatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, query,
+		override.RestaurantID,
+		override.BrandItemID,
+		override.Price,
+		override.Available,
+		override.UpdatedAt,
+	)
+	return err
+}
+
+func (r *BrandRepository) DeleteOverride(ctx context.Context, restaurantID, brandItemID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM brand_menu_overrides WHERE restaurant_id = $1 AND brand_item_id = $2 AND `+inTenant(ctx, "restaurant_id"), restaurantID, brandItemID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *BrandRepository) Publish(ctx context.Context, brandID uuid.UUID, changes *models.MenuChangeSet) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTenantBrand(ctx, tx, brandID); err != nil {
+		return err
+	}
+	if err := applyMenuChanges(ctx, tx, changes); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM brand_menu_items WHERE brand_id = $1 AND deleted_at IS NOT NULL AND `+inTenantBrand(ctx, "brand_id"), brandID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *BrandRepository) queryBrands(ctx context.Context, query string, args ...interface{}) ([]*models.Brand, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var brands []*models.Brand
+	for rows.Next() {
+		brand := &models.Brand{}
+		if err := rows.Scan(&brand.ID, &brand.Name, &brand.OwnerID, &brand.OrganizationID, &brand.CreatedAt, &brand.UpdatedAt); err != nil {
+			return nil, err
+		}
+		brands = append(brands, brand)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return brands, nil
+}
+
+func (r *BrandRepository) queryItems(ctx context.Context, query string, args ...interface{}) ([]*models.BrandMenuItem, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.BrandMenuItem
+	for rows.Next() {
+		item := &models.BrandMenuItem{}
+		var (
+			allergens, dietaryTags []string
+			translations           []byte
+		)
+		err := rows.Scan(
+			&item.ID,
+			&item.BrandID,
+			&item.SKU,
+			&item.Category,
+			&item.Name,
+			&item.Description,
+			&translations,
+			&item.Price,
+			pq.Array(&allergens),
+			pq.Array(&dietaryTags),
+			&item.CreatedAt,
+			&item.UpdatedAt,
+			&item.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		item.Allergens = fromStrings[models.Allergen](allergens)
+		item.DietaryTags = fromStrings[models.DietaryTag](dietaryTags)
+		if err := json.Unmarshal(translations, &item.Translations); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *BrandRepository) queryOverrides(ctx context.Context, query string, args ...interface{}) ([]*models.BrandMenuOverride, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*models.BrandMenuOverride
+	for rows.Next() {
+		override := &models.BrandMenuOverride{}
+		err := rows.Scan(
+			&override.RestaurantID,
+			&override.BrandItemID,
+			&override.Price,
+			&override.Available,
+			&override.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+type BundleRepository struct {
+	db *sql.DB
+}
+
+func NewBundleRepository(db *sql.DB) *BundleRepository {
+	return &BundleRepository{db: db}
+}
+
+func (r *BundleRepository) ListByMenuItem(ctx context.Context, menuItemID uuid.UUID) ([]models.BundleGroup, error) {
+	return r.load(ctx, `g.menu_item_id = $1 AND `+inTenantParent(ctx, "g.menu_item_id", "menu_items"), menuItemID)
+}
+
+func (r *BundleRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]models.BundleGroup, error) {
+	return r.load(ctx, `g.menu_item_id IN (SELECT id FROM menu_items WHERE restaurant_id = $1 AND `+inTenant(ctx, "restaurant_id")+`)`, restaurantID)
+}
+
+// Replace swaps the choice groups of a menu item for the given ones.
+func (r *BundleRepository) Replace(ctx context.Context, menuItemID uuid.UUID, groups []models.BundleGroup) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTenantParent(ctx, tx, "menu_items", menuItemID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bundle_groups WHERE menu_item_id = $1`, menuItemID); err != nil {
+		return err
+	}
+
+	for i := range groups {
+		group := &groups[i]
+		group.ID = uuid.New()
+		group.MenuItemID = menuItemID
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO bundle_groups (id, menu_item_id, name, min_selections, max_selections, position)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, group.ID, group.MenuItemID, group.Name, group.MinSelections, group.MaxSelections, group.Position)
+		if err != nil {
+			return err
+		}
+
+		for position, choice := range group.Choices {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO bundle_choices (group_id, menu_item_id, variant_id, upcharge, position)
+				VALUES ($1, $2, $3, $4, $5)
+			`, group.ID, choice.MenuItemID, choice.VariantID, choice.Upcharge, position)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// load returns the groups matching the condition on bundle_groups g, with
+// their choices.
+func (r *BundleRepository) load(ctx context.Context, condition string, arg interface{}) ([]models.BundleGroup, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT g.id, g.menu_item_id, g.name, g.min_selections, g.max_selections, g.position
+		FROM bundle_groups g
+		WHERE `+condition+`
+		ORDER BY g.menu_item_id, g.position, g.name
+	`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []models.BundleGroup
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		group := models.BundleGroup{Choices: []models.BundleChoice{}}
+		err := rows.Scan(
+			&group.ID,
+			&group.MenuItemID,
+			&group.Name,
+			&group.MinSelections,
+			&group.MaxSelections,
+			&group.Position,
+		)
+		if err != nil {
+			return nil, err
+		}
+		index[group.ID] = len(groups)
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	choiceRows, err := r.db.QueryContext(ctx, `
+		SELECT c.group_id, c.menu_item_id, c.variant_id, c.upcharge
+		FROM bundle_choices c
+		JOIN bundle_groups g ON g.id = c.group_id
+		WHERE `+condition+`
+		ORDER BY c.group_id, c.position
+	`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer choiceRows.Close()
+
+	for choiceRows.Next() {
+		var (
+			groupID uuid.UUID
+			choice  models.BundleChoice
+		)
+		if err := choiceRows.Scan(&groupID, &choice.MenuItemID, &choice.VariantID, &choice.Upcharge); err != nil {
+			return nil, err
+		}
+		if i, ok := index[groupID]; ok {
+			groups[i].Choices = append(groups[i].Choices, choice)
+		}
+	}
+
+	return groups, choiceRows.Err()
+}
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+type CategoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+const categoryColumns = `id, restaurant_id, name, COALESCE(description, ''), translations, created_at`
+
+func (r *CategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	if err := checkTenant(ctx, r.db, category.RestaurantID); err != nil {
+		return err
+	}
+	category.ID = uuid.New()
+	return insertCategory(ctx, r.db, category)
+}
+
+func (r *CategoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	categories, err := r.query(ctx, `SELECT `+categoryColumns+` FROM food_categories WHERE id = $1 AND `+inTenant(ctx, "restaurant_id"), id)
+	if err != nil || len(categories) == 0 {
+		return nil, err
+	}
+	return categories[0], nil
+}
+
+func (r *CategoryRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.Category, error) {
+	query := `SELECT ` + categoryColumns + ` FROM food_categories WHERE restaurant_id = $1 AND ` + inTenant(ctx, "restaurant_id") + ` ORDER BY name`
+	return r.query(ctx, query, restaurantID)
+}
+
+func (r *CategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	return updateCategory(ctx, r.db, category)
+}
+
+func (r *CategoryRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Category, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*models.Category
+	for rows.Next() {
+		category := &models.Category{}
+		var translations []byte
+		err := rows.Scan(
+			&category.ID,
+			&category.RestaurantID,
+			&category.Name,
+			&category.Description,
+			&translations,
+			&category.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(translations, &category.Translations); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func insertCategory(ctx context.Context, db execer, category *models.Category) error {
+	query := `
+		INSERT INTO food_categories (id, restaurant_id, name, description, translations, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	category.CreatedAt = time.Now()
+
+	translations, err := marshalTranslations(category.Translations)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, query,
+		category.ID,
+		category.RestaurantID,
+		category.Name,
+		category.Description,
+		translations,
+		category.CreatedAt,
+	)
+
+	return err
+}
+
+func updateCategory(ctx context.Context, db execer, category *models.Category) error {
+	query := `
+		UPDATE food_categories
+		SET name = $1,
+			description = $2,
+			translations = $3
+		WHERE id = $4 AND restaurant_id = $5 AND ` + inTenant(ctx, "restaurant_id")
+
+	translations, err := marshalTranslations(category.Translations)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.ExecContext(ctx, query,
+		category.Name,
+		category.Description,
+		translations,
+		category.ID,
+		category.RestaurantID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
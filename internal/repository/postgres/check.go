@@ -0,0 +1,301 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type CheckRepository struct {
+	db *sql.DB
+}
+
+func NewCheckRepository(db *sql.DB) *CheckRepository {
+	return &CheckRepository{db: db}
+}
+
+// ReplaceForOrders deletes the checks currently covering any of the given
+// orders and inserts the new split in a single transaction. It returns
+// repository.ErrCheckPaid, and replaces nothing, if any of those checks is
+// paid or has payments against it.
+func (r *CheckRepository) ReplaceForOrders(ctx context.Context, orderIDs []uuid.UUID, checks []*models.Check) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the checks makes a payment being made against one of them
+	// finish first, so it is seen below.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, status FROM checks
+		WHERE id IN (SELECT check_id FROM check_orders WHERE order_id = ANY($1))
+		  AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id"))+`
+		FOR UPDATE`, pq.Array(orderIDs))
+	if err != nil {
+		return err
+	}
+	var existing []uuid.UUID
+	for rows.Next() {
+		var (
+			id     uuid.UUID
+			status models.CheckStatus
+		)
+		if err := rows.Scan(&id, &status); err != nil {
+			rows.Close()
+			return err
+		}
+		if status == models.CheckStatusPaid {
+			rows.Close()
+			return repository.ErrCheckPaid
+		}
+		existing = append(existing, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var paid bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM payments WHERE check_id = ANY($1))`, pq.Array(existing)).Scan(&paid)
+	if err != nil {
+		return err
+	}
+	if paid {
+		return repository.ErrCheckPaid
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM checks
+		WHERE id IN (SELECT check_id FROM check_orders WHERE order_id = ANY($1))
+		  AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id")), pq.Array(orderIDs))
+	if err != nil {
+		return err
+	}
+
+	checkQuery := `
+		INSERT INTO checks (
+			id, restaurant_id, table_id, split_type, label,
+			amount, status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	orderQuery := `INSERT INTO check_orders (check_id, order_id) VALUES ($1, $2)`
+	itemQuery := `
+		INSERT INTO check_items (check_id, order_item_id, quantity, amount)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	now := time.Now()
+	for _, check := range checks {
+		if err := checkTenant(ctx, tx, check.RestaurantID); err != nil {
+			return err
+		}
+		check.ID = uuid.New()
+		check.Status = models.CheckStatusOpen
+		check.CreatedAt = now
+		check.UpdatedAt = now
+
+		_, err = tx.ExecContext(ctx, checkQuery,
+			check.ID,
+			check.RestaurantID,
+			check.TableID,
+			check.SplitType,
+			check.Label,
+			check.Amount,
+			check.Status,
+			check.CreatedAt,
+			check.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, orderID := range check.OrderIDs {
+			if _, err = tx.ExecContext(ctx, orderQuery, check.ID, orderID); err != nil {
+				return err
+			}
+		}
+
+		for i := range check.Items {
+			item := &check.Items[i]
+			item.CheckID = check.ID
+			_, err = tx.ExecContext(ctx, itemQuery,
+				item.CheckID,
+				item.OrderItemID,
+				item.Quantity,
+				item.Amount,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *CheckRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Check, error) {
+	query := `
+		SELECT id, restaurant_id, table_id, split_type, label,
+			   amount, status, paid_at, created_at, updated_at
+		FROM checks
+		WHERE id = $1 AND ` + inTenantChecks(ctx, "restaurant_id", "id")
+
+	check := &models.Check{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&check.ID,
+		&check.RestaurantID,
+		&check.TableID,
+		&check.SplitType,
+		&check.Label,
+		&check.Amount,
+		&check.Status,
+		&check.PaidAt,
+		&check.CreatedAt,
+		&check.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadDetails(ctx, check); err != nil {
+		return nil, err
+	}
+
+	return check, nil
+}
+
+func (r *CheckRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.Check, error) {
+	query := `
+		SELECT c.id, c.restaurant_id, c.table_id, c.split_type, c.label,
+			   c.amount, c.status, c.paid_at, c.created_at, c.updated_at
+		FROM checks c
+		JOIN check_orders co ON co.check_id = c.id
+		WHERE co.order_id = $1 AND ` + inTenantChecks(ctx, "c.restaurant_id", "c.id") + `
+		ORDER BY c.created_at, c.label
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checks []*models.Check
+	for rows.Next() {
+		check := &models.Check{}
+		err := rows.Scan(
+			&check.ID,
+			&check.RestaurantID,
+			&check.TableID,
+			&check.SplitType,
+			&check.Label,
+			&check.Amount,
+			&check.Status,
+			&check.PaidAt,
+			&check.CreatedAt,
+			&check.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, check := range checks {
+		if err := r.loadDetails(ctx, check); err != nil {
+			return nil, err
+		}
+	}
+
+	return checks, nil
+}
+
+func (r *CheckRepository) MarkPaid(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE checks
+		SET status = $1,
+			paid_at = $2,
+			updated_at = $2
+		WHERE id = $3 AND status = $4 AND ` + inTenantChecks(ctx, "restaurant_id", "id")
+
+	result, err := r.db.ExecContext(ctx, query,
+		models.CheckStatusPaid,
+		time.Now(),
+		id,
+		models.CheckStatusOpen,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *CheckRepository) loadDetails(ctx context.Context, check *models.Check) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT order_id FROM check_orders WHERE check_id = $1`, check.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	check.OrderIDs = nil
+	for rows.Next() {
+		var orderID uuid.UUID
+		if err := rows.Scan(&orderID); err != nil {
+			return err
+		}
+		check.OrderIDs = append(check.OrderIDs, orderID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	itemRows, err := r.db.QueryContext(ctx, `
+		SELECT check_id, order_item_id, quantity, amount
+		FROM check_items
+		WHERE check_id = $1
+	`, check.ID)
+	if err != nil {
+		return err
+	}
+	defer itemRows.Close()
+
+	check.Items = nil
+	for itemRows.Next() {
+		var item models.CheckItem
+		err := itemRows.Scan(
+			&item.CheckID,
+			&item.OrderItemID,
+			&item.Quantity,
+			&item.Amount,
+		)
+		if err != nil {
+			return err
+		}
+		check.Items = append(check.Items, item)
+	}
+
+	return itemRows.Err()
+}
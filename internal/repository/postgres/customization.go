@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+const customizationColumns = `id, menu_item_id, name, field_type, options, required`
+
+type CustomizationRepository struct {
+	db *sql.DB
+}
+
+func NewCustomizationRepository(db *sql.DB) *CustomizationRepository {
+	return &CustomizationRepository{db: db}
+}
+
+func (r *CustomizationRepository) Create(ctx context.Context, customization *models.MenuItemCustomization) error {
+	if err := checkTenantParent(ctx, r.db, "menu_items", customization.MenuItemID); err != nil {
+		return err
+	}
+	customization.ID = uuid.New()
+	return insertCustomization(ctx, r.db, customization)
+}
+
+func (r *CustomizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MenuItemCustomization, error) {
+	customizations, err := r.query(ctx, `SELECT `+customizationColumns+` FROM menu_item_customizations WHERE id = $1 AND `+inTenantParent(ctx, "menu_item_id", "menu_items"), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(customizations) == 0 {
+		return nil, nil
+	}
+	return customizations[0], nil
+}
+
+func (r *CustomizationRepository) ListByMenuItem(ctx context.Context, menuItemID uuid.UUID) ([]*models.MenuItemCustomization, error) {
+	query := `SELECT ` + customizationColumns + ` FROM menu_item_customizations WHERE menu_item_id = $1 AND ` + inTenantParent(ctx, "menu_item_id", "menu_items") + ` ORDER BY name`
+	return r.query(ctx, query, menuItemID)
+}
+
+func (r *CustomizationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM menu_item_customizations WHERE id = $1 AND `+inTenantParent(ctx, "menu_item_id", "menu_items"), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *CustomizationRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.MenuItemCustomization, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var customizations []*models.MenuItemCustomization
+	for rows.Next() {
+		var (
+			customization = &models.MenuItemCustomization{}
+			options       []byte
+		)
+		err := rows.Scan(
+			&customization.ID,
+			&customization.MenuItemID,
+			&customization.Name,
+			&customization.FieldType,
+			&options,
+			&customization.Required,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(options, &customization.Options); err != nil {
+			return nil, err
+		}
+		customizations = append(customizations, customization)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return customizations, nil
+}
+
+func insertCustomization(ctx context.Context, db execer, customization *models.MenuItemCustomization) error {
+	query := `
+		INSERT INTO menu_item_customizations (` + customizationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	if customization.Options == nil {
+		customization.Options = []models.CustomizationOption{}
+	}
+	options, err := json.Marshal(customization.Options)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, query,
+		customization.ID,
+		customization.MenuItemID,
+		customization.Name,
+		customization.FieldType,
+		options,
+		customization.Required,
+	)
+	return err
+}
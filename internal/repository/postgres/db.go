@@ -0,0 +1,247 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/KNLopez/restaurant-api/internal/tenant"
+	"github.com/google/uuid"
+)
+
+// execer is satisfied by both *sql.DB and *sql.Tx so helpers can run inside
+// or outside a transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// nullUUID maps uuid.Nil to NULL so optional foreign keys stay unset.
+func nullUUID(id uuid.UUID) interface{} {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
+
+// toInt64s and fromInt64s convert between []int and the int64 slices lib/pq
+// uses for INTEGER[] columns.
+func toInt64s(values []int) []int64 {
+	out := make([]int64, len(values))
+	for i, v := range values {
+		out[i] = int64(v)
+	}
+	return out
+}
+
+func fromInt64s(values []int64) []int {
+	out := make([]int, len(values))
+	for i, v := range values {
+		out[i] = int(v)
+	}
+	return out
+}
+
+// toStrings and fromStrings convert between string-typed enum slices and
+// the string slices lib/pq uses for TEXT[] columns.
+func toStrings[T ~string](values []T) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
+}
+
+func fromStrings[T ~string](values []string) []T {
+	out := make([]T, len(values))
+	for i, v := range values {
+		out[i] = T(v)
+	}
+	return out
+}
+
+// marshalTranslations stores missing translations as an empty object.
+func marshalTranslations(translations models.Translations) ([]byte, error) {
+	if translations == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(translations)
+}
+
+// inOrganizations returns a condition limiting organizationColumn to the
+// organizations ctx is scoped to, or TRUE if it is not scoped to any, as
+// public restaurant data is visible to guests. The IDs are formatted by
+// uuid, so they are inlined rather than numbered among the arguments of the
+// query.
+func inOrganizations(ctx context.Context, organizationColumn string) string {
+	organizationIDs, ok := tenant.Organizations(ctx)
+	if !ok {
+		return "TRUE"
+	}
+	ids := make([]string, len(organizationIDs))
+	for i, id := range organizationIDs {
+		ids[i] = id.String()
+	}
+	return organizationColumn + " = ANY('{" + strings.Join(ids, ",") + "}'::uuid[])"
+}
+
+// inTenant returns a condition limiting restaurantColumn to the restaurants
+// of the organizations ctx is scoped to, or TRUE if it is not scoped to any.
+func inTenant(ctx context.Context, restaurantColumn string) string {
+	if _, ok := tenant.Organizations(ctx); !ok {
+		return "TRUE"
+	}
+	return restaurantColumn + " IN (SELECT id FROM restaurants WHERE " + inOrganizations(ctx, "organization_id") + ")"
+}
+
+// inTenantOrShared is inTenant for rows whose restaurant is optional. Rows
+// without one are shared by every organization, such as chain-wide gift
+// cards.
+func inTenantOrShared(ctx context.Context, restaurantColumn string) string {
+	if _, ok := tenant.Organizations(ctx); !ok {
+		return "TRUE"
+	}
+	return "(" + restaurantColumn + " IS NULL OR " + inTenant(ctx, restaurantColumn) + ")"
+}
+
+// inTenantParent returns a condition limiting column to the IDs of rows of
+// table whose restaurant_id is in scope, or TRUE if ctx is not scoped to
+// organizations. It scopes rows that belong to a restaurant through a
+// parent, such as the recipe of a menu item.
+func inTenantParent(ctx context.Context, column, table string) string {
+	if _, ok := tenant.Organizations(ctx); !ok {
+		return "TRUE"
+	}
+	return column + " IN (SELECT id FROM " + table + " WHERE " + inTenant(ctx, "restaurant_id") + ")"
+}
+
+// inTenantBrand returns a condition limiting brandColumn to the brands of
+// the organizations ctx is scoped to, or TRUE if it is not scoped to any.
+func inTenantBrand(ctx context.Context, brandColumn string) string {
+	if _, ok := tenant.Organizations(ctx); !ok {
+		return "TRUE"
+	}
+	return brandColumn + " IN (SELECT id FROM brands WHERE " + inOrganizations(ctx, "organization_id") + ")"
+}
+
+// staffOnly returns condition, or FALSE for guests. It wraps the conditions
+// on a restaurant's private data, such as its stock and suppliers, which
+// guests may not see at all.
+func staffOnly(ctx context.Context, condition string) string {
+	if tenant.IsGuest(ctx) {
+		return "FALSE"
+	}
+	return condition
+}
+
+// inTenantOrders returns a condition limiting orders to those ctx may see:
+// the orders of its organizations' restaurants for staff, the orders
+// userColumn says a customer placed for customers and none for anonymous
+// guests.
+func inTenantOrders(ctx context.Context, restaurantColumn, userColumn string) string {
+	if !tenant.IsGuest(ctx) {
+		return inTenant(ctx, restaurantColumn)
+	}
+	userID, ok := tenant.Customer(ctx)
+	if !ok {
+		return "FALSE"
+	}
+	return userColumn + " = '" + userID.String() + "'"
+}
+
+// inTenantOrder limits orderColumn to the orders ctx may see. It scopes rows
+// that belong to an order, such as its items and payments.
+func inTenantOrder(ctx context.Context, orderColumn string) string {
+	if tenant.IsUnscoped(ctx) {
+		return "TRUE"
+	}
+	return orderColumn + " IN (SELECT id FROM orders WHERE " + inTenantOrders(ctx, "restaurant_id", "user_id") + ")"
+}
+
+// inTenantChecks limits checks to those ctx may see: the checks of its
+// organizations' restaurants for staff, and for guests the checks of the
+// orders they may see.
+func inTenantChecks(ctx context.Context, restaurantColumn, checkColumn string) string {
+	if !tenant.IsGuest(ctx) {
+		return inTenant(ctx, restaurantColumn)
+	}
+	return checkColumn + " IN (SELECT check_id FROM check_orders WHERE " + inTenantOrder(ctx, "order_id") + ")"
+}
+
+// outsideTenant returns repository.ErrOutsideTenant unless the query, which
+// selects whether a row is in scope, finds it is.
+func outsideTenant(ctx context.Context, q queryRower, query string, id uuid.UUID) error {
+	var visible bool
+	if err := q.QueryRowContext(ctx, query, id).Scan(&visible); err != nil {
+		return err
+	}
+	if !visible {
+		return repository.ErrOutsideTenant
+	}
+	return nil
+}
+
+// checkTenant returns repository.ErrOutsideTenant unless ctx may write data
+// of the restaurant, so rows are never written for another organization.
+// Guests may not write restaurant data.
+func checkTenant(ctx context.Context, q queryRower, restaurantID uuid.UUID) error {
+	if tenant.IsUnscoped(ctx) {
+		return nil
+	}
+	if tenant.IsGuest(ctx) {
+		return repository.ErrOutsideTenant
+	}
+	return outsideTenant(ctx, q, `SELECT EXISTS (SELECT 1 FROM restaurants WHERE id = $1 AND `+inOrganizations(ctx, "organization_id")+`)`, restaurantID)
+}
+
+// checkTenantBrand is checkTenant for data that belongs to a brand.
+func checkTenantBrand(ctx context.Context, q queryRower, brandID uuid.UUID) error {
+	if tenant.IsUnscoped(ctx) {
+		return nil
+	}
+	if tenant.IsGuest(ctx) {
+		return repository.ErrOutsideTenant
+	}
+	return outsideTenant(ctx, q, `SELECT EXISTS (SELECT 1 FROM brands WHERE id = $1 AND `+inOrganizations(ctx, "organization_id")+`)`, brandID)
+}
+
+// checkTenantParent is checkTenant for data that belongs to a restaurant
+// through the row id of table.
+func checkTenantParent(ctx context.Context, q queryRower, table string, id uuid.UUID) error {
+	if tenant.IsUnscoped(ctx) {
+		return nil
+	}
+	if tenant.IsGuest(ctx) {
+		return repository.ErrOutsideTenant
+	}
+	return outsideTenant(ctx, q, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1 AND `+inTenant(ctx, "restaurant_id")+`)`, id)
+}
+
+// checkTenantCustomer is checkTenant for data a customer writes about a
+// restaurant, such as an order or a review. Customers may only write it
+// for themselves.
+func checkTenantCustomer(ctx context.Context, q queryRower, restaurantID, userID uuid.UUID) error {
+	if !tenant.IsGuest(ctx) {
+		return checkTenant(ctx, q, restaurantID)
+	}
+	if customerID, ok := tenant.Customer(ctx); !ok || customerID != userID {
+		return repository.ErrOutsideTenant
+	}
+	return nil
+}
+
+// checkTenantOrder returns repository.ErrOutsideTenant unless ctx may see
+// the order, so rows are never written for an order of someone else.
+func checkTenantOrder(ctx context.Context, q queryRower, orderID uuid.UUID) error {
+	if tenant.IsUnscoped(ctx) {
+		return nil
+	}
+	return outsideTenant(ctx, q, `SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1 AND `+inTenantOrders(ctx, "restaurant_id", "user_id")+`)`, orderID)
+}
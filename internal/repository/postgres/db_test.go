@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KNLopez/restaurant-api/internal/tenant"
+	"github.com/google/uuid"
+)
+
+func TestTenantConditions(t *testing.T) {
+	org := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	customer := uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+	inOrg := "restaurant_id IN (SELECT id FROM restaurants WHERE organization_id = ANY('{" + org.String() + "}'::uuid[]))"
+
+	background := context.Background()
+	contexts := map[string]context.Context{
+		"unscoped": tenant.Unscoped(background),
+		"staff":    tenant.WithOrganizations(background, []uuid.UUID{org}),
+		"no orgs":  tenant.WithOrganizations(background, nil),
+		"customer": tenant.WithCustomer(background, customer),
+		"guest":    background,
+	}
+
+	tests := []struct {
+		name      string
+		condition func(ctx context.Context) string
+		want      map[string]string
+	}{
+		{
+			name:      "inTenant",
+			condition: func(ctx context.Context) string { return inTenant(ctx, "restaurant_id") },
+			want: map[string]string{
+				"unscoped": "TRUE",
+				"staff":    inOrg,
+				"no orgs":  "restaurant_id IN (SELECT id FROM restaurants WHERE organization_id = ANY('{}'::uuid[]))",
+				"customer": "TRUE",
+				"guest":    "TRUE",
+			},
+		},
+		{
+			name:      "inTenantOrShared",
+			condition: func(ctx context.Context) string { return inTenantOrShared(ctx, "restaurant_id") },
+			want: map[string]string{
+				"unscoped": "TRUE",
+				"staff":    "(restaurant_id IS NULL OR " + inOrg + ")",
+				"guest":    "TRUE",
+			},
+		},
+		{
+			name:      "staffOnly",
+			condition: func(ctx context.Context) string { return staffOnly(ctx, inTenant(ctx, "restaurant_id")) },
+			want: map[string]string{
+				"unscoped": "TRUE",
+				"staff":    inOrg,
+				"customer": "FALSE",
+				"guest":    "FALSE",
+			},
+		},
+		{
+			name:      "inTenantOrders",
+			condition: func(ctx context.Context) string { return inTenantOrders(ctx, "restaurant_id", "user_id") },
+			want: map[string]string{
+				"unscoped": "TRUE",
+				"staff":    inOrg,
+				"customer": "user_id = '" + customer.String() + "'",
+				"guest":    "FALSE",
+			},
+		},
+		{
+			name:      "inTenantOrder",
+			condition: func(ctx context.Context) string { return inTenantOrder(ctx, "order_id") },
+			want: map[string]string{
+				"unscoped": "TRUE",
+				"staff":    "order_id IN (SELECT id FROM orders WHERE " + inOrg + ")",
+				"customer": "order_id IN (SELECT id FROM orders WHERE user_id = '" + customer.String() + "')",
+				"guest":    "order_id IN (SELECT id FROM orders WHERE FALSE)",
+			},
+		},
+		{
+			name:      "inTenantChecks",
+			condition: func(ctx context.Context) string { return inTenantChecks(ctx, "restaurant_id", "id") },
+			want: map[string]string{
+				"unscoped": "TRUE",
+				"staff":    inOrg,
+				"customer": "id IN (SELECT check_id FROM check_orders WHERE order_id IN (SELECT id FROM orders WHERE user_id = '" + customer.String() + "'))",
+			},
+		},
+	}
+	for _, tt := range tests {
+		for scope, want := range tt.want {
+			t.Run(tt.name+"/"+scope, func(t *testing.T) {
+				if got := tt.condition(contexts[scope]); got != want {
+					t.Errorf("got %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
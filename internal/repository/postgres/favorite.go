@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+type FavoriteRepository struct {
+	db *sql.DB
+}
+
+func NewFavoriteRepository(db *sql.DB) *FavoriteRepository {
+	return &FavoriteRepository{db: db}
+}
+
+// Add saves a favorite. Adding one that already exists is a no-op.
+func (r *FavoriteRepository) Add(ctx context.Context, favorite *models.Favorite) error {
+	query := `
+		INSERT INTO favorites (user_id, menu_item_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, menu_item_id) DO NOTHING
+	`
+
+	favorite.CreatedAt = time.Now()
+
+	_, err := r.db.ExecContext(ctx, query,
+		favorite.UserID,
+		favorite.MenuItemID,
+		favorite.CreatedAt,
+	)
+
+	return err
+}
+
+func (r *FavoriteRepository) Remove(ctx context.Context, userID, menuItemID uuid.UUID) error {
+	query := `DELETE FROM favorites WHERE user_id = $1 AND menu_item_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userID, menuItemID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *FavoriteRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Favorite, error) {
+	query := `
+		SELECT f.user_id, f.menu_item_id, f.created_at,
+			   m.id, m.restaurant_id, m.name, m.description,
+			   m.price, m.category_id, m.created_at, m.updated_at
+		FROM favorites f
+		JOIN menu_items m ON m.id = f.menu_item_id
+		WHERE f.user_id = $1
+		ORDER BY f.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var favorites []*models.Favorite
+	for rows.Next() {
+		favorite := &models.Favorite{MenuItem: &models.MenuItem{}}
+		err := rows.Scan(
+			&favorite.UserID,
+			&favorite.MenuItemID,
+			&favorite.CreatedAt,
+			&favorite.MenuItem.ID,
+			&favorite.MenuItem.RestaurantID,
+			&favorite.MenuItem.Name,
+			&favorite.MenuItem.Description,
+			&favorite.MenuItem.Price,
+			&favorite.MenuItem.CategoryID,
+			&favorite.MenuItem.CreatedAt,
+			&favorite.MenuItem.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, favorite)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return favorites, nil
+}
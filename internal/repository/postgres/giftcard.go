@@ -0,0 +1,252 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/KNLopez/restaurant-api/internal/tenant"
+	"github.com/google/uuid"
+)
+
+const giftCardTransactionColumns = `
+	id, gift_card_id, type, amount, balance_after, order_id, payment_id,
+	restaurant_id, note, created_by, created_at`
+
+type GiftCardRepository struct {
+	db *sql.DB
+}
+
+func NewGiftCardRepository(db *sql.DB) *GiftCardRepository {
+	return &GiftCardRepository{db: db}
+}
+
+func (r *GiftCardRepository) Create(ctx context.Context, card *models.GiftCard, issue *models.GiftCardTransaction) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO gift_cards (
+			id, code, initial_balance, balance, restaurant_id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	if card.RestaurantID == nil && !tenant.Allows(ctx, nil) {
+		return repository.ErrOutsideTenant
+	}
+	if card.RestaurantID != nil {
+		if err := checkTenant(ctx, tx, *card.RestaurantID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	card.ID = uuid.New()
+	card.Balance = card.InitialBalance
+	card.CreatedAt = now
+	card.UpdatedAt = now
+
+	_, err = tx.ExecContext(ctx, query,
+		card.ID,
+		card.Code,
+		card.InitialBalance,
+		card.Balance,
+		card.RestaurantID,
+		card.CreatedAt,
+		card.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	issue.GiftCardID = card.ID
+	issue.Amount = card.InitialBalance
+	issue.BalanceAfter = card.Balance
+	if err := insertGiftCardTransaction(ctx, tx, issue); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *GiftCardRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.GiftCard, error) {
+	return r.getOne(ctx, `WHERE id = $1 AND `+inTenantOrShared(ctx, "restaurant_id"), id)
+}
+
+func (r *GiftCardRepository) GetByCode(ctx context.Context, code string) (*models.GiftCard, error) {
+	return r.getOne(ctx, `WHERE code = $1 AND `+inTenantOrShared(ctx, "restaurant_id"), code)
+}
+
+func (r *GiftCardRepository) AppendTransaction(ctx context.Context, entry *models.GiftCardTransaction, allowPartial bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the card so concurrent redemptions see each other's debits
+	var (
+		balance      float64
+		restaurantID *uuid.UUID
+	)
+	err = tx.QueryRowContext(ctx,
+		`SELECT balance, restaurant_id FROM gift_cards WHERE id = $1 AND `+inTenantOrShared(ctx, "restaurant_id")+` FOR UPDATE`,
+		entry.GiftCardID,
+	).Scan(&balance, &restaurantID)
+	if err != nil {
+		return err
+	}
+
+	// Loading a card is like issuing it: chain-wide cards can only be
+	// loaded by those who may issue them
+	if entry.Type == models.GiftCardLoad {
+		if restaurantID == nil && !tenant.Allows(ctx, nil) {
+			return repository.ErrOutsideTenant
+		}
+		if entry.RestaurantID != nil {
+			if err := checkTenant(ctx, tx, *entry.RestaurantID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if math.Round((balance+entry.Amount)*100) < 0 {
+		if !allowPartial || math.Round(balance*100) == 0 {
+			return repository.ErrInsufficientBalance
+		}
+		entry.Amount = -balance
+	}
+
+	err = tx.QueryRowContext(ctx,
+		`UPDATE gift_cards SET balance = balance + $1, updated_at = $2 WHERE id = $3 RETURNING balance`,
+		entry.Amount,
+		time.Now(),
+		entry.GiftCardID,
+	).Scan(&entry.BalanceAfter)
+	if err != nil {
+		return err
+	}
+
+	if err := insertGiftCardTransaction(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *GiftCardRepository) ListTransactions(ctx context.Context, cardID uuid.UUID) ([]*models.GiftCardTransaction, error) {
+	query := `
+		SELECT ` + giftCardTransactionColumns + `
+		FROM gift_card_transactions
+		WHERE gift_card_id = $1 AND gift_card_id IN (SELECT id FROM gift_cards WHERE ` + inTenantOrShared(ctx, "restaurant_id") + `)
+		ORDER BY created_at, id
+	`
+
+	return r.queryTransactions(ctx, query, cardID)
+}
+
+func (r *GiftCardRepository) ListTransactionsByPayment(ctx context.Context, paymentID uuid.UUID) ([]*models.GiftCardTransaction, error) {
+	query := `
+		SELECT ` + giftCardTransactionColumns + `
+		FROM gift_card_transactions
+		WHERE payment_id = $1 AND gift_card_id IN (SELECT id FROM gift_cards WHERE ` + inTenantOrShared(ctx, "restaurant_id") + `)
+		ORDER BY created_at, id
+	`
+
+	return r.queryTransactions(ctx, query, paymentID)
+}
+
+func (r *GiftCardRepository) getOne(ctx context.Context, where string, args ...interface{}) (*models.GiftCard, error) {
+	query := `
+		SELECT id, code, initial_balance, balance, restaurant_id, created_at, updated_at
+		FROM gift_cards
+		` + where
+
+	card := &models.GiftCard{}
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&card.ID,
+		&card.Code,
+		&card.InitialBalance,
+		&card.Balance,
+		&card.RestaurantID,
+		&card.CreatedAt,
+		&card.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+func (r *GiftCardRepository) queryTransactions(ctx context.Context, query string, args ...interface{}) ([]*models.GiftCardTransaction, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.GiftCardTransaction
+	for rows.Next() {
+		entry := &models.GiftCardTransaction{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.GiftCardID,
+			&entry.Type,
+			&entry.Amount,
+			&entry.BalanceAfter,
+			&entry.OrderID,
+			&entry.PaymentID,
+			&entry.RestaurantID,
+			&entry.Note,
+			&entry.CreatedBy,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func insertGiftCardTransaction(ctx context.Context, db execer, entry *models.GiftCardTransaction) error {
+	query := `
+		INSERT INTO gift_card_transactions (` + giftCardTransactionColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	entry.ID = uuid.New()
+	entry.CreatedAt = time.Now()
+
+	_, err := db.ExecContext(ctx, query,
+		entry.ID,
+		entry.GiftCardID,
+		entry.Type,
+		entry.Amount,
+		entry.BalanceAfter,
+		entry.OrderID,
+		entry.PaymentID,
+		entry.RestaurantID,
+		entry.Note,
+		entry.CreatedBy,
+		entry.CreatedAt,
+	)
+
+	return err
+}
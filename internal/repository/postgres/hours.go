@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type HoursRepository struct {
+	db *sql.DB
+}
+
+func NewHoursRepository(db *sql.DB) *HoursRepository {
+	return &HoursRepository{db: db}
+}
+
+func (r *HoursRepository) Get(ctx context.Context, restaurantID uuid.UUID) (*models.OpeningHours, error) {
+	hours, err := r.query(ctx, `SELECT restaurant_id, weekly, exceptions, updated_at FROM restaurant_hours WHERE restaurant_id = $1 AND `+inTenant(ctx, "restaurant_id"), restaurantID)
+	if err != nil || len(hours) == 0 {
+		return nil, err
+	}
+	return hours[0], nil
+}
+
+// ListByRestaurants returns the hours of the restaurants that have set
+// them, by restaurant ID.
+func (r *HoursRepository) ListByRestaurants(ctx context.Context, restaurantIDs []uuid.UUID) (map[uuid.UUID]*models.OpeningHours, error) {
+	ids := make([]string, len(restaurantIDs))
+	for i, id := range restaurantIDs {
+		ids[i] = id.String()
+	}
+
+	hours, err := r.query(ctx, `SELECT restaurant_id, weekly, exceptions, updated_at FROM restaurant_hours WHERE restaurant_id = ANY($1::uuid[]) AND `+inTenant(ctx, "restaurant_id"), pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	byRestaurant := make(map[uuid.UUID]*models.OpeningHours, len(hours))
+	for _, h := range hours {
+		byRestaurant[h.RestaurantID] = h
+	}
+	return byRestaurant, nil
+}
+
+// Save replaces the opening hours of a restaurant.
+func (r *HoursRepository) Save(ctx context.Context, hours *models.OpeningHours) error {
+	query := `
+		INSERT INTO restaurant_hours (restaurant_id, weekly, exceptions, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (restaurant_id) DO UPDATE
+		SET weekly = EXCLUDED.weekly,
+			exceptions = EXCLUDED.exceptions,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if err := checkTenant(ctx, r.db, hours.RestaurantID); err != nil {
+		return err
+	}
+
+	hours.UpdatedAt = time.Now()
+
+	if hours.Weekly == nil {
+		hours.Weekly = []models.OpeningInterval{}
+	}
+	if hours.Exceptions == nil {
+		hours.Exceptions = []models.HoursException{}
+	}
+	weekly, err := json.Marshal(hours.Weekly)
+	if err != nil {
+		return err
+	}
+	exceptions, err := json.Marshal(hours.Exceptions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, query, hours.RestaurantID, weekly, exceptions, hours.UpdatedAt)
+	return err
+}
+
+func (r *HoursRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.OpeningHours, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hours []*models.OpeningHours
+	for rows.Next() {
+		h := &models.OpeningHours{}
+		var weekly, exceptions []byte
+		if err := rows.Scan(&h.RestaurantID, &weekly, &exceptions, &h.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(weekly, &h.Weekly); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(exceptions, &h.Exceptions); err != nil {
+			return nil, err
+		}
+		hours = append(hours, h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hours, nil
+}
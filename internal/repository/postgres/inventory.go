@@ -0,0 +1,331 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+const ingredientColumns = `
+	id, restaurant_id, name, unit, stock, low_stock_threshold, par_level,
+	created_at, updated_at`
+
+const stockMovementColumns = `
+	id, ingredient_id, restaurant_id, type, quantity, stock_after, order_id,
+	note, created_by, created_at`
+
+type InventoryRepository struct {
+	db *sql.DB
+}
+
+func NewInventoryRepository(db *sql.DB) *InventoryRepository {
+	return &InventoryRepository{db: db}
+}
+
+func (r *InventoryRepository) CreateIngredient(ctx context.Context, ingredient *models.Ingredient) error {
+	query := `
+		INSERT INTO ingredients (` + ingredientColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	if err := checkTenant(ctx, r.db, ingredient.RestaurantID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ingredient.ID = uuid.New()
+	ingredient.CreatedAt = now
+	ingredient.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, query,
+		ingredient.ID,
+		ingredient.RestaurantID,
+		ingredient.Name,
+		ingredient.Unit,
+		ingredient.Stock,
+		ingredient.LowStockThreshold,
+		ingredient.ParLevel,
+		ingredient.CreatedAt,
+		ingredient.UpdatedAt,
+	)
+	return err
+}
+
+func (r *InventoryRepository) GetIngredient(ctx context.Context, id uuid.UUID) (*models.Ingredient, error) {
+	ingredients, err := r.queryIngredients(ctx, `SELECT `+ingredientColumns+` FROM ingredients WHERE id = $1 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id")), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(ingredients) == 0 {
+		return nil, nil
+	}
+	return ingredients[0], nil
+}
+
+func (r *InventoryRepository) ListIngredients(ctx context.Context, restaurantID uuid.UUID) ([]*models.Ingredient, error) {
+	query := `SELECT ` + ingredientColumns + ` FROM ingredients WHERE restaurant_id = $1 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id")) + ` ORDER BY name`
+	return r.queryIngredients(ctx, query, restaurantID)
+}
+
+// UpdateIngredient changes the name, unit and threshold of an ingredient.
+// Stock only changes through movements.
+func (r *InventoryRepository) UpdateIngredient(ctx context.Context, ingredient *models.Ingredient) error {
+	query := `
+		UPDATE ingredients
+		SET name = $1, unit = $2, low_stock_threshold = $3, par_level = $4, updated_at = $5
+		WHERE id = $6 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id")) + `
+		RETURNING stock, created_at
+	`
+
+	ingredient.UpdatedAt = time.Now()
+
+	return r.db.QueryRowContext(ctx, query,
+		ingredient.Name,
+		ingredient.Unit,
+		ingredient.LowStockThreshold,
+		ingredient.ParLevel,
+		ingredient.UpdatedAt,
+		ingredient.ID,
+	).Scan(&ingredient.Stock, &ingredient.CreatedAt)
+}
+
+func (r *InventoryRepository) DeleteIngredient(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM ingredients WHERE id = $1 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id")), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *InventoryRepository) GetRecipe(ctx context.Context, menuItemID uuid.UUID) ([]models.RecipeLine, error) {
+	query := `
+		SELECT id, menu_item_id, ingredient_id, quantity, customization_id, option
+		FROM recipe_lines
+		WHERE menu_item_id = $1 AND ` + staffOnly(ctx, inTenantParent(ctx, "menu_item_id", "menu_items"))
+
+	rows, err := r.db.QueryContext(ctx, query, menuItemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []models.RecipeLine{}
+	for rows.Next() {
+		var line models.RecipeLine
+		err := rows.Scan(
+			&line.ID,
+			&line.MenuItemID,
+			&line.IngredientID,
+			&line.Quantity,
+			&line.CustomizationID,
+			&line.Option,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+func (r *InventoryRepository) ReplaceRecipe(ctx context.Context, menuItemID uuid.UUID, lines []models.RecipeLine) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTenantParent(ctx, tx, "menu_items", menuItemID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recipe_lines WHERE menu_item_id = $1`, menuItemID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO recipe_lines (id, menu_item_id, ingredient_id, quantity, customization_id, option)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	for i := range lines {
+		line := &lines[i]
+		line.ID = uuid.New()
+		line.MenuItemID = menuItemID
+
+		_, err := tx.ExecContext(ctx, query,
+			line.ID,
+			line.MenuItemID,
+			line.IngredientID,
+			line.Quantity,
+			line.CustomizationID,
+			line.Option,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *InventoryRepository) RecordMovements(ctx context.Context, movements []*models.StockMovement) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordStockMovements(ctx, tx, movements); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *InventoryRepository) ListMovements(ctx context.Context, ingredientID uuid.UUID, limit, offset int) ([]*models.StockMovement, error) {
+	query := `
+		SELECT ` + stockMovementColumns + `
+		FROM stock_movements
+		WHERE ingredient_id = $1 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id")) + `
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	return r.queryMovements(ctx, query, ingredientID, limit, offset)
+}
+
+func (r *InventoryRepository) ListMovementsByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.StockMovement, error) {
+	query := `SELECT ` + stockMovementColumns + ` FROM stock_movements WHERE order_id = $1 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id")) + ` ORDER BY created_at`
+	return r.queryMovements(ctx, query, orderID)
+}
+
+func (r *InventoryRepository) queryIngredients(ctx context.Context, query string, args ...interface{}) ([]*models.Ingredient, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ingredients []*models.Ingredient
+	for rows.Next() {
+		ingredient := &models.Ingredient{}
+		err := rows.Scan(
+			&ingredient.ID,
+			&ingredient.RestaurantID,
+			&ingredient.Name,
+			&ingredient.Unit,
+			&ingredient.Stock,
+			&ingredient.LowStockThreshold,
+			&ingredient.ParLevel,
+			&ingredient.CreatedAt,
+			&ingredient.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ingredients, nil
+}
+
+func (r *InventoryRepository) queryMovements(ctx context.Context, query string, args ...interface{}) ([]*models.StockMovement, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []*models.StockMovement
+	for rows.Next() {
+		movement := &models.StockMovement{}
+		err := rows.Scan(
+			&movement.ID,
+			&movement.IngredientID,
+			&movement.RestaurantID,
+			&movement.Type,
+			&movement.Quantity,
+			&movement.StockAfter,
+			&movement.OrderID,
+			&movement.Note,
+			&movement.CreatedBy,
+			&movement.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// recordStockMovements applies movements to ingredient stock inside tx so
+// that other repositories can book stock atomically with their own changes.
+func recordStockMovements(ctx context.Context, tx *sql.Tx, movements []*models.StockMovement) error {
+	now := time.Now()
+	for _, movement := range movements {
+		if movement.Type == models.StockCount {
+			var stock float64
+			err := tx.QueryRowContext(ctx, `SELECT stock FROM ingredients WHERE id = $1 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id"))+` FOR UPDATE`, movement.IngredientID).Scan(&stock)
+			if err != nil {
+				return err
+			}
+			movement.Quantity = movement.StockAfter - stock
+		}
+
+		err := tx.QueryRowContext(ctx, `
+			UPDATE ingredients
+			SET stock = stock + $1, updated_at = $2
+			WHERE id = $3 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id"))+`
+			RETURNING stock
+		`, movement.Quantity, now, movement.IngredientID).Scan(&movement.StockAfter)
+		if err != nil {
+			return err
+		}
+
+		movement.ID = uuid.New()
+		movement.CreatedAt = now
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (`+stockMovementColumns+`
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`,
+			movement.ID,
+			movement.IngredientID,
+			movement.RestaurantID,
+			movement.Type,
+			movement.Quantity,
+			movement.StockAfter,
+			movement.OrderID,
+			movement.Note,
+			movement.CreatedBy,
+			movement.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
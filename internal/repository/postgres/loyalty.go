@@ -0,0 +1,520 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/KNLopez/restaurant-api/internal/tenant"
+	"github.com/google/uuid"
+)
+
+const loyaltyTransactionColumns = `
+	id, user_id, restaurant_id, order_id, adjustment_id, type, points, remaining, expires_at, created_at`
+
+type LoyaltyRepository struct {
+	db *sql.DB
+}
+
+func NewLoyaltyRepository(db *sql.DB) *LoyaltyRepository {
+	return &LoyaltyRepository{db: db}
+}
+
+func (r *LoyaltyRepository) GetProgram(ctx context.Context, restaurantID *uuid.UUID) (*models.LoyaltyProgram, error) {
+	query := `
+		SELECT id, restaurant_id, points_per_unit, point_value, expiry_days,
+			   active, created_at, updated_at
+		FROM loyalty_programs
+		WHERE restaurant_id IS NOT DISTINCT FROM $1 AND ` + inTenantOrShared(ctx, "restaurant_id")
+
+	program := &models.LoyaltyProgram{}
+	err := r.db.QueryRowContext(ctx, query, restaurantID).Scan(
+		&program.ID,
+		&program.RestaurantID,
+		&program.PointsPerUnit,
+		&program.PointValue,
+		&program.ExpiryDays,
+		&program.Active,
+		&program.CreatedAt,
+		&program.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return program, nil
+}
+
+func (r *LoyaltyRepository) SaveProgram(ctx context.Context, program *models.LoyaltyProgram) error {
+	if program.RestaurantID == nil && !tenant.Allows(ctx, nil) {
+		return repository.ErrOutsideTenant
+	}
+	if program.RestaurantID != nil {
+		if err := checkTenant(ctx, r.db, *program.RestaurantID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	program.UpdatedAt = now
+
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE loyalty_programs
+		SET points_per_unit = $1,
+			point_value = $2,
+			expiry_days = $3,
+			active = $4,
+			updated_at = $5
+		WHERE restaurant_id IS NOT DISTINCT FROM $6
+		RETURNING id, created_at
+	`,
+		program.PointsPerUnit,
+		program.PointValue,
+		program.ExpiryDays,
+		program.Active,
+		program.UpdatedAt,
+		program.RestaurantID,
+	).Scan(&program.ID, &program.CreatedAt)
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	program.ID = uuid.New()
+	program.CreatedAt = now
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO loyalty_programs (
+			id, restaurant_id, points_per_unit, point_value, expiry_days,
+			active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		program.ID,
+		program.RestaurantID,
+		program.PointsPerUnit,
+		program.PointValue,
+		program.ExpiryDays,
+		program.Active,
+		program.CreatedAt,
+		program.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *LoyaltyRepository) GetAccount(ctx context.Context, userID uuid.UUID) (*models.LoyaltyAccount, error) {
+	query := `
+		SELECT user_id, balance, lifetime_points, updated_at
+		FROM loyalty_accounts
+		WHERE user_id = $1
+	`
+
+	account := &models.LoyaltyAccount{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&account.UserID,
+		&account.Balance,
+		&account.LifetimePoints,
+		&account.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (r *LoyaltyRepository) Credit(ctx context.Context, entry *models.LoyaltyTransaction) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	inserted, err := creditLoyaltyPoints(ctx, tx, entry)
+	if err != nil || !inserted {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// creditLoyaltyPoints adds a credit to the ledger and the client's balance
+// inside tx, so that other repositories can credit points atomically with
+// their own changes. It reports false, and credits nothing, when the order
+// already has an entry of that type.
+func creditLoyaltyPoints(ctx context.Context, tx *sql.Tx, entry *models.LoyaltyTransaction) (bool, error) {
+	now := time.Now()
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO loyalty_accounts (user_id, created_at, updated_at)
+		VALUES ($1, $2, $2)
+		ON CONFLICT (user_id) DO NOTHING
+	`, entry.UserID, now)
+	if err != nil {
+		return false, err
+	}
+
+	entry.Remaining = entry.Points
+	inserted, err := insertLoyaltyTransaction(ctx, tx, entry)
+	if err != nil || !inserted {
+		return false, err
+	}
+
+	lifetime := 0
+	if entry.Type == models.LoyaltyEarn {
+		lifetime = entry.Points
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE loyalty_accounts
+		SET balance = balance + $1,
+			lifetime_points = lifetime_points + $2,
+			updated_at = $3
+		WHERE user_id = $4
+	`, entry.Points, lifetime, now, entry.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *LoyaltyRepository) Expire(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := lockLoyaltyAccount(ctx, tx, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, restaurant_id, remaining, expires_at
+		FROM loyalty_transactions
+		WHERE user_id = $1 AND remaining > 0 AND expires_at <= $2
+		ORDER BY expires_at
+	`, userID, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var lots []*models.LoyaltyTransaction
+	for rows.Next() {
+		lot := &models.LoyaltyTransaction{}
+		if err := rows.Scan(&lot.ID, &lot.RestaurantID, &lot.Remaining, &lot.ExpiresAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		lots = append(lots, lot)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, lot := range lots {
+		if _, err := tx.ExecContext(ctx, `UPDATE loyalty_transactions SET remaining = 0 WHERE id = $1`, lot.ID); err != nil {
+			return 0, err
+		}
+
+		entry := &models.LoyaltyTransaction{
+			UserID:       userID,
+			RestaurantID: lot.RestaurantID,
+			Type:         models.LoyaltyExpire,
+			Points:       -lot.Remaining,
+		}
+		if _, err := insertLoyaltyTransaction(ctx, tx, entry); err != nil {
+			return 0, err
+		}
+		expired += lot.Remaining
+	}
+
+	if expired > 0 {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE loyalty_accounts SET balance = balance - $1, updated_at = $2 WHERE user_id = $3
+		`, expired, now, userID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return expired, tx.Commit()
+}
+
+func (r *LoyaltyRepository) ListTransactions(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.LoyaltyTransaction, error) {
+	query := `
+		SELECT ` + loyaltyTransactionColumns + `
+		FROM loyalty_transactions
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3
+	`
+
+	return r.queryTransactions(ctx, query, userID, limit, offset)
+}
+
+func (r *LoyaltyRepository) ListOpenCredits(ctx context.Context, userID uuid.UUID) ([]*models.LoyaltyTransaction, error) {
+	query := `
+		SELECT ` + loyaltyTransactionColumns + `
+		FROM loyalty_transactions
+		WHERE user_id = $1 AND remaining > 0
+		ORDER BY expires_at NULLS LAST, created_at
+	`
+
+	return r.queryTransactions(ctx, query, userID)
+}
+
+func (r *LoyaltyRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.LoyaltyTransaction, error) {
+	query := `
+		SELECT ` + loyaltyTransactionColumns + `
+		FROM loyalty_transactions
+		WHERE order_id = $1
+		ORDER BY created_at
+	`
+
+	return r.queryTransactions(ctx, query, orderID)
+}
+
+func (r *LoyaltyRepository) queryTransactions(ctx context.Context, query string, args ...interface{}) ([]*models.LoyaltyTransaction, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.LoyaltyTransaction
+	for rows.Next() {
+		entry := &models.LoyaltyTransaction{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.RestaurantID,
+			&entry.OrderID,
+			&entry.AdjustmentID,
+			&entry.Type,
+			&entry.Points,
+			&entry.Remaining,
+			&entry.ExpiresAt,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// redeemLoyaltyPoints takes points off an account, using up the credits
+// that expire first. It runs inside the caller's transaction so points are
+// only spent when the order they pay for is stored.
+func redeemLoyaltyPoints(ctx context.Context, tx *sql.Tx, entry *models.LoyaltyTransaction) error {
+	if err := lockLoyaltyAccount(ctx, tx, entry.UserID); err != nil {
+		if err == sql.ErrNoRows {
+			return repository.ErrInsufficientBalance
+		}
+		return err
+	}
+
+	now := time.Now()
+	lots, err := loyaltyLots(ctx, tx, entry.UserID, nil, now)
+	if err != nil {
+		return err
+	}
+	available := 0
+	for _, l := range lots {
+		available += l.remaining
+	}
+	if available < -entry.Points {
+		return repository.ErrInsufficientBalance
+	}
+	if _, err := spendLoyaltyLots(ctx, tx, lots, -entry.Points); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE loyalty_accounts SET balance = balance + $1, updated_at = $2 WHERE user_id = $3
+	`, entry.Points, now, entry.UserID)
+	if err != nil {
+		return err
+	}
+
+	inserted, err := insertLoyaltyTransaction(ctx, tx, entry)
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		return repository.ErrInsufficientBalance
+	}
+
+	return nil
+}
+
+// Reverse takes back points credited for an order, from what is left of
+// that credit first and then from the credits that expire first. It takes
+// no more than the account holds, and reports false without changing
+// anything when the adjustment already has an entry or there is nothing to
+// take.
+func (r *LoyaltyRepository) Reverse(ctx context.Context, entry *models.LoyaltyTransaction) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := lockLoyaltyAccount(ctx, tx, entry.UserID); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var reversed bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM loyalty_transactions WHERE adjustment_id = $1)`,
+		entry.AdjustmentID,
+	).Scan(&reversed)
+	if err != nil || reversed {
+		return false, err
+	}
+
+	now := time.Now()
+	lots, err := loyaltyLots(ctx, tx, entry.UserID, entry.OrderID, now)
+	if err != nil {
+		return false, err
+	}
+	taken, err := spendLoyaltyLots(ctx, tx, lots, -entry.Points)
+	if err != nil || taken == 0 {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE loyalty_accounts
+		SET balance = balance - $1,
+			lifetime_points = GREATEST(lifetime_points - $1, 0),
+			updated_at = $2
+		WHERE user_id = $3
+	`, taken, now, entry.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	entry.Points = -taken
+	entry.Remaining = 0
+	inserted, err := insertLoyaltyTransaction(ctx, tx, entry)
+	if err != nil || !inserted {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+type loyaltyLot struct {
+	id        uuid.UUID
+	remaining int
+}
+
+// loyaltyLots returns the credits of an account that can still be spent,
+// those that expire first first. With orderID, what is left of the points
+// that order earned comes before anything else.
+func loyaltyLots(ctx context.Context, tx *sql.Tx, userID uuid.UUID, orderID *uuid.UUID, now time.Time) ([]loyaltyLot, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, remaining
+		FROM loyalty_transactions
+		WHERE user_id = $1 AND remaining > 0 AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY COALESCE(order_id = $3 AND type = $4, FALSE) DESC, expires_at NULLS LAST, created_at
+	`, userID, now, orderID, models.LoyaltyEarn)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []loyaltyLot
+	for rows.Next() {
+		var l loyaltyLot
+		if err := rows.Scan(&l.id, &l.remaining); err != nil {
+			return nil, err
+		}
+		lots = append(lots, l)
+	}
+	return lots, rows.Err()
+}
+
+// spendLoyaltyLots uses up to points of the lots in order and returns how
+// many it used.
+func spendLoyaltyLots(ctx context.Context, tx *sql.Tx, lots []loyaltyLot, points int) (int, error) {
+	spent := 0
+	for _, l := range lots {
+		if spent == points {
+			break
+		}
+		used := min(l.remaining, points-spent)
+		if _, err := tx.ExecContext(ctx, `UPDATE loyalty_transactions SET remaining = remaining - $1 WHERE id = $2`, used, l.id); err != nil {
+			return spent, err
+		}
+		spent += used
+	}
+	return spent, nil
+}
+
+func lockLoyaltyAccount(ctx context.Context, tx *sql.Tx, userID uuid.UUID) error {
+	var id uuid.UUID
+	return tx.QueryRowContext(ctx,
+		`SELECT user_id FROM loyalty_accounts WHERE user_id = $1 FOR UPDATE`,
+		userID,
+	).Scan(&id)
+}
+
+// insertLoyaltyTransaction adds a ledger entry, reporting false when the
+// order already has an entry of that type.
+func insertLoyaltyTransaction(ctx context.Context, db execer, entry *models.LoyaltyTransaction) (bool, error) {
+	query := `
+		INSERT INTO loyalty_transactions (` + loyaltyTransactionColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT DO NOTHING
+	`
+
+	entry.ID = uuid.New()
+	entry.CreatedAt = time.Now()
+
+	result, err := db.ExecContext(ctx, query,
+		entry.ID,
+		entry.UserID,
+		entry.RestaurantID,
+		entry.OrderID,
+		entry.AdjustmentID,
+		entry.Type,
+		entry.Points,
+		entry.Remaining,
+		entry.ExpiresAt,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
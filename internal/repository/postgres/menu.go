@@ -3,10 +3,13 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/KNLopez/restaurant-api/internal/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type MenuRepository struct {
@@ -17,73 +20,106 @@ func NewMenuRepository(db *sql.DB) *MenuRepository {
 	return &MenuRepository{db: db}
 }
 
-func (r *MenuRepository) Create(ctx context.Context, item *models.MenuItem) error {
-	query := `
-		INSERT INTO menu_items (
-			id, restaurant_id, name, description, 
-			price, category_id, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`
+const menuItemColumns = `id, restaurant_id, sku, brand_item_id, name, description, translations,
+	price, category_id, available, portions_remaining,
+	allergens, dietary_tags, nutrition,
+	created_at, updated_at`
 
-	now := time.Now()
+func (r *MenuRepository) Create(ctx context.Context, item *models.MenuItem) error {
+	if err := checkTenant(ctx, r.db, item.RestaurantID); err != nil {
+		return err
+	}
 	item.ID = uuid.New()
-	item.CreatedAt = now
-	item.UpdatedAt = now
+	return insertMenuItem(ctx, r.db, item)
+}
 
-	_, err := r.db.ExecContext(ctx, query,
-		item.ID,
-		item.RestaurantID,
-		item.Name,
-		item.Description,
-		item.Price,
-		item.CategoryID,
-		item.CreatedAt,
-		item.UpdatedAt,
-	)
+func (r *MenuRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MenuItem, error) {
+	items, err := r.query(ctx, `SELECT `+menuItemColumns+` FROM menu_items WHERE id = $1 AND `+inTenant(ctx, "restaurant_id"), id)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
 
-	return err
+// GetBySKU returns the item of the restaurant with the given SKU.
+func (r *MenuRepository) GetBySKU(ctx context.Context, restaurantID uuid.UUID, sku string) (*models.MenuItem, error) {
+	items, err := r.query(ctx, `SELECT `+menuItemColumns+` FROM menu_items WHERE restaurant_id = $1 AND sku = $2 AND `+inTenant(ctx, "restaurant_id"), restaurantID, sku)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
 }
 
-func (r *MenuRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MenuItem, error) {
-	query := `
-		SELECT id, restaurant_id, name, description, 
-			   price, category_id, created_at, updated_at
-		FROM menu_items
-		WHERE id = $1
-	`
+// List returns the menu of a restaurant narrowed by the filter.
+func (r *MenuRepository) List(ctx context.Context, restaurantID uuid.UUID, filter models.MenuFilter) ([]*models.MenuItem, error) {
+	query := `SELECT ` + menuItemColumns + ` FROM menu_items WHERE restaurant_id = $1 AND retired_at IS NULL AND ` + inTenant(ctx, "restaurant_id")
+	args := []interface{}{restaurantID}
 
-	item := &models.MenuItem{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&item.ID,
-		&item.RestaurantID,
-		&item.Name,
-		&item.Description,
-		&item.Price,
-		&item.CategoryID,
-		&item.CreatedAt,
-		&item.UpdatedAt,
-	)
+	if len(filter.DietaryTags) > 0 {
+		args = append(args, pq.Array(toStrings(filter.DietaryTags)))
+		query += fmt.Sprintf(" AND dietary_tags @> $%d", len(args))
+	}
+	if len(filter.ExcludeAllergens) > 0 {
+		args = append(args, pq.Array(toStrings(filter.ExcludeAllergens)))
+		query += fmt.Sprintf(" AND NOT allergens && $%d", len(args))
+	}
 
-	if err == sql.ErrNoRows {
-		return nil, nil
+	query += ` ORDER BY category_id, name`
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *MenuRepository) Update(ctx context.Context, item *models.MenuItem) error {
+	return updateMenuItem(ctx, r.db, item)
+}
+
+// SetAvailability marks an item as available or sold out and sets its
+// remaining portions, nil meaning portions are not counted.
+func (r *MenuRepository) SetAvailability(ctx context.Context, id uuid.UUID, available bool, portions *int) error {
+	query := `
+		UPDATE menu_items
+		SET available = $1,
+			portions_remaining = $2,
+			updated_at = $3
+		WHERE id = $4 AND ` + inTenant(ctx, "restaurant_id")
+
+	result, err := r.db.ExecContext(ctx, query, available, portions, time.Now(), id)
+	if err != nil {
+		return err
 	}
+
+	rows, err := result.RowsAffected()
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
 	}
 
-	return item, nil
+	return nil
 }
 
-func (r *MenuRepository) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.MenuItem, error) {
-	query := `
-		SELECT id, restaurant_id, name, description, 
-			   price, category_id, created_at, updated_at
-		FROM menu_items
-		WHERE restaurant_id = $1
-		ORDER BY category_id, name
-	`
+func (r *MenuRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM menu_items WHERE id = $1 AND ` + inTenant(ctx, "restaurant_id")
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, restaurantID)
+	return nil
+}
+
+func (r *MenuRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.MenuItem, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -91,17 +127,7 @@ func (r *MenuRepository) List(ctx context.Context, restaurantID uuid.UUID) ([]*m
 
 	var items []*models.MenuItem
 	for rows.Next() {
-		item := &models.MenuItem{}
-		err := rows.Scan(
-			&item.ID,
-			&item.RestaurantID,
-			&item.Name,
-			&item.Description,
-			&item.Price,
-			&item.CategoryID,
-			&item.CreatedAt,
-			&item.UpdatedAt,
-		)
+		item, err := scanMenuItem(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -115,47 +141,135 @@ func (r *MenuRepository) List(ctx context.Context, restaurantID uuid.UUID) ([]*m
 	return items, nil
 }
 
-func (r *MenuRepository) Update(ctx context.Context, item *models.MenuItem) error {
+// scanMenuItem scans a row of menuItemColumns followed by any extra
+// columns the query selects.
+func scanMenuItem(rows *sql.Rows, extra ...interface{}) (*models.MenuItem, error) {
+	item := &models.MenuItem{}
+	var (
+		allergens, dietaryTags  []string
+		nutrition, translations []byte
+	)
+	dest := []interface{}{
+		&item.ID,
+		&item.RestaurantID,
+		&item.SKU,
+		&item.BrandItemID,
+		&item.Name,
+		&item.Description,
+		&translations,
+		&item.Price,
+		&item.CategoryID,
+		&item.Available,
+		&item.PortionsRemaining,
+		pq.Array(&allergens),
+		pq.Array(&dietaryTags),
+		&nutrition,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	item.Allergens = fromStrings[models.Allergen](allergens)
+	item.DietaryTags = fromStrings[models.DietaryTag](dietaryTags)
+	if err := json.Unmarshal(translations, &item.Translations); err != nil {
+		return nil, err
+	}
+	if nutrition != nil {
+		if err := json.Unmarshal(nutrition, &item.Nutrition); err != nil {
+			return nil, err
+		}
+	}
+
+	return item, nil
+}
+
+func insertMenuItem(ctx context.Context, db execer, item *models.MenuItem) error {
 	query := `
-		UPDATE menu_items
-		SET name = $1,
-			description = $2,
-			price = $3,
-			category_id = $4,
-			updated_at = $5
-		WHERE id = $6 AND restaurant_id = $7
+		INSERT INTO menu_items (` + menuItemColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 
-	item.UpdatedAt = time.Now()
+	now := time.Now()
+	item.Available = item.PortionsRemaining == nil || *item.PortionsRemaining > 0
+	item.CreatedAt = now
+	item.UpdatedAt = now
 
-	result, err := r.db.ExecContext(ctx, query,
+	nutrition, err := marshalNutrition(item.Nutrition)
+	if err != nil {
+		return err
+	}
+	translations, err := marshalTranslations(item.Translations)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, query,
+		item.ID,
+		item.RestaurantID,
+		item.SKU,
+		item.BrandItemID,
 		item.Name,
 		item.Description,
+		translations,
 		item.Price,
 		item.CategoryID,
+		item.Available,
+		item.PortionsRemaining,
+		pq.Array(toStrings(item.Allergens)),
+		pq.Array(toStrings(item.DietaryTags)),
+		nutrition,
+		item.CreatedAt,
 		item.UpdatedAt,
-		item.ID,
-		item.RestaurantID,
 	)
+
+	return err
+}
+
+func updateMenuItem(ctx context.Context, db execer, item *models.MenuItem) error {
+	query := `
+		UPDATE menu_items
+		SET sku = $1,
+			brand_item_id = $2,
+			name = $3,
+			description = $4,
+			translations = $5,
+			price = $6,
+			category_id = $7,
+			allergens = $8,
+			dietary_tags = $9,
+			nutrition = $10,
+			retired_at = NULL,
+			updated_at = $11
+		WHERE id = $12 AND restaurant_id = $13 AND ` + inTenant(ctx, "restaurant_id")
+
+	item.UpdatedAt = time.Now()
+
+	nutrition, err := marshalNutrition(item.Nutrition)
 	if err != nil {
 		return err
 	}
-
-	rows, err := result.RowsAffected()
+	translations, err := marshalTranslations(item.Translations)
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
-}
-
-func (r *MenuRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM menu_items WHERE id = $1`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := db.ExecContext(ctx, query,
+		item.SKU,
+		item.BrandItemID,
+		item.Name,
+		item.Description,
+		translations,
+		item.Price,
+		item.CategoryID,
+		pq.Array(toStrings(item.Allergens)),
+		pq.Array(toStrings(item.DietaryTags)),
+		nutrition,
+		item.UpdatedAt,
+		item.ID,
+		item.RestaurantID,
+	)
 	if err != nil {
 		return err
 	}
@@ -170,3 +284,11 @@ func (r *MenuRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// marshalNutrition stores missing nutrition facts as NULL.
+func marshalNutrition(nutrition *models.NutritionFacts) (interface{}, error) {
+	if nutrition == nil {
+		return nil, nil
+	}
+	return json.Marshal(nutrition)
+}
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// Import writes a validated bulk import in one transaction. Categories and
+// items without an ID are created and the rest updated. Customizations are
+// matched by name so existing ones keep their IDs and the inventory recipes
+// attached to them.
+func (r *MenuRepository) Import(ctx context.Context, categories []*models.Category, items []models.MenuImportItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, category := range categories {
+		if err := checkTenant(ctx, tx, category.RestaurantID); err != nil {
+			return err
+		}
+		if category.ID == uuid.Nil {
+			category.ID = uuid.New()
+			err = insertCategory(ctx, tx, category)
+		} else {
+			err = updateCategory(ctx, tx, category)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range items {
+		entry.Item.CategoryID = entry.Category.ID
+		if err := checkTenant(ctx, tx, entry.Item.RestaurantID); err != nil {
+			return err
+		}
+		if entry.Item.ID == uuid.Nil {
+			entry.Item.ID = uuid.New()
+			err = insertMenuItem(ctx, tx, entry.Item)
+		} else {
+			err = updateMenuItem(ctx, tx, entry.Item)
+		}
+		if err != nil {
+			return err
+		}
+
+		if entry.Customizations != nil {
+			if err := replaceCustomizations(ctx, tx, entry.Item.ID, entry.Customizations); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func replaceCustomizations(ctx context.Context, tx *sql.Tx, menuItemID uuid.UUID, customizations []models.MenuItemCustomization) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, name FROM menu_item_customizations WHERE menu_item_id = $1 AND `+inTenantParent(ctx, "menu_item_id", "menu_items"), menuItemID)
+	if err != nil {
+		return err
+	}
+	existing := map[string]uuid.UUID{}
+	for rows.Next() {
+		var (
+			id   uuid.UUID
+			name string
+		)
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range customizations {
+		customization := &customizations[i]
+		customization.MenuItemID = menuItemID
+
+		id, ok := existing[customization.Name]
+		if !ok {
+			customization.ID = uuid.New()
+			if err := insertCustomization(ctx, tx, customization); err != nil {
+				return err
+			}
+			continue
+		}
+		delete(existing, customization.Name)
+
+		if customization.Options == nil {
+			customization.Options = []models.CustomizationOption{}
+		}
+		options, err := json.Marshal(customization.Options)
+		if err != nil {
+			return err
+		}
+		customization.ID = id
+		_, err = tx.ExecContext(ctx, `
+			UPDATE menu_item_customizations
+			SET field_type = $1, options = $2, required = $3
+			WHERE id = $4
+		`, customization.FieldType, options, customization.Required, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range existing {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM menu_item_customizations WHERE id = $1`, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyMenuChanges writes a menu publish inside the caller's transaction.
+// Removals go first so their SKUs are free for the items that replace
+// them. Items that have been ordered cannot be deleted without losing order
+// history, so they are retired instead: taken off sale, unlinked and hidden
+// from the menu until a later update brings them back.
+func applyMenuChanges(ctx context.Context, tx *sql.Tx, changes *models.MenuChangeSet) error {
+	now := time.Now()
+
+	for _, id := range changes.Remove {
+		result, err := tx.ExecContext(ctx, `
+			DELETE FROM menu_items
+			WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM order_items WHERE menu_item_id = $1)
+			  AND `+inTenant(ctx, "restaurant_id"), id)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE menu_items
+				SET available = false, sku = '', brand_item_id = NULL, retired_at = $1, updated_at = $1
+				WHERE id = $2 AND `+inTenant(ctx, "restaurant_id"), now, id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, category := range changes.Categories {
+		if err := checkTenant(ctx, tx, category.RestaurantID); err != nil {
+			return err
+		}
+		if err := insertCategory(ctx, tx, category); err != nil {
+			return err
+		}
+	}
+	for _, item := range changes.Update {
+		if err := updateMenuItem(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	for _, item := range changes.Create {
+		if err := checkTenant(ctx, tx, item.RestaurantID); err != nil {
+			return err
+		}
+		if err := insertMenuItem(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	for id, available := range changes.Availability {
+		if _, err := tx.ExecContext(ctx, `UPDATE menu_items SET available = $1, updated_at = $2 WHERE id = $3 AND `+inTenant(ctx, "restaurant_id"), available, now, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const menuColumns = `id, restaurant_id, name, active, created_at, updated_at`
+
+type MenuScheduleRepository struct {
+	db *sql.DB
+}
+
+func NewMenuScheduleRepository(db *sql.DB) *MenuScheduleRepository {
+	return &MenuScheduleRepository{db: db}
+}
+
+func (r *MenuScheduleRepository) Create(ctx context.Context, menu *models.Menu) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTenant(ctx, tx, menu.RestaurantID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO menus (` + menuColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	now := time.Now()
+	menu.ID = uuid.New()
+	menu.CreatedAt = now
+	menu.UpdatedAt = now
+
+	_, err = tx.ExecContext(ctx, query,
+		menu.ID,
+		menu.RestaurantID,
+		menu.Name,
+		menu.Active,
+		menu.CreatedAt,
+		menu.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := r.insertDetails(ctx, tx, menu); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *MenuScheduleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Menu, error) {
+	menus, err := r.query(ctx, `SELECT `+menuColumns+` FROM menus WHERE id = $1 AND `+inTenant(ctx, "restaurant_id"), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(menus) == 0 {
+		return nil, nil
+	}
+	return menus[0], nil
+}
+
+func (r *MenuScheduleRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.Menu, error) {
+	return r.query(ctx, `SELECT `+menuColumns+` FROM menus WHERE restaurant_id = $1 AND `+inTenant(ctx, "restaurant_id")+` ORDER BY name`, restaurantID)
+}
+
+func (r *MenuScheduleRepository) Update(ctx context.Context, menu *models.Menu) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE menus
+		SET name = $1, active = $2, updated_at = $3
+		WHERE id = $4 AND ` + inTenant(ctx, "restaurant_id") + `
+		RETURNING created_at
+	`
+
+	menu.UpdatedAt = time.Now()
+
+	err = tx.QueryRowContext(ctx, query, menu.Name, menu.Active, menu.UpdatedAt, menu.ID).Scan(&menu.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"menu_windows", "menu_categories", "menu_menu_items"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE menu_id = $1`, menu.ID); err != nil {
+			return err
+		}
+	}
+	if err := r.insertDetails(ctx, tx, menu); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *MenuScheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM menus WHERE id = $1 AND `+inTenant(ctx, "restaurant_id"), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *MenuScheduleRepository) CountCategories(ctx context.Context, restaurantID uuid.UUID, categoryIDs []uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM food_categories WHERE restaurant_id = $1 AND id = ANY($2) AND ` + inTenant(ctx, "restaurant_id")
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, restaurantID, pq.Array(categoryIDs)).Scan(&count)
+	return count, err
+}
+
+func (r *MenuScheduleRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Menu, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var menus []*models.Menu
+	for rows.Next() {
+		menu := &models.Menu{}
+		err := rows.Scan(
+			&menu.ID,
+			&menu.RestaurantID,
+			&menu.Name,
+			&menu.Active,
+			&menu.CreatedAt,
+			&menu.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		menus = append(menus, menu)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, menu := range menus {
+		if err := r.loadDetails(ctx, menu); err != nil {
+			return nil, err
+		}
+	}
+
+	return menus, nil
+}
+
+func (r *MenuScheduleRepository) loadDetails(ctx context.Context, menu *models.Menu) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT days_of_week, start_time, end_time
+		FROM menu_windows
+		WHERE menu_id = $1
+		ORDER BY start_time
+	`, menu.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	menu.Windows = []models.MenuWindow{}
+	for rows.Next() {
+		var (
+			window models.MenuWindow
+			days   pq.Int64Array
+		)
+		if err := rows.Scan(&days, &window.StartTime, &window.EndTime); err != nil {
+			return err
+		}
+		window.DaysOfWeek = fromInt64s(days)
+		menu.Windows = append(menu.Windows, window)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if menu.CategoryIDs, err = r.loadIDs(ctx, `SELECT category_id FROM menu_categories WHERE menu_id = $1`, menu.ID); err != nil {
+		return err
+	}
+	menu.MenuItemIDs, err = r.loadIDs(ctx, `SELECT menu_item_id FROM menu_menu_items WHERE menu_id = $1`, menu.ID)
+	return err
+}
+
+func (r *MenuScheduleRepository) loadIDs(ctx context.Context, query string, menuID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, query, menuID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *MenuScheduleRepository) insertDetails(ctx context.Context, tx *sql.Tx, menu *models.Menu) error {
+	for _, window := range menu.Windows {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO menu_windows (menu_id, days_of_week, start_time, end_time)
+			VALUES ($1, $2, $3, $4)
+		`, menu.ID, pq.Array(toInt64s(window.DaysOfWeek)), window.StartTime, window.EndTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range menu.CategoryIDs {
+		_, err := tx.ExecContext(ctx, `INSERT INTO menu_categories (menu_id, category_id) VALUES ($1, $2)`, menu.ID, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, id := range menu.MenuItemIDs {
+		_, err := tx.ExecContext(ctx, `INSERT INTO menu_menu_items (menu_id, menu_item_id) VALUES ($1, $2)`, menu.ID, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
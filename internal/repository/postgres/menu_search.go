@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// minSimilarity is how close a word of an item's name or description has
+// to be to the query for a fuzzy match.
+const minSimilarity = 0.3
+
+const headlineOptions = `StartSel=<mark>, StopSel=</mark>, HighlightAll=true`
+
+// Search returns the restaurant's items matching the words of the text,
+// best match first, with the matched words highlighted.
+func (r *MenuRepository) Search(ctx context.Context, restaurantID uuid.UUID, text string) ([]models.MenuSearchHit, error) {
+	query := `
+		SELECT ` + menuItemColumns + `,
+			   ts_rank_cd(search_vector, tsq) AS rank,
+			   ts_headline('english', name, tsq, '` + headlineOptions + `'),
+			   ts_headline('english', COALESCE(description, ''), tsq, '` + headlineOptions + `')
+		FROM menu_items, websearch_to_tsquery('english', $2) AS tsq
+		WHERE restaurant_id = $1 AND retired_at IS NULL AND search_vector @@ tsq
+		  AND ` + inTenant(ctx, "restaurant_id") + `
+		ORDER BY rank DESC, name
+	`
+	return r.search(ctx, query, restaurantID, text)
+}
+
+// SearchFuzzy returns the restaurant's items with a name or description
+// word spelled close to the text, closest first. It catches the typos that
+// full-text search misses.
+func (r *MenuRepository) SearchFuzzy(ctx context.Context, restaurantID uuid.UUID, text string) ([]models.MenuSearchHit, error) {
+	query := `
+		SELECT * FROM (
+			SELECT ` + menuItemColumns + `,
+				   GREATEST(similarity(name, $2), word_similarity($2, name), word_similarity($2, COALESCE(description, ''))) AS score,
+				   '' AS name_highlight, '' AS description_highlight
+			FROM menu_items
+			WHERE restaurant_id = $1 AND retired_at IS NULL AND ` + inTenant(ctx, "restaurant_id") + `
+		) matches
+		WHERE score >= $3
+		ORDER BY score DESC, name
+	`
+	return r.search(ctx, query, restaurantID, text, minSimilarity)
+}
+
+func (r *MenuRepository) search(ctx context.Context, query string, args ...interface{}) ([]models.MenuSearchHit, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []models.MenuSearchHit
+	for rows.Next() {
+		var hit models.MenuSearchHit
+		hit.Item, err = scanMenuItem(rows, &hit.Rank, &hit.Highlights.Name, &hit.Highlights.Description)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+type MenuVersionRepository struct {
+	db *sql.DB
+}
+
+func NewMenuVersionRepository(db *sql.DB) *MenuVersionRepository {
+	return &MenuVersionRepository{db: db}
+}
+
+const menuVersionColumns = `id, restaurant_id, number, status, note, items,
+	publish_at, published_at, created_by, created_at, updated_at`
+
+// Create adds a version numbered after the restaurant's latest one.
+func (r *MenuVersionRepository) Create(ctx context.Context, version *models.MenuVersion) error {
+	query := `
+		INSERT INTO menu_versions (` + menuVersionColumns + `)
+		SELECT $1, $2, COALESCE(MAX(number), 0) + 1, $3, $4, $5, $6, $7, $8, $9, $10
+		FROM menu_versions
+		WHERE restaurant_id = $2
+		RETURNING number
+	`
+
+	if err := checkTenant(ctx, r.db, version.RestaurantID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	version.ID = uuid.New()
+	version.CreatedAt = now
+	version.UpdatedAt = now
+
+	items, err := marshalVersionItems(version.Items)
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRowContext(ctx, query,
+		version.ID,
+		version.RestaurantID,
+		version.Status,
+		version.Note,
+		items,
+		version.PublishAt,
+		version.PublishedAt,
+		nullUUID(version.CreatedBy),
+		version.CreatedAt,
+		version.UpdatedAt,
+	).Scan(&version.Number)
+}
+
+func (r *MenuVersionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MenuVersion, error) {
+	versions, err := r.query(ctx, `SELECT `+menuVersionColumns+` FROM menu_versions WHERE id = $1 AND `+inTenant(ctx, "restaurant_id"), id)
+	if err != nil || len(versions) == 0 {
+		return nil, err
+	}
+	return versions[0], nil
+}
+
+// GetPublished returns the restaurant's live version, or nil if it has
+// never published one.
+func (r *MenuVersionRepository) GetPublished(ctx context.Context, restaurantID uuid.UUID) (*models.MenuVersion, error) {
+	versions, err := r.query(ctx, `SELECT `+menuVersionColumns+` FROM menu_versions WHERE restaurant_id = $1 AND status = $2 AND `+inTenant(ctx, "restaurant_id"),
+		restaurantID, models.MenuVersionPublished)
+	if err != nil || len(versions) == 0 {
+		return nil, err
+	}
+	return versions[0], nil
+}
+
+func (r *MenuVersionRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.MenuVersion, error) {
+	return r.query(ctx, `SELECT `+menuVersionColumns+` FROM menu_versions WHERE restaurant_id = $1 AND `+inTenant(ctx, "restaurant_id")+` ORDER BY number DESC`, restaurantID)
+}
+
+// ListDue returns the scheduled versions whose publish time has passed.
+func (r *MenuVersionRepository) ListDue(ctx context.Context, now time.Time) ([]*models.MenuVersion, error) {
+	return r.query(ctx, `SELECT `+menuVersionColumns+` FROM menu_versions WHERE status = $1 AND publish_at <= $2 AND `+inTenant(ctx, "restaurant_id")+` ORDER BY publish_at`,
+		models.MenuVersionScheduled, now)
+}
+
+// Update saves the note, items, status and publish time of a version that
+// has not been published yet.
+func (r *MenuVersionRepository) Update(ctx context.Context, version *models.MenuVersion) error {
+	query := `
+		UPDATE menu_versions
+		SET status = $1,
+			note = $2,
+			items = $3,
+			publish_at = $4,
+			updated_at = $5
+		WHERE id = $6 AND status IN ($7, $8) AND ` + inTenant(ctx, "restaurant_id")
+
+	version.UpdatedAt = time.Now()
+
+	items, err := marshalVersionItems(version.Items)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, query,
+		version.Status,
+		version.Note,
+		items,
+		version.PublishAt,
+		version.UpdatedAt,
+		version.ID,
+		models.MenuVersionDraft,
+		models.MenuVersionScheduled,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Delete discards a version that has not been published yet.
+func (r *MenuVersionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM menu_versions WHERE id = $1 AND status IN ($2, $3) AND `+inTenant(ctx, "restaurant_id"),
+		id, models.MenuVersionDraft, models.MenuVersionScheduled)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Publish makes the version live in one transaction: the current live
+// version is archived, the version is marked published with its final
+// items and the changes are written to the menu. It returns sql.ErrNoRows
+// if the version was published or discarded in the meantime.
+func (r *MenuVersionRepository) Publish(ctx context.Context, version *models.MenuVersion, changes *models.MenuChangeSet) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTenant(ctx, tx, version.RestaurantID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE menu_versions
+		SET status = $1, updated_at = $2
+		WHERE restaurant_id = $3 AND status = $4
+	`, models.MenuVersionArchived, now, version.RestaurantID, models.MenuVersionPublished)
+	if err != nil {
+		return err
+	}
+
+	items, err := marshalVersionItems(version.Items)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE menu_versions
+		SET status = $1, items = $2, publish_at = NULL, published_at = $3, updated_at = $3
+		WHERE id = $4 AND status IN ($5, $6) AND restaurant_id = $7
+	`, models.MenuVersionPublished, items, now, version.ID, models.MenuVersionDraft, models.MenuVersionScheduled, version.RestaurantID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := applyMenuChanges(ctx, tx, changes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	version.Status = models.MenuVersionPublished
+	version.PublishAt = nil
+	version.PublishedAt = &now
+	version.UpdatedAt = now
+
+	return nil
+}
+
+func (r *MenuVersionRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.MenuVersion, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*models.MenuVersion
+	for rows.Next() {
+		version := &models.MenuVersion{}
+		var items []byte
+		err := rows.Scan(
+			&version.ID,
+			&version.RestaurantID,
+			&version.Number,
+			&version.Status,
+			&version.Note,
+			&items,
+			&version.PublishAt,
+			&version.PublishedAt,
+			&version.CreatedBy,
+			&version.CreatedAt,
+			&version.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(items, &version.Items); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func marshalVersionItems(items []models.MenuVersionItem) ([]byte, error) {
+	if items == nil {
+		items = []models.MenuVersionItem{}
+	}
+	return json.Marshal(items)
+}
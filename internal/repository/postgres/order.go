@@ -3,9 +3,12 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
 	"github.com/google/uuid"
 )
 
@@ -24,12 +27,19 @@ func (r *OrderRepository) Create(ctx context.Context, order *models.Order) error
 	}
 	defer tx.Rollback()
 
+	if err := checkTenantCustomer(ctx, tx, order.RestaurantID, order.UserID); err != nil {
+		return err
+	}
+
 	// Create order
 	query := `
 		INSERT INTO orders (
-			id, user_id, restaurant_id, status,
-			total_amount, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			id, user_id, restaurant_id, table_id, status, payment_status,
+			subtotal, discount_total, total_amount, promo_code, loyalty_points,
+			menu_version_id, scheduled_for, service_charge, tax_total, currency,
+			order_type, pickup_at, customer_name, delivery_address, contact_phone, delivery_fee,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 	`
 
 	now := time.Now()
@@ -37,13 +47,31 @@ func (r *OrderRepository) Create(ctx context.Context, order *models.Order) error
 	order.CreatedAt = now
 	order.UpdatedAt = now
 	order.Status = models.OrderStatusPending
+	order.PaymentStatus = models.OrderPaymentUnpaid
 
 	_, err = tx.ExecContext(ctx, query,
 		order.ID,
 		order.UserID,
 		order.RestaurantID,
+		nullUUID(order.TableID),
 		order.Status,
+		order.PaymentStatus,
+		order.Subtotal,
+		order.DiscountTotal,
 		order.TotalAmount,
+		order.PromoCode,
+		order.LoyaltyPoints,
+		order.MenuVersionID,
+		order.ScheduledFor,
+		order.ServiceCharge,
+		order.TaxTotal,
+		order.Currency,
+		order.Type,
+		order.PickupAt,
+		order.CustomerName,
+		order.DeliveryAddress,
+		order.ContactPhone,
+		order.DeliveryFee,
 		order.CreatedAt,
 		order.UpdatedAt,
 	)
@@ -52,24 +80,35 @@ func (r *OrderRepository) Create(ctx context.Context, order *models.Order) error
 	}
 
 	// Create order items
-	itemQuery := `
-		INSERT INTO order_items (
-			id, order_id, menu_item_id,
-			quantity, price
-		) VALUES ($1, $2, $3, $4, $5)
-	`
+	for i := range order.Items {
+		order.Items[i].ID = uuid.New()
+		for j := range order.Items[i].Components {
+			order.Items[i].Components[j].ID = uuid.New()
+		}
+	}
+	if err := r.insertItems(ctx, tx, order); err != nil {
+		return err
+	}
 
-	for _, item := range order.Items {
-		item.ID = uuid.New()
-		item.OrderID = order.ID
-
-		_, err = tx.ExecContext(ctx, itemQuery,
-			item.ID,
-			item.OrderID,
-			item.MenuItemID,
-			item.Quantity,
-			item.Price,
-		)
+	if err := swapRuleUses(ctx, tx, order.ID, order.Discounts); err != nil {
+		return err
+	}
+	if err := insertOrderDiscounts(ctx, tx, order); err != nil {
+		return err
+	}
+
+	if err := claimPortions(ctx, tx, order.Items); err != nil {
+		return err
+	}
+
+	if order.LoyaltyPoints > 0 {
+		err := redeemLoyaltyPoints(ctx, tx, &models.LoyaltyTransaction{
+			UserID:       order.UserID,
+			RestaurantID: &order.RestaurantID,
+			OrderID:      &order.ID,
+			Type:         models.LoyaltyRedeem,
+			Points:       -order.LoyaltyPoints,
+		})
 		if err != nil {
 			return err
 		}
@@ -81,19 +120,38 @@ func (r *OrderRepository) Create(ctx context.Context, order *models.Order) error
 func (r *OrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
 	// Get order
 	orderQuery := `
-		SELECT id, user_id, restaurant_id, status,
-			   total_amount, created_at, updated_at
+		SELECT id, user_id, restaurant_id, table_id, status, payment_status,
+			   subtotal, discount_total, total_amount, promo_code, loyalty_points,
+			   menu_version_id, scheduled_for, service_charge, tax_total, currency,
+			   order_type, pickup_at, customer_name, delivery_address, contact_phone, delivery_fee,
+			   created_at, updated_at
 		FROM orders
-		WHERE id = $1
-	`
+		WHERE id = $1 AND ` + inTenantOrders(ctx, "restaurant_id", "user_id")
 
 	order := &models.Order{}
 	err := r.db.QueryRowContext(ctx, orderQuery, id).Scan(
 		&order.ID,
 		&order.UserID,
 		&order.RestaurantID,
+		&order.TableID,
 		&order.Status,
+		&order.PaymentStatus,
+		&order.Subtotal,
+		&order.DiscountTotal,
 		&order.TotalAmount,
+		&order.PromoCode,
+		&order.LoyaltyPoints,
+		&order.MenuVersionID,
+		&order.ScheduledFor,
+		&order.ServiceCharge,
+		&order.TaxTotal,
+		&order.Currency,
+		&order.Type,
+		&order.PickupAt,
+		&order.CustomerName,
+		&order.DeliveryAddress,
+		&order.ContactPhone,
+		&order.DeliveryFee,
 		&order.CreatedAt,
 		&order.UpdatedAt,
 	)
@@ -105,51 +163,132 @@ func (r *OrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Or
 		return nil, err
 	}
 
-	// Get order items
-	itemsQuery := `
-		SELECT id, order_id, menu_item_id, quantity, price
-		FROM order_items
-		WHERE order_id = $1
+	// Get order items and discounts
+	if err := r.loadDetails(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r *OrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Order, error) {
+	query := `
+		SELECT id, user_id, restaurant_id, table_id, status, payment_status,
+			   subtotal, discount_total, total_amount, promo_code, loyalty_points,
+			   menu_version_id, scheduled_for, service_charge, tax_total, currency,
+			   order_type, pickup_at, customer_name, delivery_address, contact_phone, delivery_fee,
+			   created_at, updated_at
+		FROM orders
+		WHERE user_id = $1 AND ` + inTenantOrders(ctx, "restaurant_id", "user_id") + `
+		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, itemsQuery, id)
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var orders []*models.Order
 	for rows.Next() {
-		item := &models.OrderItem{}
+		order := &models.Order{}
 		err := rows.Scan(
-			&item.ID,
-			&item.OrderID,
-			&item.MenuItemID,
-			&item.Quantity,
-			&item.Price,
+			&order.ID,
+			&order.UserID,
+			&order.RestaurantID,
+			&order.TableID,
+			&order.Status,
+			&order.PaymentStatus,
+			&order.Subtotal,
+			&order.DiscountTotal,
+			&order.TotalAmount,
+			&order.PromoCode,
+			&order.LoyaltyPoints,
+			&order.MenuVersionID,
+			&order.ScheduledFor,
+			&order.ServiceCharge,
+			&order.TaxTotal,
+			&order.Currency,
+			&order.Type,
+			&order.PickupAt,
+			&order.CustomerName,
+			&order.DeliveryAddress,
+			&order.ContactPhone,
+			&order.DeliveryFee,
+			&order.CreatedAt,
+			&order.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
-		order.Items = append(order.Items, *item)
+		orders = append(orders, order)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return order, nil
+	// Get items for each order
+	for _, order := range orders {
+		if err := r.loadDetails(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	return orders, nil
 }
 
-func (r *OrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Order, error) {
+// ListByUser returns a page of a user's orders, newest first, with their
+// items.
+func (r *OrderRepository) ListByUser(ctx context.Context, userID uuid.UUID, filter models.OrderFilter) ([]*models.Order, error) {
+	return r.list(ctx, "user_id", userID, filter)
+}
+
+func (r *OrderRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, filter models.OrderFilter) ([]*models.Order, error) {
+	return r.list(ctx, "restaurant_id", restaurantID, filter)
+}
+
+// list returns the orders whose owner column matches id, narrowed by the
+// filter.
+func (r *OrderRepository) list(ctx context.Context, owner string, id uuid.UUID, filter models.OrderFilter) ([]*models.Order, error) {
 	query := `
-		SELECT id, user_id, restaurant_id, status,
-			   total_amount, created_at, updated_at
+		SELECT id, user_id, restaurant_id, table_id, status, payment_status,
+			   subtotal, discount_total, total_amount, promo_code, loyalty_points,
+			   menu_version_id, scheduled_for, service_charge, tax_total, currency,
+			   order_type, pickup_at, customer_name, delivery_address, contact_phone, delivery_fee,
+			   created_at, updated_at
 		FROM orders
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-	`
+		WHERE ` + owner + ` = $1 AND ` + inTenantOrders(ctx, "restaurant_id", "user_id")
+	args := []interface{}{id}
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		query += fmt.Sprintf(" AND order_type = $%d", len(args))
+	}
+	if filter.RestaurantID != nil {
+		args = append(args, *filter.RestaurantID)
+		query += fmt.Sprintf(" AND restaurant_id = $%d", len(args))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+
+	query += " ORDER BY created_at DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -162,8 +301,25 @@ func (r *OrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]
 			&order.ID,
 			&order.UserID,
 			&order.RestaurantID,
+			&order.TableID,
 			&order.Status,
+			&order.PaymentStatus,
+			&order.Subtotal,
+			&order.DiscountTotal,
 			&order.TotalAmount,
+			&order.PromoCode,
+			&order.LoyaltyPoints,
+			&order.MenuVersionID,
+			&order.ScheduledFor,
+			&order.ServiceCharge,
+			&order.TaxTotal,
+			&order.Currency,
+			&order.Type,
+			&order.PickupAt,
+			&order.CustomerName,
+			&order.DeliveryAddress,
+			&order.ContactPhone,
+			&order.DeliveryFee,
 			&order.CreatedAt,
 			&order.UpdatedAt,
 		)
@@ -177,49 +333,67 @@ func (r *OrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]
 		return nil, err
 	}
 
-	// Get items for each order
 	for _, order := range orders {
-		itemsQuery := `
-			SELECT id, order_id, menu_item_id, quantity, price
-			FROM order_items
-			WHERE order_id = $1
-		`
-
-		rows, err := r.db.QueryContext(ctx, itemsQuery, order.ID)
-		if err != nil {
+		if err := r.loadDetails(ctx, order); err != nil {
 			return nil, err
 		}
-		defer rows.Close()
-
-		for rows.Next() {
-			item := &models.OrderItem{}
-			err := rows.Scan(
-				&item.ID,
-				&item.OrderID,
-				&item.MenuItemID,
-				&item.Quantity,
-				&item.Price,
-			)
-			if err != nil {
-				return nil, err
-			}
-			order.Items = append(order.Items, *item)
-		}
+	}
+
+	return orders, nil
+}
+
+func (r *OrderRepository) SalesByItem(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) ([]models.ItemSales, error) {
+	query := `
+		SELECT i.menu_item_id, COALESCE(m.name, ''), i.variant_id, i.variant_name,
+			   SUM(i.quantity - i.voided_quantity - i.refunded_quantity),
+			   SUM(i.price * (i.quantity - i.voided_quantity - i.refunded_quantity))
+		FROM order_items i
+		JOIN orders o ON o.id = i.order_id
+		LEFT JOIN menu_items m ON m.id = i.menu_item_id
+		WHERE o.restaurant_id = $1 AND ` + staffOnly(ctx, inTenant(ctx, "o.restaurant_id")) + `
+		  AND i.parent_item_id IS NULL
+		  AND o.status = $2
+		  AND o.created_at >= $3 AND o.created_at < $4
+		GROUP BY i.menu_item_id, m.name, i.variant_id, i.variant_name
+		HAVING SUM(i.quantity - i.voided_quantity - i.refunded_quantity) > 0
+		ORDER BY 5 DESC, 2, 4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, restaurantID, models.OrderStatusComplete, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-		if err = rows.Err(); err != nil {
+	var sales []models.ItemSales
+	for rows.Next() {
+		var line models.ItemSales
+		err := rows.Scan(
+			&line.MenuItemID,
+			&line.Name,
+			&line.VariantID,
+			&line.VariantName,
+			&line.Quantity,
+			&line.Revenue,
+		)
+		if err != nil {
 			return nil, err
 		}
+		sales = append(sales, line)
 	}
 
-	return orders, nil
+	return sales, rows.Err()
 }
 
 func (r *OrderRepository) GetByRestaurantID(ctx context.Context, restaurantID uuid.UUID) ([]*models.Order, error) {
 	query := `
-		SELECT id, user_id, restaurant_id, status,
-			   total_amount, created_at, updated_at
+		SELECT id, user_id, restaurant_id, table_id, status, payment_status,
+			   subtotal, discount_total, total_amount, promo_code, loyalty_points,
+			   menu_version_id, scheduled_for, service_charge, tax_total, currency,
+			   order_type, pickup_at, customer_name, delivery_address, contact_phone, delivery_fee,
+			   created_at, updated_at
 		FROM orders
-		WHERE restaurant_id = $1
+		WHERE restaurant_id = $1 AND ` + inTenantOrders(ctx, "restaurant_id", "user_id") + `
 		ORDER BY created_at DESC
 	`
 
@@ -236,8 +410,25 @@ func (r *OrderRepository) GetByRestaurantID(ctx context.Context, restaurantID uu
 			&order.ID,
 			&order.UserID,
 			&order.RestaurantID,
+			&order.TableID,
 			&order.Status,
+			&order.PaymentStatus,
+			&order.Subtotal,
+			&order.DiscountTotal,
 			&order.TotalAmount,
+			&order.PromoCode,
+			&order.LoyaltyPoints,
+			&order.MenuVersionID,
+			&order.ScheduledFor,
+			&order.ServiceCharge,
+			&order.TaxTotal,
+			&order.Currency,
+			&order.Type,
+			&order.PickupAt,
+			&order.CustomerName,
+			&order.DeliveryAddress,
+			&order.ContactPhone,
+			&order.DeliveryFee,
 			&order.CreatedAt,
 			&order.UpdatedAt,
 		)
@@ -254,6 +445,74 @@ func (r *OrderRepository) GetByRestaurantID(ctx context.Context, restaurantID uu
 	return orders, nil
 }
 
+// GetOpenByTableID returns the orders of a table that are neither complete
+// nor canceled, with their items.
+func (r *OrderRepository) GetOpenByTableID(ctx context.Context, tableID uuid.UUID) ([]*models.Order, error) {
+	query := `
+		SELECT id, user_id, restaurant_id, table_id, status, payment_status,
+			   subtotal, discount_total, total_amount, promo_code, loyalty_points,
+			   menu_version_id, scheduled_for, service_charge, tax_total, currency,
+			   order_type, pickup_at, customer_name, delivery_address, contact_phone, delivery_fee,
+			   created_at, updated_at
+		FROM orders
+		WHERE table_id = $1 AND status NOT IN ($2, $3) AND ` + inTenantOrders(ctx, "restaurant_id", "user_id") + `
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tableID, models.OrderStatusComplete, models.OrderStatusCanceled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*models.Order
+	for rows.Next() {
+		order := &models.Order{}
+		err := rows.Scan(
+			&order.ID,
+			&order.UserID,
+			&order.RestaurantID,
+			&order.TableID,
+			&order.Status,
+			&order.PaymentStatus,
+			&order.Subtotal,
+			&order.DiscountTotal,
+			&order.TotalAmount,
+			&order.PromoCode,
+			&order.LoyaltyPoints,
+			&order.MenuVersionID,
+			&order.ScheduledFor,
+			&order.ServiceCharge,
+			&order.TaxTotal,
+			&order.Currency,
+			&order.Type,
+			&order.PickupAt,
+			&order.CustomerName,
+			&order.DeliveryAddress,
+			&order.ContactPhone,
+			&order.DeliveryFee,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		if err := r.loadDetails(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	return orders, nil
+}
+
 func (r *OrderRepository) Update(ctx context.Context, order *models.Order) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -265,18 +524,40 @@ func (r *OrderRepository) Update(ctx context.Context, order *models.Order) error
 	query := `
 		UPDATE orders
 		SET status = $1,
-			total_amount = $2,
-			updated_at = $3
-		WHERE id = $4
-	`
+			subtotal = $2,
+			discount_total = $3,
+			total_amount = $4,
+			promo_code = $5,
+			service_charge = $6,
+			tax_total = $7,
+			currency = $8,
+			pickup_at = $9,
+			customer_name = $10,
+			delivery_address = $11,
+			contact_phone = $12,
+			delivery_fee = $13,
+			updated_at = $14
+		WHERE id = $15 AND status = $16 AND ` + inTenantOrders(ctx, "restaurant_id", "user_id")
 
 	order.UpdatedAt = time.Now()
 
 	result, err := tx.ExecContext(ctx, query,
 		order.Status,
+		order.Subtotal,
+		order.DiscountTotal,
 		order.TotalAmount,
+		order.PromoCode,
+		order.ServiceCharge,
+		order.TaxTotal,
+		order.Currency,
+		order.PickupAt,
+		order.CustomerName,
+		order.DeliveryAddress,
+		order.ContactPhone,
+		order.DeliveryFee,
 		order.UpdatedAt,
 		order.ID,
+		models.OrderStatusPending,
 	)
 	if err != nil {
 		return err
@@ -290,6 +571,11 @@ func (r *OrderRepository) Update(ctx context.Context, order *models.Order) error
 		return sql.ErrNoRows
 	}
 
+	// Give back the portions of the old items before claiming the new ones
+	if err := releasePortions(ctx, tx, order.ID); err != nil {
+		return err
+	}
+
 	// Delete existing items
 	_, err = tx.ExecContext(ctx, "DELETE FROM order_items WHERE order_id = $1", order.ID)
 	if err != nil {
@@ -297,27 +583,82 @@ func (r *OrderRepository) Update(ctx context.Context, order *models.Order) error
 	}
 
 	// Insert updated items
-	itemQuery := `
-		INSERT INTO order_items (
-			id, order_id, menu_item_id,
-			quantity, price
-		) VALUES ($1, $2, $3, $4, $5)
-	`
+	if err := claimPortions(ctx, tx, order.Items); err != nil {
+		return err
+	}
+	if err := r.insertItems(ctx, tx, order); err != nil {
+		return err
+	}
 
-	for _, item := range order.Items {
-		if item.ID == uuid.Nil {
-			item.ID = uuid.New()
+	// Replace discounts
+	if err := swapRuleUses(ctx, tx, order.ID, order.Discounts); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM order_discounts WHERE order_id = $1", order.ID)
+	if err != nil {
+		return err
+	}
+	if err := insertOrderDiscounts(ctx, tx, order); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateStatus moves an order from change.From to change.To and, in the
+// same transaction, books what the change brings with it: accepting sends
+// the items to the kitchen, canceling gives the portions back to the menu,
+// and the change's stock movements and loyalty entry are recorded. It
+// returns sql.ErrNoRows if the order is no longer in the from status.
+func (r *OrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, change models.OrderStatusChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE orders
+		SET status = $1,
+			updated_at = $2
+		WHERE id = $3 AND status = $4 AND `+inTenantOrders(ctx, "restaurant_id", "user_id"),
+		change.To,
+		now,
+		id,
+		change.From,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	switch change.To {
+	case models.OrderStatusAccepted:
+		// From now on the items can only be refunded, not voided
+		_, err = tx.ExecContext(ctx, `UPDATE order_items SET sent_at = $1 WHERE order_id = $2 AND sent_at IS NULL`, now, id)
+	case models.OrderStatusCanceled:
+		err = releasePortions(ctx, tx, id)
+		if err == nil {
+			// The promotions can be used again by someone else
+			err = swapRuleUses(ctx, tx, id, nil)
 		}
-		item.OrderID = order.ID
-
-		_, err = tx.ExecContext(ctx, itemQuery,
-			item.ID,
-			item.OrderID,
-			item.MenuItemID,
-			item.Quantity,
-			item.Price,
-		)
-		if err != nil {
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := recordStockMovements(ctx, tx, change.StockMovements); err != nil {
+		return err
+	}
+	if change.LoyaltyEntry != nil {
+		if _, err := creditLoyaltyPoints(ctx, tx, change.LoyaltyEntry); err != nil {
 			return err
 		}
 	}
@@ -325,13 +666,12 @@ func (r *OrderRepository) Update(ctx context.Context, order *models.Order) error
 	return tx.Commit()
 }
 
-func (r *OrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.OrderStatus) error {
+func (r *OrderRepository) UpdatePaymentStatus(ctx context.Context, id uuid.UUID, status models.OrderPaymentStatus) error {
 	query := `
 		UPDATE orders
-		SET status = $1,
+		SET payment_status = $1,
 			updated_at = $2
-		WHERE id = $3
-	`
+		WHERE id = $3 AND ` + inTenantOrders(ctx, "restaurant_id", "user_id")
 
 	result, err := r.db.ExecContext(ctx, query,
 		status,
@@ -353,32 +693,336 @@ func (r *OrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status
 	return nil
 }
 
-func (r *OrderRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
+func (r *OrderRepository) loadDetails(ctx context.Context, order *models.Order) error {
+	var err error
+	if order.Items, err = r.loadItems(ctx, order.ID); err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	order.Discounts, err = r.loadDiscounts(ctx, order.ID)
+	return err
+}
 
-	// Delete order items first
-	_, err = tx.ExecContext(ctx, "DELETE FROM order_items WHERE order_id = $1", id)
+// loadItems returns the items of an order with bundle components nested
+// under their bundle.
+func (r *OrderRepository) loadItems(ctx context.Context, orderID uuid.UUID) ([]models.OrderItem, error) {
+	query := `
+		SELECT id, order_id, parent_item_id, bundle_group_id, menu_item_id,
+			   variant_id, variant_name, quantity, price, seat, options,
+			   sent_at, voided_quantity, refunded_quantity
+		FROM order_items
+		WHERE order_id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
 	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		items      []models.OrderItem
+		components = make(map[uuid.UUID][]models.OrderItem)
+	)
+	for rows.Next() {
+		var (
+			item     models.OrderItem
+			parentID *uuid.UUID
+			options  []byte
+		)
+		err := rows.Scan(
+			&item.ID,
+			&item.OrderID,
+			&parentID,
+			&item.BundleGroupID,
+			&item.MenuItemID,
+			&item.VariantID,
+			&item.VariantName,
+			&item.Quantity,
+			&item.Price,
+			&item.Seat,
+			&options,
+			&item.SentAt,
+			&item.VoidedQuantity,
+			&item.RefundedQuantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(options, &item.Options); err != nil {
+			return nil, err
+		}
+		if parentID != nil {
+			components[*parentID] = append(components[*parentID], item)
+			continue
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].Components = components[items[i].ID]
+	}
+
+	return items, nil
+}
+
+func (r *OrderRepository) loadDiscounts(ctx context.Context, orderID uuid.UUID) ([]models.OrderDiscount, error) {
+	query := `
+		SELECT id, order_id, rule_id, code, description, amount
+		FROM order_discounts
+		WHERE order_id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var discounts []models.OrderDiscount
+	for rows.Next() {
+		var discount models.OrderDiscount
+		err := rows.Scan(
+			&discount.ID,
+			&discount.OrderID,
+			&discount.RuleID,
+			&discount.Code,
+			&discount.Description,
+			&discount.Amount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, discount)
+	}
+
+	return discounts, rows.Err()
+}
+
+// claimPortions takes the ordered quantities off the portion counts of the
+// items and bundle components, marking an item unavailable once it has no portions left. It fails
+// if an item is unavailable or has too few portions.
+func claimPortions(ctx context.Context, tx *sql.Tx, items []models.OrderItem) error {
+	var ids []uuid.UUID
+	quantities := make(map[uuid.UUID]int)
+	add := func(id uuid.UUID, quantity int) {
+		if _, ok := quantities[id]; !ok {
+			ids = append(ids, id)
+		}
+		quantities[id] += quantity
+	}
+	for _, item := range items {
+		add(item.MenuItemID, item.Quantity)
+		for _, component := range item.Components {
+			add(component.MenuItemID, component.Quantity*item.Quantity)
+		}
+	}
+
+	query := `
+		UPDATE menu_items
+		SET portions_remaining = portions_remaining - $1,
+			available = portions_remaining IS NULL OR portions_remaining > $1
+		WHERE id = $2 AND available
+			AND (portions_remaining IS NULL OR portions_remaining >= $1)
+			AND ` + inTenant(ctx, "restaurant_id")
+
+	for _, id := range ids {
+		result, err := tx.ExecContext(ctx, query, quantities[id], id)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return repository.ErrSoldOut
+		}
+	}
+
+	return nil
+}
+
+// swapRuleUses counts a use of each promotion in discounts that the order
+// did not have yet, failing if one has run out, and gives back the use of
+// each one it had that discounts leave out. It must run before the order's
+// discounts are replaced.
+func swapRuleUses(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, discounts []models.OrderDiscount) error {
+	rows, err := tx.QueryContext(ctx, `SELECT rule_id FROM order_discounts WHERE order_id = $1 AND rule_id IS NOT NULL`, orderID)
+	if err != nil {
+		return err
+	}
+	had := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var ruleID uuid.UUID
+		if err := rows.Scan(&ruleID); err != nil {
+			rows.Close()
+			return err
+		}
+		had[ruleID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
 		return err
 	}
 
-	// Delete order
-	result, err := tx.ExecContext(ctx, "DELETE FROM orders WHERE id = $1", id)
+	for _, discount := range discounts {
+		if discount.RuleID == nil {
+			continue
+		}
+		if had[*discount.RuleID] {
+			delete(had, *discount.RuleID)
+			continue
+		}
+		result, err := tx.ExecContext(ctx, `
+			UPDATE pricing_rules
+			SET usage_count = usage_count + 1
+			WHERE id = $1 AND (usage_limit IS NULL OR usage_count < usage_limit)
+		`, discount.RuleID)
+		if err != nil {
+			return err
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return repository.ErrUsageLimitReached
+		}
+	}
+
+	for ruleID := range had {
+		_, err := tx.ExecContext(ctx, `UPDATE pricing_rules SET usage_count = GREATEST(usage_count - 1, 0) WHERE id = $1`, ruleID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releasePortions adds the quantities of an order's items and bundle
+// components back to their portion counts, making items that had run out
+// available again. Voided quantities were given back when they were voided.
+func releasePortions(ctx context.Context, tx *sql.Tx, orderID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE menu_items m
+		SET portions_remaining = m.portions_remaining + q.quantity,
+			available = m.available OR m.portions_remaining = 0
+		FROM (
+			SELECT i.menu_item_id, SUM((i.quantity - i.voided_quantity) * COALESCE(p.quantity - p.voided_quantity, 1)) AS quantity
+			FROM order_items i
+			LEFT JOIN order_items p ON p.id = i.parent_item_id
+			WHERE i.order_id = $1
+			GROUP BY i.menu_item_id
+		) q
+		WHERE m.id = q.menu_item_id AND m.portions_remaining IS NOT NULL
+			AND `+inTenant(ctx, "m.restaurant_id"), orderID)
+	return err
+}
+
+// releaseItemPortions adds quantity of an order item, and its bundle
+// components, back to their portion counts.
+func releaseItemPortions(ctx context.Context, tx *sql.Tx, itemID uuid.UUID, quantity int) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE menu_items m
+		SET portions_remaining = m.portions_remaining + q.quantity,
+			available = m.available OR m.portions_remaining = 0
+		FROM (
+			SELECT menu_item_id, SUM(CASE WHEN id = $1 THEN $2 ELSE quantity * $2 END) AS quantity
+			FROM order_items
+			WHERE id = $1 OR parent_item_id = $1
+			GROUP BY menu_item_id
+		) q
+		WHERE m.id = q.menu_item_id AND m.portions_remaining IS NOT NULL
+			AND `+inTenant(ctx, "m.restaurant_id"), itemID, quantity)
+	return err
+}
+
+// insertItems stores the items of an order and their bundle components,
+// giving new items an ID.
+func (r *OrderRepository) insertItems(ctx context.Context, tx *sql.Tx, order *models.Order) error {
+	for i := range order.Items {
+		if err := r.insertItem(ctx, tx, order.ID, nil, &order.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *OrderRepository) insertItem(ctx context.Context, tx *sql.Tx, orderID uuid.UUID, parentID *uuid.UUID, item *models.OrderItem) error {
+	query := `
+		INSERT INTO order_items (
+			id, order_id, parent_item_id, bundle_group_id, menu_item_id,
+			variant_id, variant_name, quantity, price, seat, options
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	if item.ID == uuid.Nil {
+		item.ID = uuid.New()
+	}
+	item.OrderID = orderID
+
+	options := item.Options
+	if options == nil {
+		options = []models.OrderItemOption{}
+	}
+	encoded, err := json.Marshal(options)
 	if err != nil {
 		return err
 	}
 
-	rows, err := result.RowsAffected()
+	_, err = tx.ExecContext(ctx, query,
+		item.ID,
+		item.OrderID,
+		parentID,
+		item.BundleGroupID,
+		item.MenuItemID,
+		item.VariantID,
+		item.VariantName,
+		item.Quantity,
+		item.Price,
+		item.Seat,
+		encoded,
+	)
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
-		return sql.ErrNoRows
+
+	for i := range item.Components {
+		if err := r.insertItem(ctx, tx, orderID, &item.ID, &item.Components[i]); err != nil {
+			return err
+		}
 	}
 
-	return tx.Commit()
+	return nil
+}
+
+func insertOrderDiscounts(ctx context.Context, db execer, order *models.Order) error {
+	query := `
+		INSERT INTO order_discounts (id, order_id, rule_id, code, description, amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	for i := range order.Discounts {
+		discount := &order.Discounts[i]
+		discount.ID = uuid.New()
+		discount.OrderID = order.ID
+
+		_, err := db.ExecContext(ctx, query,
+			discount.ID,
+			discount.OrderID,
+			discount.RuleID,
+			discount.Code,
+			discount.Description,
+			discount.Amount,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
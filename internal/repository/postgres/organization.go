@@ -0,0 +1,273 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+func (r *OrganizationRepository) Create(ctx context.Context, organization *models.Organization) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	organization.ID = uuid.New()
+	organization.CreatedAt = now
+	organization.UpdatedAt = now
+	organization.Role = models.OrganizationRoleOwner
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organizations (id, name, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, organization.ID, organization.Name, organization.CreatedBy, organization.CreatedAt, organization.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO organization_members (organization_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, organization.ID, organization.CreatedBy, models.OrganizationRoleOwner, now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *OrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	organizations, err := r.query(ctx, `
+		SELECT id, name, created_by, '' AS role, created_at, updated_at
+		FROM organizations
+		WHERE id = $1 AND `+staffOnly(ctx, inOrganizations(ctx, "id")), id)
+	if err != nil || len(organizations) == 0 {
+		return nil, err
+	}
+	return organizations[0], nil
+}
+
+func (r *OrganizationRepository) List(ctx context.Context) ([]*models.Organization, error) {
+	return r.query(ctx, `
+		SELECT id, name, created_by, '' AS role, created_at, updated_at
+		FROM organizations
+		WHERE `+staffOnly(ctx, inOrganizations(ctx, "id"))+`
+		ORDER BY name
+	`)
+}
+
+func (r *OrganizationRepository) ListByMember(ctx context.Context, userID uuid.UUID) ([]*models.Organization, error) {
+	return r.query(ctx, `
+		SELECT o.id, o.name, o.created_by, m.role, o.created_at, o.updated_at
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1 AND `+staffOnly(ctx, inOrganizations(ctx, "o.id"))+`
+		ORDER BY o.name
+	`, userID)
+}
+
+func (r *OrganizationRepository) Update(ctx context.Context, organization *models.Organization) error {
+	organization.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE organizations SET name = $1, updated_at = $2
+		WHERE id = $3 AND `+staffOnly(ctx, inOrganizations(ctx, "id")),
+		organization.Name, organization.UpdatedAt, organization.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *OrganizationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM organizations WHERE id = $1 AND `+staffOnly(ctx, inOrganizations(ctx, "id")), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *OrganizationRepository) CountRestaurants(ctx context.Context, id uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM restaurants WHERE organization_id = $1 AND `+staffOnly(ctx, inOrganizations(ctx, "organization_id")), id).Scan(&count)
+	return count, err
+}
+
+func (r *OrganizationRepository) ListMembers(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error) {
+	return r.queryMembers(ctx, `
+		SELECT m.organization_id, m.user_id, u.email, m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1 AND `+staffOnly(ctx, inOrganizations(ctx, "m.organization_id"))+`
+		ORDER BY u.email
+	`, organizationID)
+}
+
+func (r *OrganizationRepository) GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	members, err := r.queryMembers(ctx, `
+		SELECT m.organization_id, m.user_id, u.email, m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1 AND m.user_id = $2 AND `+staffOnly(ctx, inOrganizations(ctx, "m.organization_id")),
+		organizationID, userID)
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+	return members[0], nil
+}
+
+// ListMemberships is not tenant-scoped: it is what the scope of a request
+// is worked out from.
+func (r *OrganizationRepository) ListMemberships(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT organization_id FROM organization_members WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *OrganizationRepository) SaveMember(ctx context.Context, member *models.OrganizationMember) error {
+	query := `
+		INSERT INTO organization_members (organization_id, user_id, role, created_at)
+		SELECT $1, $2, $3, $4
+		WHERE ` + staffOnly(ctx, inOrganizations(ctx, "$1::uuid")) + `
+		ON CONFLICT (organization_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING created_at
+	`
+	return r.db.QueryRowContext(ctx, query, member.OrganizationID, member.UserID, member.Role, time.Now()).Scan(&member.CreatedAt)
+}
+
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM organization_members
+		WHERE organization_id = $1 AND user_id = $2 AND `+staffOnly(ctx, inOrganizations(ctx, "organization_id")),
+		organizationID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *OrganizationRepository) CountOwners(ctx context.Context, organizationID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM organization_members
+		WHERE organization_id = $1 AND role = $2 AND `+staffOnly(ctx, inOrganizations(ctx, "organization_id")),
+		organizationID, models.OrganizationRoleOwner).Scan(&count)
+	return count, err
+}
+
+func (r *OrganizationRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Organization, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	organizations := []*models.Organization{}
+	for rows.Next() {
+		organization := &models.Organization{}
+		if err := rows.Scan(
+			&organization.ID,
+			&organization.Name,
+			&organization.CreatedBy,
+			&organization.Role,
+			&organization.CreatedAt,
+			&organization.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		organizations = append(organizations, organization)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return organizations, nil
+}
+
+func (r *OrganizationRepository) queryMembers(ctx context.Context, query string, args ...interface{}) ([]*models.OrganizationMember, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.OrganizationMember{}
+	for rows.Next() {
+		member := &models.OrganizationMember{}
+		if err := rows.Scan(
+			&member.OrganizationID,
+			&member.UserID,
+			&member.Email,
+			&member.Role,
+			&member.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
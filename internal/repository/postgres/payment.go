@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+type PaymentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRepository(db *sql.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+func (r *PaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	query := `
+		INSERT INTO payments (
+			id, order_id, check_id, provider, provider_ref, amount,
+			captured_amount, refunded_amount, status, failure_reason,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	if err := checkTenantOrder(ctx, r.db, payment.OrderID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	payment.ID = uuid.New()
+	payment.CreatedAt = now
+	payment.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, query,
+		payment.ID,
+		payment.OrderID,
+		payment.CheckID,
+		payment.Provider,
+		payment.ProviderRef,
+		payment.Amount,
+		payment.CapturedAmount,
+		payment.RefundedAmount,
+		payment.Status,
+		payment.FailureReason,
+		payment.CreatedAt,
+		payment.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *PaymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
+	query := `
+		SELECT id, order_id, check_id, provider, provider_ref, amount,
+			   captured_amount, refunded_amount, status, failure_reason,
+			   created_at, updated_at
+		FROM payments
+		WHERE id = $1 AND ` + inTenantOrder(ctx, "order_id")
+
+	return r.getOne(ctx, query, id)
+}
+
+func (r *PaymentRepository) GetByProviderRef(ctx context.Context, provider, ref string) (*models.Payment, error) {
+	query := `
+		SELECT id, order_id, check_id, provider, provider_ref, amount,
+			   captured_amount, refunded_amount, status, failure_reason,
+			   created_at, updated_at
+		FROM payments
+		WHERE provider = $1 AND provider_ref = $2 AND ` + inTenantOrder(ctx, "order_id")
+
+	return r.getOne(ctx, query, provider, ref)
+}
+
+func (r *PaymentRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.Payment, error) {
+	query := `
+		SELECT id, order_id, check_id, provider, provider_ref, amount,
+			   captured_amount, refunded_amount, status, failure_reason,
+			   created_at, updated_at
+		FROM payments
+		WHERE order_id = $1 AND ` + inTenantOrder(ctx, "order_id") + `
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*models.Payment
+	for rows.Next() {
+		payment := &models.Payment{}
+		err := rows.Scan(
+			&payment.ID,
+			&payment.OrderID,
+			&payment.CheckID,
+			&payment.Provider,
+			&payment.ProviderRef,
+			&payment.Amount,
+			&payment.CapturedAmount,
+			&payment.RefundedAmount,
+			&payment.Status,
+			&payment.FailureReason,
+			&payment.CreatedAt,
+			&payment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// Update writes the payment if it is still in the from status with the
+// given refunded amount, so that two changes made from the same read cannot
+// both be stored. It returns sql.ErrNoRows if the payment has changed.
+func (r *PaymentRepository) Update(ctx context.Context, payment *models.Payment, from models.PaymentStatus, refunded float64) error {
+	query := `
+		UPDATE payments
+		SET provider_ref = $1,
+			amount = $2,
+			captured_amount = $3,
+			refunded_amount = $4,
+			status = $5,
+			failure_reason = $6,
+			updated_at = $7
+		WHERE id = $8 AND status = $9 AND refunded_amount = $10 AND ` + inTenantOrder(ctx, "order_id")
+
+	payment.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		payment.ProviderRef,
+		payment.Amount,
+		payment.CapturedAmount,
+		payment.RefundedAmount,
+		payment.Status,
+		payment.FailureReason,
+		payment.UpdatedAt,
+		payment.ID,
+		from,
+		refunded,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *PaymentRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.Payment, error) {
+	payment := &models.Payment{}
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&payment.ID,
+		&payment.OrderID,
+		&payment.CheckID,
+		&payment.Provider,
+		&payment.ProviderRef,
+		&payment.Amount,
+		&payment.CapturedAmount,
+		&payment.RefundedAmount,
+		&payment.Status,
+		&payment.FailureReason,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
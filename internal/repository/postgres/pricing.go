@@ -0,0 +1,230 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type PricingRuleRepository struct {
+	db *sql.DB
+}
+
+func NewPricingRuleRepository(db *sql.DB) *PricingRuleRepository {
+	return &PricingRuleRepository{db: db}
+}
+
+const pricingRuleColumns = `
+	id, restaurant_id, name, type, scope, scope_id, value, buy_quantity,
+	get_quantity, promo_code, min_subtotal, valid_from, valid_until,
+	days_of_week, start_time, end_time, usage_limit, usage_count, stackable,
+	priority, active, created_at, updated_at
+`
+
+func (r *PricingRuleRepository) Create(ctx context.Context, rule *models.PricingRule) error {
+	query := `
+		INSERT INTO pricing_rules (` + pricingRuleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+				$14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+	`
+
+	if err := checkTenant(ctx, r.db, rule.RestaurantID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rule.ID = uuid.New()
+	rule.UsageCount = 0
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, query,
+		rule.ID,
+		rule.RestaurantID,
+		rule.Name,
+		rule.Type,
+		rule.Scope,
+		rule.ScopeID,
+		rule.Value,
+		rule.BuyQuantity,
+		rule.GetQuantity,
+		rule.PromoCode,
+		rule.MinSubtotal,
+		rule.ValidFrom,
+		rule.ValidUntil,
+		pq.Array(toInt64s(rule.DaysOfWeek)),
+		rule.StartTime,
+		rule.EndTime,
+		rule.UsageLimit,
+		rule.UsageCount,
+		rule.Stackable,
+		rule.Priority,
+		rule.Active,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *PricingRuleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PricingRule, error) {
+	query := `SELECT ` + pricingRuleColumns + ` FROM pricing_rules WHERE id = $1 AND ` + inTenant(ctx, "restaurant_id")
+
+	rules, err := r.query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	return rules[0], nil
+}
+
+func (r *PricingRuleRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.PricingRule, error) {
+	query := `
+		SELECT ` + pricingRuleColumns + `
+		FROM pricing_rules
+		WHERE restaurant_id = $1 AND ` + inTenant(ctx, "restaurant_id") + `
+		ORDER BY priority DESC, created_at
+	`
+
+	return r.query(ctx, query, restaurantID)
+}
+
+func (r *PricingRuleRepository) Update(ctx context.Context, rule *models.PricingRule) error {
+	query := `
+		UPDATE pricing_rules
+		SET name = $1,
+			type = $2,
+			scope = $3,
+			scope_id = $4,
+			value = $5,
+			buy_quantity = $6,
+			get_quantity = $7,
+			promo_code = $8,
+			min_subtotal = $9,
+			valid_from = $10,
+			valid_until = $11,
+			days_of_week = $12,
+			start_time = $13,
+			end_time = $14,
+			usage_limit = $15,
+			stackable = $16,
+			priority = $17,
+			active = $18,
+			updated_at = $19
+		WHERE id = $20 AND restaurant_id = $21 AND ` + inTenant(ctx, "restaurant_id")
+
+	rule.UpdatedAt = time.Now()
+
+	result, err := r.db.ExecContext(ctx, query,
+		rule.Name,
+		rule.Type,
+		rule.Scope,
+		rule.ScopeID,
+		rule.Value,
+		rule.BuyQuantity,
+		rule.GetQuantity,
+		rule.PromoCode,
+		rule.MinSubtotal,
+		rule.ValidFrom,
+		rule.ValidUntil,
+		pq.Array(toInt64s(rule.DaysOfWeek)),
+		rule.StartTime,
+		rule.EndTime,
+		rule.UsageLimit,
+		rule.Stackable,
+		rule.Priority,
+		rule.Active,
+		rule.UpdatedAt,
+		rule.ID,
+		rule.RestaurantID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *PricingRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM pricing_rules WHERE id = $1 AND ` + inTenant(ctx, "restaurant_id")
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *PricingRuleRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.PricingRule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*models.PricingRule
+	for rows.Next() {
+		rule := &models.PricingRule{}
+		var days pq.Int64Array
+		err := rows.Scan(
+			&rule.ID,
+			&rule.RestaurantID,
+			&rule.Name,
+			&rule.Type,
+			&rule.Scope,
+			&rule.ScopeID,
+			&rule.Value,
+			&rule.BuyQuantity,
+			&rule.GetQuantity,
+			&rule.PromoCode,
+			&rule.MinSubtotal,
+			&rule.ValidFrom,
+			&rule.ValidUntil,
+			&days,
+			&rule.StartTime,
+			&rule.EndTime,
+			&rule.UsageLimit,
+			&rule.UsageCount,
+			&rule.Stackable,
+			&rule.Priority,
+			&rule.Active,
+			&rule.CreatedAt,
+			&rule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rule.DaysOfWeek = fromInt64s(days)
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
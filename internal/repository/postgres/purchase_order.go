@@ -0,0 +1,354 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+const purchaseOrderColumns = `
+	id, restaurant_id, supplier_id, status, expected_at, notes, total,
+	created_by, sent_at, created_at, updated_at`
+
+type PurchaseOrderRepository struct {
+	db *sql.DB
+}
+
+func NewPurchaseOrderRepository(db *sql.DB) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{db: db}
+}
+
+func (r *PurchaseOrderRepository) Create(ctx context.Context, order *models.PurchaseOrder) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTenant(ctx, tx, order.RestaurantID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO purchase_orders (` + purchaseOrderColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	now := time.Now()
+	order.ID = uuid.New()
+	order.Status = models.PurchaseOrderDraft
+	order.CreatedAt = now
+	order.UpdatedAt = now
+
+	_, err = tx.ExecContext(ctx, query,
+		order.ID,
+		order.RestaurantID,
+		order.SupplierID,
+		order.Status,
+		order.ExpectedAt,
+		order.Notes,
+		order.Total,
+		order.CreatedBy,
+		order.SentAt,
+		order.CreatedAt,
+		order.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := r.insertLines(ctx, tx, order); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PurchaseOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PurchaseOrder, error) {
+	orders, err := r.query(ctx, `SELECT `+purchaseOrderColumns+` FROM purchase_orders WHERE id = $1 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id")), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, nil
+	}
+	return orders[0], nil
+}
+
+func (r *PurchaseOrderRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, filter models.PurchaseOrderFilter) ([]*models.PurchaseOrder, error) {
+	query := `SELECT ` + purchaseOrderColumns + ` FROM purchase_orders WHERE restaurant_id = $1 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id"))
+	args := []interface{}{restaurantID}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.SupplierID != nil {
+		args = append(args, *filter.SupplierID)
+		query += fmt.Sprintf(" AND supplier_id = $%d", len(args))
+	}
+	query += " ORDER BY created_at DESC"
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *PurchaseOrderRepository) Update(ctx context.Context, order *models.PurchaseOrder) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE purchase_orders
+		SET expected_at = $1, notes = $2, total = $3, updated_at = $4
+		WHERE id = $5 AND status = $6 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id"))
+
+	order.UpdatedAt = time.Now()
+
+	result, err := tx.ExecContext(ctx, query,
+		order.ExpectedAt,
+		order.Notes,
+		order.Total,
+		order.UpdatedAt,
+		order.ID,
+		models.PurchaseOrderDraft,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM purchase_order_lines WHERE purchase_order_id = $1`, order.ID); err != nil {
+		return err
+	}
+	for i := range order.Lines {
+		order.Lines[i].ID = uuid.Nil
+	}
+	if err := r.insertLines(ctx, tx, order); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PurchaseOrderRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE purchase_orders
+		SET status = $1, sent_at = $2, updated_at = $2
+		WHERE id = $3 AND status = $4 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id"))
+
+	result, err := r.db.ExecContext(ctx, query, models.PurchaseOrderSent, time.Now(), id, models.PurchaseOrderDraft)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *PurchaseOrderRepository) Receive(ctx context.Context, order *models.PurchaseOrder, received map[uuid.UUID]float64, movements []*models.StockMovement) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTenantParent(ctx, tx, "purchase_orders", order.ID); err != nil {
+		return err
+	}
+
+	lineQuery := `
+		UPDATE purchase_order_lines
+		SET received_quantity = received_quantity + $1
+		WHERE id = $2 AND purchase_order_id = $3 AND received_quantity + $1 <= quantity
+	`
+
+	for lineID, quantity := range received {
+		result, err := tx.ExecContext(ctx, lineQuery, quantity, lineID, order.ID)
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return sql.ErrNoRows
+		}
+	}
+
+	var complete bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT bool_and(received_quantity >= quantity)
+		FROM purchase_order_lines
+		WHERE purchase_order_id = $1
+	`, order.ID).Scan(&complete)
+	if err != nil {
+		return err
+	}
+
+	order.Status = models.PurchaseOrderPartiallyReceived
+	if complete {
+		order.Status = models.PurchaseOrderReceived
+	}
+	order.UpdatedAt = time.Now()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE purchase_orders SET status = $1, updated_at = $2 WHERE id = $3
+	`, order.Status, order.UpdatedAt, order.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := recordStockMovements(ctx, tx, movements); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	order.Lines, err = r.loadLines(ctx, order.ID)
+	return err
+}
+
+func (r *PurchaseOrderRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM purchase_orders WHERE id = $1 AND status = $2 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id")), id, models.PurchaseOrderDraft)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *PurchaseOrderRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.PurchaseOrder, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*models.PurchaseOrder
+	for rows.Next() {
+		order := &models.PurchaseOrder{}
+		err := rows.Scan(
+			&order.ID,
+			&order.RestaurantID,
+			&order.SupplierID,
+			&order.Status,
+			&order.ExpectedAt,
+			&order.Notes,
+			&order.Total,
+			&order.CreatedBy,
+			&order.SentAt,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		if order.Lines, err = r.loadLines(ctx, order.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return orders, nil
+}
+
+func (r *PurchaseOrderRepository) loadLines(ctx context.Context, orderID uuid.UUID) ([]models.PurchaseOrderLine, error) {
+	query := `
+		SELECT id, purchase_order_id, ingredient_id, quantity, received_quantity, unit_cost
+		FROM purchase_order_lines
+		WHERE purchase_order_id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []models.PurchaseOrderLine{}
+	for rows.Next() {
+		var line models.PurchaseOrderLine
+		err := rows.Scan(
+			&line.ID,
+			&line.PurchaseOrderID,
+			&line.IngredientID,
+			&line.Quantity,
+			&line.ReceivedQuantity,
+			&line.UnitCost,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+func (r *PurchaseOrderRepository) insertLines(ctx context.Context, tx *sql.Tx, order *models.PurchaseOrder) error {
+	query := `
+		INSERT INTO purchase_order_lines (
+			id, purchase_order_id, ingredient_id, quantity, received_quantity, unit_cost
+		) VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		if line.ID == uuid.Nil {
+			line.ID = uuid.New()
+		}
+		line.PurchaseOrderID = order.ID
+
+		_, err := tx.ExecContext(ctx, query,
+			line.ID,
+			line.PurchaseOrderID,
+			line.IngredientID,
+			line.Quantity,
+			line.ReceivedQuantity,
+			line.UnitCost,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -3,12 +3,23 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/KNLopez/restaurant-api/internal/tenant"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// earthRadiusKm is the mean radius of the Earth, for distances between
+// coordinates.
+const earthRadiusKm = 6371.0
+
 type RestaurantRepository struct {
 	db *sql.DB
 }
@@ -17,24 +28,44 @@ func NewRestaurantRepository(db *sql.DB) *RestaurantRepository {
 	return &RestaurantRepository{db: db}
 }
 
+const restaurantColumns = `id, name, description, manager_id, organization_id, brand_id, address,
+	latitude, longitude, cuisine_tags, phone, timezone, translations, created_at, updated_at`
+
 func (r *RestaurantRepository) Create(ctx context.Context, restaurant *models.Restaurant) error {
 	query := `
-		INSERT INTO restaurants (id, name, description, manager_id, address, phone, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO restaurants (
+			id, name, description, manager_id, organization_id, address, latitude, longitude,
+			cuisine_tags, phone, timezone, translations, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
+	if !tenant.Allows(ctx, restaurant.OrganizationID) {
+		return repository.ErrOutsideTenant
+	}
+
 	now := time.Now()
 	restaurant.ID = uuid.New()
 	restaurant.CreatedAt = now
 	restaurant.UpdatedAt = now
 
-	_, err := r.db.ExecContext(ctx, query,
+	translations, err := marshalTranslations(restaurant.Translations)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
 		restaurant.ID,
 		restaurant.Name,
 		restaurant.Description,
 		restaurant.ManagerID,
+		restaurant.OrganizationID,
 		restaurant.Address,
+		restaurant.Latitude,
+		restaurant.Longitude,
+		pq.Array(restaurant.CuisineTags),
 		restaurant.Phone,
+		restaurant.Timezone,
+		translations,
 		restaurant.CreatedAt,
 		restaurant.UpdatedAt,
 	)
@@ -43,42 +74,124 @@ func (r *RestaurantRepository) Create(ctx context.Context, restaurant *models.Re
 }
 
 func (r *RestaurantRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Restaurant, error) {
+	restaurants, err := r.query(ctx, `SELECT `+restaurantColumns+` FROM restaurants WHERE id = $1 AND `+inOrganizations(ctx, "organization_id"), id)
+	if err != nil || len(restaurants) == 0 {
+		return nil, err
+	}
+	return restaurants[0], nil
+}
+
+func (r *RestaurantRepository) GetByManagerID(ctx context.Context, managerID uuid.UUID) ([]*models.Restaurant, error) {
+	return r.query(ctx, `SELECT `+restaurantColumns+` FROM restaurants WHERE manager_id = $1 AND `+inOrganizations(ctx, "organization_id")+` ORDER BY name`, managerID)
+}
+
+// GetByBrandID returns the locations of a brand.
+func (r *RestaurantRepository) GetByBrandID(ctx context.Context, brandID uuid.UUID) ([]*models.Restaurant, error) {
+	return r.query(ctx, `SELECT `+restaurantColumns+` FROM restaurants WHERE brand_id = $1 AND `+inOrganizations(ctx, "organization_id")+` ORDER BY name`, brandID)
+}
+
+// List returns the restaurants matching the filter with their rating. Around
+// a point it only returns those within the radius, nearest first, with their
+// distance; otherwise it sorts by name. A zero limit returns every match.
+func (r *RestaurantRepository) List(ctx context.Context, filter models.RestaurantFilter) ([]*models.Restaurant, error) {
+	var args []interface{}
+	distance := `NULL::DOUBLE PRECISION`
+	conditions := []string{inOrganizations(ctx, "organization_id")}
+
+	near := filter.Latitude != nil && filter.Longitude != nil
+	if near {
+		args = append(args, *filter.Latitude, *filter.Longitude)
+		distance = fmt.Sprintf(`%f * 2 * ASIN(SQRT(
+			POWER(SIN(RADIANS(latitude - $1) / 2), 2) +
+			COS(RADIANS($1)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - $2) / 2), 2)
+		))`, earthRadiusKm)
+
+		// A degree of latitude is about 111 km; longitude degrees shrink
+		// towards the poles, so the box only narrows by latitude there
+		args = append(args, filter.RadiusKm/111)
+		conditions = append(conditions, `latitude BETWEEN $1 - $3 AND $1 + $3`)
+		if math.Abs(*filter.Latitude) < 80 {
+			args = append(args, filter.RadiusKm/(111*math.Cos(*filter.Latitude*math.Pi/180)))
+			conditions = append(conditions, fmt.Sprintf(`longitude BETWEEN $2 - $%d AND $2 + $%d`, len(args), len(args)))
+		}
+	}
+	if filter.OrganizationID != nil {
+		args = append(args, *filter.OrganizationID)
+		conditions = append(conditions, fmt.Sprintf(`organization_id = $%d`, len(args)))
+	}
+	if len(filter.CuisineTags) > 0 {
+		args = append(args, pq.Array(filter.CuisineTags))
+		conditions = append(conditions, fmt.Sprintf(`cuisine_tags && $%d`, len(args)))
+	}
+
 	query := `
-		SELECT id, name, description, manager_id, address, phone, created_at, updated_at
-		FROM restaurants
-		WHERE id = $1
-	`
+		SELECT * FROM (
+			SELECT ` + restaurantColumns + `,
+				   COALESCE(ratings.average, 0) AS rating_average,
+				   COALESCE(ratings.count, 0) AS rating_count,
+				   ` + distance + ` AS distance
+			FROM restaurants
+			LEFT JOIN (
+				SELECT restaurant_id, AVG(rating) AS average, COUNT(*) AS count
+				FROM reviews
+				WHERE NOT hidden
+				GROUP BY restaurant_id
+			) ratings ON ratings.restaurant_id = restaurants.id
+			WHERE ` + strings.Join(conditions, " AND ") + `
+		) matches
+		WHERE true`
 
-	restaurant := &models.Restaurant{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&restaurant.ID,
-		&restaurant.Name,
-		&restaurant.Description,
-		&restaurant.ManagerID,
-		&restaurant.Address,
-		&restaurant.Phone,
-		&restaurant.CreatedAt,
-		&restaurant.UpdatedAt,
-	)
+	if near {
+		args = append(args, filter.RadiusKm)
+		query += fmt.Sprintf(" AND distance <= $%d", len(args))
+	}
+	if filter.MinRating > 0 {
+		args = append(args, filter.MinRating)
+		query += fmt.Sprintf(" AND rating_count > 0 AND rating_average >= $%d", len(args))
+	}
 
-	if err == sql.ErrNoRows {
-		return nil, nil
+	if near {
+		query += " ORDER BY distance, name"
+	} else {
+		query += " ORDER BY name"
 	}
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return restaurant, nil
-}
+	var restaurants []*models.Restaurant
+	for rows.Next() {
+		var (
+			rating   models.RatingSummary
+			distance sql.NullFloat64
+		)
+		restaurant, err := scanRestaurant(rows, &rating.Average, &rating.Count, &distance)
+		if err != nil {
+			return nil, err
+		}
+		restaurant.Rating = &rating
+		if distance.Valid {
+			restaurant.DistanceKm = &distance.Float64
+		}
+		restaurants = append(restaurants, restaurant)
+	}
 
-func (r *RestaurantRepository) GetByManagerID(ctx context.Context, managerID uuid.UUID) ([]*models.Restaurant, error) {
-	query := `
-		SELECT id, name, description, manager_id, address, phone, created_at, updated_at
-		FROM restaurants
-		WHERE manager_id = $1
-	`
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return restaurants, nil
+}
 
-	rows, err := r.db.QueryContext(ctx, query, managerID)
+func (r *RestaurantRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Restaurant, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -86,26 +199,60 @@ func (r *RestaurantRepository) GetByManagerID(ctx context.Context, managerID uui
 
 	var restaurants []*models.Restaurant
 	for rows.Next() {
-		restaurant := &models.Restaurant{}
-		err := rows.Scan(
-			&restaurant.ID,
-			&restaurant.Name,
-			&restaurant.Description,
-			&restaurant.ManagerID,
-			&restaurant.Address,
-			&restaurant.Phone,
-			&restaurant.CreatedAt,
-			&restaurant.UpdatedAt,
-		)
+		restaurant, err := scanRestaurant(rows)
 		if err != nil {
 			return nil, err
 		}
 		restaurants = append(restaurants, restaurant)
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return restaurants, nil
 }
 
+// scanRestaurant scans a row of restaurantColumns followed by any extra
+// columns the query selects.
+func scanRestaurant(rows *sql.Rows, extra ...interface{}) (*models.Restaurant, error) {
+	restaurant := &models.Restaurant{}
+	var (
+		cuisineTags  []string
+		translations []byte
+	)
+	dest := []interface{}{
+		&restaurant.ID,
+		&restaurant.Name,
+		&restaurant.Description,
+		&restaurant.ManagerID,
+		&restaurant.OrganizationID,
+		&restaurant.BrandID,
+		&restaurant.Address,
+		&restaurant.Latitude,
+		&restaurant.Longitude,
+		pq.Array(&cuisineTags),
+		&restaurant.Phone,
+		&restaurant.Timezone,
+		&translations,
+		&restaurant.CreatedAt,
+		&restaurant.UpdatedAt,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	restaurant.CuisineTags = cuisineTags
+	if restaurant.CuisineTags == nil {
+		restaurant.CuisineTags = []string{}
+	}
+	if err := json.Unmarshal(translations, &restaurant.Translations); err != nil {
+		return nil, err
+	}
+
+	return restaurant, nil
+}
+
 func (r *RestaurantRepository) Update(ctx context.Context, restaurant *models.Restaurant) error {
 	query := `
 		UPDATE restaurants
@@ -113,19 +260,33 @@ func (r *RestaurantRepository) Update(ctx context.Context, restaurant *models.Re
 			description = $2,
 			manager_id = $3,
 			address = $4,
-			phone = $5,
-			updated_at = $6
-		WHERE id = $7
-	`
+			latitude = $5,
+			longitude = $6,
+			cuisine_tags = $7,
+			phone = $8,
+			timezone = $9,
+			translations = $10,
+			updated_at = $11
+		WHERE id = $12 AND ` + inOrganizations(ctx, "organization_id")
 
 	restaurant.UpdatedAt = time.Now()
 
+	translations, err := marshalTranslations(restaurant.Translations)
+	if err != nil {
+		return err
+	}
+
 	result, err := r.db.ExecContext(ctx, query,
 		restaurant.Name,
 		restaurant.Description,
 		restaurant.ManagerID,
 		restaurant.Address,
+		restaurant.Latitude,
+		restaurant.Longitude,
+		pq.Array(restaurant.CuisineTags),
 		restaurant.Phone,
+		restaurant.Timezone,
+		translations,
 		restaurant.UpdatedAt,
 		restaurant.ID,
 	)
@@ -144,8 +305,60 @@ func (r *RestaurantRepository) Update(ctx context.Context, restaurant *models.Re
 	return nil
 }
 
+// SetBrand makes a restaurant a location of the brand, or an independent
+// restaurant when brandID is nil. Leaving a brand unlinks its menu items
+// from the master menu.
+func (r *RestaurantRepository) SetBrand(ctx context.Context, id uuid.UUID, brandID *uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE restaurants SET brand_id = $1, updated_at = $2 WHERE id = $3 AND `+inOrganizations(ctx, "organization_id"), brandID, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if brandID == nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE menu_items SET brand_item_id = NULL WHERE restaurant_id = $1 AND `+inTenant(ctx, "restaurant_id"), id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetOrganization moves a restaurant to another organization.
+func (r *RestaurantRepository) SetOrganization(ctx context.Context, id, organizationID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE restaurants SET organization_id = $1, updated_at = $2
+		WHERE id = $3 AND `+inOrganizations(ctx, "organization_id"),
+		organizationID, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
 func (r *RestaurantRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM restaurants WHERE id = $1`
+	query := `DELETE FROM restaurants WHERE id = $1 AND ` + inOrganizations(ctx, "organization_id")
 
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
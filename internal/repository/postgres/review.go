@@ -0,0 +1,284 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+const reviewColumns = `
+	id, order_id, restaurant_id, user_id, rating, comment, reply,
+	replied_by, replied_at, hidden, created_at, updated_at`
+
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+func (r *ReviewRepository) Create(ctx context.Context, review *models.Review) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkTenantCustomer(ctx, tx, review.RestaurantID, review.UserID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO reviews (` + reviewColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	now := time.Now()
+	review.ID = uuid.New()
+	review.CreatedAt = now
+	review.UpdatedAt = now
+
+	_, err = tx.ExecContext(ctx, query,
+		review.ID,
+		review.OrderID,
+		review.RestaurantID,
+		review.UserID,
+		review.Rating,
+		review.Comment,
+		review.Reply,
+		review.RepliedBy,
+		review.RepliedAt,
+		review.Hidden,
+		review.CreatedAt,
+		review.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	itemQuery := `
+		INSERT INTO review_items (review_id, menu_item_id, rating, comment)
+		VALUES ($1, $2, $3, $4)
+	`
+	for i := range review.Items {
+		item := &review.Items[i]
+		item.ReviewID = review.ID
+
+		_, err = tx.ExecContext(ctx, itemQuery,
+			item.ReviewID,
+			item.MenuItemID,
+			item.Rating,
+			item.Comment,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *ReviewRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Review, error) {
+	return r.getOne(ctx, `SELECT `+reviewColumns+` FROM reviews WHERE id = $1 AND `+inTenant(ctx, "restaurant_id"), id)
+}
+
+func (r *ReviewRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.Review, error) {
+	return r.getOne(ctx, `SELECT `+reviewColumns+` FROM reviews WHERE order_id = $1 AND `+inTenant(ctx, "restaurant_id"), orderID)
+}
+
+func (r *ReviewRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, filter models.ReviewFilter) ([]*models.Review, error) {
+	query := `SELECT ` + reviewColumns + ` FROM reviews WHERE restaurant_id = $1 AND ` + inTenant(ctx, "restaurant_id")
+	args := []interface{}{restaurantID}
+
+	if !filter.IncludeHidden {
+		query += " AND NOT hidden"
+	}
+	if filter.MaxRating > 0 {
+		args = append(args, filter.MaxRating)
+		query += fmt.Sprintf(" AND rating <= $%d", len(args))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	reviews, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, review := range reviews {
+		if err := r.loadItems(ctx, review); err != nil {
+			return nil, err
+		}
+	}
+
+	return reviews, nil
+}
+
+func (r *ReviewRepository) Reply(ctx context.Context, review *models.Review) error {
+	query := `
+		UPDATE reviews
+		SET reply = $1, replied_by = $2, replied_at = $3, updated_at = $3
+		WHERE id = $4 AND ` + inTenant(ctx, "restaurant_id")
+
+	now := time.Now()
+	review.RepliedAt = &now
+	review.UpdatedAt = now
+
+	return r.exec(ctx, query, review.Reply, review.RepliedBy, now, review.ID)
+}
+
+func (r *ReviewRepository) SetHidden(ctx context.Context, id uuid.UUID, hidden bool) error {
+	query := `UPDATE reviews SET hidden = $1, updated_at = $2 WHERE id = $3 AND ` + inTenant(ctx, "restaurant_id")
+
+	return r.exec(ctx, query, hidden, time.Now(), id)
+}
+
+func (r *ReviewRepository) ItemRatings(ctx context.Context, restaurantID uuid.UUID) (map[uuid.UUID]models.RatingSummary, error) {
+	query := `
+		SELECT ri.menu_item_id, AVG(ri.rating), COUNT(*)
+		FROM review_items ri
+		JOIN reviews rv ON rv.id = ri.review_id
+		WHERE rv.restaurant_id = $1 AND NOT rv.hidden AND ` + inTenant(ctx, "rv.restaurant_id") + `
+		GROUP BY ri.menu_item_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := make(map[uuid.UUID]models.RatingSummary)
+	for rows.Next() {
+		var (
+			menuItemID uuid.UUID
+			summary    models.RatingSummary
+		)
+		if err := rows.Scan(&menuItemID, &summary.Average, &summary.Count); err != nil {
+			return nil, err
+		}
+		ratings[menuItemID] = summary
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+func (r *ReviewRepository) RestaurantRating(ctx context.Context, restaurantID uuid.UUID) (models.RatingSummary, error) {
+	query := `
+		SELECT COALESCE(AVG(rating), 0), COUNT(*)
+		FROM reviews
+		WHERE restaurant_id = $1 AND NOT hidden AND ` + inTenant(ctx, "restaurant_id")
+
+	var summary models.RatingSummary
+	err := r.db.QueryRowContext(ctx, query, restaurantID).Scan(&summary.Average, &summary.Count)
+
+	return summary, err
+}
+
+func (r *ReviewRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.Review, error) {
+	reviews, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return nil, nil
+	}
+
+	if err := r.loadItems(ctx, reviews[0]); err != nil {
+		return nil, err
+	}
+
+	return reviews[0], nil
+}
+
+func (r *ReviewRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Review, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*models.Review
+	for rows.Next() {
+		review := &models.Review{}
+		err := rows.Scan(
+			&review.ID,
+			&review.OrderID,
+			&review.RestaurantID,
+			&review.UserID,
+			&review.Rating,
+			&review.Comment,
+			&review.Reply,
+			&review.RepliedBy,
+			&review.RepliedAt,
+			&review.Hidden,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (r *ReviewRepository) loadItems(ctx context.Context, review *models.Review) error {
+	query := `
+		SELECT review_id, menu_item_id, rating, comment
+		FROM review_items
+		WHERE review_id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, review.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.ReviewItem
+		if err := rows.Scan(&item.ReviewID, &item.MenuItemID, &item.Rating, &item.Comment); err != nil {
+			return err
+		}
+		review.Items = append(review.Items, item)
+	}
+
+	return rows.Err()
+}
+
+func (r *ReviewRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+const settingsColumns = `restaurant_id, version, currency, timezone, tax_mode, tax_rate,
+	service_charge_rate, delivery_fee, auto_accept_orders, receipt_footer, updated_by, updated_at`
+
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+func NewSettingsRepository(db *sql.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+func (r *SettingsRepository) Get(ctx context.Context, restaurantID uuid.UUID) (*models.RestaurantSettings, error) {
+	settings, err := r.query(ctx, `
+		SELECT `+settingsColumns+`
+		FROM restaurant_settings
+		WHERE restaurant_id = $1 AND `+inTenant(ctx, "restaurant_id")+`
+		ORDER BY version DESC
+		LIMIT 1
+	`, restaurantID)
+	if err != nil || len(settings) == 0 {
+		return nil, err
+	}
+	return settings[0], nil
+}
+
+func (r *SettingsRepository) ListVersions(ctx context.Context, restaurantID uuid.UUID) ([]*models.RestaurantSettings, error) {
+	return r.query(ctx, `
+		SELECT `+settingsColumns+`
+		FROM restaurant_settings
+		WHERE restaurant_id = $1 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id"))+`
+		ORDER BY version DESC
+	`, restaurantID)
+}
+
+func (r *SettingsRepository) Save(ctx context.Context, settings *models.RestaurantSettings) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the restaurant serializes saves of its settings
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT true FROM restaurants WHERE id = $1 AND `+inOrganizations(ctx, "organization_id")+` FOR UPDATE`, settings.RestaurantID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrOutsideTenant
+	}
+	if err != nil {
+		return err
+	}
+
+	var current int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(version), 0) FROM restaurant_settings WHERE restaurant_id = $1
+	`, settings.RestaurantID).Scan(&current)
+	if err != nil {
+		return err
+	}
+	if current != settings.Version {
+		return repository.ErrStaleVersion
+	}
+
+	now := time.Now()
+	query := `
+		INSERT INTO restaurant_settings (
+			restaurant_id, version, currency, timezone, tax_mode, tax_rate,
+			service_charge_rate, delivery_fee, auto_accept_orders, receipt_footer, updated_by, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		settings.RestaurantID,
+		current+1,
+		settings.Currency,
+		settings.Timezone,
+		settings.TaxMode,
+		settings.TaxRate,
+		settings.ServiceChargeRate,
+		settings.DeliveryFee,
+		settings.AutoAcceptOrders,
+		settings.ReceiptFooter,
+		settings.UpdatedBy,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE restaurants SET timezone = $1, updated_at = $2 WHERE id = $3`,
+		settings.Timezone, now, settings.RestaurantID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	settings.Version = current + 1
+	settings.UpdatedAt = &now
+	return nil
+}
+
+func (r *SettingsRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.RestaurantSettings, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := []*models.RestaurantSettings{}
+	for rows.Next() {
+		s := &models.RestaurantSettings{}
+		if err := rows.Scan(
+			&s.RestaurantID,
+			&s.Version,
+			&s.Currency,
+			&s.Timezone,
+			&s.TaxMode,
+			&s.TaxRate,
+			&s.ServiceChargeRate,
+			&s.DeliveryFee,
+			&s.AutoAcceptOrders,
+			&s.ReceiptFooter,
+			&s.UpdatedBy,
+			&s.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		settings = append(settings, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
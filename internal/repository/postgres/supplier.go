@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+const supplierColumns = `
+	id, restaurant_id, name, contact_name, email, phone, notes, created_at, updated_at`
+
+const supplierItemQuery = `
+	SELECT si.id, si.supplier_id, si.ingredient_id, si.sku, si.unit_cost,
+		   i.name, i.unit, i.stock
+	FROM supplier_items si
+	JOIN ingredients i ON i.id = si.ingredient_id`
+
+type SupplierRepository struct {
+	db *sql.DB
+}
+
+func NewSupplierRepository(db *sql.DB) *SupplierRepository {
+	return &SupplierRepository{db: db}
+}
+
+func (r *SupplierRepository) Create(ctx context.Context, supplier *models.Supplier) error {
+	query := `
+		INSERT INTO suppliers (` + supplierColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	if err := checkTenant(ctx, r.db, supplier.RestaurantID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	supplier.ID = uuid.New()
+	supplier.CreatedAt = now
+	supplier.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, query,
+		supplier.ID,
+		supplier.RestaurantID,
+		supplier.Name,
+		supplier.ContactName,
+		supplier.Email,
+		supplier.Phone,
+		supplier.Notes,
+		supplier.CreatedAt,
+		supplier.UpdatedAt,
+	)
+	return err
+}
+
+func (r *SupplierRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Supplier, error) {
+	suppliers, err := r.query(ctx, `SELECT `+supplierColumns+` FROM suppliers WHERE id = $1 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id")), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(suppliers) == 0 {
+		return nil, nil
+	}
+	return suppliers[0], nil
+}
+
+func (r *SupplierRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.Supplier, error) {
+	return r.query(ctx, `SELECT `+supplierColumns+` FROM suppliers WHERE restaurant_id = $1 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id"))+` ORDER BY name`, restaurantID)
+}
+
+func (r *SupplierRepository) Update(ctx context.Context, supplier *models.Supplier) error {
+	query := `
+		UPDATE suppliers
+		SET name = $1, contact_name = $2, email = $3, phone = $4, notes = $5, updated_at = $6
+		WHERE id = $7 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id")) + `
+		RETURNING created_at
+	`
+
+	supplier.UpdatedAt = time.Now()
+
+	return r.db.QueryRowContext(ctx, query,
+		supplier.Name,
+		supplier.ContactName,
+		supplier.Email,
+		supplier.Phone,
+		supplier.Notes,
+		supplier.UpdatedAt,
+		supplier.ID,
+	).Scan(&supplier.CreatedAt)
+}
+
+func (r *SupplierRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.exec(ctx, `DELETE FROM suppliers WHERE id = $1 AND `+staffOnly(ctx, inTenant(ctx, "restaurant_id")), id)
+}
+
+func (r *SupplierRepository) SaveItem(ctx context.Context, item *models.SupplierItem) error {
+	query := `
+		INSERT INTO supplier_items (id, supplier_id, ingredient_id, sku, unit_cost)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (supplier_id, ingredient_id)
+		DO UPDATE SET sku = EXCLUDED.sku, unit_cost = EXCLUDED.unit_cost
+		RETURNING id
+	`
+
+	if err := checkTenantParent(ctx, r.db, "suppliers", item.SupplierID); err != nil {
+		return err
+	}
+
+	err := r.db.QueryRowContext(ctx, query,
+		uuid.New(),
+		item.SupplierID,
+		item.IngredientID,
+		item.SKU,
+		item.UnitCost,
+	).Scan(&item.ID)
+	if err != nil {
+		return err
+	}
+
+	saved, err := r.GetItem(ctx, item.ID)
+	if err != nil {
+		return err
+	}
+	*item = *saved
+
+	return nil
+}
+
+func (r *SupplierRepository) GetItem(ctx context.Context, id uuid.UUID) (*models.SupplierItem, error) {
+	items, err := r.queryItems(ctx, supplierItemQuery+` WHERE si.id = $1 AND `+staffOnly(ctx, inTenantParent(ctx, "si.supplier_id", "suppliers")), id)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return items[0], nil
+}
+
+func (r *SupplierRepository) ListItems(ctx context.Context, supplierID uuid.UUID) ([]*models.SupplierItem, error) {
+	return r.queryItems(ctx, supplierItemQuery+` WHERE si.supplier_id = $1 AND `+staffOnly(ctx, inTenantParent(ctx, "si.supplier_id", "suppliers"))+` ORDER BY i.name`, supplierID)
+}
+
+func (r *SupplierRepository) ListItemsByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.SupplierItem, error) {
+	return r.queryItems(ctx, supplierItemQuery+` WHERE i.restaurant_id = $1 AND `+staffOnly(ctx, inTenant(ctx, "i.restaurant_id"))+` ORDER BY i.name`, restaurantID)
+}
+
+func (r *SupplierRepository) DeleteItem(ctx context.Context, id uuid.UUID) error {
+	return r.exec(ctx, `DELETE FROM supplier_items WHERE id = $1 AND `+staffOnly(ctx, inTenantParent(ctx, "supplier_id", "suppliers")), id)
+}
+
+func (r *SupplierRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Supplier, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suppliers []*models.Supplier
+	for rows.Next() {
+		supplier := &models.Supplier{}
+		err := rows.Scan(
+			&supplier.ID,
+			&supplier.RestaurantID,
+			&supplier.Name,
+			&supplier.ContactName,
+			&supplier.Email,
+			&supplier.Phone,
+			&supplier.Notes,
+			&supplier.CreatedAt,
+			&supplier.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suppliers, nil
+}
+
+func (r *SupplierRepository) queryItems(ctx context.Context, query string, args ...interface{}) ([]*models.SupplierItem, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.SupplierItem
+	for rows.Next() {
+		item := &models.SupplierItem{}
+		err := rows.Scan(
+			&item.ID,
+			&item.SupplierID,
+			&item.IngredientID,
+			&item.SKU,
+			&item.UnitCost,
+			&item.Name,
+			&item.Unit,
+			&item.OnHand,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *SupplierRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -25,6 +25,10 @@ func (r *TableRepository) Create(ctx context.Context, table *models.Table) error
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
+	if err := checkTenant(ctx, r.db, table.RestaurantID); err != nil {
+		return err
+	}
+
 	now := time.Now()
 	table.ID = uuid.New()
 	table.CreatedAt = now
@@ -50,8 +54,7 @@ func (r *TableRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Ta
 		SELECT id, restaurant_id, number, capacity,
 			   status, qr_code, created_at, updated_at
 		FROM tables
-		WHERE id = $1
-	`
+		WHERE id = $1 AND ` + inTenant(ctx, "restaurant_id")
 
 	table := &models.Table{}
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
@@ -80,7 +83,7 @@ func (r *TableRepository) GetByRestaurantID(ctx context.Context, restaurantID uu
 		SELECT id, restaurant_id, number, capacity,
 			   status, qr_code, created_at, updated_at
 		FROM tables
-		WHERE restaurant_id = $1
+		WHERE restaurant_id = $1 AND ` + inTenant(ctx, "restaurant_id") + `
 		ORDER BY number
 	`
 
@@ -121,8 +124,7 @@ func (r *TableRepository) GetByQRCode(ctx context.Context, qrCode string) (*mode
 		SELECT id, restaurant_id, number, capacity,
 			   status, qr_code, created_at, updated_at
 		FROM tables
-		WHERE qr_code = $1
-	`
+		WHERE qr_code = $1 AND ` + inTenant(ctx, "restaurant_id")
 
 	table := &models.Table{}
 	err := r.db.QueryRowContext(ctx, query, qrCode).Scan(
@@ -154,8 +156,7 @@ func (r *TableRepository) Update(ctx context.Context, table *models.Table) error
 			status = $3,
 			qr_code = $4,
 			updated_at = $5
-		WHERE id = $6 AND restaurant_id = $7
-	`
+		WHERE id = $6 AND restaurant_id = $7 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id"))
 
 	table.UpdatedAt = time.Now()
 
@@ -188,8 +189,7 @@ func (r *TableRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status
 		UPDATE tables
 		SET status = $1,
 			updated_at = $2
-		WHERE id = $3
-	`
+		WHERE id = $3 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id"))
 
 	result, err := r.db.ExecContext(ctx, query,
 		status,
@@ -212,7 +212,7 @@ func (r *TableRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status
 }
 
 func (r *TableRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM tables WHERE id = $1`
+	query := `DELETE FROM tables WHERE id = $1 AND ` + staffOnly(ctx, inTenant(ctx, "restaurant_id"))
 
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
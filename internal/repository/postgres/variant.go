@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+const variantColumns = `id, menu_item_id, name, sku, price, available, position, created_at, updated_at`
+
+type VariantRepository struct {
+	db *sql.DB
+}
+
+func NewVariantRepository(db *sql.DB) *VariantRepository {
+	return &VariantRepository{db: db}
+}
+
+func (r *VariantRepository) Create(ctx context.Context, variant *models.MenuItemVariant) error {
+	query := `
+		INSERT INTO menu_item_variants (` + variantColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	if err := checkTenantParent(ctx, r.db, "menu_items", variant.MenuItemID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	variant.ID = uuid.New()
+	variant.CreatedAt = now
+	variant.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, query,
+		variant.ID,
+		variant.MenuItemID,
+		variant.Name,
+		variant.SKU,
+		variant.Price,
+		variant.Available,
+		variant.Position,
+		variant.CreatedAt,
+		variant.UpdatedAt,
+	)
+	return err
+}
+
+func (r *VariantRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MenuItemVariant, error) {
+	variants, err := r.query(ctx, `SELECT `+variantColumns+` FROM menu_item_variants WHERE id = $1 AND `+inTenantParent(ctx, "menu_item_id", "menu_items"), id)
+	if err != nil || len(variants) == 0 {
+		return nil, err
+	}
+	return variants[0], nil
+}
+
+func (r *VariantRepository) ListByMenuItem(ctx context.Context, menuItemID uuid.UUID) ([]*models.MenuItemVariant, error) {
+	query := `SELECT ` + variantColumns + ` FROM menu_item_variants WHERE menu_item_id = $1 AND ` + inTenantParent(ctx, "menu_item_id", "menu_items") + ` ORDER BY position, name`
+	return r.query(ctx, query, menuItemID)
+}
+
+func (r *VariantRepository) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.MenuItemVariant, error) {
+	query := `
+		SELECT v.id, v.menu_item_id, v.name, v.sku, v.price, v.available, v.position, v.created_at, v.updated_at
+		FROM menu_item_variants v
+		JOIN menu_items m ON m.id = v.menu_item_id
+		WHERE m.restaurant_id = $1 AND ` + inTenant(ctx, "m.restaurant_id") + `
+		ORDER BY v.menu_item_id, v.position, v.name
+	`
+	return r.query(ctx, query, restaurantID)
+}
+
+func (r *VariantRepository) Update(ctx context.Context, variant *models.MenuItemVariant) error {
+	query := `
+		UPDATE menu_item_variants
+		SET name = $1,
+			sku = $2,
+			price = $3,
+			available = $4,
+			position = $5,
+			updated_at = $6
+		WHERE id = $7 AND menu_item_id = $8 AND ` + inTenantParent(ctx, "menu_item_id", "menu_items") + `
+		RETURNING created_at
+	`
+
+	variant.UpdatedAt = time.Now()
+
+	return r.db.QueryRowContext(ctx, query,
+		variant.Name,
+		variant.SKU,
+		variant.Price,
+		variant.Available,
+		variant.Position,
+		variant.UpdatedAt,
+		variant.ID,
+		variant.MenuItemID,
+	).Scan(&variant.CreatedAt)
+}
+
+func (r *VariantRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM menu_item_variants WHERE id = $1 AND `+inTenantParent(ctx, "menu_item_id", "menu_items"), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *VariantRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.MenuItemVariant, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []*models.MenuItemVariant
+	for rows.Next() {
+		variant := &models.MenuItemVariant{}
+		err := rows.Scan(
+			&variant.ID,
+			&variant.MenuItemID,
+			&variant.Name,
+			&variant.SKU,
+			&variant.Price,
+			&variant.Available,
+			&variant.Position,
+			&variant.CreatedAt,
+			&variant.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
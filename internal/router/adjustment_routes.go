@@ -0,0 +1,18 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerAdjustmentRoutes(mux *http.ServeMux, h *handler.AdjustmentHandler) {
+	mux.HandleFunc("POST "+constants.OrdersRoute+"/{id}/voids", h.Void)
+	mux.HandleFunc("POST "+constants.OrdersRoute+"/{id}/refunds", h.Refund)
+	mux.HandleFunc("GET "+constants.OrdersRoute+"/{id}/adjustments", h.ListByOrder)
+	mux.HandleFunc("POST "+constants.AdjustmentsRoute+"/{id}/approve", h.Approve)
+	mux.HandleFunc("POST "+constants.AdjustmentsRoute+"/{id}/retry", h.Retry)
+	mux.HandleFunc("POST "+constants.AdjustmentsRoute+"/{id}/reject", h.Reject)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/reports/adjustments", h.Report)
+}
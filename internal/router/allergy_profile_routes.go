@@ -0,0 +1,13 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerAllergyProfileRoutes(mux *http.ServeMux, h *handler.AllergyProfileHandler) {
+	mux.HandleFunc("GET "+constants.UsersRoute+"/me/allergy-profile", h.Get)
+	mux.HandleFunc("PUT "+constants.UsersRoute+"/me/allergy-profile", h.Save)
+}
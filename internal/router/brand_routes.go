@@ -0,0 +1,28 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerBrandRoutes(mux *http.ServeMux, h *handler.BrandHandler) {
+	mux.HandleFunc("POST "+constants.BrandsRoute, h.Create)
+	mux.HandleFunc("GET "+constants.BrandsRoute, h.List)
+	mux.HandleFunc("GET "+constants.BrandsRoute+"/{id}", h.Get)
+	mux.HandleFunc("PUT "+constants.BrandsRoute+"/{id}", h.Update)
+	mux.HandleFunc("GET "+constants.BrandsRoute+"/{id}/restaurants", h.ListLocations)
+	mux.HandleFunc("PUT "+constants.BrandsRoute+"/{id}/restaurants/{restaurant_id}", h.AddLocation)
+	mux.HandleFunc("DELETE "+constants.BrandsRoute+"/{id}/restaurants/{restaurant_id}", h.RemoveLocation)
+	mux.HandleFunc("GET "+constants.BrandsRoute+"/{id}/menu-items", h.ListItems)
+	mux.HandleFunc("POST "+constants.BrandsRoute+"/{id}/menu-items", h.CreateItem)
+	mux.HandleFunc("PUT "+constants.BrandsRoute+"/{id}/menu-items/{item_id}", h.UpdateItem)
+	mux.HandleFunc("DELETE "+constants.BrandsRoute+"/{id}/menu-items/{item_id}", h.DeleteItem)
+	mux.HandleFunc("GET "+constants.BrandsRoute+"/{id}/publish", h.Preview)
+	mux.HandleFunc("POST "+constants.BrandsRoute+"/{id}/publish", h.Publish)
+
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/brand-overrides", h.ListOverrides)
+	mux.HandleFunc("PUT "+constants.RestaurantsRoute+"/{id}/brand-overrides/{brand_item_id}", h.SetOverride)
+	mux.HandleFunc("DELETE "+constants.RestaurantsRoute+"/{id}/brand-overrides/{brand_item_id}", h.DeleteOverride)
+}
@@ -0,0 +1,15 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerCategoryRoutes(mux *http.ServeMux, h *handler.CategoryHandler) {
+	mux.HandleFunc("POST "+constants.RestaurantsRoute+"/{id}/categories", h.Create)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/categories", h.List)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/categories/{category_id}", h.Get)
+	mux.HandleFunc("PUT "+constants.RestaurantsRoute+"/{id}/categories/{category_id}", h.Update)
+}
@@ -0,0 +1,15 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerCheckRoutes(mux *http.ServeMux, h *handler.CheckHandler) {
+	mux.HandleFunc("POST "+constants.OrdersRoute+"/{id}/split", h.SplitOrder)
+	mux.HandleFunc("GET "+constants.OrdersRoute+"/{id}/checks", h.ListByOrder)
+	mux.HandleFunc("POST "+constants.RestaurantsRoute+"/{id}/tables/{table_id}/split", h.SplitTable)
+	mux.HandleFunc("GET "+constants.ChecksRoute+"/{id}", h.Get)
+}
@@ -0,0 +1,14 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerFavoriteRoutes(mux *http.ServeMux, h *handler.FavoriteHandler) {
+	mux.HandleFunc("GET "+constants.UsersRoute+"/me/favorites", h.List)
+	mux.HandleFunc("POST "+constants.UsersRoute+"/me/favorites", h.Add)
+	mux.HandleFunc("DELETE "+constants.UsersRoute+"/me/favorites/{menu_item_id}", h.Remove)
+}
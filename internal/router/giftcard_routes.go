@@ -0,0 +1,15 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerGiftCardRoutes(mux *http.ServeMux, h *handler.GiftCardHandler) {
+	mux.HandleFunc("POST "+constants.GiftCardsRoute, h.Issue)
+	mux.HandleFunc("GET "+constants.GiftCardsRoute+"/{code}", h.Statement)
+	mux.HandleFunc("GET "+constants.GiftCardsRoute+"/{code}/balance", h.Balance)
+	mux.HandleFunc("POST "+constants.GiftCardsRoute+"/{code}/load", h.Load)
+}
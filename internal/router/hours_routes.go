@@ -0,0 +1,13 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerHoursRoutes(mux *http.ServeMux, h *handler.HoursHandler) {
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/hours", h.Get)
+	mux.HandleFunc("PUT "+constants.RestaurantsRoute+"/{id}/hours", h.Update)
+}
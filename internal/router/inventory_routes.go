@@ -0,0 +1,22 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerInventoryRoutes(mux *http.ServeMux, h *handler.InventoryHandler) {
+	base := constants.RestaurantsRoute + "/{id}/ingredients"
+	mux.HandleFunc("POST "+base, h.CreateIngredient)
+	mux.HandleFunc("GET "+base, h.ListIngredients)
+	mux.HandleFunc("GET "+base+"/{ingredient_id}", h.GetIngredient)
+	mux.HandleFunc("PUT "+base+"/{ingredient_id}", h.UpdateIngredient)
+	mux.HandleFunc("DELETE "+base+"/{ingredient_id}", h.DeleteIngredient)
+	mux.HandleFunc("GET "+base+"/{ingredient_id}/movements", h.ListMovements)
+	mux.HandleFunc("POST "+base+"/{ingredient_id}/adjustments", h.Adjust)
+
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/menu-items/{item_id}/recipe", h.GetRecipe)
+	mux.HandleFunc("PUT "+constants.RestaurantsRoute+"/{id}/menu-items/{item_id}/recipe", h.SetRecipe)
+}
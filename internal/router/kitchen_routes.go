@@ -0,0 +1,12 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerKitchenRoutes(mux *http.ServeMux, h *handler.KitchenHandler) {
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/kitchen/tickets", h.Tickets)
+}
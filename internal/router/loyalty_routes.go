@@ -0,0 +1,17 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerLoyaltyRoutes(mux *http.ServeMux, h *handler.LoyaltyHandler) {
+	mux.HandleFunc("GET "+constants.UsersRoute+"/me/loyalty", h.Account)
+	mux.HandleFunc("GET "+constants.UsersRoute+"/me/loyalty/transactions", h.History)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/loyalty-program", h.GetProgram)
+	mux.HandleFunc("PUT "+constants.RestaurantsRoute+"/{id}/loyalty-program", h.SaveProgram)
+	mux.HandleFunc("GET "+constants.LoyaltyRoute+"/program", h.GetChainProgram)
+	mux.HandleFunc("PUT "+constants.LoyaltyRoute+"/program", h.SaveChainProgram)
+}
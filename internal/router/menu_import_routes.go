@@ -0,0 +1,13 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerMenuImportRoutes(mux *http.ServeMux, h *handler.MenuImportHandler) {
+	mux.HandleFunc("POST "+constants.RestaurantsRoute+"/{id}/menu-items/import", h.Import)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/menu-items/export", h.Export)
+}
@@ -14,4 +14,14 @@ func registerMenuRoutes(mux *http.ServeMux, h *handler.MenuHandler) {
 	mux.HandleFunc("GET "+base, h.List)
 	mux.HandleFunc("PUT "+base+"/{item_id}", h.Update)
 	mux.HandleFunc("DELETE "+base+"/{item_id}", h.Delete)
+	mux.HandleFunc("PUT "+base+"/{item_id}/availability", h.SetAvailability)
+	mux.HandleFunc("GET "+base+"/{item_id}/customizations", h.ListCustomizations)
+	mux.HandleFunc("POST "+base+"/{item_id}/customizations", h.CreateCustomization)
+	mux.HandleFunc("DELETE "+base+"/{item_id}/customizations/{customization_id}", h.DeleteCustomization)
+	mux.HandleFunc("GET "+base+"/{item_id}/variants", h.ListVariants)
+	mux.HandleFunc("POST "+base+"/{item_id}/variants", h.CreateVariant)
+	mux.HandleFunc("PUT "+base+"/{item_id}/variants/{variant_id}", h.UpdateVariant)
+	mux.HandleFunc("DELETE "+base+"/{item_id}/variants/{variant_id}", h.DeleteVariant)
+	mux.HandleFunc("GET "+base+"/{item_id}/bundle", h.GetBundle)
+	mux.HandleFunc("PUT "+base+"/{item_id}/bundle", h.SetBundle)
 }
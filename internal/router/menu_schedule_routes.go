@@ -0,0 +1,16 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerMenuScheduleRoutes(mux *http.ServeMux, h *handler.MenuScheduleHandler) {
+	mux.HandleFunc("POST "+constants.RestaurantsRoute+"/{id}/menus", h.Create)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/menus", h.List)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/menus/{menu_id}", h.Get)
+	mux.HandleFunc("PUT "+constants.RestaurantsRoute+"/{id}/menus/{menu_id}", h.Update)
+	mux.HandleFunc("DELETE "+constants.RestaurantsRoute+"/{id}/menus/{menu_id}", h.Delete)
+}
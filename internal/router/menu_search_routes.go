@@ -0,0 +1,12 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerMenuSearchRoutes(mux *http.ServeMux, h *handler.MenuSearchHandler) {
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/menu-items/search", h.Search)
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerMenuVersionRoutes(mux *http.ServeMux, h *handler.MenuVersionHandler) {
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/menu-versions", h.List)
+	mux.HandleFunc("POST "+constants.RestaurantsRoute+"/{id}/menu-versions", h.Create)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/menu-versions/{version_id}", h.Get)
+	mux.HandleFunc("PUT "+constants.RestaurantsRoute+"/{id}/menu-versions/{version_id}", h.Update)
+	mux.HandleFunc("DELETE "+constants.RestaurantsRoute+"/{id}/menu-versions/{version_id}", h.Delete)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/menu-versions/{version_id}/preview", h.Preview)
+	mux.HandleFunc("POST "+constants.RestaurantsRoute+"/{id}/menu-versions/{version_id}/publish", h.Publish)
+	mux.HandleFunc("POST "+constants.RestaurantsRoute+"/{id}/menu-versions/{version_id}/rollback", h.Rollback)
+}
@@ -9,8 +9,11 @@ import (
 
 func registerOrderRoutes(mux *http.ServeMux, h *handler.OrderHandler) {
 	mux.HandleFunc("POST "+constants.OrdersRoute, h.Create)
+	mux.HandleFunc("POST "+constants.OrdersRoute+"/quote", h.Quote)
 	mux.HandleFunc("GET "+constants.OrdersRoute+"/{id}", h.Get)
 	mux.HandleFunc("PUT "+constants.OrdersRoute+"/{id}", h.Update)
 	mux.HandleFunc("PUT "+constants.OrdersRoute+"/{id}/status", h.UpdateStatus)
 	mux.HandleFunc("DELETE "+constants.OrdersRoute+"/{id}", h.Delete)
+	mux.HandleFunc("POST "+constants.OrdersRoute+"/{id}/reorder", h.Reorder)
+	mux.HandleFunc("GET "+constants.UsersRoute+"/me/orders", h.ListMine)
 }
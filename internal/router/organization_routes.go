@@ -0,0 +1,21 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerOrganizationRoutes(mux *http.ServeMux, h *handler.OrganizationHandler) {
+	mux.HandleFunc("POST "+constants.OrganizationsRoute, h.Create)
+	mux.HandleFunc("GET "+constants.OrganizationsRoute, h.List)
+	mux.HandleFunc("GET "+constants.OrganizationsRoute+"/{id}", h.Get)
+	mux.HandleFunc("PUT "+constants.OrganizationsRoute+"/{id}", h.Update)
+	mux.HandleFunc("DELETE "+constants.OrganizationsRoute+"/{id}", h.Delete)
+	mux.HandleFunc("GET "+constants.OrganizationsRoute+"/{id}/members", h.ListMembers)
+	mux.HandleFunc("PUT "+constants.OrganizationsRoute+"/{id}/members/{user_id}", h.SaveMember)
+	mux.HandleFunc("DELETE "+constants.OrganizationsRoute+"/{id}/members/{user_id}", h.RemoveMember)
+	mux.HandleFunc("GET "+constants.OrganizationsRoute+"/{id}/restaurants", h.ListRestaurants)
+	mux.HandleFunc("PUT "+constants.OrganizationsRoute+"/{id}/restaurants/{restaurant_id}", h.AssignRestaurant)
+}
@@ -0,0 +1,17 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerPaymentRoutes(mux *http.ServeMux, h *handler.PaymentHandler) {
+	mux.HandleFunc("POST "+constants.OrdersRoute+"/{id}/payments", h.Create)
+	mux.HandleFunc("GET "+constants.OrdersRoute+"/{id}/payments", h.ListByOrder)
+	mux.HandleFunc("GET "+constants.PaymentsRoute+"/{id}", h.Get)
+	mux.HandleFunc("POST "+constants.PaymentsRoute+"/{id}/capture", h.Capture)
+	mux.HandleFunc("POST "+constants.PaymentsRoute+"/{id}/void", h.Void)
+	mux.HandleFunc("POST "+constants.WebhooksRoute+"/payments/{provider}", h.Webhook)
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerPricingRoutes(mux *http.ServeMux, h *handler.PricingRuleHandler) {
+	mux.HandleFunc("POST "+constants.RestaurantsRoute+"/{id}/pricing-rules", h.Create)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/pricing-rules", h.List)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/pricing-rules/{rule_id}", h.Get)
+	mux.HandleFunc("PUT "+constants.RestaurantsRoute+"/{id}/pricing-rules/{rule_id}", h.Update)
+	mux.HandleFunc("DELETE "+constants.RestaurantsRoute+"/{id}/pricing-rules/{rule_id}", h.Delete)
+}
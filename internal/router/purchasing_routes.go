@@ -0,0 +1,31 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerPurchasingRoutes(mux *http.ServeMux, h *handler.PurchasingHandler) {
+	suppliers := constants.RestaurantsRoute + "/{id}/suppliers"
+	mux.HandleFunc("POST "+suppliers, h.CreateSupplier)
+	mux.HandleFunc("GET "+suppliers, h.ListSuppliers)
+	mux.HandleFunc("GET "+suppliers+"/{supplier_id}", h.GetSupplier)
+	mux.HandleFunc("PUT "+suppliers+"/{supplier_id}", h.UpdateSupplier)
+	mux.HandleFunc("DELETE "+suppliers+"/{supplier_id}", h.DeleteSupplier)
+	mux.HandleFunc("GET "+suppliers+"/{supplier_id}/items", h.ListCatalog)
+	mux.HandleFunc("POST "+suppliers+"/{supplier_id}/items", h.SaveCatalogItem)
+	mux.HandleFunc("DELETE "+suppliers+"/{supplier_id}/items/{item_id}", h.DeleteCatalogItem)
+
+	orders := constants.RestaurantsRoute + "/{id}/purchase-orders"
+	mux.HandleFunc("POST "+orders, h.CreatePurchaseOrder)
+	mux.HandleFunc("GET "+orders, h.ListPurchaseOrders)
+	mux.HandleFunc("GET "+orders+"/suggestions", h.SuggestPurchaseOrders)
+	mux.HandleFunc("POST "+orders+"/suggestions", h.CreateSuggestedPurchaseOrders)
+	mux.HandleFunc("GET "+orders+"/{po_id}", h.GetPurchaseOrder)
+	mux.HandleFunc("PUT "+orders+"/{po_id}", h.UpdatePurchaseOrder)
+	mux.HandleFunc("DELETE "+orders+"/{po_id}", h.DeletePurchaseOrder)
+	mux.HandleFunc("POST "+orders+"/{po_id}/send", h.SendPurchaseOrder)
+	mux.HandleFunc("POST "+orders+"/{po_id}/receive", h.ReceivePurchaseOrder)
+}
@@ -0,0 +1,12 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerReportRoutes(mux *http.ServeMux, h *handler.ReportHandler) {
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/reports/sales", h.Sales)
+}
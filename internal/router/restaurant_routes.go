@@ -9,6 +9,7 @@ import (
 
 func registerRestaurantRoutes(mux *http.ServeMux, h *handler.RestaurantHandler) {
 	mux.HandleFunc("POST "+constants.RestaurantsRoute, h.Create)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute, h.List)
 	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}", h.Get)
 	mux.HandleFunc("PUT "+constants.RestaurantsRoute+"/{id}", h.Update)
 	mux.HandleFunc("DELETE "+constants.RestaurantsRoute+"/{id}", h.Delete)
@@ -0,0 +1,17 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerReviewRoutes(mux *http.ServeMux, h *handler.ReviewHandler) {
+	mux.HandleFunc("POST "+constants.OrdersRoute+"/{id}/review", h.Create)
+	mux.HandleFunc("GET "+constants.OrdersRoute+"/{id}/review", h.GetByOrder)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/reviews", h.ListByRestaurant)
+	mux.HandleFunc("POST "+constants.ReviewsRoute+"/{id}/reply", h.Reply)
+	mux.HandleFunc("POST "+constants.ReviewsRoute+"/{id}/hide", h.Hide)
+	mux.HandleFunc("POST "+constants.ReviewsRoute+"/{id}/unhide", h.Unhide)
+}
@@ -9,11 +9,36 @@ import (
 )
 
 func NewRouter(
+	jwtSecret string,
+	memberships middleware.MembershipLookup,
 	userHandler *handler.UserHandler,
 	restaurantHandler *handler.RestaurantHandler,
 	menuHandler *handler.MenuHandler,
 	orderHandler *handler.OrderHandler,
 	tableHandler *handler.TableHandler,
+	checkHandler *handler.CheckHandler,
+	paymentHandler *handler.PaymentHandler,
+	adjustmentHandler *handler.AdjustmentHandler,
+	pricingRuleHandler *handler.PricingRuleHandler,
+	giftCardHandler *handler.GiftCardHandler,
+	loyaltyHandler *handler.LoyaltyHandler,
+	favoriteHandler *handler.FavoriteHandler,
+	reviewHandler *handler.ReviewHandler,
+	inventoryHandler *handler.InventoryHandler,
+	purchasingHandler *handler.PurchasingHandler,
+	menuScheduleHandler *handler.MenuScheduleHandler,
+	allergyProfileHandler *handler.AllergyProfileHandler,
+	kitchenHandler *handler.KitchenHandler,
+	reportHandler *handler.ReportHandler,
+	categoryHandler *handler.CategoryHandler,
+	translationHandler *handler.TranslationHandler,
+	menuImportHandler *handler.MenuImportHandler,
+	brandHandler *handler.BrandHandler,
+	menuVersionHandler *handler.MenuVersionHandler,
+	menuSearchHandler *handler.MenuSearchHandler,
+	hoursHandler *handler.HoursHandler,
+	settingsHandler *handler.SettingsHandler,
+	organizationHandler *handler.OrganizationHandler,
 ) http.Handler {
 	mux := http.NewServeMux()
 
@@ -22,6 +47,8 @@ func NewRouter(
 		middleware.Logger,
 		middleware.Recoverer,
 		middleware.CORS,
+		middleware.Authenticate(jwtSecret),
+		middleware.ScopeTenant(memberships),
 	)
 
 	// Health check endpoint
@@ -41,6 +68,29 @@ func NewRouter(
 	registerMenuRoutes(mux, menuHandler)
 	registerOrderRoutes(mux, orderHandler)
 	registerTableRoutes(mux, tableHandler)
+	registerCheckRoutes(mux, checkHandler)
+	registerPaymentRoutes(mux, paymentHandler)
+	registerAdjustmentRoutes(mux, adjustmentHandler)
+	registerPricingRoutes(mux, pricingRuleHandler)
+	registerGiftCardRoutes(mux, giftCardHandler)
+	registerLoyaltyRoutes(mux, loyaltyHandler)
+	registerFavoriteRoutes(mux, favoriteHandler)
+	registerReviewRoutes(mux, reviewHandler)
+	registerInventoryRoutes(mux, inventoryHandler)
+	registerPurchasingRoutes(mux, purchasingHandler)
+	registerMenuScheduleRoutes(mux, menuScheduleHandler)
+	registerAllergyProfileRoutes(mux, allergyProfileHandler)
+	registerKitchenRoutes(mux, kitchenHandler)
+	registerReportRoutes(mux, reportHandler)
+	registerCategoryRoutes(mux, categoryHandler)
+	registerTranslationRoutes(mux, translationHandler)
+	registerMenuImportRoutes(mux, menuImportHandler)
+	registerBrandRoutes(mux, brandHandler)
+	registerMenuVersionRoutes(mux, menuVersionHandler)
+	registerMenuSearchRoutes(mux, menuSearchHandler)
+	registerHoursRoutes(mux, hoursHandler)
+	registerSettingsRoutes(mux, settingsHandler)
+	registerOrganizationRoutes(mux, organizationHandler)
 
 	return handler(mux)
 }
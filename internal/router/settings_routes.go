@@ -0,0 +1,14 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerSettingsRoutes(mux *http.ServeMux, h *handler.SettingsHandler) {
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/settings", h.Get)
+	mux.HandleFunc("PUT "+constants.RestaurantsRoute+"/{id}/settings", h.Update)
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/settings/versions", h.ListVersions)
+}
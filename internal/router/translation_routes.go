@@ -0,0 +1,12 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/KNLopez/restaurant-api/internal/constants"
+	"github.com/KNLopez/restaurant-api/internal/handler"
+)
+
+func registerTranslationRoutes(mux *http.ServeMux, h *handler.TranslationHandler) {
+	mux.HandleFunc("GET "+constants.RestaurantsRoute+"/{id}/translations/missing", h.Missing)
+}
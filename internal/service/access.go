@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// managesRestaurant reports whether the user is an admin or an owner or
+// manager of the organization that runs the restaurant. A restaurant that
+// is in no organization is managed by admins only.
+func managesRestaurant(ctx context.Context, restaurantRepo repository.RestaurantRepository, organizationRepo repository.OrganizationRepository, restaurantID uuid.UUID, user *models.AuthUser) (bool, error) {
+	if user.Role == models.RoleAdmin {
+		return true, nil
+	}
+	if !user.IsStaff() {
+		return false, nil
+	}
+
+	restaurant, err := restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil || restaurant == nil || restaurant.OrganizationID == nil {
+		return false, err
+	}
+	return hasOrganizationRole(ctx, organizationRepo, *restaurant.OrganizationID, user, models.OrganizationRoleOwner, models.OrganizationRoleManager)
+}
+
+// hasOrganizationRole reports whether the user is an admin or a member of
+// the organization with one of the roles.
+func hasOrganizationRole(ctx context.Context, organizationRepo repository.OrganizationRepository, organizationID uuid.UUID, user *models.AuthUser, roles ...models.OrganizationRole) (bool, error) {
+	if user.Role == models.RoleAdmin {
+		return true, nil
+	}
+	member, err := organizationRepo.GetMember(ctx, organizationID, user.ID)
+	if err != nil || member == nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if member.Role == role {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type AdjustmentService struct {
+	adjustmentRepo    repository.AdjustmentRepository
+	orderRepo         repository.OrderRepository
+	restaurantRepo    repository.RestaurantRepository
+	organizationRepo  repository.OrganizationRepository
+	orderService      *OrderService
+	paymentService    *PaymentService
+	loyalty           *LoyaltyService
+	approvalThreshold float64
+}
+
+func NewAdjustmentService(
+	adjustmentRepo repository.AdjustmentRepository,
+	orderRepo repository.OrderRepository,
+	restaurantRepo repository.RestaurantRepository,
+	organizationRepo repository.OrganizationRepository,
+	orderService *OrderService,
+	paymentService *PaymentService,
+	loyalty *LoyaltyService,
+	approvalThreshold float64,
+) *AdjustmentService {
+	return &AdjustmentService{
+		adjustmentRepo:    adjustmentRepo,
+		orderRepo:         orderRepo,
+		restaurantRepo:    restaurantRepo,
+		organizationRepo:  organizationRepo,
+		orderService:      orderService,
+		paymentService:    paymentService,
+		loyalty:           loyalty,
+		approvalThreshold: approvalThreshold,
+	}
+}
+
+// Void removes some or all of an order item that has not been sent to the
+// kitchen and reprices the order without it, so discounts, charges and tax
+// follow the items left. The void is for what the total went down by.
+func (s *AdjustmentService) Void(ctx context.Context, orderID uuid.UUID, req models.AdjustmentRequest, user *models.AuthUser) (*models.OrderAdjustment, error) {
+	if !req.ReasonCode.Valid() {
+		return nil, fmt.Errorf("%w: unknown reason code %q", ErrInvalidInput, req.ReasonCode)
+	}
+	if req.OrderItemID == nil {
+		return nil, fmt.Errorf("%w: order_item_id is required", ErrInvalidInput)
+	}
+
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == models.OrderStatusComplete || order.Status == models.OrderStatusCanceled {
+		return nil, fmt.Errorf("%w: order is %s", ErrConflict, order.Status)
+	}
+
+	item, err := findItem(order, *req.OrderItemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.SentAt != nil {
+		return nil, fmt.Errorf("%w: item was already sent to the kitchen, refund it instead", ErrConflict)
+	}
+
+	available := item.ActiveQuantity() - item.RefundedQuantity
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = available
+	}
+	if quantity <= 0 || quantity > available {
+		return nil, fmt.Errorf("%w: quantity must be between 1 and %d", ErrInvalidInput, available)
+	}
+
+	repriced := *order
+	repriced.Items = append([]models.OrderItem(nil), order.Items...)
+	for i := range repriced.Items {
+		if repriced.Items[i].ID == item.ID {
+			repriced.Items[i].VoidedQuantity += quantity
+		}
+	}
+	if err := s.orderService.price(ctx, &repriced, false); err != nil {
+		return nil, err
+	}
+
+	adjustment := &models.OrderAdjustment{
+		OrderID:      order.ID,
+		RestaurantID: order.RestaurantID,
+		OrderItemID:  &item.ID,
+		Type:         models.AdjustmentVoid,
+		Quantity:     quantity,
+		Amount:       fromCents(max(toCents(order.TotalAmount)-toCents(repriced.TotalAmount), 0)),
+		ReasonCode:   req.ReasonCode,
+		Note:         req.Note,
+		Status:       models.AdjustmentCompleted,
+		RequestedBy:  user.ID,
+	}
+
+	if err := s.adjustmentRepo.CreateVoid(ctx, adjustment, &repriced); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: item can no longer be voided or the order has changed, try again", ErrConflict)
+		}
+		if errors.Is(err, repository.ErrUsageLimitReached) {
+			return nil, fmt.Errorf("%w: a discount on this order is no longer available", ErrConflict)
+		}
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+// RequestRefund records a full or partial refund. Refunds above the approval
+// threshold wait for a manager unless the requester can approve them.
+func (s *AdjustmentService) RequestRefund(ctx context.Context, orderID uuid.UUID, req models.AdjustmentRequest, user *models.AuthUser) (*models.OrderAdjustment, error) {
+	if !req.ReasonCode.Valid() {
+		return nil, fmt.Errorf("%w: unknown reason code %q", ErrInvalidInput, req.ReasonCode)
+	}
+
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.paymentService.orderPayments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.adjustmentRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	refundable := netPaid(payments, nil)
+	for _, adjustment := range existing {
+		if adjustment.Type == models.AdjustmentRefund && adjustment.Status.Outstanding() {
+			refundable -= toCents(adjustment.Amount) - toCents(adjustment.RefundedAmount)
+		}
+	}
+	if refundable <= 0 {
+		return nil, fmt.Errorf("%w: order has no paid amount left to refund", ErrConflict)
+	}
+
+	adjustment := &models.OrderAdjustment{
+		OrderID:      order.ID,
+		RestaurantID: order.RestaurantID,
+		Type:         models.AdjustmentRefund,
+		ReasonCode:   req.ReasonCode,
+		Note:         req.Note,
+		Status:       models.AdjustmentPendingApproval,
+		RequestedBy:  user.ID,
+	}
+
+	amount := toCents(req.Amount)
+	if req.OrderItemID != nil {
+		item, err := findItem(order, *req.OrderItemID)
+		if err != nil {
+			return nil, err
+		}
+
+		eligible := item.ActiveQuantity() - item.RefundedQuantity
+		for _, a := range existing {
+			if a.Type == models.AdjustmentRefund && a.Status.Outstanding() &&
+				a.OrderItemID != nil && *a.OrderItemID == item.ID {
+				eligible -= a.Quantity
+			}
+		}
+
+		quantity := req.Quantity
+		if quantity == 0 {
+			quantity = eligible
+		}
+		if quantity <= 0 || quantity > eligible {
+			return nil, fmt.Errorf("%w: quantity must be between 1 and %d", ErrInvalidInput, eligible)
+		}
+
+		itemAmount := toCents(item.Price) * int64(quantity)
+		if amount == 0 {
+			amount = itemAmount
+		}
+		if amount > itemAmount {
+			return nil, fmt.Errorf("%w: amount exceeds the price of the refunded items", ErrInvalidInput)
+		}
+
+		adjustment.OrderItemID = &item.ID
+		adjustment.Quantity = quantity
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be greater than 0", ErrInvalidInput)
+	}
+	if amount > refundable {
+		return nil, fmt.Errorf("%w: at most %.2f can be refunded", ErrInvalidInput, fromCents(refundable))
+	}
+	adjustment.Amount = fromCents(amount)
+
+	canApprove, err := s.canApprove(ctx, order.RestaurantID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.adjustmentRepo.Create(ctx, adjustment); err != nil {
+		return nil, err
+	}
+
+	if amount > toCents(s.approvalThreshold) && !canApprove {
+		return adjustment, nil
+	}
+
+	return adjustment, s.execute(ctx, adjustment, user, models.AdjustmentPendingApproval)
+}
+
+// Approve executes a refund that is waiting for manager approval.
+func (s *AdjustmentService) Approve(ctx context.Context, id uuid.UUID, user *models.AuthUser) (*models.OrderAdjustment, error) {
+	adjustment, err := s.getDecidable(ctx, id, user, models.AdjustmentPendingApproval)
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustment, s.execute(ctx, adjustment, user, models.AdjustmentPendingApproval)
+}
+
+// Retry refunds what is left of a refund that failed part way.
+func (s *AdjustmentService) Retry(ctx context.Context, id uuid.UUID, user *models.AuthUser) (*models.OrderAdjustment, error) {
+	adjustment, err := s.getDecidable(ctx, id, user, models.AdjustmentFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustment, s.execute(ctx, adjustment, user, models.AdjustmentFailed)
+}
+
+// Reject declines a refund that is waiting for manager approval.
+func (s *AdjustmentService) Reject(ctx context.Context, id uuid.UUID, user *models.AuthUser) (*models.OrderAdjustment, error) {
+	adjustment, err := s.getDecidable(ctx, id, user, models.AdjustmentPendingApproval)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment.ApprovedBy = &user.ID
+	if err := s.adjustmentRepo.Reject(ctx, adjustment); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: adjustment is no longer pending", ErrConflict)
+		}
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+func (s *AdjustmentService) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.OrderAdjustment, error) {
+	return s.adjustmentRepo.GetByOrderID(ctx, orderID)
+}
+
+// Report summarizes voids and refunds of a restaurant by type and reason.
+func (s *AdjustmentService) Report(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (*models.AdjustmentReport, error) {
+	adjustments, err := s.adjustmentRepo.ListByRestaurant(ctx, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.adjustmentRepo.ListPending(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		Type   models.AdjustmentType
+		Reason models.ReasonCode
+	}
+	totals := make(map[key]*models.AdjustmentSummary)
+	amounts := make(map[key]int64)
+	for _, adjustment := range adjustments {
+		if adjustment.Status != models.AdjustmentCompleted {
+			continue
+		}
+		k := key{adjustment.Type, adjustment.ReasonCode}
+		summary, ok := totals[k]
+		if !ok {
+			summary = &models.AdjustmentSummary{Type: k.Type, ReasonCode: k.Reason}
+			totals[k] = summary
+		}
+		summary.Count++
+		summary.Quantity += adjustment.Quantity
+		amounts[k] += toCents(adjustment.Amount)
+	}
+
+	report := &models.AdjustmentReport{
+		RestaurantID: restaurantID,
+		From:         from,
+		To:           to,
+		Summary:      []models.AdjustmentSummary{},
+		Pending:      pending,
+		Adjustments:  adjustments,
+	}
+	for k, summary := range totals {
+		summary.Amount = fromCents(amounts[k])
+		report.Summary = append(report.Summary, *summary)
+	}
+	sort.Slice(report.Summary, func(i, j int) bool {
+		if report.Summary[i].Type != report.Summary[j].Type {
+			return report.Summary[i].Type < report.Summary[j].Type
+		}
+		return report.Summary[i].ReasonCode < report.Summary[j].ReasonCode
+	})
+
+	return report, nil
+}
+
+// execute claims the refund, so that it is only paid out once, and
+// refunds what is left of it across the order's captured payments, newest
+// first. Every payment refunded is recorded; if one fails, or the refund
+// cannot be recorded or completed, it is marked failed with what was paid
+// back so far, to be retried. Once it is
+// completed, the loyalty points the refunded share of the order earned are
+// taken back.
+func (s *AdjustmentService) execute(ctx context.Context, adjustment *models.OrderAdjustment, approver *models.AuthUser, from models.AdjustmentStatus) error {
+	adjustment.ApprovedBy = &approver.ID
+	if err := s.adjustmentRepo.Claim(ctx, adjustment, from); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: adjustment is no longer %s", ErrConflict, from)
+		}
+		return err
+	}
+
+	payments, err := s.paymentService.orderPayments(ctx, adjustment.OrderID)
+	if err != nil {
+		return s.fail(ctx, adjustment, err)
+	}
+
+	remaining := toCents(adjustment.Amount) - toCents(adjustment.RefundedAmount)
+	for i := len(payments) - 1; i >= 0 && remaining > 0; i-- {
+		p := payments[i]
+		refundable := toCents(p.CapturedAmount) - toCents(p.RefundedAmount)
+		if refundable <= 0 {
+			continue
+		}
+
+		amount := min(refundable, remaining)
+		attempt := &models.RefundAttempt{
+			AdjustmentID: adjustment.ID,
+			PaymentID:    p.ID,
+			Amount:       fromCents(amount),
+		}
+		_, refundErr := s.paymentService.Refund(ctx, p.ID, fromCents(amount))
+		if refundErr != nil {
+			attempt.Error = refundErr.Error()
+		} else {
+			attempt.Succeeded = true
+		}
+		recordErr := s.adjustmentRepo.RecordRefund(ctx, attempt)
+		adjustment.Refunds = append(adjustment.Refunds, *attempt)
+		if refundErr != nil {
+			return s.fail(ctx, adjustment, fmt.Errorf("refunding payment %s: %w", p.ID, refundErr))
+		}
+
+		adjustment.RefundedAmount = fromCents(toCents(adjustment.RefundedAmount) + amount)
+		remaining -= amount
+		if recordErr != nil {
+			return s.fail(ctx, adjustment, fmt.Errorf("recording the refund of payment %s: %w", p.ID, recordErr))
+		}
+	}
+	if remaining > 0 {
+		return s.fail(ctx, adjustment, fmt.Errorf("%w: payments do not cover the refund", ErrConflict))
+	}
+
+	if err := s.adjustmentRepo.Complete(ctx, adjustment); err != nil {
+		return s.fail(ctx, adjustment, err)
+	}
+
+	// The money is back with the guest whatever happens to their points
+	if err := s.reversePoints(ctx, adjustment); err != nil {
+		log.Printf("adjustments: reversing loyalty points for refund %s: %v", adjustment.ID, err)
+	}
+	return nil
+}
+
+// reversePoints takes back the points a completed refund paid for.
+func (s *AdjustmentService) reversePoints(ctx context.Context, refund *models.OrderAdjustment) error {
+	order, err := s.orderRepo.GetByID(ctx, refund.OrderID)
+	if err != nil || order == nil {
+		return err
+	}
+	return s.loyalty.ReverseForRefund(ctx, order, refund)
+}
+
+// fail marks a claimed refund as failed and returns the reason.
+func (s *AdjustmentService) fail(ctx context.Context, adjustment *models.OrderAdjustment, reason error) error {
+	if err := s.adjustmentRepo.Fail(ctx, adjustment); err != nil {
+		return fmt.Errorf("%v; marking the refund failed: %w", reason, err)
+	}
+	return reason
+}
+
+// getDecidable loads an adjustment in the given status that the user can
+// approve.
+func (s *AdjustmentService) getDecidable(ctx context.Context, id uuid.UUID, user *models.AuthUser, status models.AdjustmentStatus) (*models.OrderAdjustment, error) {
+	adjustment, err := s.adjustmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if adjustment == nil {
+		return nil, fmt.Errorf("%w: adjustment %s", ErrNotFound, id)
+	}
+	if adjustment.Status != status {
+		return nil, fmt.Errorf("%w: adjustment is %s", ErrConflict, adjustment.Status)
+	}
+
+	canApprove, err := s.canApprove(ctx, adjustment.RestaurantID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !canApprove {
+		return nil, fmt.Errorf("%w: only the restaurant's manager can approve refunds", ErrForbidden)
+	}
+
+	return adjustment, nil
+}
+
+// canApprove reports whether the user manages the restaurant.
+func (s *AdjustmentService) canApprove(ctx context.Context, restaurantID uuid.UUID, user *models.AuthUser) (bool, error) {
+	return managesRestaurant(ctx, s.restaurantRepo, s.organizationRepo, restaurantID, user)
+}
+
+func (s *AdjustmentService) getOrder(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, fmt.Errorf("%w: order %s", ErrNotFound, id)
+	}
+	return order, nil
+}
+
+func findItem(order *models.Order, itemID uuid.UUID) (*models.OrderItem, error) {
+	for i := range order.Items {
+		if order.Items[i].ID == itemID {
+			return &order.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: order item %s is not on the order", ErrInvalidInput, itemID)
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/payment"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// fakeAdjustmentRepo keeps one adjustment in memory, and the order a void
+// repriced. recordErr and completeErr make RecordRefund and Complete fail
+// after the refund was paid out.
+type fakeAdjustmentRepo struct {
+	repository.AdjustmentRepository
+	stored      models.OrderAdjustment
+	repriced    *models.Order
+	recordErr   error
+	completeErr error
+}
+
+// load returns a copy of the stored refund, as GetByID would.
+func (r *fakeAdjustmentRepo) load() *models.OrderAdjustment {
+	copied := r.stored
+	return &copied
+}
+
+func (r *fakeAdjustmentRepo) decide(adjustment *models.OrderAdjustment, from, status models.AdjustmentStatus) error {
+	if r.stored.Status != from {
+		return errors.New("adjustment is no longer " + string(from))
+	}
+	r.stored.Status = status
+	adjustment.Status = status
+	return nil
+}
+
+func (r *fakeAdjustmentRepo) Claim(ctx context.Context, adjustment *models.OrderAdjustment, from models.AdjustmentStatus) error {
+	return r.decide(adjustment, from, models.AdjustmentProcessing)
+}
+
+func (r *fakeAdjustmentRepo) RecordRefund(ctx context.Context, refund *models.RefundAttempt) error {
+	if r.recordErr != nil {
+		err := r.recordErr
+		r.recordErr = nil
+		return err
+	}
+	if refund.Succeeded {
+		r.stored.RefundedAmount = fromCents(toCents(r.stored.RefundedAmount) + toCents(refund.Amount))
+	}
+	return nil
+}
+
+func (r *fakeAdjustmentRepo) Fail(ctx context.Context, adjustment *models.OrderAdjustment) error {
+	if err := r.decide(adjustment, models.AdjustmentProcessing, models.AdjustmentFailed); err != nil {
+		return err
+	}
+	r.stored.RefundedAmount = max(r.stored.RefundedAmount, adjustment.RefundedAmount)
+	return nil
+}
+
+func (r *fakeAdjustmentRepo) Complete(ctx context.Context, adjustment *models.OrderAdjustment) error {
+	if r.completeErr != nil {
+		return r.completeErr
+	}
+	return r.decide(adjustment, models.AdjustmentProcessing, models.AdjustmentCompleted)
+}
+
+func TestExecuteRefund(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      float64
+		refundErr   error
+		recordErr   error
+		completeErr error
+		status      models.AdjustmentStatus
+		refunded    float64
+		paidBack    []float64
+	}{
+		{name: "newest payment first", amount: 35, status: models.AdjustmentCompleted, refunded: 35, paidBack: []float64{15, 20}},
+		{name: "more than was paid", amount: 60, status: models.AdjustmentFailed, refunded: 50, paidBack: []float64{30, 20}},
+		{name: "provider fails", amount: 35, refundErr: errors.New("gateway down"), status: models.AdjustmentFailed},
+		{name: "paid out but not recorded", amount: 35, recordErr: errors.New("connection reset"), status: models.AdjustmentFailed, refunded: 20, paidBack: []float64{0, 20}},
+		{name: "paid out but not completed", amount: 35, completeErr: errors.New("connection reset"), status: models.AdjustmentFailed, refunded: 35, paidBack: []float64{15, 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{ID: uuid.New(), TotalAmount: 50}
+			older, newer := capturedPayment(order.ID, 30), capturedPayment(order.ID, 20)
+			payments := newFakePaymentRepo(older, newer)
+			provider := &countingProvider{ManualProvider: payment.NewManualProvider(payment.ProviderCash), refundErr: tt.refundErr}
+			orders := newFakeOrderRepo(order)
+			adjustments := &fakeAdjustmentRepo{
+				stored: models.OrderAdjustment{
+					ID: uuid.New(), OrderID: order.ID, Type: models.AdjustmentRefund,
+					Amount: tt.amount, Status: models.AdjustmentPendingApproval,
+				},
+				recordErr:   tt.recordErr,
+				completeErr: tt.completeErr,
+			}
+			s := NewAdjustmentService(adjustments, orders, nil, nil, nil, newTestPaymentService(orders, payments, provider), NewLoyaltyService(&fakeLoyaltyRepo{}, nil), 0)
+
+			err := s.execute(context.Background(), adjustments.load(), staff, models.AdjustmentPendingApproval)
+			if (err != nil) != (tt.status == models.AdjustmentFailed) {
+				t.Fatalf("execute() error = %v, want status %s", err, tt.status)
+			}
+			if adjustments.stored.Status != tt.status || adjustments.stored.RefundedAmount != tt.refunded {
+				t.Errorf("refund is %s with %v refunded, want %s with %v", adjustments.stored.Status, adjustments.stored.RefundedAmount, tt.status, tt.refunded)
+			}
+			for i, p := range []*models.Payment{older, newer} {
+				want := 0.0
+				if tt.paidBack != nil {
+					want = tt.paidBack[i]
+				}
+				if got := payments.payments[p.ID].RefundedAmount; got != want {
+					t.Errorf("payment %d refunded %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryRefundPaysOutOnce(t *testing.T) {
+	order := &models.Order{ID: uuid.New(), TotalAmount: 50}
+	older, newer := capturedPayment(order.ID, 30), capturedPayment(order.ID, 20)
+	payments := newFakePaymentRepo(older, newer)
+	provider := &countingProvider{ManualProvider: payment.NewManualProvider(payment.ProviderCash)}
+	orders := newFakeOrderRepo(order)
+	adjustments := &fakeAdjustmentRepo{
+		stored: models.OrderAdjustment{
+			ID: uuid.New(), OrderID: order.ID, Type: models.AdjustmentRefund,
+			Amount: 35, Status: models.AdjustmentPendingApproval,
+		},
+		recordErr: errors.New("connection reset"),
+	}
+	s := NewAdjustmentService(adjustments, orders, nil, nil, nil, newTestPaymentService(orders, payments, provider), NewLoyaltyService(&fakeLoyaltyRepo{}, nil), 0)
+
+	if err := s.execute(context.Background(), adjustments.load(), staff, models.AdjustmentPendingApproval); err == nil {
+		t.Fatal("execute() error = nil, want the recording error")
+	}
+	if err := s.execute(context.Background(), adjustments.load(), staff, models.AdjustmentFailed); err != nil {
+		t.Fatalf("retrying: execute() error = %v", err)
+	}
+
+	if adjustments.stored.Status != models.AdjustmentCompleted || adjustments.stored.RefundedAmount != 35 {
+		t.Errorf("refund is %s with %v refunded, want completed with 35", adjustments.stored.Status, adjustments.stored.RefundedAmount)
+	}
+	if got := payments.payments[older.ID].RefundedAmount + payments.payments[newer.ID].RefundedAmount; got != 35 {
+		t.Errorf("payments refunded %v in total, want 35", got)
+	}
+	if provider.refunds != 2 {
+		t.Errorf("provider refunded %d times, want 2", provider.refunds)
+	}
+}
+
+// The fakes below price orders from an in-memory menu without variants,
+// options or bundles.
+
+type fakeMenuRepo struct {
+	repository.MenuRepository
+	items map[uuid.UUID]*models.MenuItem
+}
+
+func (r *fakeMenuRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.MenuItem, error) {
+	return r.items[id], nil
+}
+
+type fakeVariantRepo struct{ repository.VariantRepository }
+
+func (fakeVariantRepo) ListByMenuItem(ctx context.Context, menuItemID uuid.UUID) ([]*models.MenuItemVariant, error) {
+	return nil, nil
+}
+
+type fakeCustomizationRepo struct {
+	repository.CustomizationRepository
+}
+
+func (fakeCustomizationRepo) ListByMenuItem(ctx context.Context, menuItemID uuid.UUID) ([]*models.MenuItemCustomization, error) {
+	return nil, nil
+}
+
+type fakeBundleRepo struct{ repository.BundleRepository }
+
+func (fakeBundleRepo) ListByMenuItem(ctx context.Context, menuItemID uuid.UUID) ([]models.BundleGroup, error) {
+	return nil, nil
+}
+
+type fakeRuleRepo struct {
+	repository.PricingRuleRepository
+	rules []*models.PricingRule
+}
+
+func (r *fakeRuleRepo) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID) ([]*models.PricingRule, error) {
+	return r.rules, nil
+}
+
+// fakeRestaurantRepo returns the restaurants it holds, and a bare
+// restaurant for any other ID.
+type fakeRestaurantRepo struct {
+	repository.RestaurantRepository
+	restaurants map[uuid.UUID]*models.Restaurant
+}
+
+func (r fakeRestaurantRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Restaurant, error) {
+	if restaurant, ok := r.restaurants[id]; ok {
+		return restaurant, nil
+	}
+	return &models.Restaurant{ID: id}, nil
+}
+
+type fakeSettingsRepo struct {
+	repository.SettingsRepository
+	settings models.RestaurantSettings
+}
+
+func (r *fakeSettingsRepo) Get(ctx context.Context, restaurantID uuid.UUID) (*models.RestaurantSettings, error) {
+	settings := r.settings
+	settings.RestaurantID = restaurantID
+	return &settings, nil
+}
+
+// newPricingOrderService returns an order service that prices orders from
+// the menu items and rules given, with the settings given.
+func newPricingOrderService(orders repository.OrderRepository, items []*models.MenuItem, rules []*models.PricingRule, settings models.RestaurantSettings) *OrderService {
+	menu := &fakeMenuRepo{items: make(map[uuid.UUID]*models.MenuItem)}
+	for _, item := range items {
+		menu.items[item.ID] = item
+	}
+	pricing := NewPricingService(&fakeRuleRepo{rules: rules}, menu, fakeCustomizationRepo{}, fakeVariantRepo{}, fakeBundleRepo{}, fakeRestaurantRepo{})
+	loyalty := NewLoyaltyService(&fakeLoyaltyRepo{}, nil)
+	settingsService := NewSettingsService(&fakeSettingsRepo{settings: settings}, fakeRestaurantRepo{}, 0)
+	return NewOrderService(orders, nil, menu, fakeVariantRepo{}, pricing, loyalty, nil, nil, NewDietaryService(nil, menu), nil, nil, settingsService)
+}
+
+func (r *fakeAdjustmentRepo) CreateVoid(ctx context.Context, adjustment *models.OrderAdjustment, order *models.Order) error {
+	r.stored = *adjustment
+	r.repriced = order
+	return nil
+}
+
+func TestVoidReprices(t *testing.T) {
+	restaurantID := uuid.New()
+	burger := &models.MenuItem{ID: uuid.New(), RestaurantID: restaurantID, Price: 10}
+	fifteenOff := &models.PricingRule{ID: uuid.New(), RestaurantID: restaurantID, Name: "15 off", Type: models.PricingAmountOff, Scope: models.PricingScopeOrder, Value: 15, Active: true}
+	tenPercent := &models.PricingRule{ID: uuid.New(), RestaurantID: restaurantID, Name: "10% off", Type: models.PricingPercentOff, Scope: models.PricingScopeOrder, Value: 10, Active: true}
+
+	tests := []struct {
+		name     string
+		rules    []*models.PricingRule
+		settings models.RestaurantSettings
+		total    float64
+		voided   float64
+		want     float64
+		tax      float64
+	}{
+		{
+			name:   "discount is capped at what is left",
+			rules:  []*models.PricingRule{fifteenOff},
+			total:  5,
+			voided: 5,
+			want:   0,
+		},
+		{
+			name:     "discount, service charge and tax follow the items",
+			rules:    []*models.PricingRule{tenPercent},
+			settings: models.RestaurantSettings{TaxMode: models.TaxModeExclusive, TaxRate: 10, ServiceChargeRate: 10},
+			total:    21.78,
+			voided:   10.89,
+			want:     10.89,
+			tax:      0.99,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := models.OrderItem{ID: uuid.New(), MenuItemID: burger.ID, Quantity: 2, Price: 10}
+			order := &models.Order{ID: uuid.New(), RestaurantID: restaurantID, Type: models.OrderTypeDineIn, Status: models.OrderStatusPending, TotalAmount: tt.total, Items: []models.OrderItem{item}}
+			orders := newFakeOrderRepo(order)
+			if tt.settings.TaxMode == "" {
+				tt.settings.TaxMode = models.TaxModeNone
+			}
+			adjustments := &fakeAdjustmentRepo{}
+			s := NewAdjustmentService(adjustments, orders, nil, nil, newPricingOrderService(orders, []*models.MenuItem{burger}, tt.rules, tt.settings), nil, nil, 0)
+
+			adjustment, err := s.Void(context.Background(), order.ID, models.AdjustmentRequest{OrderItemID: &item.ID, Quantity: 1, ReasonCode: models.ReasonWrongItem}, staff)
+			if err != nil {
+				t.Fatalf("Void() error = %v", err)
+			}
+			if adjustment.Amount != tt.voided {
+				t.Errorf("voided amount = %v, want %v", adjustment.Amount, tt.voided)
+			}
+			repriced := adjustments.repriced
+			if repriced.TotalAmount != tt.want || repriced.TaxTotal != tt.tax {
+				t.Errorf("repriced total %v with tax %v, want %v with %v", repriced.TotalAmount, repriced.TaxTotal, tt.want, tt.tax)
+			}
+			if repriced.Items[0].VoidedQuantity != 1 || order.Items[0].VoidedQuantity != 0 {
+				t.Errorf("voided quantity = %d on the repriced order and %d on the one read", repriced.Items[0].VoidedQuantity, order.Items[0].VoidedQuantity)
+			}
+		})
+	}
+}
@@ -0,0 +1,614 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type BrandService struct {
+	brandRepo        repository.BrandRepository
+	restaurantRepo   repository.RestaurantRepository
+	menuRepo         repository.MenuRepository
+	categoryRepo     repository.CategoryRepository
+	organizationRepo repository.OrganizationRepository
+}
+
+func NewBrandService(
+	brandRepo repository.BrandRepository,
+	restaurantRepo repository.RestaurantRepository,
+	menuRepo repository.MenuRepository,
+	categoryRepo repository.CategoryRepository,
+	organizationRepo repository.OrganizationRepository,
+) *BrandService {
+	return &BrandService{
+		brandRepo:        brandRepo,
+		restaurantRepo:   restaurantRepo,
+		organizationRepo: organizationRepo,
+		menuRepo:         menuRepo,
+		categoryRepo:     categoryRepo,
+	}
+}
+
+// Create registers a brand owned by the calling manager in one of their
+// organizations. Only admins may create brands outside an organization.
+func (s *BrandService) Create(ctx context.Context, user *models.AuthUser, brand *models.Brand) error {
+	if user.Role != models.RoleAdmin && user.Role != models.RoleManager {
+		return fmt.Errorf("%w: only managers can create brands", ErrForbidden)
+	}
+	brand.Name = strings.TrimSpace(brand.Name)
+	if brand.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if brand.OrganizationID == nil && user.Role != models.RoleAdmin {
+		return fmt.Errorf("%w: organization_id is required", ErrInvalidInput)
+	}
+	if brand.OrganizationID != nil {
+		ok, err := hasOrganizationRole(ctx, s.organizationRepo, *brand.OrganizationID, user, models.OrganizationRoleOwner, models.OrganizationRoleManager)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: only the organization's owners and managers can create its brands", ErrForbidden)
+		}
+	}
+	brand.OwnerID = user.ID
+	return s.brandRepo.Create(ctx, brand)
+}
+
+func (s *BrandService) Get(ctx context.Context, id uuid.UUID) (*models.Brand, error) {
+	brand, err := s.brandRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if brand == nil {
+		return nil, fmt.Errorf("%w: brand %s", ErrNotFound, id)
+	}
+	return brand, nil
+}
+
+// List returns the brands the user owns or that belong to their
+// organizations.
+func (s *BrandService) List(ctx context.Context, user *models.AuthUser) ([]*models.Brand, error) {
+	return s.brandRepo.ListByMember(ctx, user.ID)
+}
+
+func (s *BrandService) Update(ctx context.Context, user *models.AuthUser, brand *models.Brand) error {
+	existing, err := s.getOwned(ctx, brand.ID, user)
+	if err != nil {
+		return err
+	}
+	brand.Name = strings.TrimSpace(brand.Name)
+	if brand.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	brand.OwnerID = existing.OwnerID
+	brand.OrganizationID = existing.OrganizationID
+	brand.CreatedAt = existing.CreatedAt
+	return s.brandRepo.Update(ctx, brand)
+}
+
+func (s *BrandService) ListLocations(ctx context.Context, brandID uuid.UUID) ([]*models.Restaurant, error) {
+	if _, err := s.Get(ctx, brandID); err != nil {
+		return nil, err
+	}
+	return s.restaurantRepo.GetByBrandID(ctx, brandID)
+}
+
+// AddLocation makes a restaurant the user manages a location of the brand.
+// A brand of an organization only takes restaurants of that organization.
+// Its menu follows the master menu from the next publish on.
+func (s *BrandService) AddLocation(ctx context.Context, user *models.AuthUser, brandID, restaurantID uuid.UUID) error {
+	brand, err := s.getOwned(ctx, brandID, user)
+	if err != nil {
+		return err
+	}
+
+	restaurant, err := s.getManagedRestaurant(ctx, restaurantID, user)
+	if err != nil {
+		return err
+	}
+	if brand.OrganizationID != nil && (restaurant.OrganizationID == nil || *restaurant.OrganizationID != *brand.OrganizationID) {
+		return fmt.Errorf("%w: the restaurant is not in the brand's organization", ErrConflict)
+	}
+	if restaurant.BrandID != nil {
+		if *restaurant.BrandID == brandID {
+			return nil
+		}
+		return fmt.Errorf("%w: the restaurant already belongs to another brand", ErrConflict)
+	}
+
+	return s.restaurantRepo.SetBrand(ctx, restaurantID, &brandID)
+}
+
+// RemoveLocation makes a location an independent restaurant again. It
+// keeps its menu items, which no longer follow the master menu.
+func (s *BrandService) RemoveLocation(ctx context.Context, user *models.AuthUser, brandID, restaurantID uuid.UUID) error {
+	if _, err := s.getOwned(ctx, brandID, user); err != nil {
+		return err
+	}
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+	if restaurant == nil || restaurant.BrandID == nil || *restaurant.BrandID != brandID {
+		return fmt.Errorf("%w: restaurant %s is not a location of the brand", ErrNotFound, restaurantID)
+	}
+
+	return s.restaurantRepo.SetBrand(ctx, restaurantID, nil)
+}
+
+func (s *BrandService) ListItems(ctx context.Context, brandID uuid.UUID) ([]*models.BrandMenuItem, error) {
+	if _, err := s.Get(ctx, brandID); err != nil {
+		return nil, err
+	}
+	return s.brandRepo.ListItems(ctx, brandID, false)
+}
+
+// CreateItem adds an item to the master menu. Locations get it when the
+// master menu is next published.
+func (s *BrandService) CreateItem(ctx context.Context, user *models.AuthUser, item *models.BrandMenuItem) error {
+	if _, err := s.getOwned(ctx, item.BrandID, user); err != nil {
+		return err
+	}
+	if err := s.validateItem(ctx, item); err != nil {
+		return err
+	}
+	return s.brandRepo.CreateItem(ctx, item)
+}
+
+func (s *BrandService) UpdateItem(ctx context.Context, user *models.AuthUser, item *models.BrandMenuItem) error {
+	if _, err := s.getOwned(ctx, item.BrandID, user); err != nil {
+		return err
+	}
+	existing, err := s.getItem(ctx, item.BrandID, item.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateItem(ctx, item); err != nil {
+		return err
+	}
+	item.CreatedAt = existing.CreatedAt
+	return s.brandRepo.UpdateItem(ctx, item)
+}
+
+// DeleteItem takes an item off the master menu. Locations keep serving it
+// until the master menu is next published.
+func (s *BrandService) DeleteItem(ctx context.Context, user *models.AuthUser, brandID, id uuid.UUID) error {
+	if _, err := s.getOwned(ctx, brandID, user); err != nil {
+		return err
+	}
+	if _, err := s.getItem(ctx, brandID, id); err != nil {
+		return err
+	}
+	return s.brandRepo.DeleteItem(ctx, id)
+}
+
+func (s *BrandService) ListOverrides(ctx context.Context, restaurantID uuid.UUID) ([]*models.BrandMenuOverride, error) {
+	return s.brandRepo.ListOverrides(ctx, restaurantID)
+}
+
+// SetOverride gives a location its own price or availability for a master
+// menu item. It applies to the location's menu right away and is kept by
+// later publishes.
+func (s *BrandService) SetOverride(ctx context.Context, user *models.AuthUser, override *models.BrandMenuOverride) error {
+	restaurant, err := s.getManagedRestaurant(ctx, override.RestaurantID, user)
+	if err != nil {
+		return err
+	}
+	if restaurant.BrandID == nil {
+		return fmt.Errorf("%w: the restaurant is not part of a brand", ErrInvalidInput)
+	}
+	master, err := s.getItem(ctx, *restaurant.BrandID, override.BrandItemID)
+	if err != nil {
+		return err
+	}
+	if override.Price != nil && *override.Price < 0 {
+		return fmt.Errorf("%w: price cannot be negative", ErrInvalidInput)
+	}
+	if override.Price == nil && override.Available == nil {
+		return fmt.Errorf("%w: set a price or availability, or delete the override", ErrInvalidInput)
+	}
+
+	if err := s.brandRepo.SaveOverride(ctx, override); err != nil {
+		return err
+	}
+	return s.applyOverride(ctx, restaurant.ID, master, override)
+}
+
+// DeleteOverride makes a location follow the master menu price again.
+func (s *BrandService) DeleteOverride(ctx context.Context, user *models.AuthUser, restaurantID, brandItemID uuid.UUID) error {
+	restaurant, err := s.getManagedRestaurant(ctx, restaurantID, user)
+	if err != nil {
+		return err
+	}
+
+	if err := s.brandRepo.DeleteOverride(ctx, restaurantID, brandItemID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: no override for item %s", ErrNotFound, brandItemID)
+		}
+		return err
+	}
+
+	if restaurant.BrandID == nil {
+		return nil
+	}
+	master, err := s.brandRepo.GetItem(ctx, brandItemID)
+	if err != nil || master == nil {
+		return err
+	}
+	return s.applyOverride(ctx, restaurantID, master, &models.BrandMenuOverride{})
+}
+
+// applyOverride updates the location's copy of a master item, if it has
+// one yet, with the override's price and availability.
+func (s *BrandService) applyOverride(ctx context.Context, restaurantID uuid.UUID, master *models.BrandMenuItem, override *models.BrandMenuOverride) error {
+	items, err := s.menuRepo.List(ctx, restaurantID, models.MenuFilter{})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.BrandItemID == nil || *item.BrandItemID != master.ID {
+			continue
+		}
+
+		price := master.Price
+		if override.Price != nil {
+			price = *override.Price
+		}
+		if item.Price != price {
+			item.Price = price
+			if err := s.menuRepo.Update(ctx, item); err != nil {
+				return err
+			}
+		}
+		if override.Available != nil && item.Available != *override.Available {
+			if err := s.menuRepo.SetAvailability(ctx, item.ID, *override.Available, item.PortionsRemaining); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Preview returns what publishing the master menu would change at each
+// location without changing anything.
+func (s *BrandService) Preview(ctx context.Context, user *models.AuthUser, brandID uuid.UUID) (*models.BrandMenuDiff, error) {
+	if _, err := s.getOwned(ctx, brandID, user); err != nil {
+		return nil, err
+	}
+	diff, _, err := s.plan(ctx, brandID)
+	return diff, err
+}
+
+// Publish copies the master menu to every location in one transaction and
+// returns what changed.
+func (s *BrandService) Publish(ctx context.Context, user *models.AuthUser, brandID uuid.UUID) (*models.BrandMenuDiff, error) {
+	if _, err := s.getOwned(ctx, brandID, user); err != nil {
+		return nil, err
+	}
+
+	diff, publish, err := s.plan(ctx, brandID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.brandRepo.Publish(ctx, brandID, publish); err != nil {
+		return nil, err
+	}
+	diff.Published = true
+
+	return diff, nil
+}
+
+// plan works out the changes publishing makes at every location. A
+// location's items are matched to master items by link, or by SKU for
+// items the location had before it joined the brand.
+func (s *BrandService) plan(ctx context.Context, brandID uuid.UUID) (*models.BrandMenuDiff, *models.MenuChangeSet, error) {
+	masters, err := s.brandRepo.ListItems(ctx, brandID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	overrides, err := s.brandRepo.ListOverridesByBrand(ctx, brandID)
+	if err != nil {
+		return nil, nil, err
+	}
+	locations, err := s.restaurantRepo.GetByBrandID(ctx, brandID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type overrideKey struct{ restaurantID, brandItemID uuid.UUID }
+	overrideFor := make(map[overrideKey]*models.BrandMenuOverride, len(overrides))
+	for _, override := range overrides {
+		overrideFor[overrideKey{override.RestaurantID, override.BrandItemID}] = override
+	}
+
+	diff := &models.BrandMenuDiff{BrandID: brandID, Locations: []models.LocationMenuDiff{}}
+	publish := &models.MenuChangeSet{Availability: map[uuid.UUID]bool{}}
+
+	for _, location := range locations {
+		items, err := s.menuRepo.List(ctx, location.ID, models.MenuFilter{})
+		if err != nil {
+			return nil, nil, err
+		}
+		categories, err := s.categoryRepo.ListByRestaurant(ctx, location.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		linked := map[uuid.UUID]*models.MenuItem{}
+		bySKU := map[string]*models.MenuItem{}
+		for _, item := range items {
+			if item.BrandItemID != nil {
+				linked[*item.BrandItemID] = item
+			} else if item.SKU != "" {
+				bySKU[item.SKU] = item
+			}
+		}
+		categoryIDs := map[string]uuid.UUID{}
+		for _, category := range categories {
+			categoryIDs[strings.ToLower(category.Name)] = category.ID
+		}
+		categoryID := func(name string) uuid.UUID {
+			key := strings.ToLower(name)
+			if id, ok := categoryIDs[key]; ok {
+				return id
+			}
+			category := &models.Category{ID: uuid.New(), RestaurantID: location.ID, Name: name}
+			publish.Categories = append(publish.Categories, category)
+			categoryIDs[key] = category.ID
+			return category.ID
+		}
+
+		locationDiff := models.LocationMenuDiff{
+			RestaurantID:   location.ID,
+			RestaurantName: location.Name,
+			Changes:        []models.MenuItemChange{},
+		}
+
+		for _, master := range masters {
+			existing, ok := linked[master.ID]
+			if !ok && master.SKU != "" {
+				existing = bySKU[master.SKU]
+			}
+
+			if master.DeletedAt != nil {
+				if existing != nil && existing.BrandItemID != nil {
+					publish.Remove = append(publish.Remove, existing.ID)
+					locationDiff.Changes = append(locationDiff.Changes, models.MenuItemChange{
+						Action:      models.MenuChangeRemove,
+						BrandItemID: &master.ID,
+						MenuItemID:  &existing.ID,
+						Name:        existing.Name,
+					})
+				}
+				continue
+			}
+
+			override := overrideFor[overrideKey{location.ID, master.ID}]
+			price := master.Price
+			if override != nil && override.Price != nil {
+				price = *override.Price
+			}
+
+			if existing == nil {
+				item := &models.MenuItem{
+					ID:           uuid.New(),
+					RestaurantID: location.ID,
+					BrandItemID:  &master.ID,
+				}
+				applyMaster(item, master, price, categoryID(master.Category))
+				publish.Create = append(publish.Create, item)
+				if override != nil && override.Available != nil && !*override.Available {
+					publish.Availability[item.ID] = false
+				}
+				locationDiff.Changes = append(locationDiff.Changes, models.MenuItemChange{
+					Action:      models.MenuChangeCreate,
+					BrandItemID: &master.ID,
+					Name:        master.Name,
+					NewPrice:    &price,
+				})
+				continue
+			}
+
+			item := *existing
+			applyMaster(&item, master, price, categoryID(master.Category))
+			item.BrandItemID = &master.ID
+			fields := changedFields(existing, &item)
+			if len(fields) > 0 {
+				publish.Update = append(publish.Update, &item)
+			}
+			if override != nil && override.Available != nil && existing.Available != *override.Available {
+				fields = append(fields, "available")
+				publish.Availability[existing.ID] = *override.Available
+			}
+			if len(fields) == 0 {
+				continue
+			}
+
+			change := models.MenuItemChange{
+				Action:      models.MenuChangeUpdate,
+				BrandItemID: &master.ID,
+				MenuItemID:  &existing.ID,
+				Name:        item.Name,
+				Fields:      fields,
+			}
+			if existing.Price != item.Price {
+				oldPrice := existing.Price
+				change.OldPrice, change.NewPrice = &oldPrice, &price
+			}
+			locationDiff.Changes = append(locationDiff.Changes, change)
+		}
+
+		diff.ItemsAffected += len(locationDiff.Changes)
+		diff.Locations = append(diff.Locations, locationDiff)
+	}
+
+	return diff, publish, nil
+}
+
+// applyMaster copies the master item's content onto a location's item.
+func applyMaster(item *models.MenuItem, master *models.BrandMenuItem, price float64, categoryID uuid.UUID) {
+	item.SKU = master.SKU
+	item.CategoryID = categoryID
+	item.Name = master.Name
+	item.Description = master.Description
+	item.Translations = master.Translations
+	item.Price = price
+	item.Allergens = master.Allergens
+	item.DietaryTags = master.DietaryTags
+}
+
+// changedFields names the published fields that differ between two
+// versions of a location's item.
+func changedFields(old, updated *models.MenuItem) []string {
+	var fields []string
+	if !reflect.DeepEqual(old.BrandItemID, updated.BrandItemID) {
+		fields = append(fields, "brand_item_id")
+	}
+	if old.SKU != updated.SKU {
+		fields = append(fields, "sku")
+	}
+	if old.CategoryID != updated.CategoryID {
+		fields = append(fields, "category")
+	}
+	if old.Name != updated.Name {
+		fields = append(fields, "name")
+	}
+	if old.Description != updated.Description {
+		fields = append(fields, "description")
+	}
+	if (len(old.Translations) > 0 || len(updated.Translations) > 0) && !reflect.DeepEqual(old.Translations, updated.Translations) {
+		fields = append(fields, "translations")
+	}
+	if old.Price != updated.Price {
+		fields = append(fields, "price")
+	}
+	if !reflect.DeepEqual(toSet(old.Allergens), toSet(updated.Allergens)) {
+		fields = append(fields, "allergens")
+	}
+	if !reflect.DeepEqual(toSet(old.DietaryTags), toSet(updated.DietaryTags)) {
+		fields = append(fields, "dietary_tags")
+	}
+	if !reflect.DeepEqual(old.Nutrition, updated.Nutrition) {
+		fields = append(fields, "nutrition")
+	}
+	return fields
+}
+
+func toSet[T comparable](values []T) map[T]bool {
+	set := make(map[T]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// validateItem checks a master item and requires its SKU, if it has one,
+// to be unique within the brand.
+func (s *BrandService) validateItem(ctx context.Context, item *models.BrandMenuItem) error {
+	item.SKU = strings.TrimSpace(item.SKU)
+	item.Category = strings.TrimSpace(item.Category)
+	item.Name = strings.TrimSpace(item.Name)
+	item.Description = strings.TrimSpace(item.Description)
+
+	if item.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if item.Category == "" {
+		return fmt.Errorf("%w: category is required", ErrInvalidInput)
+	}
+	if item.Price < 0 {
+		return fmt.Errorf("%w: price cannot be negative", ErrInvalidInput)
+	}
+	if len(item.SKU) > maxSKULength {
+		return fmt.Errorf("%w: SKU is longer than %d characters", ErrInvalidInput, maxSKULength)
+	}
+
+	var err error
+	if item.Allergens, err = validateAllergens(item.Allergens); err != nil {
+		return err
+	}
+	if item.DietaryTags, err = validateDietaryTags(item.DietaryTags); err != nil {
+		return err
+	}
+	if item.Translations, err = normalizeTranslations(item.Translations, true); err != nil {
+		return err
+	}
+
+	if item.SKU == "" {
+		return nil
+	}
+	others, err := s.brandRepo.ListItems(ctx, item.BrandID, false)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID != item.ID && other.SKU == item.SKU {
+			return fmt.Errorf("%w: SKU %q is already in use", ErrConflict, item.SKU)
+		}
+	}
+
+	return nil
+}
+
+// getOwned loads a brand the user owns or manages through its
+// organization.
+func (s *BrandService) getOwned(ctx context.Context, id uuid.UUID, user *models.AuthUser) (*models.Brand, error) {
+	brand, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == models.RoleAdmin || brand.OwnerID == user.ID {
+		return brand, nil
+	}
+	if brand.OrganizationID != nil {
+		ok, err := hasOrganizationRole(ctx, s.organizationRepo, *brand.OrganizationID, user, models.OrganizationRoleOwner, models.OrganizationRoleManager)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return brand, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: only the brand's owner or its organization's managers can change it", ErrForbidden)
+}
+
+func (s *BrandService) getItem(ctx context.Context, brandID, id uuid.UUID) (*models.BrandMenuItem, error) {
+	item, err := s.brandRepo.GetItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || item.BrandID != brandID {
+		return nil, fmt.Errorf("%w: brand menu item %s", ErrNotFound, id)
+	}
+	return item, nil
+}
+
+func (s *BrandService) getManagedRestaurant(ctx context.Context, restaurantID uuid.UUID, user *models.AuthUser) (*models.Restaurant, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if restaurant == nil {
+		return nil, fmt.Errorf("%w: restaurant %s", ErrNotFound, restaurantID)
+	}
+	ok, err := managesRestaurant(ctx, s.restaurantRepo, s.organizationRepo, restaurantID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: only the restaurant's manager can do this", ErrForbidden)
+	}
+	return restaurant, nil
+}
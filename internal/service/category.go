@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type CategoryService struct {
+	categoryRepo repository.CategoryRepository
+}
+
+func NewCategoryService(categoryRepo repository.CategoryRepository) *CategoryService {
+	return &CategoryService{
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (s *CategoryService) Create(ctx context.Context, category *models.Category) error {
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	return s.categoryRepo.Create(ctx, category)
+}
+
+// Get returns a category of the restaurant.
+func (s *CategoryService) Get(ctx context.Context, restaurantID, id uuid.UUID) (*models.Category, error) {
+	category, err := s.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil || category.RestaurantID != restaurantID {
+		return nil, fmt.Errorf("%w: category %s", ErrNotFound, id)
+	}
+	return category, nil
+}
+
+func (s *CategoryService) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.Category, error) {
+	return s.categoryRepo.ListByRestaurant(ctx, restaurantID)
+}
+
+func (s *CategoryService) Update(ctx context.Context, category *models.Category) error {
+	existing, err := s.Get(ctx, category.RestaurantID, category.ID)
+	if err != nil {
+		return err
+	}
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	category.CreatedAt = existing.CreatedAt
+	return s.categoryRepo.Update(ctx, category)
+}
+
+func validateCategory(category *models.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	var err error
+	category.Translations, err = normalizeTranslations(category.Translations, true)
+	return err
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type CheckService struct {
+	checkRepo    repository.CheckRepository
+	orderRepo    repository.OrderRepository
+	tableRepo    repository.TableRepository
+	orderService *OrderService
+}
+
+func NewCheckService(checkRepo repository.CheckRepository, orderRepo repository.OrderRepository, tableRepo repository.TableRepository, orderService *OrderService) *CheckService {
+	return &CheckService{
+		checkRepo:    checkRepo,
+		orderRepo:    orderRepo,
+		tableRepo:    tableRepo,
+		orderService: orderService,
+	}
+}
+
+// SplitOrder splits a single order into checks, replacing any previous
+// split as long as none of its checks is paid or has payments against it.
+func (s *CheckService) SplitOrder(ctx context.Context, orderID uuid.UUID, req models.SplitRequest) ([]*models.Check, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, fmt.Errorf("%w: order %s", ErrNotFound, orderID)
+	}
+
+	return s.split(ctx, []*models.Order{order}, nil, req)
+}
+
+// SplitTable splits all open orders of a restaurant's table into checks.
+func (s *CheckService) SplitTable(ctx context.Context, restaurantID, tableID uuid.UUID, req models.SplitRequest) ([]*models.Check, error) {
+	table, err := s.tableRepo.GetByID(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil || table.RestaurantID != restaurantID {
+		return nil, fmt.Errorf("%w: table %s", ErrNotFound, tableID)
+	}
+
+	orders, err := s.orderRepo.GetOpenByTableID(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("%w: table has no open orders", ErrInvalidInput)
+	}
+
+	return s.split(ctx, orders, &tableID, req)
+}
+
+func (s *CheckService) GetByID(ctx context.Context, id uuid.UUID) (*models.Check, error) {
+	return s.checkRepo.GetByID(ctx, id)
+}
+
+func (s *CheckService) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.Check, error) {
+	return s.checkRepo.GetByOrderID(ctx, orderID)
+}
+
+// Pay marks a check as paid once payments cover it. Every order covered by
+// the check whose checks are now all paid is marked complete if its status
+// flow allows it.
+func (s *CheckService) Pay(ctx context.Context, id uuid.UUID) (*models.Check, error) {
+	check, err := s.checkRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if check == nil {
+		return nil, fmt.Errorf("%w: check %s", ErrNotFound, id)
+	}
+	if check.Status == models.CheckStatusPaid {
+		return nil, fmt.Errorf("%w: check is already paid", ErrConflict)
+	}
+
+	if err := s.checkRepo.MarkPaid(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: check is already paid", ErrConflict)
+		}
+		return nil, err
+	}
+
+	for _, orderID := range check.OrderIDs {
+		checks, err := s.checkRepo.GetByOrderID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if !allPaid(checks) {
+			continue
+		}
+		// Orders that are not ready to complete, such as a pickup still
+		// being cooked, are completed through their status flow later
+		order, err := s.orderService.GetByID(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if order == nil || !canMoveTo(order, models.OrderStatusComplete) {
+			continue
+		}
+		if err := s.orderService.UpdateStatus(ctx, orderID, models.OrderStatusComplete); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.checkRepo.GetByID(ctx, id)
+}
+
+func (s *CheckService) split(ctx context.Context, orders []*models.Order, tableID *uuid.UUID, req models.SplitRequest) ([]*models.Check, error) {
+	orderIDs := make([]uuid.UUID, 0, len(orders))
+	var total int64
+	for _, order := range orders {
+		if order.Status == models.OrderStatusComplete || order.Status == models.OrderStatusCanceled {
+			return nil, fmt.Errorf("%w: order %s is %s", ErrConflict, order.ID, order.Status)
+		}
+
+		existing, err := s.checkRepo.GetByOrderID(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, check := range existing {
+			if check.Status == models.CheckStatusPaid {
+				return nil, fmt.Errorf("%w: order %s already has paid checks", ErrConflict, order.ID)
+			}
+		}
+
+		orderIDs = append(orderIDs, order.ID)
+		total += toCents(order.TotalAmount)
+	}
+
+	var (
+		checks []*models.Check
+		err    error
+	)
+	switch req.Type {
+	case models.SplitByItem:
+		checks, err = splitByItem(orders, req.Checks)
+		applyDiscounts(checks, total)
+	case models.SplitBySeat:
+		checks, err = splitBySeat(orders)
+		applyDiscounts(checks, total)
+	case models.SplitEvenly:
+		checks, err = splitEvenly(total, req.Parts)
+	case models.SplitByAmount:
+		checks, err = splitByAmount(total, req.Amounts)
+	default:
+		return nil, fmt.Errorf("%w: unknown split type %q", ErrInvalidInput, req.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, check := range checks {
+		check.RestaurantID = orders[0].RestaurantID
+		check.TableID = tableID
+		check.SplitType = req.Type
+		check.OrderIDs = orderIDs
+	}
+
+	if err := s.checkRepo.ReplaceForOrders(ctx, orderIDs, checks); err != nil {
+		if errors.Is(err, repository.ErrCheckPaid) {
+			return nil, fmt.Errorf("%w: the bill already has payments against its checks", ErrConflict)
+		}
+		return nil, err
+	}
+
+	return checks, nil
+}
+
+func splitByItem(orders []*models.Order, details []models.SplitCheckDetail) ([]*models.Check, error) {
+	if len(details) == 0 {
+		return nil, fmt.Errorf("%w: at least one check is required", ErrInvalidInput)
+	}
+
+	items := make(map[uuid.UUID]models.OrderItem)
+	remaining := make(map[uuid.UUID]int)
+	for _, order := range orders {
+		for _, item := range order.Items {
+			items[item.ID] = item
+			remaining[item.ID] = item.ActiveQuantity()
+		}
+	}
+
+	checks := make([]*models.Check, 0, len(details))
+	for i, detail := range details {
+		check := &models.Check{Label: detail.Label}
+		if check.Label == "" {
+			check.Label = fmt.Sprintf("Check %d", i+1)
+		}
+
+		var amount int64
+		for _, assignment := range detail.Items {
+			item, ok := items[assignment.OrderItemID]
+			if !ok {
+				return nil, fmt.Errorf("%w: order item %s is not on the bill", ErrInvalidInput, assignment.OrderItemID)
+			}
+
+			quantity := assignment.Quantity
+			if quantity == 0 {
+				quantity = remaining[item.ID]
+			}
+			if quantity <= 0 || quantity > remaining[item.ID] {
+				return nil, fmt.Errorf("%w: invalid quantity for order item %s", ErrInvalidInput, item.ID)
+			}
+			remaining[item.ID] -= quantity
+
+			itemAmount := toCents(item.Price) * int64(quantity)
+			amount += itemAmount
+			check.Items = append(check.Items, models.CheckItem{
+				OrderItemID: item.ID,
+				Quantity:    quantity,
+				Amount:      fromCents(itemAmount),
+			})
+		}
+
+		check.Amount = fromCents(amount)
+		checks = append(checks, check)
+	}
+
+	for id, quantity := range remaining {
+		if quantity > 0 {
+			return nil, fmt.Errorf("%w: order item %s is not fully assigned", ErrInvalidInput, id)
+		}
+	}
+
+	return checks, nil
+}
+
+func splitBySeat(orders []*models.Order) ([]*models.Check, error) {
+	const shared = -1
+
+	bySeat := make(map[int]*models.Check)
+	amounts := make(map[int]int64)
+	for _, order := range orders {
+		for _, item := range order.Items {
+			if item.ActiveQuantity() == 0 {
+				continue
+			}
+
+			seat := shared
+			if item.Seat != nil {
+				seat = *item.Seat
+			}
+
+			check, ok := bySeat[seat]
+			if !ok {
+				check = &models.Check{Label: "Shared"}
+				if seat != shared {
+					check.Label = fmt.Sprintf("Seat %d", seat)
+				}
+				bySeat[seat] = check
+			}
+
+			itemAmount := toCents(item.Price) * int64(item.ActiveQuantity())
+			amounts[seat] += itemAmount
+			check.Items = append(check.Items, models.CheckItem{
+				OrderItemID: item.ID,
+				Quantity:    item.ActiveQuantity(),
+				Amount:      fromCents(itemAmount),
+			})
+		}
+	}
+
+	if len(bySeat) == 0 {
+		return nil, fmt.Errorf("%w: there are no items to split", ErrInvalidInput)
+	}
+
+	seats := make([]int, 0, len(bySeat))
+	for seat := range bySeat {
+		seats = append(seats, seat)
+	}
+	sort.Ints(seats)
+
+	checks := make([]*models.Check, 0, len(seats))
+	for _, seat := range seats {
+		check := bySeat[seat]
+		check.Amount = fromCents(amounts[seat])
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// applyDiscounts scales checks priced at item prices down to the
+// discounted bill total, with any rounding remainder on the last check.
+func applyDiscounts(checks []*models.Check, total int64) {
+	var gross int64
+	for _, check := range checks {
+		gross += toCents(check.Amount)
+	}
+	if gross == 0 || gross == total {
+		return
+	}
+
+	remaining := total
+	for i, check := range checks {
+		amount := remaining
+		if i < len(checks)-1 {
+			amount = int64(math.Round(float64(toCents(check.Amount)) * float64(total) / float64(gross)))
+		}
+		remaining -= amount
+		check.Amount = fromCents(amount)
+	}
+}
+
+func splitEvenly(total int64, parts int) ([]*models.Check, error) {
+	if parts <= 0 {
+		return nil, fmt.Errorf("%w: parts must be greater than 0", ErrInvalidInput)
+	}
+
+	// Hand out the leftover cents one by one so the parts add up to the total.
+	share := total / int64(parts)
+	remainder := total % int64(parts)
+
+	checks := make([]*models.Check, 0, parts)
+	for i := 0; i < parts; i++ {
+		amount := share
+		if int64(i) < remainder {
+			amount++
+		}
+		checks = append(checks, &models.Check{
+			Label:  fmt.Sprintf("Check %d", i+1),
+			Amount: fromCents(amount),
+		})
+	}
+
+	return checks, nil
+}
+
+func splitByAmount(total int64, amounts []float64) ([]*models.Check, error) {
+	if len(amounts) == 0 {
+		return nil, fmt.Errorf("%w: at least one amount is required", ErrInvalidInput)
+	}
+
+	var sum int64
+	checks := make([]*models.Check, 0, len(amounts))
+	for i, amount := range amounts {
+		cents := toCents(amount)
+		if cents <= 0 {
+			return nil, fmt.Errorf("%w: amounts must be greater than 0", ErrInvalidInput)
+		}
+		sum += cents
+		checks = append(checks, &models.Check{
+			Label:  fmt.Sprintf("Check %d", i+1),
+			Amount: fromCents(cents),
+		})
+	}
+
+	if sum != total {
+		return nil, fmt.Errorf("%w: amounts add up to %.2f, expected %.2f", ErrInvalidInput, fromCents(sum), fromCents(total))
+	}
+
+	return checks, nil
+}
+
+func allPaid(checks []*models.Check) bool {
+	for _, check := range checks {
+		if check.Status != models.CheckStatusPaid {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+func checkAmounts(checks []*models.Check) []float64 {
+	amounts := make([]float64, len(checks))
+	for i, check := range checks {
+		amounts[i] = check.Amount
+	}
+	return amounts
+}
+
+func equalAmounts(got, want []float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSplitEvenly(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int64
+		parts int
+		want  []float64
+		err   error
+	}{
+		{"even", 3000, 3, []float64{10, 10, 10}, nil},
+		{"leftover cents go first", 1000, 3, []float64{3.34, 3.33, 3.33}, nil},
+		{"one part", 1999, 1, []float64{19.99}, nil},
+		{"no parts", 1000, 0, nil, ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := splitEvenly(tt.total, tt.parts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("splitEvenly() error = %v, want %v", err, tt.err)
+			}
+			if got := checkAmounts(checks); !equalAmounts(got, tt.want) {
+				t.Errorf("splitEvenly() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitByAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		amounts []float64
+		want    []float64
+		err     error
+	}{
+		{"adds up", 2550, []float64{20, 5.5}, []float64{20, 5.5}, nil},
+		{"float amounts add up in cents", 30, []float64{0.1, 0.2}, []float64{0.1, 0.2}, nil},
+		{"short of the total", 2550, []float64{20, 5}, nil, ErrInvalidInput},
+		{"zero amount", 2000, []float64{20, 0}, nil, ErrInvalidInput},
+		{"no amounts", 2000, nil, nil, ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := splitByAmount(tt.total, tt.amounts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("splitByAmount() error = %v, want %v", err, tt.err)
+			}
+			if got := checkAmounts(checks); !equalAmounts(got, tt.want) {
+				t.Errorf("splitByAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitByItem(t *testing.T) {
+	burger := models.OrderItem{ID: uuid.New(), Quantity: 2, Price: 12.5}
+	soda := models.OrderItem{ID: uuid.New(), Quantity: 3, Price: 2, VoidedQuantity: 1}
+	orders := []*models.Order{{Items: []models.OrderItem{burger, soda}}}
+
+	tests := []struct {
+		name    string
+		details []models.SplitCheckDetail
+		want    []float64
+		err     error
+	}{
+		{
+			name: "by quantity",
+			details: []models.SplitCheckDetail{
+				{Items: []models.SplitItemDetail{{OrderItemID: burger.ID, Quantity: 1}, {OrderItemID: soda.ID, Quantity: 1}}},
+				{Items: []models.SplitItemDetail{{OrderItemID: burger.ID, Quantity: 1}, {OrderItemID: soda.ID, Quantity: 1}}},
+			},
+			want: []float64{14.5, 14.5},
+		},
+		{
+			name: "quantity defaults to what is left",
+			details: []models.SplitCheckDetail{
+				{Items: []models.SplitItemDetail{{OrderItemID: burger.ID}}},
+				{Items: []models.SplitItemDetail{{OrderItemID: soda.ID}}},
+			},
+			want: []float64{25, 4},
+		},
+		{
+			name: "voided quantity cannot be assigned",
+			details: []models.SplitCheckDetail{
+				{Items: []models.SplitItemDetail{{OrderItemID: burger.ID}, {OrderItemID: soda.ID, Quantity: 3}}},
+			},
+			err: ErrInvalidInput,
+		},
+		{
+			name: "every item must be assigned",
+			details: []models.SplitCheckDetail{
+				{Items: []models.SplitItemDetail{{OrderItemID: burger.ID}}},
+			},
+			err: ErrInvalidInput,
+		},
+		{
+			name: "item not on the bill",
+			details: []models.SplitCheckDetail{
+				{Items: []models.SplitItemDetail{{OrderItemID: uuid.New()}}},
+			},
+			err: ErrInvalidInput,
+		},
+		{name: "no checks", err: ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks, err := splitByItem(orders, tt.details)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("splitByItem() error = %v, want %v", err, tt.err)
+			}
+			if got := checkAmounts(checks); !equalAmounts(got, tt.want) {
+				t.Errorf("splitByItem() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitBySeat(t *testing.T) {
+	one, two := 1, 2
+	orders := []*models.Order{
+		{Items: []models.OrderItem{
+			{ID: uuid.New(), Quantity: 1, Price: 10, Seat: &two},
+			{ID: uuid.New(), Quantity: 2, Price: 4},
+		}},
+		{Items: []models.OrderItem{
+			{ID: uuid.New(), Quantity: 1, Price: 7.25, Seat: &one},
+			{ID: uuid.New(), Quantity: 1, Price: 3, Seat: &one, VoidedQuantity: 1},
+		}},
+	}
+
+	checks, err := splitBySeat(orders)
+	if err != nil {
+		t.Fatalf("splitBySeat() error = %v", err)
+	}
+	labels := make([]string, len(checks))
+	for i, check := range checks {
+		labels[i] = check.Label
+	}
+	if len(labels) != 3 || labels[0] != "Shared" || labels[1] != "Seat 1" || labels[2] != "Seat 2" {
+		t.Errorf("labels = %v, want [Shared Seat 1 Seat 2]", labels)
+	}
+	if got, want := checkAmounts(checks), []float64{8, 7.25, 10}; !equalAmounts(got, want) {
+		t.Errorf("amounts = %v, want %v", got, want)
+	}
+
+	voided := []*models.Order{{Items: []models.OrderItem{{ID: uuid.New(), Quantity: 1, Price: 5, VoidedQuantity: 1}}}}
+	if _, err := splitBySeat(voided); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("splitBySeat() with nothing left error = %v, want %v", err, ErrInvalidInput)
+	}
+}
+
+func TestApplyDiscounts(t *testing.T) {
+	checks := []*models.Check{{Amount: 10}, {Amount: 10}, {Amount: 10}}
+	applyDiscounts(checks, 2000)
+	if got, want := checkAmounts(checks), []float64{6.67, 6.67, 6.66}; !equalAmounts(got, want) {
+		t.Errorf("applyDiscounts() = %v, want %v", got, want)
+	}
+}
+
+// fakeOrderRepo keeps orders in memory. Methods a test does not expect to
+// be called panic through the nil embedded interface.
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	orders  map[uuid.UUID]*models.Order
+	changes []models.OrderStatusChange
+	// beforeUpdate runs before UpdateStatus, as another request would.
+	beforeUpdate func()
+}
+
+func newFakeOrderRepo(orders ...*models.Order) *fakeOrderRepo {
+	r := &fakeOrderRepo{orders: make(map[uuid.UUID]*models.Order)}
+	for _, order := range orders {
+		r.orders[order.ID] = order
+	}
+	return r
+}
+
+func (r *fakeOrderRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *order
+	copied.Items = append([]models.OrderItem(nil), order.Items...)
+	return &copied, nil
+}
+
+func (r *fakeOrderRepo) UpdateStatus(ctx context.Context, id uuid.UUID, change models.OrderStatusChange) error {
+	if r.beforeUpdate != nil {
+		r.beforeUpdate()
+	}
+	order := r.orders[id]
+	if order.Status != change.From {
+		return sql.ErrNoRows
+	}
+	order.Status = change.To
+	r.changes = append(r.changes, change)
+	return nil
+}
+
+func (r *fakeOrderRepo) UpdatePaymentStatus(ctx context.Context, id uuid.UUID, status models.OrderPaymentStatus) error {
+	r.orders[id].PaymentStatus = status
+	return nil
+}
+
+// fakeCheckRepo keeps checks in memory. replaceErr makes ReplaceForOrders
+// fail, as it does when a check was paid after the split read them.
+type fakeCheckRepo struct {
+	repository.CheckRepository
+	checks     []*models.Check
+	replaceErr error
+}
+
+func (r *fakeCheckRepo) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.Check, error) {
+	var checks []*models.Check
+	for _, check := range r.checks {
+		if containsID(check.OrderIDs, orderID) {
+			checks = append(checks, check)
+		}
+	}
+	return checks, nil
+}
+
+func (r *fakeCheckRepo) ReplaceForOrders(ctx context.Context, orderIDs []uuid.UUID, checks []*models.Check) error {
+	if r.replaceErr != nil {
+		return r.replaceErr
+	}
+	r.checks = checks
+	return nil
+}
+
+func TestSplitKeepsPaidChecks(t *testing.T) {
+	order := &models.Order{ID: uuid.New(), Status: models.OrderStatusAccepted, TotalAmount: 30}
+	paid := &models.Check{ID: uuid.New(), OrderIDs: []uuid.UUID{order.ID}, Amount: 15, Status: models.CheckStatusPaid}
+	open := &models.Check{ID: uuid.New(), OrderIDs: []uuid.UUID{order.ID}, Amount: 15, Status: models.CheckStatusOpen}
+
+	tests := []struct {
+		name  string
+		repo  *fakeCheckRepo
+		want  error
+		count int
+	}{
+		{"open checks are replaced", &fakeCheckRepo{checks: []*models.Check{open}}, nil, 3},
+		{"paid check", &fakeCheckRepo{checks: []*models.Check{paid, open}}, ErrConflict, 0},
+		{"paid or paid against meanwhile", &fakeCheckRepo{checks: []*models.Check{open}, replaceErr: repository.ErrCheckPaid}, ErrConflict, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewCheckService(tt.repo, newFakeOrderRepo(order), nil, nil)
+
+			checks, err := s.SplitOrder(context.Background(), order.ID, models.SplitRequest{Type: models.SplitEvenly, Parts: 3})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("SplitOrder() error = %v, want %v", err, tt.want)
+			}
+			if len(checks) != tt.count {
+				t.Errorf("SplitOrder() made %d checks, want %d", len(checks), tt.count)
+			}
+		})
+	}
+}
+
+type fakeTableRepo struct {
+	repository.TableRepository
+	tables map[uuid.UUID]*models.Table
+}
+
+func (r *fakeTableRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Table, error) {
+	return r.tables[id], nil
+}
+
+func TestSplitTableChecksRestaurant(t *testing.T) {
+	restaurantID := uuid.New()
+	table := &models.Table{ID: uuid.New(), RestaurantID: restaurantID}
+	tables := &fakeTableRepo{tables: map[uuid.UUID]*models.Table{table.ID: table}}
+	s := NewCheckService(&fakeCheckRepo{}, newFakeOrderRepo(), tables, nil)
+
+	tests := []struct {
+		name         string
+		restaurantID uuid.UUID
+		tableID      uuid.UUID
+	}{
+		{"table of another restaurant", uuid.New(), table.ID},
+		{"unknown table", restaurantID, uuid.New()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SplitTable(context.Background(), tt.restaurantID, tt.tableID, models.SplitRequest{Type: models.SplitEvenly, Parts: 2})
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("SplitTable() error = %v, want %v", err, ErrNotFound)
+			}
+		})
+	}
+}
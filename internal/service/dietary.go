@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type DietaryService struct {
+	profileRepo repository.AllergyProfileRepository
+	menuRepo    repository.MenuRepository
+}
+
+func NewDietaryService(profileRepo repository.AllergyProfileRepository, menuRepo repository.MenuRepository) *DietaryService {
+	return &DietaryService{
+		profileRepo: profileRepo,
+		menuRepo:    menuRepo,
+	}
+}
+
+// GetProfile returns the user's allergy profile, which is empty until they
+// save one.
+func (s *DietaryService) GetProfile(ctx context.Context, userID uuid.UUID) (*models.AllergyProfile, error) {
+	profile, err := s.profileRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		profile = &models.AllergyProfile{
+			UserID:      userID,
+			Allergens:   []models.Allergen{},
+			DietaryTags: []models.DietaryTag{},
+		}
+	}
+	return profile, nil
+}
+
+func (s *DietaryService) SaveProfile(ctx context.Context, profile *models.AllergyProfile) error {
+	var err error
+	if profile.Allergens, err = validateAllergens(profile.Allergens); err != nil {
+		return err
+	}
+	if profile.DietaryTags, err = validateDietaryTags(profile.DietaryTags); err != nil {
+		return err
+	}
+	return s.profileRepo.Save(ctx, profile)
+}
+
+// OrderWarnings checks the items of an order against the guest's allergy
+// profile. Conflicts do not block the order; the guest is warned instead.
+func (s *DietaryService) OrderWarnings(ctx context.Context, order *models.Order) ([]models.OrderWarning, error) {
+	if order.UserID == uuid.Nil {
+		return nil, nil
+	}
+
+	profile, err := s.profileRepo.Get(ctx, order.UserID)
+	if err != nil || profile == nil {
+		return nil, err
+	}
+
+	// Bundle components are checked like any other item
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, item := range order.Items {
+		for _, id := range append([]uuid.UUID{item.MenuItemID}, componentIDs(item)...) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	var warnings []models.OrderWarning
+	for _, id := range ids {
+		menuItem, err := s.menuRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if menuItem == nil {
+			continue
+		}
+
+		if conflicts := dietaryConflicts(profile, menuItem); len(conflicts) > 0 {
+			warnings = append(warnings, models.OrderWarning{
+				MenuItemID: &menuItem.ID,
+				Message:    fmt.Sprintf("%s %s", menuItem.Name, strings.Join(conflicts, " and ")),
+			})
+		}
+	}
+
+	return warnings, nil
+}
+
+func componentIDs(item models.OrderItem) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(item.Components))
+	for _, component := range item.Components {
+		ids = append(ids, component.MenuItemID)
+	}
+	return ids
+}
+
+// dietaryConflicts describes how a menu item clashes with a profile.
+func dietaryConflicts(profile *models.AllergyProfile, item *models.MenuItem) []string {
+	var contains []string
+	for _, allergen := range profile.Allergens {
+		if containsAllergen(item.Allergens, allergen) {
+			contains = append(contains, string(allergen))
+		}
+	}
+
+	var missing []string
+	for _, tag := range profile.DietaryTags {
+		if !hasDietaryTag(item.DietaryTags, tag) {
+			missing = append(missing, string(tag))
+		}
+	}
+
+	var conflicts []string
+	if len(contains) > 0 {
+		conflicts = append(conflicts, "contains "+strings.Join(contains, ", "))
+	}
+	if len(missing) > 0 {
+		conflicts = append(conflicts, "is not marked "+strings.Join(missing, ", "))
+	}
+	return conflicts
+}
+
+func hasDietaryTag(tags []models.DietaryTag, tag models.DietaryTag) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDietary checks the allergens, dietary tags and nutrition facts
+// of a menu item.
+func validateDietary(item *models.MenuItem) error {
+	var err error
+	if item.Allergens, err = validateAllergens(item.Allergens); err != nil {
+		return err
+	}
+	if item.DietaryTags, err = validateDietaryTags(item.DietaryTags); err != nil {
+		return err
+	}
+
+	if n := item.Nutrition; n != nil {
+		if n.Calories < 0 || n.Fat < 0 || n.SaturatedFat < 0 || n.Carbohydrates < 0 ||
+			n.Sugars < 0 || n.Protein < 0 || n.Salt < 0 {
+			return fmt.Errorf("%w: nutrition facts cannot be negative", ErrInvalidInput)
+		}
+	}
+
+	return nil
+}
+
+// validateAllergens rejects unknown allergens and drops duplicates.
+func validateAllergens(allergens []models.Allergen) ([]models.Allergen, error) {
+	out := []models.Allergen{}
+	for _, allergen := range allergens {
+		if !models.ValidAllergen(allergen) {
+			return nil, fmt.Errorf("%w: unknown allergen %q", ErrInvalidInput, allergen)
+		}
+		if !containsAllergen(out, allergen) {
+			out = append(out, allergen)
+		}
+	}
+	return out, nil
+}
+
+// validateDietaryTags rejects unknown tags and drops duplicates.
+func validateDietaryTags(tags []models.DietaryTag) ([]models.DietaryTag, error) {
+	out := []models.DietaryTag{}
+	for _, tag := range tags {
+		if !models.ValidDietaryTag(tag) {
+			return nil, fmt.Errorf("%w: unknown dietary tag %q", ErrInvalidInput, tag)
+		}
+		if !hasDietaryTag(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out, nil
+}
+
+func containsAllergen(allergens []models.Allergen, allergen models.Allergen) bool {
+	for _, a := range allergens {
+		if a == allergen {
+			return true
+		}
+	}
+	return false
+}
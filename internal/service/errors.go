@@ -0,0 +1,12 @@
+package service
+
+import "errors"
+
+// Sentinel errors returned by services so handlers can map them to HTTP
+// status codes. Wrap them with fmt.Errorf("%w: ...") to add detail.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
+)
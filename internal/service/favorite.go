@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type FavoriteService struct {
+	favoriteRepo repository.FavoriteRepository
+	menuRepo     repository.MenuRepository
+}
+
+func NewFavoriteService(favoriteRepo repository.FavoriteRepository, menuRepo repository.MenuRepository) *FavoriteService {
+	return &FavoriteService{
+		favoriteRepo: favoriteRepo,
+		menuRepo:     menuRepo,
+	}
+}
+
+func (s *FavoriteService) Add(ctx context.Context, userID, menuItemID uuid.UUID) (*models.Favorite, error) {
+	item, err := s.menuRepo.GetByID(ctx, menuItemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("%w: menu item %s", ErrNotFound, menuItemID)
+	}
+
+	favorite := &models.Favorite{UserID: userID, MenuItemID: menuItemID, MenuItem: item}
+	if err := s.favoriteRepo.Add(ctx, favorite); err != nil {
+		return nil, err
+	}
+
+	return favorite, nil
+}
+
+func (s *FavoriteService) Remove(ctx context.Context, userID, menuItemID uuid.UUID) error {
+	if err := s.favoriteRepo.Remove(ctx, userID, menuItemID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: menu item %s is not a favorite", ErrNotFound, menuItemID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FavoriteService) List(ctx context.Context, userID uuid.UUID) ([]*models.Favorite, error) {
+	return s.favoriteRepo.ListByUser(ctx, userID)
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// giftCardAlphabet leaves out characters that are easily confused when a
+// code is read out or typed in, such as 0/O and 1/I.
+const (
+	giftCardAlphabet   = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	giftCardCodeLength = 16
+)
+
+type GiftCardService struct {
+	giftCardRepo   repository.GiftCardRepository
+	orderRepo      repository.OrderRepository
+	restaurantRepo repository.RestaurantRepository
+}
+
+func NewGiftCardService(giftCardRepo repository.GiftCardRepository, orderRepo repository.OrderRepository, restaurantRepo repository.RestaurantRepository) *GiftCardService {
+	return &GiftCardService{
+		giftCardRepo:   giftCardRepo,
+		orderRepo:      orderRepo,
+		restaurantRepo: restaurantRepo,
+	}
+}
+
+// Issue sells a new gift card loaded with the requested amount.
+func (s *GiftCardService) Issue(ctx context.Context, req models.GiftCardRequest, user *models.AuthUser) (*models.GiftCard, error) {
+	if toCents(req.Amount) <= 0 {
+		return nil, fmt.Errorf("%w: amount must be greater than 0", ErrInvalidInput)
+	}
+
+	code, err := generateGiftCardCode()
+	if err != nil {
+		return nil, err
+	}
+
+	card := &models.GiftCard{
+		Code:           code,
+		InitialBalance: fromCents(toCents(req.Amount)),
+		RestaurantID:   req.RestaurantID,
+	}
+	issue := &models.GiftCardTransaction{
+		Type:         models.GiftCardIssue,
+		RestaurantID: req.RestaurantID,
+		Note:         req.Note,
+		CreatedBy:    &user.ID,
+	}
+	if err := s.giftCardRepo.Create(ctx, card, issue); err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// Balance looks up the remaining balance of a card by its code.
+func (s *GiftCardService) Balance(ctx context.Context, code string) (*models.GiftCardBalance, error) {
+	card, err := s.getByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GiftCardBalance{Code: card.Code, Balance: card.Balance}, nil
+}
+
+// Statement returns a card with its ledger and checks that the ledger adds
+// up to the card balance.
+func (s *GiftCardService) Statement(ctx context.Context, code string) (*models.GiftCardStatement, error) {
+	card, err := s.getByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.giftCardRepo.ListTransactions(ctx, card.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sum int64
+	for _, entry := range entries {
+		sum += toCents(entry.Amount)
+	}
+
+	return &models.GiftCardStatement{
+		Card:          card,
+		Transactions:  entries,
+		LedgerBalance: fromCents(sum),
+		Reconciled:    sum == toCents(card.Balance),
+	}, nil
+}
+
+// Load adds value to an existing card. Chain-wide cards can only be loaded
+// by those who may issue them.
+func (s *GiftCardService) Load(ctx context.Context, code string, req models.GiftCardRequest, user *models.AuthUser) (*models.GiftCardTransaction, error) {
+	if toCents(req.Amount) <= 0 {
+		return nil, fmt.Errorf("%w: amount must be greater than 0", ErrInvalidInput)
+	}
+
+	card, err := s.getByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.GiftCardTransaction{
+		GiftCardID:   card.ID,
+		Type:         models.GiftCardLoad,
+		Amount:       fromCents(toCents(req.Amount)),
+		RestaurantID: req.RestaurantID,
+		Note:         req.Note,
+		CreatedBy:    &user.ID,
+	}
+	if err := s.giftCardRepo.AppendTransaction(ctx, entry, false); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Redeem takes up to amount off a card for an order payment and returns
+// the amount taken. A card sold by a restaurant can only pay for orders at
+// the restaurants of the same organization or brand.
+func (s *GiftCardService) Redeem(ctx context.Context, code string, amount float64, orderID, paymentID uuid.UUID) (float64, error) {
+	card, err := s.getByCode(ctx, code)
+	if err != nil {
+		return 0, err
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return 0, err
+	}
+	if order == nil {
+		return 0, fmt.Errorf("%w: order %s", ErrNotFound, orderID)
+	}
+	if err := s.checkRedeemableAt(ctx, card, order.RestaurantID); err != nil {
+		return 0, err
+	}
+
+	entry := &models.GiftCardTransaction{
+		GiftCardID:   card.ID,
+		Type:         models.GiftCardRedeem,
+		Amount:       -fromCents(toCents(amount)),
+		OrderID:      &order.ID,
+		PaymentID:    &paymentID,
+		RestaurantID: &order.RestaurantID,
+	}
+	if err := s.giftCardRepo.AppendTransaction(ctx, entry, true); err != nil {
+		if errors.Is(err, repository.ErrInsufficientBalance) {
+			return 0, fmt.Errorf("%w: gift card has no balance left", ErrConflict)
+		}
+		return 0, err
+	}
+
+	return -entry.Amount, nil
+}
+
+// RefundPayment credits part of a gift card payment back to the card it
+// was redeemed from.
+func (s *GiftCardService) RefundPayment(ctx context.Context, paymentID uuid.UUID, amount float64) error {
+	entries, err := s.giftCardRepo.ListTransactionsByPayment(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		redemption *models.GiftCardTransaction
+		net        int64
+	)
+	for _, entry := range entries {
+		if entry.Type == models.GiftCardRedeem {
+			redemption = entry
+		}
+		net += toCents(entry.Amount)
+	}
+	if redemption == nil {
+		return fmt.Errorf("%w: no gift card redemption for payment %s", ErrNotFound, paymentID)
+	}
+
+	cents := toCents(amount)
+	if cents <= 0 || cents > -net {
+		return fmt.Errorf("%w: refund amount must be between 0 and %.2f", ErrInvalidInput, fromCents(-net))
+	}
+
+	entry := &models.GiftCardTransaction{
+		GiftCardID:   redemption.GiftCardID,
+		Type:         models.GiftCardRefund,
+		Amount:       fromCents(cents),
+		OrderID:      redemption.OrderID,
+		PaymentID:    &paymentID,
+		RestaurantID: redemption.RestaurantID,
+	}
+	return s.giftCardRepo.AppendTransaction(ctx, entry, false)
+}
+
+// checkRedeemableAt rejects cards sold by a restaurant of another
+// organization and brand than the one the order is at.
+func (s *GiftCardService) checkRedeemableAt(ctx context.Context, card *models.GiftCard, restaurantID uuid.UUID) error {
+	if card.RestaurantID == nil || *card.RestaurantID == restaurantID {
+		return nil
+	}
+
+	seller, err := s.restaurantRepo.GetByID(ctx, *card.RestaurantID)
+	if err != nil {
+		return err
+	}
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+	if seller != nil && restaurant != nil &&
+		(sameID(seller.OrganizationID, restaurant.OrganizationID) || sameID(seller.BrandID, restaurant.BrandID)) {
+		return nil
+	}
+	return fmt.Errorf("%w: gift card cannot be used at this restaurant", ErrInvalidInput)
+}
+
+// sameID reports whether both IDs are set and equal.
+func sameID(a, b *uuid.UUID) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+func (s *GiftCardService) getByCode(ctx context.Context, code string) (*models.GiftCard, error) {
+	code = normalizeGiftCardCode(code)
+	card, err := s.giftCardRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, fmt.Errorf("%w: gift card %s", ErrNotFound, code)
+	}
+	return card, nil
+}
+
+func generateGiftCardCode() (string, error) {
+	buf := make([]byte, giftCardCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = giftCardAlphabet[int(b)%len(giftCardAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// normalizeGiftCardCode accepts codes typed with spaces, dashes or in lower
+// case.
+func normalizeGiftCardCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestGiftCardRedeemableAt(t *testing.T) {
+	orgA, orgB, brand := uuid.New(), uuid.New(), uuid.New()
+	seller := &models.Restaurant{ID: uuid.New(), OrganizationID: &orgA}
+	sister := &models.Restaurant{ID: uuid.New(), OrganizationID: &orgA}
+	franchise := &models.Restaurant{ID: uuid.New(), OrganizationID: &orgB, BrandID: &brand}
+	brandSeller := &models.Restaurant{ID: uuid.New(), BrandID: &brand}
+	other := &models.Restaurant{ID: uuid.New(), OrganizationID: &orgB}
+	restaurants := fakeRestaurantRepo{restaurants: map[uuid.UUID]*models.Restaurant{}}
+	for _, r := range []*models.Restaurant{seller, sister, franchise, brandSeller, other} {
+		restaurants.restaurants[r.ID] = r
+	}
+	s := NewGiftCardService(nil, nil, restaurants)
+
+	tests := []struct {
+		name   string
+		soldAt *uuid.UUID
+		at     uuid.UUID
+		want   error
+	}{
+		{"chain-wide card", nil, other.ID, nil},
+		{"where it was sold", &seller.ID, seller.ID, nil},
+		{"same organization", &seller.ID, sister.ID, nil},
+		{"same brand", &brandSeller.ID, franchise.ID, nil},
+		{"another organization", &seller.ID, other.ID, ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := &models.GiftCard{ID: uuid.New(), RestaurantID: tt.soldAt}
+			if err := s.checkRedeemableAt(context.Background(), card, tt.at); !errors.Is(err, tt.want) {
+				t.Errorf("checkRedeemableAt() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
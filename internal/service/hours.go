@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// hoursLookaheadDays is how far ahead the next opening is looked for.
+const hoursLookaheadDays = 14
+
+const dateLayout = "2006-01-02"
+
+type HoursService struct {
+	hoursRepo      repository.HoursRepository
+	restaurantRepo repository.RestaurantRepository
+}
+
+func NewHoursService(hoursRepo repository.HoursRepository, restaurantRepo repository.RestaurantRepository) *HoursService {
+	return &HoursService{
+		hoursRepo:      hoursRepo,
+		restaurantRepo: restaurantRepo,
+	}
+}
+
+// Get returns the opening hours of a restaurant. A restaurant that has not
+// set them has none and is always open.
+func (s *HoursService) Get(ctx context.Context, restaurantID uuid.UUID) (*models.OpeningHours, error) {
+	if _, err := s.getRestaurant(ctx, restaurantID); err != nil {
+		return nil, err
+	}
+	hours, err := s.hoursRepo.Get(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if hours == nil {
+		hours = &models.OpeningHours{
+			RestaurantID: restaurantID,
+			Weekly:       []models.OpeningInterval{},
+			Exceptions:   []models.HoursException{},
+		}
+	}
+	return hours, nil
+}
+
+// Save replaces the opening hours of a restaurant.
+func (s *HoursService) Save(ctx context.Context, hours *models.OpeningHours) error {
+	if _, err := s.getRestaurant(ctx, hours.RestaurantID); err != nil {
+		return err
+	}
+	if err := validateHours(hours); err != nil {
+		return err
+	}
+	return s.hoursRepo.Save(ctx, hours)
+}
+
+// Status returns whether the restaurant is open at the given time and, if
+// it is not, when it next opens.
+func (s *HoursService) Status(ctx context.Context, restaurantID uuid.UUID, at time.Time) (models.OpenStatus, error) {
+	restaurant, err := s.getRestaurant(ctx, restaurantID)
+	if err != nil {
+		return models.OpenStatus{}, err
+	}
+	hours, err := s.hoursRepo.Get(ctx, restaurantID)
+	if err != nil {
+		return models.OpenStatus{}, err
+	}
+	return openStatus(hours, restaurantLocation(restaurant), at), nil
+}
+
+// Annotate sets whether each restaurant is open at the given time and when
+// the closed ones next open.
+func (s *HoursService) Annotate(ctx context.Context, restaurants []*models.Restaurant, at time.Time) error {
+	if len(restaurants) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(restaurants))
+	for i, restaurant := range restaurants {
+		ids[i] = restaurant.ID
+	}
+	hours, err := s.hoursRepo.ListByRestaurants(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, restaurant := range restaurants {
+		status := openStatus(hours[restaurant.ID], restaurantLocation(restaurant), at)
+		restaurant.IsOpen = &status.IsOpen
+		restaurant.NextOpenAt = status.NextOpenAt
+	}
+	return nil
+}
+
+func (s *HoursService) getRestaurant(ctx context.Context, restaurantID uuid.UUID) (*models.Restaurant, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if restaurant == nil {
+		return nil, fmt.Errorf("%w: restaurant %s", ErrNotFound, restaurantID)
+	}
+	return restaurant, nil
+}
+
+// openStatus works out the status from the intervals of the days around
+// the given time. An interval belongs to the day it opens on, so one from
+// the day before may still be running, and an exception replaces only the
+// intervals that open on its date.
+func openStatus(hours *models.OpeningHours, loc *time.Location, at time.Time) models.OpenStatus {
+	if hours == nil || (len(hours.Weekly) == 0 && len(hours.Exceptions) == 0) {
+		return models.OpenStatus{IsOpen: true}
+	}
+
+	exceptions := make(map[string][]models.TimeRange, len(hours.Exceptions))
+	for _, exception := range hours.Exceptions {
+		exceptions[exception.Date] = exception.Intervals
+	}
+
+	local := at.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	var next *time.Time
+	for offset := -1; offset <= hoursLookaheadDays; offset++ {
+		day := today.AddDate(0, 0, offset)
+		ranges, ok := exceptions[day.Format(dateLayout)]
+		if !ok {
+			ranges = weeklyRanges(hours.Weekly, day.Weekday())
+		}
+
+		for _, r := range ranges {
+			opens, closes, err := intervalOn(day, r)
+			if err != nil {
+				continue
+			}
+			if !at.Before(opens) && at.Before(closes) {
+				return models.OpenStatus{IsOpen: true}
+			}
+			if opens.After(at) && (next == nil || opens.Before(*next)) {
+				next = &opens
+			}
+		}
+	}
+
+	return models.OpenStatus{NextOpenAt: next}
+}
+
+func weeklyRanges(weekly []models.OpeningInterval, weekday time.Weekday) []models.TimeRange {
+	var ranges []models.TimeRange
+	for _, interval := range weekly {
+		if interval.DayOfWeek == int(weekday) {
+			ranges = append(ranges, interval.TimeRange)
+		}
+	}
+	return ranges
+}
+
+// intervalOn returns when a time range opening on the given local day
+// starts and ends. A range that closes at or before it opens ends on the
+// next day.
+func intervalOn(day time.Time, r models.TimeRange) (time.Time, time.Time, error) {
+	start, err := parseClock(r.Opens)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseClock(r.Closes)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	opens := time.Date(day.Year(), day.Month(), day.Day(), start/60, start%60, 0, 0, day.Location())
+	closes := time.Date(day.Year(), day.Month(), day.Day(), end/60, end%60, 0, 0, day.Location())
+	if end <= start {
+		closes = time.Date(day.Year(), day.Month(), day.Day()+1, end/60, end%60, 0, 0, day.Location())
+	}
+	return opens, closes, nil
+}
+
+func validateHours(hours *models.OpeningHours) error {
+	if hours.Weekly == nil {
+		hours.Weekly = []models.OpeningInterval{}
+	}
+	if hours.Exceptions == nil {
+		hours.Exceptions = []models.HoursException{}
+	}
+
+	byDay := map[int][]models.TimeRange{}
+	for _, interval := range hours.Weekly {
+		if interval.DayOfWeek < 0 || interval.DayOfWeek > 6 {
+			return fmt.Errorf("%w: days of week run from 0 (Sunday) to 6", ErrInvalidInput)
+		}
+		byDay[interval.DayOfWeek] = append(byDay[interval.DayOfWeek], interval.TimeRange)
+	}
+	for day, ranges := range byDay {
+		if err := validateTimeRanges(ranges); err != nil {
+			return fmt.Errorf("%w on %s", err, time.Weekday(day))
+		}
+	}
+	sort.SliceStable(hours.Weekly, func(i, j int) bool {
+		a, b := hours.Weekly[i], hours.Weekly[j]
+		if a.DayOfWeek != b.DayOfWeek {
+			return a.DayOfWeek < b.DayOfWeek
+		}
+		return a.Opens < b.Opens
+	})
+
+	seen := map[string]bool{}
+	for i := range hours.Exceptions {
+		exception := &hours.Exceptions[i]
+		date, err := time.Parse(dateLayout, exception.Date)
+		if err != nil {
+			return fmt.Errorf("%w: invalid date %q, expected YYYY-MM-DD", ErrInvalidInput, exception.Date)
+		}
+		exception.Date = date.Format(dateLayout)
+		if seen[exception.Date] {
+			return fmt.Errorf("%w: more than one exception on %s", ErrInvalidInput, exception.Date)
+		}
+		seen[exception.Date] = true
+
+		exception.Name = strings.TrimSpace(exception.Name)
+		if exception.Intervals == nil {
+			exception.Intervals = []models.TimeRange{}
+		}
+		if err := validateTimeRanges(exception.Intervals); err != nil {
+			return fmt.Errorf("%w on %s", err, exception.Date)
+		}
+	}
+	sort.Slice(hours.Exceptions, func(i, j int) bool {
+		return hours.Exceptions[i].Date < hours.Exceptions[j].Date
+	})
+
+	return nil
+}
+
+// validateTimeRanges checks the ranges of one day are valid times and do
+// not overlap. A range running past midnight may not take more than a day.
+func validateTimeRanges(ranges []models.TimeRange) error {
+	type span struct{ start, end int }
+	spans := make([]span, 0, len(ranges))
+	for _, r := range ranges {
+		start, err := parseClock(r.Opens)
+		if err != nil {
+			return err
+		}
+		end, err := parseClock(r.Closes)
+		if err != nil {
+			return err
+		}
+		if end <= start {
+			end += 24 * 60
+		}
+		spans = append(spans, span{start, end})
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].start < spans[i-1].end {
+			return fmt.Errorf("%w: opening intervals overlap", ErrInvalidInput)
+		}
+	}
+	return nil
+}
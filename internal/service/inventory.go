@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type InventoryService struct {
+	inventoryRepo     repository.InventoryRepository
+	menuRepo          repository.MenuRepository
+	customizationRepo repository.CustomizationRepository
+}
+
+func NewInventoryService(
+	inventoryRepo repository.InventoryRepository,
+	menuRepo repository.MenuRepository,
+	customizationRepo repository.CustomizationRepository,
+) *InventoryService {
+	return &InventoryService{
+		inventoryRepo:     inventoryRepo,
+		menuRepo:          menuRepo,
+		customizationRepo: customizationRepo,
+	}
+}
+
+// CreateIngredient adds an ingredient. Any starting stock is booked as an
+// opening count so the movement history adds up to the stock.
+func (s *InventoryService) CreateIngredient(ctx context.Context, ingredient *models.Ingredient, user *models.AuthUser) error {
+	if err := validateIngredient(ingredient); err != nil {
+		return err
+	}
+	if ingredient.Stock < 0 {
+		return fmt.Errorf("%w: stock cannot be negative", ErrInvalidInput)
+	}
+
+	opening := roundQuantity(ingredient.Stock)
+	ingredient.Stock = 0
+	if err := s.inventoryRepo.CreateIngredient(ctx, ingredient); err != nil {
+		return err
+	}
+	if opening == 0 {
+		return nil
+	}
+
+	movement := &models.StockMovement{
+		IngredientID: ingredient.ID,
+		RestaurantID: ingredient.RestaurantID,
+		Type:         models.StockCount,
+		StockAfter:   opening,
+		Note:         "opening stock",
+		CreatedBy:    &user.ID,
+	}
+	if err := s.inventoryRepo.RecordMovements(ctx, []*models.StockMovement{movement}); err != nil {
+		return err
+	}
+	ingredient.Stock = movement.StockAfter
+
+	return nil
+}
+
+// GetIngredient returns an ingredient of the restaurant.
+func (s *InventoryService) GetIngredient(ctx context.Context, restaurantID, id uuid.UUID) (*models.Ingredient, error) {
+	ingredient, err := s.inventoryRepo.GetIngredient(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ingredient == nil || ingredient.RestaurantID != restaurantID {
+		return nil, fmt.Errorf("%w: ingredient %s", ErrNotFound, id)
+	}
+	return ingredient, nil
+}
+
+func (s *InventoryService) ListIngredients(ctx context.Context, restaurantID uuid.UUID) ([]*models.Ingredient, error) {
+	return s.inventoryRepo.ListIngredients(ctx, restaurantID)
+}
+
+func (s *InventoryService) UpdateIngredient(ctx context.Context, ingredient *models.Ingredient) error {
+	if _, err := s.GetIngredient(ctx, ingredient.RestaurantID, ingredient.ID); err != nil {
+		return err
+	}
+	if err := validateIngredient(ingredient); err != nil {
+		return err
+	}
+	return s.inventoryRepo.UpdateIngredient(ctx, ingredient)
+}
+
+// DeleteIngredient removes an ingredient together with its recipe lines and
+// movement history.
+func (s *InventoryService) DeleteIngredient(ctx context.Context, restaurantID, id uuid.UUID) error {
+	if _, err := s.GetIngredient(ctx, restaurantID, id); err != nil {
+		return err
+	}
+	return s.inventoryRepo.DeleteIngredient(ctx, id)
+}
+
+// Adjust records a delivery, waste or stock count for an ingredient.
+func (s *InventoryService) Adjust(ctx context.Context, restaurantID, ingredientID uuid.UUID, req models.StockAdjustmentRequest, user *models.AuthUser) (*models.StockMovement, error) {
+	if _, err := s.GetIngredient(ctx, restaurantID, ingredientID); err != nil {
+		return nil, err
+	}
+
+	movement := &models.StockMovement{
+		IngredientID: ingredientID,
+		RestaurantID: restaurantID,
+		Type:         req.Type,
+		Note:         strings.TrimSpace(req.Note),
+		CreatedBy:    &user.ID,
+	}
+
+	quantity := roundQuantity(req.Quantity)
+	switch req.Type {
+	case models.StockDelivery, models.StockWaste:
+		if quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity must be greater than 0", ErrInvalidInput)
+		}
+		movement.Quantity = quantity
+		if req.Type == models.StockWaste {
+			movement.Quantity = -quantity
+		}
+	case models.StockCount:
+		if quantity < 0 {
+			return nil, fmt.Errorf("%w: counted quantity cannot be negative", ErrInvalidInput)
+		}
+		movement.StockAfter = quantity
+	default:
+		return nil, fmt.Errorf("%w: adjustment type must be delivery, waste or count", ErrInvalidInput)
+	}
+
+	if err := s.inventoryRepo.RecordMovements(ctx, []*models.StockMovement{movement}); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// Movements returns a page of an ingredient's stock history, newest first.
+func (s *InventoryService) Movements(ctx context.Context, restaurantID, ingredientID uuid.UUID, limit, offset int) ([]*models.StockMovement, error) {
+	if _, err := s.GetIngredient(ctx, restaurantID, ingredientID); err != nil {
+		return nil, err
+	}
+	return s.inventoryRepo.ListMovements(ctx, ingredientID, limit, offset)
+}
+
+func (s *InventoryService) GetRecipe(ctx context.Context, restaurantID, menuItemID uuid.UUID) (*models.Recipe, error) {
+	if err := s.checkMenuItem(ctx, restaurantID, menuItemID); err != nil {
+		return nil, err
+	}
+
+	lines, err := s.inventoryRepo.GetRecipe(ctx, menuItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Recipe{MenuItemID: menuItemID, Lines: lines}, nil
+}
+
+// SetRecipe replaces the recipe of a menu item. Every ingredient must
+// belong to the restaurant and every customization line must name an
+// option of a customization of the item.
+func (s *InventoryService) SetRecipe(ctx context.Context, restaurantID uuid.UUID, recipe *models.Recipe) error {
+	if err := s.checkMenuItem(ctx, restaurantID, recipe.MenuItemID); err != nil {
+		return err
+	}
+
+	customizations, err := s.customizationRepo.ListByMenuItem(ctx, recipe.MenuItemID)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]*models.MenuItemCustomization, len(customizations))
+	for _, c := range customizations {
+		byID[c.ID] = c
+	}
+
+	if recipe.Lines == nil {
+		recipe.Lines = []models.RecipeLine{}
+	}
+	for i := range recipe.Lines {
+		line := &recipe.Lines[i]
+		line.Quantity = roundQuantity(line.Quantity)
+		if line.Quantity <= 0 {
+			return fmt.Errorf("%w: recipe quantities must be greater than 0", ErrInvalidInput)
+		}
+		if _, err := s.GetIngredient(ctx, restaurantID, line.IngredientID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("%w: ingredient %s is not stocked by the restaurant", ErrInvalidInput, line.IngredientID)
+			}
+			return err
+		}
+
+		if line.CustomizationID == nil {
+			line.Option = ""
+			continue
+		}
+		c, ok := byID[*line.CustomizationID]
+		if !ok {
+			return fmt.Errorf("%w: customization %s is not available for this item", ErrInvalidInput, *line.CustomizationID)
+		}
+		switch c.FieldType {
+		case models.CustomizationBoolean:
+			line.Option = "true"
+		case models.CustomizationText:
+			return fmt.Errorf("%w: text customizations cannot have recipe lines", ErrInvalidInput)
+		default:
+			if _, ok := c.Option(line.Option); !ok {
+				return fmt.Errorf("%w: %q is not an option of %s", ErrInvalidInput, line.Option, c.Name)
+			}
+		}
+	}
+
+	return s.inventoryRepo.ReplaceRecipe(ctx, recipe.MenuItemID, recipe.Lines)
+}
+
+// saleMovements returns the stock movements that book the ingredients used
+// by an order being accepted out of stock. Stock is allowed to go negative
+// because the food has already been made. Bundle components are booked out
+// by their own recipes.
+func (s *InventoryService) saleMovements(ctx context.Context, order *models.Order) ([]*models.StockMovement, error) {
+	usage := make(map[uuid.UUID]float64)
+	var ingredientIDs []uuid.UUID
+	recipes := make(map[uuid.UUID][]models.RecipeLine)
+	use := func(item models.OrderItem, quantity int) error {
+		lines, ok := recipes[item.MenuItemID]
+		if !ok {
+			var err error
+			lines, err = s.inventoryRepo.GetRecipe(ctx, item.MenuItemID)
+			if err != nil {
+				return err
+			}
+			recipes[item.MenuItemID] = lines
+		}
+
+		for _, line := range lines {
+			if !line.AppliesTo(item.Options) {
+				continue
+			}
+			if _, ok := usage[line.IngredientID]; !ok {
+				ingredientIDs = append(ingredientIDs, line.IngredientID)
+			}
+			usage[line.IngredientID] += line.Quantity * float64(quantity)
+		}
+		return nil
+	}
+
+	for _, item := range order.Items {
+		quantity := item.ActiveQuantity()
+		if quantity == 0 {
+			continue
+		}
+
+		if err := use(item, quantity); err != nil {
+			return nil, err
+		}
+		for _, component := range item.Components {
+			if err := use(component, component.Quantity*quantity); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	movements := make([]*models.StockMovement, 0, len(ingredientIDs))
+	for _, id := range ingredientIDs {
+		movements = append(movements, &models.StockMovement{
+			IngredientID: id,
+			RestaurantID: order.RestaurantID,
+			Type:         models.StockSale,
+			Quantity:     -roundQuantity(usage[id]),
+			OrderID:      &order.ID,
+		})
+	}
+	return movements, nil
+}
+
+// reversalMovements returns the stock movements that put back what was
+// booked out for an order being canceled.
+func (s *InventoryService) reversalMovements(ctx context.Context, order *models.Order) ([]*models.StockMovement, error) {
+	existing, err := s.inventoryRepo.ListMovementsByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var movements []*models.StockMovement
+	for _, sale := range existing {
+		if sale.Type != models.StockSale {
+			continue
+		}
+		movements = append(movements, &models.StockMovement{
+			IngredientID: sale.IngredientID,
+			RestaurantID: sale.RestaurantID,
+			Type:         models.StockSaleReversal,
+			Quantity:     -sale.Quantity,
+			OrderID:      &order.ID,
+			Note:         "order canceled",
+		})
+	}
+	return movements, nil
+}
+
+func (s *InventoryService) checkMenuItem(ctx context.Context, restaurantID, menuItemID uuid.UUID) error {
+	item, err := s.menuRepo.GetByID(ctx, menuItemID)
+	if err != nil {
+		return err
+	}
+	if item == nil || item.RestaurantID != restaurantID {
+		return fmt.Errorf("%w: menu item %s", ErrNotFound, menuItemID)
+	}
+	return nil
+}
+
+func validateIngredient(ingredient *models.Ingredient) error {
+	ingredient.Name = strings.TrimSpace(ingredient.Name)
+	if ingredient.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if !models.ValidIngredientUnit(ingredient.Unit) {
+		return fmt.Errorf("%w: unknown unit %q", ErrInvalidInput, ingredient.Unit)
+	}
+	if ingredient.LowStockThreshold < 0 {
+		return fmt.Errorf("%w: low stock threshold cannot be negative", ErrInvalidInput)
+	}
+	if ingredient.ParLevel < 0 {
+		return fmt.Errorf("%w: par level cannot be negative", ErrInvalidInput)
+	}
+	ingredient.LowStockThreshold = roundQuantity(ingredient.LowStockThreshold)
+	ingredient.ParLevel = roundQuantity(ingredient.ParLevel)
+	return nil
+}
+
+// roundQuantity rounds to the three decimals stock is stored with.
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
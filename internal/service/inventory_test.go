@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// fakeInventoryRepo keeps recipes and stock movements in memory.
+type fakeInventoryRepo struct {
+	repository.InventoryRepository
+	recipes   map[uuid.UUID][]models.RecipeLine
+	movements []*models.StockMovement
+}
+
+func (r *fakeInventoryRepo) GetRecipe(ctx context.Context, menuItemID uuid.UUID) ([]models.RecipeLine, error) {
+	return r.recipes[menuItemID], nil
+}
+
+func (r *fakeInventoryRepo) ListMovementsByOrder(ctx context.Context, orderID uuid.UUID) ([]*models.StockMovement, error) {
+	var movements []*models.StockMovement
+	for _, movement := range r.movements {
+		if movement.OrderID != nil && *movement.OrderID == orderID {
+			movements = append(movements, movement)
+		}
+	}
+	return movements, nil
+}
+
+func movementQuantities(movements []*models.StockMovement) map[uuid.UUID]float64 {
+	quantities := make(map[uuid.UUID]float64)
+	for _, movement := range movements {
+		quantities[movement.IngredientID] += movement.Quantity
+	}
+	return quantities
+}
+
+func TestSaleMovements(t *testing.T) {
+	burger, fries, combo := uuid.New(), uuid.New(), uuid.New()
+	bun, patty, potato, cheese := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	extras := uuid.New()
+	repo := &fakeInventoryRepo{recipes: map[uuid.UUID][]models.RecipeLine{
+		burger: {
+			{IngredientID: bun, Quantity: 1},
+			{IngredientID: patty, Quantity: 0.15},
+			{IngredientID: cheese, Quantity: 0.02, CustomizationID: &extras, Option: "cheese"},
+		},
+		fries: {{IngredientID: potato, Quantity: 0.2}},
+	}}
+	s := NewInventoryService(repo, nil, nil)
+
+	tests := []struct {
+		name  string
+		items []models.OrderItem
+		want  map[uuid.UUID]float64
+	}{
+		{
+			name:  "quantity multiplies the recipe",
+			items: []models.OrderItem{{MenuItemID: burger, Quantity: 3}},
+			want:  map[uuid.UUID]float64{bun: -3, patty: -0.45},
+		},
+		{
+			name:  "voided quantity is not used",
+			items: []models.OrderItem{{MenuItemID: fries, Quantity: 3, VoidedQuantity: 1}},
+			want:  map[uuid.UUID]float64{potato: -0.4},
+		},
+		{
+			name: "option lines only apply when chosen",
+			items: []models.OrderItem{{MenuItemID: burger, Quantity: 2, Options: []models.OrderItemOption{
+				{CustomizationID: extras, Value: "cheese"},
+			}}},
+			want: map[uuid.UUID]float64{bun: -2, patty: -0.3, cheese: -0.04},
+		},
+		{
+			name: "bundle components use their own recipes",
+			items: []models.OrderItem{{MenuItemID: combo, Quantity: 2, Components: []models.OrderItem{
+				{MenuItemID: burger, Quantity: 1},
+				{MenuItemID: fries, Quantity: 2},
+			}}},
+			want: map[uuid.UUID]float64{bun: -2, patty: -0.3, potato: -0.8},
+		},
+		{
+			name:  "fully voided",
+			items: []models.OrderItem{{MenuItemID: burger, Quantity: 1, VoidedQuantity: 1}},
+			want:  map[uuid.UUID]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{ID: uuid.New(), RestaurantID: uuid.New(), Items: tt.items}
+
+			movements, err := s.saleMovements(context.Background(), order)
+			if err != nil {
+				t.Fatalf("saleMovements() error = %v", err)
+			}
+			got := movementQuantities(movements)
+			if len(got) != len(tt.want) {
+				t.Fatalf("saleMovements() = %v, want %v", got, tt.want)
+			}
+			for id, quantity := range tt.want {
+				if got[id] != quantity {
+					t.Errorf("ingredient %s moved %v, want %v", id, got[id], quantity)
+				}
+			}
+			for _, movement := range movements {
+				if movement.Type != models.StockSale || *movement.OrderID != order.ID || movement.RestaurantID != order.RestaurantID {
+					t.Errorf("movement = %+v, want a sale for the order", movement)
+				}
+			}
+		})
+	}
+}
+
+func TestReversalMovements(t *testing.T) {
+	orderID, other := uuid.New(), uuid.New()
+	bun, patty := uuid.New(), uuid.New()
+	repo := &fakeInventoryRepo{movements: []*models.StockMovement{
+		{IngredientID: bun, Type: models.StockSale, Quantity: -2, OrderID: &orderID},
+		{IngredientID: patty, Type: models.StockSale, Quantity: -0.3, OrderID: &orderID},
+		{IngredientID: bun, Type: models.StockWaste, Quantity: -1, OrderID: &orderID},
+		{IngredientID: bun, Type: models.StockSale, Quantity: -5, OrderID: &other},
+	}}
+	s := NewInventoryService(repo, nil, nil)
+
+	movements, err := s.reversalMovements(context.Background(), &models.Order{ID: orderID})
+	if err != nil {
+		t.Fatalf("reversalMovements() error = %v", err)
+	}
+	got := movementQuantities(movements)
+	if len(got) != 2 || got[bun] != 2 || got[patty] != 0.3 {
+		t.Errorf("reversalMovements() = %v, want bun 2 and patty 0.3 back", got)
+	}
+	for _, movement := range movements {
+		if movement.Type != models.StockSaleReversal {
+			t.Errorf("movement type = %s, want %s", movement.Type, models.StockSaleReversal)
+		}
+	}
+
+	if movements, _ := s.reversalMovements(context.Background(), &models.Order{ID: uuid.New()}); len(movements) != 0 {
+		t.Errorf("reversalMovements() for an order never accepted = %v, want none", movements)
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type KitchenService struct {
+	orderRepo         repository.OrderRepository
+	menuRepo          repository.MenuRepository
+	customizationRepo repository.CustomizationRepository
+}
+
+func NewKitchenService(
+	orderRepo repository.OrderRepository,
+	menuRepo repository.MenuRepository,
+	customizationRepo repository.CustomizationRepository,
+) *KitchenService {
+	return &KitchenService{
+		orderRepo:         orderRepo,
+		menuRepo:          menuRepo,
+		customizationRepo: customizationRepo,
+	}
+}
+
+// Tickets returns the orders the kitchen is working on, oldest first.
+func (s *KitchenService) Tickets(ctx context.Context, restaurantID uuid.UUID) ([]*models.KitchenTicket, error) {
+	orders, err := s.orderRepo.ListByRestaurant(ctx, restaurantID, models.OrderFilter{Status: models.OrderStatusAccepted})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+	})
+
+	names := newTicketNames(s.menuRepo, s.customizationRepo)
+	tickets := make([]*models.KitchenTicket, 0, len(orders))
+	for _, order := range orders {
+		ticket := &models.KitchenTicket{
+			OrderID:      order.ID,
+			Type:         order.Type,
+			Label:        ticketLabel(order),
+			PickupAt:     order.PickupAt,
+			CustomerName: order.CustomerName,
+			Status:       order.Status,
+			Items:        []models.KitchenTicketItem{},
+			CreatedAt:    order.CreatedAt,
+		}
+		if order.TableID != uuid.Nil {
+			tableID := order.TableID
+			ticket.TableID = &tableID
+		}
+
+		for _, item := range order.Items {
+			if item.ActiveQuantity() == 0 {
+				continue
+			}
+			line, err := names.item(ctx, item, item.ActiveQuantity())
+			if err != nil {
+				return nil, err
+			}
+			for _, component := range item.Components {
+				componentLine, err := names.item(ctx, component, component.Quantity*item.ActiveQuantity())
+				if err != nil {
+					return nil, err
+				}
+				line.Components = append(line.Components, componentLine)
+			}
+			ticket.Items = append(ticket.Items, line)
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, nil
+}
+
+// ticketLabel says on a kitchen ticket how the order leaves the kitchen.
+func ticketLabel(order *models.Order) string {
+	switch order.Type {
+	case models.OrderTypePickup:
+		if order.CustomerName != "" {
+			return "PICKUP: " + order.CustomerName
+		}
+		return "PICKUP"
+	case models.OrderTypeDelivery:
+		return "DELIVERY"
+	}
+	return "DINE IN"
+}
+
+// ticketNames looks up and caches the menu item and customization names
+// printed on kitchen tickets.
+type ticketNames struct {
+	menuRepo          repository.MenuRepository
+	customizationRepo repository.CustomizationRepository
+	items             map[uuid.UUID]string
+	customizations    map[uuid.UUID]*models.MenuItemCustomization
+}
+
+func newTicketNames(menuRepo repository.MenuRepository, customizationRepo repository.CustomizationRepository) *ticketNames {
+	return &ticketNames{
+		menuRepo:          menuRepo,
+		customizationRepo: customizationRepo,
+		items:             make(map[uuid.UUID]string),
+		customizations:    make(map[uuid.UUID]*models.MenuItemCustomization),
+	}
+}
+
+func (n *ticketNames) item(ctx context.Context, item models.OrderItem, quantity int) (models.KitchenTicketItem, error) {
+	name, ok := n.items[item.MenuItemID]
+	if !ok {
+		menuItem, err := n.menuRepo.GetByID(ctx, item.MenuItemID)
+		if err != nil {
+			return models.KitchenTicketItem{}, err
+		}
+		if menuItem != nil {
+			name = menuItem.Name
+		}
+		n.items[item.MenuItemID] = name
+	}
+
+	line := models.KitchenTicketItem{
+		OrderItemID: item.ID,
+		Name:        name,
+		Variant:     item.VariantName,
+		Quantity:    quantity,
+		Seat:        item.Seat,
+		SentAt:      item.SentAt,
+	}
+
+	for _, option := range item.Options {
+		customization, ok := n.customizations[option.CustomizationID]
+		if !ok {
+			var err error
+			customization, err = n.customizationRepo.GetByID(ctx, option.CustomizationID)
+			if err != nil {
+				return models.KitchenTicketItem{}, err
+			}
+			n.customizations[option.CustomizationID] = customization
+		}
+		line.Options = append(line.Options, optionLabel(customization, option))
+	}
+
+	return line, nil
+}
+
+// optionLabel renders a chosen option as "Milk: Oat", or just the
+// customization name for a checked boolean.
+func optionLabel(customization *models.MenuItemCustomization, option models.OrderItemOption) string {
+	if customization == nil {
+		return option.Value
+	}
+	if customization.FieldType == models.CustomizationBoolean {
+		return customization.Name
+	}
+	return customization.Name + ": " + option.Value
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// expiryWarningWindow is how far ahead an account shows points that are
+// about to expire.
+const expiryWarningWindow = 30 * 24 * time.Hour
+
+type LoyaltyService struct {
+	loyaltyRepo repository.LoyaltyRepository
+	userRepo    repository.UserRepository
+}
+
+func NewLoyaltyService(loyaltyRepo repository.LoyaltyRepository, userRepo repository.UserRepository) *LoyaltyService {
+	return &LoyaltyService{
+		loyaltyRepo: loyaltyRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// Program returns the program in effect at a restaurant: its own, else the
+// chain-wide one, else the defaults.
+func (s *LoyaltyService) Program(ctx context.Context, restaurantID uuid.UUID) (*models.LoyaltyProgram, error) {
+	program, err := s.loyaltyRepo.GetProgram(ctx, &restaurantID)
+	if err != nil || program != nil {
+		return program, err
+	}
+	return s.ChainProgram(ctx)
+}
+
+// ChainProgram returns the chain-wide program, or the defaults.
+func (s *LoyaltyService) ChainProgram(ctx context.Context) (*models.LoyaltyProgram, error) {
+	program, err := s.loyaltyRepo.GetProgram(ctx, nil)
+	if err != nil || program != nil {
+		return program, err
+	}
+	return models.DefaultLoyaltyProgram(), nil
+}
+
+// SaveProgram configures the program of a restaurant, or the chain-wide
+// program when it has no restaurant.
+func (s *LoyaltyService) SaveProgram(ctx context.Context, program *models.LoyaltyProgram) error {
+	if program.PointsPerUnit < 0 {
+		return fmt.Errorf("%w: points_per_unit cannot be negative", ErrInvalidInput)
+	}
+	if program.PointValue <= 0 {
+		return fmt.Errorf("%w: point_value must be greater than 0", ErrInvalidInput)
+	}
+	if program.ExpiryDays < 0 {
+		return fmt.Errorf("%w: expiry_days cannot be negative", ErrInvalidInput)
+	}
+	return s.loyaltyRepo.SaveProgram(ctx, program)
+}
+
+// Account returns a member's balance and tier, writing off expired points
+// first.
+func (s *LoyaltyService) Account(ctx context.Context, userID uuid.UUID) (*models.LoyaltyAccount, error) {
+	now := time.Now()
+	if _, err := s.loyaltyRepo.Expire(ctx, userID, now); err != nil {
+		return nil, err
+	}
+
+	account, err := s.loyaltyRepo.GetAccount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		account = &models.LoyaltyAccount{UserID: userID}
+	}
+
+	level := models.TierFor(account.LifetimePoints)
+	account.Tier = level.Tier
+	for _, next := range models.LoyaltyTiers {
+		if next.MinPoints > level.MinPoints {
+			account.NextTier = next.Tier
+			account.PointsToNext = next.MinPoints - account.LifetimePoints
+			break
+		}
+	}
+
+	credits, err := s.loyaltyRepo.ListOpenCredits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, credit := range credits {
+		if credit.ExpiresAt == nil || credit.ExpiresAt.After(now.Add(expiryWarningWindow)) {
+			continue
+		}
+		if account.NextExpiryAt == nil {
+			account.NextExpiryAt = credit.ExpiresAt
+		}
+		if credit.ExpiresAt.Equal(*account.NextExpiryAt) {
+			account.ExpiringPoints += credit.Remaining
+		}
+	}
+
+	return account, nil
+}
+
+func (s *LoyaltyService) History(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.LoyaltyTransaction, error) {
+	return s.loyaltyRepo.ListTransactions(ctx, userID, limit, offset)
+}
+
+// earning returns the entry crediting the points an order being completed
+// earns its client, on what its items cost after discounts and voids; tax,
+// the service charge and the delivery fee earn nothing. It returns nil if
+// the order earns nothing.
+func (s *LoyaltyService) earning(ctx context.Context, order *models.Order) (*models.LoyaltyTransaction, error) {
+	user, err := s.userRepo.GetByID(ctx, order.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Role != models.RoleClient {
+		return nil, nil
+	}
+
+	program, err := s.Program(ctx, order.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if !program.Active {
+		return nil, nil
+	}
+
+	account, err := s.loyaltyRepo.GetAccount(ctx, order.UserID)
+	if err != nil {
+		return nil, err
+	}
+	lifetime := 0
+	if account != nil {
+		lifetime = account.LifetimePoints
+	}
+
+	multiplier := models.TierFor(lifetime).Multiplier
+	points := int(math.Floor(fromCents(earningAmount(order)) * program.PointsPerUnit * multiplier))
+	if points <= 0 {
+		return nil, nil
+	}
+
+	return &models.LoyaltyTransaction{
+		UserID:       order.UserID,
+		RestaurantID: &order.RestaurantID,
+		OrderID:      &order.ID,
+		Type:         models.LoyaltyEarn,
+		Points:       points,
+		ExpiresAt:    program.ExpiryFrom(time.Now()),
+	}, nil
+}
+
+// earningAmount returns the cents an order earns points on: its items
+// after discounts, less the items voided since it was placed.
+func earningAmount(order *models.Order) int64 {
+	amount := toCents(order.Subtotal) - toCents(order.DiscountTotal)
+	for _, item := range order.Items {
+		amount -= toCents(item.Price) * int64(item.VoidedQuantity)
+	}
+	return max(amount, 0)
+}
+
+// ReverseForRefund takes back the share of the points an order earned
+// that a completed refund paid back, in proportion to the order total. It
+// is safe to call more than once per refund.
+func (s *LoyaltyService) ReverseForRefund(ctx context.Context, order *models.Order, refund *models.OrderAdjustment) error {
+	entries, err := s.loyaltyRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		earned, reversed int
+		userID           uuid.UUID
+	)
+	for _, entry := range entries {
+		switch entry.Type {
+		case models.LoyaltyEarn:
+			earned += entry.Points
+			userID = entry.UserID
+		case models.LoyaltyReverse:
+			reversed -= entry.Points
+		}
+	}
+	if earned == 0 {
+		return nil
+	}
+
+	points := earned
+	if total := toCents(order.TotalAmount); toCents(refund.Amount) < total {
+		points = int(math.Round(float64(earned) * float64(toCents(refund.Amount)) / float64(total)))
+	}
+	points = min(points, earned-reversed)
+	if points <= 0 {
+		return nil
+	}
+
+	_, err = s.loyaltyRepo.Reverse(ctx, &models.LoyaltyTransaction{
+		UserID:       userID,
+		RestaurantID: &order.RestaurantID,
+		OrderID:      &order.ID,
+		AdjustmentID: &refund.ID,
+		Type:         models.LoyaltyReverse,
+		Points:       -points,
+	})
+	return err
+}
+
+// ApplyRedemption adds the discount for the points the order redeems. With
+// checkBalance the client must currently hold the points; it is off when
+// repricing an order whose points were already taken.
+func (s *LoyaltyService) ApplyRedemption(ctx context.Context, order *models.Order, checkBalance bool) error {
+	if order.LoyaltyPoints < 0 {
+		return fmt.Errorf("%w: loyalty_points cannot be negative", ErrInvalidInput)
+	}
+	if order.LoyaltyPoints == 0 {
+		return nil
+	}
+
+	program, err := s.Program(ctx, order.RestaurantID)
+	if err != nil {
+		return err
+	}
+	if !program.Active {
+		return fmt.Errorf("%w: loyalty points cannot be redeemed at this restaurant", ErrInvalidInput)
+	}
+
+	if checkBalance {
+		account, err := s.Account(ctx, order.UserID)
+		if err != nil {
+			return err
+		}
+		if account.Balance < order.LoyaltyPoints {
+			return fmt.Errorf("%w: only %d loyalty points available", ErrInvalidInput, account.Balance)
+		}
+	}
+
+	value := toCents(float64(order.LoyaltyPoints) * program.PointValue)
+	total := toCents(order.TotalAmount)
+	if value > total {
+		maxPoints := int(fromCents(total) / program.PointValue)
+		return fmt.Errorf("%w: at most %d loyalty points can be redeemed on this order", ErrInvalidInput, maxPoints)
+	}
+
+	order.Discounts = append(order.Discounts, models.OrderDiscount{
+		Description: fmt.Sprintf("%d loyalty points", order.LoyaltyPoints),
+		Amount:      fromCents(value),
+	})
+	order.DiscountTotal = fromCents(toCents(order.DiscountTotal) + value)
+	order.TotalAmount = fromCents(total - value)
+
+	return nil
+}
+
+// restoring returns the entry giving back the points redeemed on an order
+// being canceled, or nil if none were.
+func (s *LoyaltyService) restoring(ctx context.Context, order *models.Order) (*models.LoyaltyTransaction, error) {
+	entries, err := s.loyaltyRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Type != models.LoyaltyRedeem {
+			continue
+		}
+
+		program, err := s.Program(ctx, order.RestaurantID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.LoyaltyTransaction{
+			UserID:       entry.UserID,
+			RestaurantID: entry.RestaurantID,
+			OrderID:      &order.ID,
+			Type:         models.LoyaltyRestore,
+			Points:       -entry.Points,
+			ExpiresAt:    program.ExpiryFrom(time.Now()),
+		}, nil
+	}
+
+	return nil, nil
+}
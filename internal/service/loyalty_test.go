@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// fakeLoyaltyRepo keeps one program, accounts and the ledger in memory.
+type fakeLoyaltyRepo struct {
+	repository.LoyaltyRepository
+	program  *models.LoyaltyProgram
+	accounts map[uuid.UUID]*models.LoyaltyAccount
+	entries  []*models.LoyaltyTransaction
+}
+
+func (r *fakeLoyaltyRepo) GetProgram(ctx context.Context, restaurantID *uuid.UUID) (*models.LoyaltyProgram, error) {
+	return r.program, nil
+}
+
+func (r *fakeLoyaltyRepo) GetAccount(ctx context.Context, userID uuid.UUID) (*models.LoyaltyAccount, error) {
+	return r.accounts[userID], nil
+}
+
+func (r *fakeLoyaltyRepo) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.LoyaltyTransaction, error) {
+	var entries []*models.LoyaltyTransaction
+	for _, entry := range r.entries {
+		if entry.OrderID != nil && *entry.OrderID == orderID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// fakeUserRepo keeps users in memory.
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return r.users[id], nil
+}
+
+func TestLoyaltyEarning(t *testing.T) {
+	client, gold, staffer := uuid.New(), uuid.New(), uuid.New()
+	users := &fakeUserRepo{users: map[uuid.UUID]*models.User{
+		client:  {ID: client, Role: models.RoleClient},
+		gold:    {ID: gold, Role: models.RoleClient},
+		staffer: {ID: staffer, Role: models.RoleEmployee},
+	}}
+	accounts := map[uuid.UUID]*models.LoyaltyAccount{gold: {UserID: gold, LifetimePoints: 6000}}
+	inactive := models.DefaultLoyaltyProgram()
+	inactive.Active = false
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		program *models.LoyaltyProgram
+		order   models.Order
+		want    int
+	}{
+		{
+			name:   "items after discounts earn",
+			userID: client,
+			order:  models.Order{Subtotal: 50, DiscountTotal: 10, TaxTotal: 4, ServiceCharge: 5, TotalAmount: 49},
+			want:   40,
+		},
+		{
+			name:   "voided items earn nothing",
+			userID: client,
+			order:  models.Order{Subtotal: 30, Items: []models.OrderItem{{Quantity: 3, VoidedQuantity: 1, Price: 10}}},
+			want:   20,
+		},
+		{
+			name:   "tier multiplies",
+			userID: gold,
+			order:  models.Order{Subtotal: 25},
+			want:   37,
+		},
+		{name: "staff earn nothing", userID: staffer, order: models.Order{Subtotal: 25}},
+		{name: "inactive program", userID: client, program: inactive, order: models.Order{Subtotal: 25}},
+		{name: "discounted to nothing", userID: client, order: models.Order{Subtotal: 25, DiscountTotal: 25}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewLoyaltyService(&fakeLoyaltyRepo{program: tt.program, accounts: accounts}, users)
+			order := tt.order
+			order.ID, order.UserID, order.RestaurantID = uuid.New(), tt.userID, uuid.New()
+
+			entry, err := s.earning(context.Background(), &order)
+			if err != nil {
+				t.Fatalf("earning() error = %v", err)
+			}
+			if tt.want == 0 {
+				if entry != nil {
+					t.Errorf("earning() = %d points, want none", entry.Points)
+				}
+				return
+			}
+			if entry == nil || entry.Points != tt.want || entry.Type != models.LoyaltyEarn || *entry.OrderID != order.ID {
+				t.Errorf("earning() = %+v, want an earn of %d points", entry, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoyaltyRestoring(t *testing.T) {
+	userID, redeemed, earned := uuid.New(), uuid.New(), uuid.New()
+	repo := &fakeLoyaltyRepo{entries: []*models.LoyaltyTransaction{
+		{UserID: userID, OrderID: &redeemed, Type: models.LoyaltyRedeem, Points: -300},
+		{UserID: userID, OrderID: &earned, Type: models.LoyaltyEarn, Points: 40},
+	}}
+	s := NewLoyaltyService(repo, nil)
+
+	entry, err := s.restoring(context.Background(), &models.Order{ID: redeemed})
+	if err != nil {
+		t.Fatalf("restoring() error = %v", err)
+	}
+	if entry == nil || entry.Points != 300 || entry.Type != models.LoyaltyRestore || entry.UserID != userID {
+		t.Errorf("restoring() = %+v, want 300 points restored", entry)
+	}
+
+	entry, err = s.restoring(context.Background(), &models.Order{ID: earned})
+	if err != nil || entry != nil {
+		t.Errorf("restoring() without a redemption = %+v, %v, want nothing", entry, err)
+	}
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/KNLopez/restaurant-api/internal/models"
 	"github.com/KNLopez/restaurant-api/internal/repository"
@@ -9,31 +12,478 @@ import (
 )
 
 type MenuService struct {
-	menuRepo repository.MenuRepository
+	menuRepo          repository.MenuRepository
+	reviewRepo        repository.ReviewRepository
+	customizationRepo repository.CustomizationRepository
+	variantRepo       repository.VariantRepository
+	bundleRepo        repository.BundleRepository
+	schedules         *MenuScheduleService
 }
 
-func NewMenuService(menuRepo repository.MenuRepository) *MenuService {
+func NewMenuService(
+	menuRepo repository.MenuRepository,
+	reviewRepo repository.ReviewRepository,
+	customizationRepo repository.CustomizationRepository,
+	variantRepo repository.VariantRepository,
+	bundleRepo repository.BundleRepository,
+	schedules *MenuScheduleService,
+) *MenuService {
 	return &MenuService{
-		menuRepo: menuRepo,
+		menuRepo:          menuRepo,
+		reviewRepo:        reviewRepo,
+		customizationRepo: customizationRepo,
+		variantRepo:       variantRepo,
+		bundleRepo:        bundleRepo,
+		schedules:         schedules,
 	}
 }
 
 func (s *MenuService) Create(ctx context.Context, item *models.MenuItem) error {
+	item.BrandItemID = nil
+	if err := validateDietary(item); err != nil {
+		return err
+	}
+	var err error
+	if item.Translations, err = normalizeTranslations(item.Translations, true); err != nil {
+		return err
+	}
+	if err := s.validateSKU(ctx, item); err != nil {
+		return err
+	}
 	return s.menuRepo.Create(ctx, item)
 }
 
-func (s *MenuService) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.MenuItem, error) {
-	return s.menuRepo.List(ctx, restaurantID)
+// List returns the menu of a restaurant, narrowed by dietary tags and
+// allergens, with each item's aggregated rating.
+func (s *MenuService) List(ctx context.Context, restaurantID uuid.UUID, filter models.MenuFilter) ([]*models.MenuItem, error) {
+	var err error
+	if filter.DietaryTags, err = validateDietaryTags(filter.DietaryTags); err != nil {
+		return nil, err
+	}
+	if filter.ExcludeAllergens, err = validateAllergens(filter.ExcludeAllergens); err != nil {
+		return nil, err
+	}
+
+	items, err := s.menuRepo.List(ctx, restaurantID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachRatings(ctx, restaurantID, items); err != nil {
+		return nil, err
+	}
+	if err := s.attachVariants(ctx, restaurantID, items); err != nil {
+		return nil, err
+	}
+	if err := s.attachBundles(ctx, restaurantID, items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// ListOrderable returns the items of a restaurant that are on a menu being
+// served at the given time.
+func (s *MenuService) ListOrderable(ctx context.Context, restaurantID uuid.UUID, filter models.MenuFilter, at time.Time) ([]*models.MenuItem, error) {
+	items, err := s.List(ctx, restaurantID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.schedules.Filter(ctx, restaurantID, items, at)
 }
 
 func (s *MenuService) GetByID(ctx context.Context, id uuid.UUID) (*models.MenuItem, error) {
-	return s.menuRepo.GetByID(ctx, id)
+	item, err := s.menuRepo.GetByID(ctx, id)
+	if err != nil || item == nil {
+		return item, err
+	}
+
+	if err := s.attachRatings(ctx, item.RestaurantID, []*models.MenuItem{item}); err != nil {
+		return nil, err
+	}
+	if err := s.attachVariants(ctx, item.RestaurantID, []*models.MenuItem{item}); err != nil {
+		return nil, err
+	}
+	if err := s.attachBundles(ctx, item.RestaurantID, []*models.MenuItem{item}); err != nil {
+		return nil, err
+	}
+
+	return item, nil
 }
 
+// Update replaces the content of an item. Items copied from a brand's
+// master menu stay linked to it.
 func (s *MenuService) Update(ctx context.Context, item *models.MenuItem) error {
+	existing, err := s.getItem(ctx, item.RestaurantID, item.ID)
+	if err != nil {
+		return err
+	}
+	item.BrandItemID = existing.BrandItemID
+
+	if err := validateDietary(item); err != nil {
+		return err
+	}
+	if item.Translations, err = normalizeTranslations(item.Translations, true); err != nil {
+		return err
+	}
+	if err := s.validateSKU(ctx, item); err != nil {
+		return err
+	}
 	return s.menuRepo.Update(ctx, item)
 }
 
+// SetAvailability 86es a menu item or brings it back. An item with no
+// portions left cannot be made available.
+func (s *MenuService) SetAvailability(ctx context.Context, restaurantID, id uuid.UUID, req models.MenuItemAvailability) (*models.MenuItem, error) {
+	item, err := s.getItem(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PortionsRemaining != nil {
+		if *req.PortionsRemaining < 0 {
+			return nil, fmt.Errorf("%w: portions remaining cannot be negative", ErrInvalidInput)
+		}
+		if *req.PortionsRemaining == 0 && req.Available {
+			return nil, fmt.Errorf("%w: an item with no portions left cannot be available", ErrInvalidInput)
+		}
+	}
+
+	if err := s.menuRepo.SetAvailability(ctx, id, req.Available, req.PortionsRemaining); err != nil {
+		return nil, err
+	}
+
+	item.Available = req.Available
+	item.PortionsRemaining = req.PortionsRemaining
+	return item, nil
+}
+
 func (s *MenuService) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.menuRepo.Delete(ctx, id)
 }
+
+func (s *MenuService) ListCustomizations(ctx context.Context, restaurantID, menuItemID uuid.UUID) ([]*models.MenuItemCustomization, error) {
+	if _, err := s.getItem(ctx, restaurantID, menuItemID); err != nil {
+		return nil, err
+	}
+	return s.customizationRepo.ListByMenuItem(ctx, menuItemID)
+}
+
+func (s *MenuService) CreateCustomization(ctx context.Context, restaurantID uuid.UUID, customization *models.MenuItemCustomization) error {
+	if _, err := s.getItem(ctx, restaurantID, customization.MenuItemID); err != nil {
+		return err
+	}
+	if err := validateCustomization(customization); err != nil {
+		return err
+	}
+	return s.customizationRepo.Create(ctx, customization)
+}
+
+func (s *MenuService) DeleteCustomization(ctx context.Context, restaurantID, menuItemID, id uuid.UUID) error {
+	if _, err := s.getItem(ctx, restaurantID, menuItemID); err != nil {
+		return err
+	}
+
+	customization, err := s.customizationRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if customization == nil || customization.MenuItemID != menuItemID {
+		return fmt.Errorf("%w: customization %s", ErrNotFound, id)
+	}
+
+	return s.customizationRepo.Delete(ctx, id)
+}
+
+func (s *MenuService) ListVariants(ctx context.Context, restaurantID, menuItemID uuid.UUID) ([]*models.MenuItemVariant, error) {
+	if _, err := s.getItem(ctx, restaurantID, menuItemID); err != nil {
+		return nil, err
+	}
+	return s.variantRepo.ListByMenuItem(ctx, menuItemID)
+}
+
+func (s *MenuService) CreateVariant(ctx context.Context, restaurantID uuid.UUID, variant *models.MenuItemVariant) error {
+	if err := s.validateVariant(ctx, restaurantID, variant); err != nil {
+		return err
+	}
+	return s.variantRepo.Create(ctx, variant)
+}
+
+func (s *MenuService) UpdateVariant(ctx context.Context, restaurantID uuid.UUID, variant *models.MenuItemVariant) error {
+	if _, err := s.getVariant(ctx, restaurantID, variant.MenuItemID, variant.ID); err != nil {
+		return err
+	}
+	if err := s.validateVariant(ctx, restaurantID, variant); err != nil {
+		return err
+	}
+	return s.variantRepo.Update(ctx, variant)
+}
+
+// DeleteVariant removes a variant. Past orders keep its name.
+func (s *MenuService) DeleteVariant(ctx context.Context, restaurantID, menuItemID, id uuid.UUID) error {
+	if _, err := s.getVariant(ctx, restaurantID, menuItemID, id); err != nil {
+		return err
+	}
+	return s.variantRepo.Delete(ctx, id)
+}
+
+func (s *MenuService) getVariant(ctx context.Context, restaurantID, menuItemID, id uuid.UUID) (*models.MenuItemVariant, error) {
+	if _, err := s.getItem(ctx, restaurantID, menuItemID); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.variantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil || variant.MenuItemID != menuItemID {
+		return nil, fmt.Errorf("%w: variant %s", ErrNotFound, id)
+	}
+	return variant, nil
+}
+
+// validateSKU requires the SKU of an item, if it has one, to be unique for
+// the restaurant, as bulk imports match items by SKU.
+func (s *MenuService) validateSKU(ctx context.Context, item *models.MenuItem) error {
+	item.SKU = strings.TrimSpace(item.SKU)
+	if item.SKU == "" {
+		return nil
+	}
+	if len(item.SKU) > maxSKULength {
+		return fmt.Errorf("%w: SKU is longer than %d characters", ErrInvalidInput, maxSKULength)
+	}
+
+	other, err := s.menuRepo.GetBySKU(ctx, item.RestaurantID, item.SKU)
+	if err != nil {
+		return err
+	}
+	if other != nil && other.ID != item.ID {
+		return fmt.Errorf("%w: SKU %q is already in use", ErrConflict, item.SKU)
+	}
+	return nil
+}
+
+// validateVariant requires a name that is unique for the item and a SKU
+// that is unique for the restaurant.
+func (s *MenuService) validateVariant(ctx context.Context, restaurantID uuid.UUID, variant *models.MenuItemVariant) error {
+	if _, err := s.getItem(ctx, restaurantID, variant.MenuItemID); err != nil {
+		return err
+	}
+
+	variant.Name = strings.TrimSpace(variant.Name)
+	variant.SKU = strings.TrimSpace(variant.SKU)
+	if variant.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if variant.Price < 0 {
+		return fmt.Errorf("%w: price cannot be negative", ErrInvalidInput)
+	}
+
+	existing, err := s.variantRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID == variant.ID {
+			continue
+		}
+		if other.MenuItemID == variant.MenuItemID && strings.EqualFold(other.Name, variant.Name) {
+			return fmt.Errorf("%w: the item already has a variant named %q", ErrConflict, variant.Name)
+		}
+		if variant.SKU != "" && other.SKU == variant.SKU {
+			return fmt.Errorf("%w: SKU %q is already in use", ErrConflict, variant.SKU)
+		}
+	}
+
+	return nil
+}
+
+func (s *MenuService) GetBundle(ctx context.Context, restaurantID, menuItemID uuid.UUID) (*models.Bundle, error) {
+	if _, err := s.getItem(ctx, restaurantID, menuItemID); err != nil {
+		return nil, err
+	}
+
+	groups, err := s.bundleRepo.ListByMenuItem(ctx, menuItemID)
+	if err != nil {
+		return nil, err
+	}
+	if groups == nil {
+		groups = []models.BundleGroup{}
+	}
+
+	return &models.Bundle{MenuItemID: menuItemID, Groups: groups}, nil
+}
+
+// SetBundle replaces the choice groups of a menu item, making it a bundle
+// sold at its menu price. Without groups the item is a plain item again.
+func (s *MenuService) SetBundle(ctx context.Context, restaurantID uuid.UUID, bundle *models.Bundle) error {
+	if _, err := s.getItem(ctx, restaurantID, bundle.MenuItemID); err != nil {
+		return err
+	}
+	if bundle.Groups == nil {
+		bundle.Groups = []models.BundleGroup{}
+	}
+
+	for i := range bundle.Groups {
+		group := &bundle.Groups[i]
+		group.Name = strings.TrimSpace(group.Name)
+		if group.Name == "" {
+			return fmt.Errorf("%w: group names are required", ErrInvalidInput)
+		}
+		if group.MinSelections < 0 || group.MaxSelections < 1 || group.MinSelections > group.MaxSelections {
+			return fmt.Errorf("%w: %s must allow between min_selections and max_selections choices, with max_selections at least 1", ErrInvalidInput, group.Name)
+		}
+		if len(group.Choices) == 0 {
+			return fmt.Errorf("%w: %s needs at least one choice", ErrInvalidInput, group.Name)
+		}
+		if group.Position == 0 {
+			group.Position = i
+		}
+
+		for _, choice := range group.Choices {
+			if err := s.validateBundleChoice(ctx, restaurantID, bundle.MenuItemID, choice); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.bundleRepo.Replace(ctx, bundle.MenuItemID, bundle.Groups)
+}
+
+// validateBundleChoice requires choices to be plain items of the same
+// restaurant, so bundles do not nest.
+func (s *MenuService) validateBundleChoice(ctx context.Context, restaurantID, bundleID uuid.UUID, choice models.BundleChoice) error {
+	if choice.MenuItemID == bundleID {
+		return fmt.Errorf("%w: a bundle cannot contain itself", ErrInvalidInput)
+	}
+	if choice.Upcharge < 0 {
+		return fmt.Errorf("%w: upcharges cannot be negative", ErrInvalidInput)
+	}
+
+	item, err := s.menuRepo.GetByID(ctx, choice.MenuItemID)
+	if err != nil {
+		return err
+	}
+	if item == nil || item.RestaurantID != restaurantID {
+		return fmt.Errorf("%w: menu item %s is not on the restaurant's menu", ErrInvalidInput, choice.MenuItemID)
+	}
+
+	groups, err := s.bundleRepo.ListByMenuItem(ctx, item.ID)
+	if err != nil {
+		return err
+	}
+	if len(groups) > 0 {
+		return fmt.Errorf("%w: %s is a bundle and cannot be a choice", ErrInvalidInput, item.Name)
+	}
+
+	if choice.VariantID != nil {
+		variant, err := s.variantRepo.GetByID(ctx, *choice.VariantID)
+		if err != nil {
+			return err
+		}
+		if variant == nil || variant.MenuItemID != item.ID {
+			return fmt.Errorf("%w: variant %s is not a variant of %s", ErrInvalidInput, *choice.VariantID, item.Name)
+		}
+	}
+
+	return nil
+}
+
+// getItem returns a menu item of the restaurant.
+func (s *MenuService) getItem(ctx context.Context, restaurantID, id uuid.UUID) (*models.MenuItem, error) {
+	item, err := s.menuRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || item.RestaurantID != restaurantID {
+		return nil, fmt.Errorf("%w: menu item %s", ErrNotFound, id)
+	}
+	return item, nil
+}
+
+func validateCustomization(c *models.MenuItemCustomization) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	switch c.FieldType {
+	case models.CustomizationSingleSelect, models.CustomizationMultiSelect:
+		if len(c.Options) == 0 {
+			return fmt.Errorf("%w: %s customizations need at least one option", ErrInvalidInput, c.FieldType)
+		}
+	case models.CustomizationBoolean, models.CustomizationText:
+		if len(c.Options) > 0 {
+			return fmt.Errorf("%w: %s customizations have no options", ErrInvalidInput, c.FieldType)
+		}
+	default:
+		return fmt.Errorf("%w: unknown field type %q", ErrInvalidInput, c.FieldType)
+	}
+
+	seen := make(map[string]bool, len(c.Options))
+	for i := range c.Options {
+		option := &c.Options[i]
+		option.Name = strings.TrimSpace(option.Name)
+		if option.Name == "" {
+			return fmt.Errorf("%w: option names are required", ErrInvalidInput)
+		}
+		if seen[option.Name] {
+			return fmt.Errorf("%w: duplicate option %q", ErrInvalidInput, option.Name)
+		}
+		if option.Price < 0 {
+			return fmt.Errorf("%w: option prices cannot be negative", ErrInvalidInput)
+		}
+		seen[option.Name] = true
+	}
+
+	return nil
+}
+
+func (s *MenuService) attachRatings(ctx context.Context, restaurantID uuid.UUID, items []*models.MenuItem) error {
+	ratings, err := s.reviewRepo.ItemRatings(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if rating, ok := ratings[item.ID]; ok {
+			item.Rating = &rating
+		}
+	}
+
+	return nil
+}
+
+func (s *MenuService) attachVariants(ctx context.Context, restaurantID uuid.UUID, items []*models.MenuItem) error {
+	variants, err := s.variantRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	byItem := make(map[uuid.UUID][]models.MenuItemVariant)
+	for _, variant := range variants {
+		byItem[variant.MenuItemID] = append(byItem[variant.MenuItemID], *variant)
+	}
+	for _, item := range items {
+		item.Variants = byItem[item.ID]
+	}
+
+	return nil
+}
+
+func (s *MenuService) attachBundles(ctx context.Context, restaurantID uuid.UUID, items []*models.MenuItem) error {
+	groups, err := s.bundleRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	byItem := make(map[uuid.UUID][]models.BundleGroup)
+	for _, group := range groups {
+		byItem[group.MenuItemID] = append(byItem[group.MenuItemID], group)
+	}
+	for _, item := range items {
+		item.BundleGroups = byItem[item.ID]
+	}
+
+	return nil
+}
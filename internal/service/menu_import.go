@@ -0,0 +1,446 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+const maxSKULength = 100
+
+// menuCSVColumns are the columns of the CSV import and export format.
+// Allergens and dietary tags are separated by "|" and customizations are
+// a JSON array in one cell.
+var menuCSVColumns = []string{"sku", "category", "name", "description", "price", "allergens", "dietary_tags", "customizations"}
+
+type MenuImportService struct {
+	menuRepo          repository.MenuRepository
+	categoryRepo      repository.CategoryRepository
+	customizationRepo repository.CustomizationRepository
+}
+
+func NewMenuImportService(
+	menuRepo repository.MenuRepository,
+	categoryRepo repository.CategoryRepository,
+	customizationRepo repository.CustomizationRepository,
+) *MenuImportService {
+	return &MenuImportService{
+		menuRepo:          menuRepo,
+		categoryRepo:      categoryRepo,
+		customizationRepo: customizationRepo,
+	}
+}
+
+// ImportJSON imports a menu given in the JSON format.
+func (s *MenuImportService) ImportJSON(ctx context.Context, restaurantID uuid.UUID, menu *models.MenuImport, dryRun bool) (*models.MenuImportResult, error) {
+	for i := range menu.Items {
+		menu.Items[i].Row = i + 1
+	}
+	return s.importMenu(ctx, restaurantID, menu, nil, dryRun)
+}
+
+// ImportCSV imports a menu given in the CSV format. Rows are numbered by
+// line, the header being line 1.
+func (s *MenuImportService) ImportCSV(ctx context.Context, restaurantID uuid.UUID, r io.Reader, dryRun bool) (*models.MenuImportResult, error) {
+	menu, errs := parseMenuCSV(r)
+	return s.importMenu(ctx, restaurantID, menu, errs, dryRun)
+}
+
+// importMenu validates every row, reporting all problems at once, and
+// applies the import in one transaction unless it is a dry run or any row
+// is invalid. Items are upserted by SKU and categories by name.
+func (s *MenuImportService) importMenu(ctx context.Context, restaurantID uuid.UUID, menu *models.MenuImport, errs []models.MenuImportError, dryRun bool) (*models.MenuImportResult, error) {
+	result := &models.MenuImportResult{
+		RestaurantID: restaurantID,
+		DryRun:       dryRun,
+		Rows:         len(menu.Items),
+		Errors:       append([]models.MenuImportError{}, errs...),
+	}
+
+	existingCategories, err := s.categoryRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	existingItems, err := s.menuRepo.List(ctx, restaurantID, models.MenuFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	categoriesByName := make(map[string]*models.Category, len(existingCategories))
+	for _, category := range existingCategories {
+		categoriesByName[strings.ToLower(category.Name)] = category
+	}
+	itemsBySKU := make(map[string]*models.MenuItem, len(existingItems))
+	for _, item := range existingItems {
+		if item.SKU != "" {
+			itemsBySKU[item.SKU] = item
+		}
+	}
+
+	var changed []*models.Category
+	planned := map[string]*models.Category{}
+	isChanged := map[*models.Category]bool{}
+	planCategory := func(name string) *models.Category {
+		key := strings.ToLower(name)
+		if category, ok := planned[key]; ok {
+			return category
+		}
+		category := &models.Category{RestaurantID: restaurantID, Name: name}
+		if existing, ok := categoriesByName[key]; ok {
+			clone := *existing
+			category = &clone
+		} else {
+			changed = append(changed, category)
+			isChanged[category] = true
+			result.CategoriesCreated++
+		}
+		planned[key] = category
+		return category
+	}
+
+	for i, c := range menu.Categories {
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			result.Errors = append(result.Errors, models.MenuImportError{
+				Field:   "categories",
+				Message: fmt.Sprintf("category %d: name is required", i+1),
+			})
+			continue
+		}
+		category := planCategory(name)
+		if description := strings.TrimSpace(c.Description); category.Description != description {
+			category.Description = description
+			if !isChanged[category] {
+				changed = append(changed, category)
+				isChanged[category] = true
+				result.CategoriesUpdated++
+			}
+		}
+	}
+
+	var items []models.MenuImportItem
+	skuRows := map[string]int{}
+	for _, row := range menu.Items {
+		customizations, rowErrs := validateImportRow(&row)
+		if row.SKU != "" {
+			if first, ok := skuRows[row.SKU]; ok {
+				rowErrs = append(rowErrs, models.MenuImportError{
+					Field:   "sku",
+					Message: fmt.Sprintf("SKU is also used on row %d", first),
+				})
+			} else {
+				skuRows[row.SKU] = row.Row
+			}
+		}
+		if len(rowErrs) > 0 {
+			for _, e := range rowErrs {
+				e.Row, e.SKU = row.Row, row.SKU
+				result.Errors = append(result.Errors, e)
+			}
+			continue
+		}
+
+		item := &models.MenuItem{RestaurantID: restaurantID, SKU: row.SKU}
+		if existing, ok := itemsBySKU[row.SKU]; ok {
+			clone := *existing
+			item = &clone
+			result.ItemsUpdated++
+		} else {
+			result.ItemsCreated++
+		}
+		item.Name = row.Name
+		item.Description = row.Description
+		item.Price = row.Price
+		item.Allergens = row.Allergens
+		item.DietaryTags = row.DietaryTags
+
+		items = append(items, models.MenuImportItem{
+			Item:           item,
+			Category:       planCategory(row.Category),
+			Customizations: customizations,
+		})
+	}
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+	if err := s.menuRepo.Import(ctx, changed, items); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+
+	return result, nil
+}
+
+// validateImportRow normalizes a row and returns its customizations, nil
+// when the row leaves them unchanged, and its problems.
+func validateImportRow(row *models.MenuImportRow) ([]models.MenuItemCustomization, []models.MenuImportError) {
+	var errs []models.MenuImportError
+	fail := func(field string, err error) {
+		errs = append(errs, models.MenuImportError{
+			Field:   field,
+			Message: strings.TrimPrefix(err.Error(), ErrInvalidInput.Error()+": "),
+		})
+	}
+
+	row.SKU = strings.TrimSpace(row.SKU)
+	row.Category = strings.TrimSpace(row.Category)
+	row.Name = strings.TrimSpace(row.Name)
+	row.Description = strings.TrimSpace(row.Description)
+
+	if row.SKU == "" {
+		fail("sku", errors.New("SKU is required"))
+	} else if len(row.SKU) > maxSKULength {
+		fail("sku", fmt.Errorf("SKU is longer than %d characters", maxSKULength))
+	}
+	if row.Category == "" {
+		fail("category", errors.New("category is required"))
+	}
+	if row.Name == "" {
+		fail("name", errors.New("name is required"))
+	}
+	if row.Price < 0 {
+		fail("price", errors.New("price cannot be negative"))
+	}
+
+	var err error
+	if row.Allergens, err = validateAllergens(row.Allergens); err != nil {
+		fail("allergens", err)
+	}
+	if row.DietaryTags, err = validateDietaryTags(row.DietaryTags); err != nil {
+		fail("dietary_tags", err)
+	}
+
+	var customizations []models.MenuItemCustomization
+	if row.Customizations != nil {
+		customizations = make([]models.MenuItemCustomization, 0, len(row.Customizations))
+	}
+	names := map[string]bool{}
+	for _, c := range row.Customizations {
+		customization := models.MenuItemCustomization{
+			Name:      c.Name,
+			FieldType: c.FieldType,
+			Options:   c.Options,
+			Required:  c.Required,
+		}
+		if err := validateCustomization(&customization); err != nil {
+			fail("customizations", err)
+			continue
+		}
+		if names[customization.Name] {
+			fail("customizations", fmt.Errorf("duplicate customization %q", customization.Name))
+		}
+		names[customization.Name] = true
+		customizations = append(customizations, customization)
+	}
+
+	return customizations, errs
+}
+
+// Export returns the menu of a restaurant in the import format, so it can
+// be edited and imported again or copied to another restaurant.
+func (s *MenuImportService) Export(ctx context.Context, restaurantID uuid.UUID) (*models.MenuImport, error) {
+	categories, err := s.categoryRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.menuRepo.List(ctx, restaurantID, models.MenuFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	menu := &models.MenuImport{
+		Categories: []models.MenuImportCategory{},
+		Items:      []models.MenuImportRow{},
+	}
+	categoryNames := make(map[uuid.UUID]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+		menu.Categories = append(menu.Categories, models.MenuImportCategory{
+			Name:        category.Name,
+			Description: category.Description,
+		})
+	}
+
+	for _, item := range items {
+		customizations, err := s.customizationRepo.ListByMenuItem(ctx, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		row := models.MenuImportRow{
+			SKU:         item.SKU,
+			Category:    categoryNames[item.CategoryID],
+			Name:        item.Name,
+			Description: item.Description,
+			Price:       item.Price,
+			Allergens:   item.Allergens,
+			DietaryTags: item.DietaryTags,
+		}
+		for _, customization := range customizations {
+			row.Customizations = append(row.Customizations, models.MenuImportCustomization{
+				Name:      customization.Name,
+				FieldType: customization.FieldType,
+				Options:   customization.Options,
+				Required:  customization.Required,
+			})
+		}
+		menu.Items = append(menu.Items, row)
+	}
+
+	return menu, nil
+}
+
+// parseMenuCSV reads a menu in the CSV format. The header row names the
+// columns, in any order. Rows that cannot be read are reported and left
+// out.
+func parseMenuCSV(r io.Reader) (*models.MenuImport, []models.MenuImportError) {
+	menu := &models.MenuImport{}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return menu, []models.MenuImportError{{Message: "the file is empty"}}
+	}
+	if err != nil {
+		return menu, []models.MenuImportError{{Row: 1, Message: err.Error()}}
+	}
+
+	var errs []models.MenuImportError
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !containsString(menuCSVColumns, name) {
+			errs = append(errs, models.MenuImportError{Row: 1, Field: name, Message: "unknown column"})
+			continue
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"sku", "category", "name", "price"} {
+		if _, ok := columns[name]; !ok {
+			errs = append(errs, models.MenuImportError{Row: 1, Field: name, Message: "missing column"})
+		}
+	}
+	if len(errs) > 0 {
+		return menu, errs
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, models.MenuImportError{Row: line, Message: err.Error()})
+			continue
+		}
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		row := models.MenuImportRow{
+			Row:         line,
+			SKU:         cell("sku"),
+			Category:    cell("category"),
+			Name:        cell("name"),
+			Description: cell("description"),
+			Allergens:   splitList[models.Allergen](cell("allergens")),
+			DietaryTags: splitList[models.DietaryTag](cell("dietary_tags")),
+		}
+		if row.Price, err = strconv.ParseFloat(cell("price"), 64); err != nil {
+			errs = append(errs, models.MenuImportError{Row: line, SKU: row.SKU, Field: "price", Message: "price must be a number"})
+			continue
+		}
+		if value := cell("customizations"); value != "" {
+			if err := json.Unmarshal([]byte(value), &row.Customizations); err != nil {
+				errs = append(errs, models.MenuImportError{Row: line, SKU: row.SKU, Field: "customizations", Message: "customizations must be a JSON array"})
+				continue
+			}
+			if row.Customizations == nil {
+				row.Customizations = []models.MenuImportCustomization{}
+			}
+		}
+		menu.Items = append(menu.Items, row)
+	}
+
+	return menu, errs
+}
+
+// WriteMenuCSV writes a menu in the CSV format. Category descriptions are
+// not part of the CSV format.
+func WriteMenuCSV(w io.Writer, menu *models.MenuImport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(menuCSVColumns); err != nil {
+		return err
+	}
+
+	for _, row := range menu.Items {
+		customizations := ""
+		if len(row.Customizations) > 0 {
+			data, err := json.Marshal(row.Customizations)
+			if err != nil {
+				return err
+			}
+			customizations = string(data)
+		}
+		err := writer.Write([]string{
+			row.SKU,
+			row.Category,
+			row.Name,
+			row.Description,
+			strconv.FormatFloat(row.Price, 'f', 2, 64),
+			strings.Join(toStringSlice(row.Allergens), "|"),
+			strings.Join(toStringSlice(row.DietaryTags), "|"),
+			customizations,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func splitList[T ~string](value string) []T {
+	var values []T
+	for _, part := range strings.Split(value, "|") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, T(part))
+		}
+	}
+	return values
+}
+
+func toStringSlice[T ~string](values []T) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type MenuScheduleService struct {
+	scheduleRepo   repository.MenuScheduleRepository
+	restaurantRepo repository.RestaurantRepository
+	menuRepo       repository.MenuRepository
+}
+
+func NewMenuScheduleService(
+	scheduleRepo repository.MenuScheduleRepository,
+	restaurantRepo repository.RestaurantRepository,
+	menuRepo repository.MenuRepository,
+) *MenuScheduleService {
+	return &MenuScheduleService{
+		scheduleRepo:   scheduleRepo,
+		restaurantRepo: restaurantRepo,
+		menuRepo:       menuRepo,
+	}
+}
+
+func (s *MenuScheduleService) Create(ctx context.Context, menu *models.Menu) error {
+	if err := s.validate(ctx, menu); err != nil {
+		return err
+	}
+	return s.scheduleRepo.Create(ctx, menu)
+}
+
+// Get returns a menu of the restaurant.
+func (s *MenuScheduleService) Get(ctx context.Context, restaurantID, id uuid.UUID) (*models.Menu, error) {
+	menu, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if menu == nil || menu.RestaurantID != restaurantID {
+		return nil, fmt.Errorf("%w: menu %s", ErrNotFound, id)
+	}
+	return menu, nil
+}
+
+func (s *MenuScheduleService) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.Menu, error) {
+	return s.scheduleRepo.ListByRestaurant(ctx, restaurantID)
+}
+
+func (s *MenuScheduleService) Update(ctx context.Context, menu *models.Menu) error {
+	if _, err := s.Get(ctx, menu.RestaurantID, menu.ID); err != nil {
+		return err
+	}
+	if err := s.validate(ctx, menu); err != nil {
+		return err
+	}
+	return s.scheduleRepo.Update(ctx, menu)
+}
+
+func (s *MenuScheduleService) Delete(ctx context.Context, restaurantID, id uuid.UUID) error {
+	if _, err := s.Get(ctx, restaurantID, id); err != nil {
+		return err
+	}
+	if err := s.scheduleRepo.Delete(ctx, id); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// LocalTime converts t to the restaurant's timezone.
+func (s *MenuScheduleService) LocalTime(ctx context.Context, restaurantID uuid.UUID, t time.Time) (time.Time, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(restaurantLocation(restaurant)), nil
+}
+
+// Filter returns the items that can be ordered at the given time.
+func (s *MenuScheduleService) Filter(ctx context.Context, restaurantID uuid.UUID, items []*models.MenuItem, at time.Time) ([]*models.MenuItem, error) {
+	schedule, err := s.scheduleAt(ctx, restaurantID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	orderable := []*models.MenuItem{}
+	for _, item := range items {
+		if schedule.orderable(item) {
+			orderable = append(orderable, item)
+		}
+	}
+
+	return orderable, nil
+}
+
+// menuSchedule answers whether items are on a menu that is being served at
+// a given restaurant-local time.
+type menuSchedule struct {
+	at         time.Time
+	byItem     map[uuid.UUID][]*models.Menu
+	byCategory map[uuid.UUID][]*models.Menu
+}
+
+func (s *MenuScheduleService) scheduleAt(ctx context.Context, restaurantID uuid.UUID, at time.Time) (*menuSchedule, error) {
+	local, err := s.LocalTime(ctx, restaurantID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	menus, err := s.scheduleRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &menuSchedule{
+		at:         local,
+		byItem:     make(map[uuid.UUID][]*models.Menu),
+		byCategory: make(map[uuid.UUID][]*models.Menu),
+	}
+	for _, menu := range menus {
+		for _, id := range menu.MenuItemIDs {
+			schedule.byItem[id] = append(schedule.byItem[id], menu)
+		}
+		for _, id := range menu.CategoryIDs {
+			schedule.byCategory[id] = append(schedule.byCategory[id], menu)
+		}
+	}
+
+	return schedule, nil
+}
+
+// orderable reports whether the item can be ordered. Items that are not
+// on any menu, directly or through their category, are always orderable.
+func (m *menuSchedule) orderable(item *models.MenuItem) bool {
+	menus := append(m.byItem[item.ID], m.byCategory[item.CategoryID]...)
+	if len(menus) == 0 {
+		return true
+	}
+	for _, menu := range menus {
+		if menuOpenAt(menu, m.at) {
+			return true
+		}
+	}
+	return false
+}
+
+// menuOpenAt reports whether an active menu is served at the local time t.
+func menuOpenAt(menu *models.Menu, t time.Time) bool {
+	if !menu.Active {
+		return false
+	}
+	if len(menu.Windows) == 0 {
+		return true
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	today := int(t.Weekday())
+	yesterday := (today + 6) % 7
+	servedOn := func(window models.MenuWindow, day int) bool {
+		return len(window.DaysOfWeek) == 0 || containsInt(window.DaysOfWeek, day)
+	}
+
+	for _, window := range menu.Windows {
+		start, err := parseClock(window.StartTime)
+		if err != nil {
+			continue
+		}
+		end, err := parseClock(window.EndTime)
+		if err != nil {
+			continue
+		}
+
+		if start < end {
+			if now >= start && now < end && servedOn(window, today) {
+				return true
+			}
+			continue
+		}
+
+		// Past midnight the window still belongs to the day it started
+		if (now >= start && servedOn(window, today)) || (now < end && servedOn(window, yesterday)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *MenuScheduleService) validate(ctx context.Context, menu *models.Menu) error {
+	menu.Name = strings.TrimSpace(menu.Name)
+	if menu.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	if menu.Windows == nil {
+		menu.Windows = []models.MenuWindow{}
+	}
+	for _, window := range menu.Windows {
+		start, err := parseClock(window.StartTime)
+		if err != nil {
+			return err
+		}
+		end, err := parseClock(window.EndTime)
+		if err != nil {
+			return err
+		}
+		if start == end {
+			return fmt.Errorf("%w: a window must not start and end at the same time", ErrInvalidInput)
+		}
+		for _, day := range window.DaysOfWeek {
+			if day < 0 || day > 6 {
+				return fmt.Errorf("%w: days of week run from 0 (Sunday) to 6", ErrInvalidInput)
+			}
+		}
+	}
+
+	menu.MenuItemIDs = uniqueIDs(menu.MenuItemIDs)
+	for _, id := range menu.MenuItemIDs {
+		item, err := s.menuRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if item == nil || item.RestaurantID != menu.RestaurantID {
+			return fmt.Errorf("%w: menu item %s is not on the restaurant's menu", ErrInvalidInput, id)
+		}
+	}
+
+	menu.CategoryIDs = uniqueIDs(menu.CategoryIDs)
+	if len(menu.CategoryIDs) > 0 {
+		count, err := s.scheduleRepo.CountCategories(ctx, menu.RestaurantID, menu.CategoryIDs)
+		if err != nil {
+			return err
+		}
+		if count != len(menu.CategoryIDs) {
+			return fmt.Errorf("%w: every category must belong to the restaurant", ErrInvalidInput)
+		}
+	}
+
+	return nil
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := []uuid.UUID{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
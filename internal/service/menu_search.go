@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// maxSearchLength caps the search text so a query stays cheap to parse.
+const maxSearchLength = 200
+
+// priceBreaks are the bounds of the price range facets.
+var priceBreaks = []float64{10, 20, 30, 50}
+
+type MenuSearchService struct {
+	menuRepo     repository.MenuRepository
+	categoryRepo repository.CategoryRepository
+}
+
+func NewMenuSearchService(menuRepo repository.MenuRepository, categoryRepo repository.CategoryRepository) *MenuSearchService {
+	return &MenuSearchService{
+		menuRepo:     menuRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// Search finds the items of a restaurant matching the query text, falling
+// back to close spellings if no word matches. Facets are counted over
+// every hit before the filters are applied.
+func (s *MenuSearchService) Search(ctx context.Context, restaurantID uuid.UUID, query models.MenuSearchQuery) (*models.MenuSearchResult, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, fmt.Errorf("%w: search text is required", ErrInvalidInput)
+	}
+	if len(query.Text) > maxSearchLength {
+		return nil, fmt.Errorf("%w: search text is longer than %d characters", ErrInvalidInput, maxSearchLength)
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return nil, fmt.Errorf("%w: min_price is above max_price", ErrInvalidInput)
+	}
+	var err error
+	if query.DietaryTags, err = validateDietaryTags(query.DietaryTags); err != nil {
+		return nil, err
+	}
+
+	result := &models.MenuSearchResult{Query: query.Text}
+
+	hits, err := s.menuRepo.Search(ctx, restaurantID, query.Text)
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		if hits, err = s.menuRepo.SearchFuzzy(ctx, restaurantID, query.Text); err != nil {
+			return nil, err
+		}
+		result.Fuzzy = true
+	}
+
+	if result.Facets, err = s.facets(ctx, restaurantID, hits); err != nil {
+		return nil, err
+	}
+
+	categories := toSet(query.CategoryIDs)
+	matched := []models.MenuSearchHit{}
+	for _, hit := range hits {
+		if !matchesSearchFilters(hit.Item, query, categories) {
+			continue
+		}
+		// ts_headline returns the text as is when nothing in it matched
+		if !strings.Contains(hit.Highlights.Name, "<mark>") {
+			hit.Highlights.Name = ""
+		}
+		if !strings.Contains(hit.Highlights.Description, "<mark>") {
+			hit.Highlights.Description = ""
+		}
+		matched = append(matched, hit)
+	}
+
+	result.Total = len(matched)
+	start := min(query.Offset, len(matched))
+	end := len(matched)
+	if query.Limit > 0 {
+		end = min(start+query.Limit, end)
+	}
+	result.Hits = matched[start:end]
+
+	return result, nil
+}
+
+func (s *MenuSearchService) facets(ctx context.Context, restaurantID uuid.UUID, hits []models.MenuSearchHit) (models.MenuSearchFacets, error) {
+	facets := models.MenuSearchFacets{
+		Categories:  []models.CategoryFacet{},
+		PriceRanges: []models.PriceRangeFacet{},
+		DietaryTags: []models.DietaryTagFacet{},
+	}
+	if len(hits) == 0 {
+		return facets, nil
+	}
+
+	categories, err := s.categoryRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return facets, err
+	}
+	names := make(map[uuid.UUID]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	categoryCounts := map[uuid.UUID]int{}
+	tagCounts := map[models.DietaryTag]int{}
+	priceCounts := make([]int, len(priceBreaks)+1)
+	for _, hit := range hits {
+		categoryCounts[hit.Item.CategoryID]++
+		for _, tag := range hit.Item.DietaryTags {
+			tagCounts[tag]++
+		}
+		bucket := 0
+		for bucket < len(priceBreaks) && hit.Item.Price >= priceBreaks[bucket] {
+			bucket++
+		}
+		priceCounts[bucket]++
+	}
+
+	for id, count := range categoryCounts {
+		facets.Categories = append(facets.Categories, models.CategoryFacet{CategoryID: id, Name: names[id], Count: count})
+	}
+	sort.Slice(facets.Categories, func(i, j int) bool {
+		a, b := facets.Categories[i], facets.Categories[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Name < b.Name
+	})
+
+	for tag, count := range tagCounts {
+		facets.DietaryTags = append(facets.DietaryTags, models.DietaryTagFacet{Tag: tag, Count: count})
+	}
+	sort.Slice(facets.DietaryTags, func(i, j int) bool {
+		a, b := facets.DietaryTags[i], facets.DietaryTags[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Tag < b.Tag
+	})
+
+	for i, count := range priceCounts {
+		if count == 0 {
+			continue
+		}
+		bucket := models.PriceRangeFacet{Count: count}
+		if i > 0 {
+			bucket.Min = priceBreaks[i-1]
+		}
+		if i < len(priceBreaks) {
+			upper := priceBreaks[i]
+			bucket.Max = &upper
+		}
+		facets.PriceRanges = append(facets.PriceRanges, bucket)
+	}
+
+	return facets, nil
+}
+
+func matchesSearchFilters(item *models.MenuItem, query models.MenuSearchQuery, categories map[uuid.UUID]bool) bool {
+	if len(categories) > 0 && !categories[item.CategoryID] {
+		return false
+	}
+	if query.MinPrice != nil && item.Price < *query.MinPrice {
+		return false
+	}
+	if query.MaxPrice != nil && item.Price > *query.MaxPrice {
+		return false
+	}
+	tags := toSet(item.DietaryTags)
+	for _, tag := range query.DietaryTags {
+		if !tags[tag] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,474 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type MenuVersionService struct {
+	versionRepo  repository.MenuVersionRepository
+	menuRepo     repository.MenuRepository
+	categoryRepo repository.CategoryRepository
+}
+
+func NewMenuVersionService(
+	versionRepo repository.MenuVersionRepository,
+	menuRepo repository.MenuRepository,
+	categoryRepo repository.CategoryRepository,
+) *MenuVersionService {
+	return &MenuVersionService{
+		versionRepo:  versionRepo,
+		menuRepo:     menuRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// CreateDraft opens a draft for the restaurant. Without items the draft
+// starts as a copy of the live menu. A restaurant has one open draft at a
+// time.
+func (s *MenuVersionService) CreateDraft(ctx context.Context, user *models.AuthUser, version *models.MenuVersion) error {
+	if err := s.requireNoOpen(ctx, version.RestaurantID); err != nil {
+		return err
+	}
+
+	version.Status = models.MenuVersionDraft
+	version.Note = strings.TrimSpace(version.Note)
+	version.PublishAt = nil
+	version.PublishedAt = nil
+	version.CreatedBy = user.ID
+
+	if version.Items == nil {
+		live, err := s.menuRepo.List(ctx, version.RestaurantID, models.MenuFilter{})
+		if err != nil {
+			return err
+		}
+		version.Items = make([]models.MenuVersionItem, 0, len(live))
+		for _, item := range live {
+			version.Items = append(version.Items, toVersionItem(item))
+		}
+	} else if err := s.validateItems(ctx, version.RestaurantID, version.Items); err != nil {
+		return err
+	}
+
+	return s.versionRepo.Create(ctx, version)
+}
+
+// List returns the versions of a restaurant, newest first, without their
+// items.
+func (s *MenuVersionService) List(ctx context.Context, restaurantID uuid.UUID) ([]*models.MenuVersion, error) {
+	versions, err := s.versionRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		version.Items = nil
+	}
+	return versions, nil
+}
+
+func (s *MenuVersionService) Get(ctx context.Context, restaurantID, id uuid.UUID) (*models.MenuVersion, error) {
+	return s.getVersion(ctx, restaurantID, id)
+}
+
+// UpdateDraft replaces the note and items of a draft or scheduled version.
+// A scheduled version keeps its publish time.
+func (s *MenuVersionService) UpdateDraft(ctx context.Context, version *models.MenuVersion) (*models.MenuVersion, error) {
+	existing, err := s.getOpen(ctx, version.RestaurantID, version.ID)
+	if err != nil {
+		return nil, err
+	}
+	if version.Items == nil {
+		version.Items = []models.MenuVersionItem{}
+	}
+	if err := s.validateItems(ctx, version.RestaurantID, version.Items); err != nil {
+		return nil, err
+	}
+
+	existing.Note = strings.TrimSpace(version.Note)
+	existing.Items = version.Items
+	if err := s.versionRepo.Update(ctx, existing); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: version %d has already been published", ErrConflict, existing.Number)
+		}
+		return nil, err
+	}
+	return existing, nil
+}
+
+// Discard deletes a draft or scheduled version.
+func (s *MenuVersionService) Discard(ctx context.Context, restaurantID, id uuid.UUID) error {
+	existing, err := s.getOpen(ctx, restaurantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.versionRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: version %d has already been published", ErrConflict, existing.Number)
+		}
+		return err
+	}
+	return nil
+}
+
+// Preview lists what publishing a version would change on the live menu.
+func (s *MenuVersionService) Preview(ctx context.Context, restaurantID, id uuid.UUID) (*models.MenuVersionPreview, error) {
+	version, err := s.getVersion(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+	preview, _, err := s.plan(ctx, version)
+	return preview, err
+}
+
+// Publish publishes a draft right away, or schedules it if PublishAt is in
+// the future. Publishing a scheduled version without a time publishes it
+// now.
+func (s *MenuVersionService) Publish(ctx context.Context, restaurantID, id uuid.UUID, req models.MenuVersionPublish) (*models.MenuVersion, error) {
+	version, err := s.getOpen(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PublishAt != nil && req.PublishAt.After(time.Now()) {
+		version.Status = models.MenuVersionScheduled
+		version.PublishAt = req.PublishAt
+		if err := s.versionRepo.Update(ctx, version); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: version %d has already been published", ErrConflict, version.Number)
+			}
+			return nil, err
+		}
+		return version, nil
+	}
+
+	if err := s.publish(ctx, version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// Rollback republishes an earlier version as a new version, so the history
+// stays linear. It fails while another draft is open.
+func (s *MenuVersionService) Rollback(ctx context.Context, user *models.AuthUser, restaurantID, id uuid.UUID) (*models.MenuVersion, error) {
+	target, err := s.getVersion(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch target.Status {
+	case models.MenuVersionPublished:
+		return nil, fmt.Errorf("%w: version %d is already live", ErrConflict, target.Number)
+	case models.MenuVersionDraft, models.MenuVersionScheduled:
+		return nil, fmt.Errorf("%w: version %d has never been published", ErrInvalidInput, target.Number)
+	}
+	if err := s.requireNoOpen(ctx, restaurantID); err != nil {
+		return nil, err
+	}
+
+	version := &models.MenuVersion{
+		RestaurantID: restaurantID,
+		Status:       models.MenuVersionDraft,
+		Note:         fmt.Sprintf("Rollback to version %d", target.Number),
+		Items:        target.Items,
+		CreatedBy:    user.ID,
+	}
+	if err := s.versionRepo.Create(ctx, version); err != nil {
+		return nil, err
+	}
+	if err := s.publish(ctx, version); err != nil {
+		if discardErr := s.versionRepo.Delete(ctx, version.ID); discardErr != nil {
+			log.Printf("menu versions: discarding failed rollback %s: %v", version.ID, discardErr)
+		}
+		return nil, err
+	}
+	return version, nil
+}
+
+// PublishDue publishes the scheduled versions whose time has come. A
+// version that fails to publish is logged and retried on the next run.
+func (s *MenuVersionService) PublishDue(ctx context.Context, now time.Time) error {
+	due, err := s.versionRepo.ListDue(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, version := range due {
+		if err := s.publish(ctx, version); err != nil {
+			log.Printf("menu versions: publishing version %d of restaurant %s: %v", version.Number, version.RestaurantID, err)
+		}
+	}
+	return nil
+}
+
+// Run publishes due versions every interval until the context is canceled.
+func (s *MenuVersionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.PublishDue(ctx, now); err != nil {
+				log.Printf("menu versions: %v", err)
+			}
+		}
+	}
+}
+
+// Current returns the ID of the restaurant's live version, or nil if it
+// has never published one.
+func (s *MenuVersionService) Current(ctx context.Context, restaurantID uuid.UUID) (*uuid.UUID, error) {
+	version, err := s.versionRepo.GetPublished(ctx, restaurantID)
+	if err != nil || version == nil {
+		return nil, err
+	}
+	return &version.ID, nil
+}
+
+func (s *MenuVersionService) publish(ctx context.Context, version *models.MenuVersion) error {
+	if err := s.validateItems(ctx, version.RestaurantID, version.Items); err != nil {
+		return err
+	}
+	_, changes, err := s.plan(ctx, version)
+	if err != nil {
+		return err
+	}
+	if err := s.versionRepo.Publish(ctx, version, changes); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: version %d has already been published or discarded", ErrConflict, version.Number)
+		}
+		return err
+	}
+	return nil
+}
+
+// plan diffs a version against the live menu. Items the version adds get
+// their IDs here, which are written back into the version so that a later
+// rollback finds them. Live items missing from the version are removed;
+// retired items the version still has are brought back.
+func (s *MenuVersionService) plan(ctx context.Context, version *models.MenuVersion) (*models.MenuVersionPreview, *models.MenuChangeSet, error) {
+	live, err := s.menuRepo.List(ctx, version.RestaurantID, models.MenuFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+	liveByID := make(map[uuid.UUID]*models.MenuItem, len(live))
+	for _, item := range live {
+		liveByID[item.ID] = item
+	}
+
+	preview := &models.MenuVersionPreview{VersionID: version.ID, Changes: []models.MenuItemChange{}}
+	changes := &models.MenuChangeSet{Availability: map[uuid.UUID]bool{}}
+	kept := map[uuid.UUID]bool{}
+
+	for i := range version.Items {
+		entry := &version.Items[i]
+
+		if entry.MenuItemID == nil {
+			id := uuid.New()
+			entry.MenuItemID = &id
+		}
+		id := *entry.MenuItemID
+		kept[id] = true
+
+		existing, ok := liveByID[id]
+		if !ok {
+			// Retired items are hidden from the live menu but still exist
+			retired, err := s.menuRepo.GetByID(ctx, id)
+			if err != nil {
+				return nil, nil, err
+			}
+			if retired != nil && retired.RestaurantID != version.RestaurantID {
+				return nil, nil, fmt.Errorf("%w: item %d belongs to another restaurant", ErrInvalidInput, i+1)
+			}
+
+			item := &models.MenuItem{ID: id, RestaurantID: version.RestaurantID}
+			if retired != nil {
+				*item = *retired
+				changes.Update = append(changes.Update, item)
+				changes.Availability[id] = true
+			} else {
+				changes.Create = append(changes.Create, item)
+			}
+			applyVersionItem(item, entry)
+			price := item.Price
+			preview.Changes = append(preview.Changes, models.MenuItemChange{
+				Action:     models.MenuChangeCreate,
+				MenuItemID: &id,
+				Name:       item.Name,
+				NewPrice:   &price,
+			})
+			continue
+		}
+
+		item := *existing
+		applyVersionItem(&item, entry)
+		fields := changedFields(existing, &item)
+		if len(fields) == 0 {
+			continue
+		}
+		changes.Update = append(changes.Update, &item)
+
+		change := models.MenuItemChange{
+			Action:     models.MenuChangeUpdate,
+			MenuItemID: &existing.ID,
+			Name:       item.Name,
+			Fields:     fields,
+		}
+		if existing.Price != item.Price {
+			oldPrice, newPrice := existing.Price, item.Price
+			change.OldPrice, change.NewPrice = &oldPrice, &newPrice
+		}
+		preview.Changes = append(preview.Changes, change)
+	}
+
+	for _, item := range live {
+		if kept[item.ID] {
+			continue
+		}
+		changes.Remove = append(changes.Remove, item.ID)
+		preview.Changes = append(preview.Changes, models.MenuItemChange{
+			Action:     models.MenuChangeRemove,
+			MenuItemID: &item.ID,
+			Name:       item.Name,
+		})
+	}
+
+	return preview, changes, nil
+}
+
+// validateItems checks the items of a version the way menu item edits are
+// checked, and requires SKUs and menu item IDs to be unique within it.
+func (s *MenuVersionService) validateItems(ctx context.Context, restaurantID uuid.UUID, items []models.MenuVersionItem) error {
+	categories, err := s.categoryRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+	categoryIDs := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		categoryIDs[category.ID] = true
+	}
+
+	skus := map[string]bool{}
+	ids := map[uuid.UUID]bool{}
+	for i := range items {
+		entry := &items[i]
+		entry.SKU = strings.TrimSpace(entry.SKU)
+		entry.Name = strings.TrimSpace(entry.Name)
+		entry.Description = strings.TrimSpace(entry.Description)
+
+		if entry.Name == "" {
+			return fmt.Errorf("%w: item %d: name is required", ErrInvalidInput, i+1)
+		}
+		if entry.Price < 0 {
+			return fmt.Errorf("%w: item %d: price cannot be negative", ErrInvalidInput, i+1)
+		}
+		if !categoryIDs[entry.CategoryID] {
+			return fmt.Errorf("%w: item %d: category %s not found", ErrInvalidInput, i+1, entry.CategoryID)
+		}
+		if len(entry.SKU) > maxSKULength {
+			return fmt.Errorf("%w: item %d: SKU is longer than %d characters", ErrInvalidInput, i+1, maxSKULength)
+		}
+		if entry.SKU != "" {
+			if skus[entry.SKU] {
+				return fmt.Errorf("%w: item %d: SKU %q is used twice", ErrInvalidInput, i+1, entry.SKU)
+			}
+			skus[entry.SKU] = true
+		}
+		if entry.MenuItemID != nil {
+			if ids[*entry.MenuItemID] {
+				return fmt.Errorf("%w: item %d: menu item %s is listed twice", ErrInvalidInput, i+1, *entry.MenuItemID)
+			}
+			ids[*entry.MenuItemID] = true
+		}
+
+		item := &models.MenuItem{}
+		applyVersionItem(item, entry)
+		if err := validateDietary(item); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+		if item.Translations, err = normalizeTranslations(item.Translations, true); err != nil {
+			return fmt.Errorf("item %d: %w", i+1, err)
+		}
+		entry.Allergens = item.Allergens
+		entry.DietaryTags = item.DietaryTags
+		entry.Translations = item.Translations
+	}
+
+	return nil
+}
+
+func (s *MenuVersionService) requireNoOpen(ctx context.Context, restaurantID uuid.UUID) error {
+	versions, err := s.versionRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if version.Status == models.MenuVersionDraft || version.Status == models.MenuVersionScheduled {
+			return fmt.Errorf("%w: version %d is still open, publish or discard it first", ErrConflict, version.Number)
+		}
+	}
+	return nil
+}
+
+// getVersion returns a version of the restaurant.
+func (s *MenuVersionService) getVersion(ctx context.Context, restaurantID, id uuid.UUID) (*models.MenuVersion, error) {
+	version, err := s.versionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version == nil || version.RestaurantID != restaurantID {
+		return nil, fmt.Errorf("%w: menu version %s", ErrNotFound, id)
+	}
+	return version, nil
+}
+
+// getOpen returns a version of the restaurant that can still be edited.
+func (s *MenuVersionService) getOpen(ctx context.Context, restaurantID, id uuid.UUID) (*models.MenuVersion, error) {
+	version, err := s.getVersion(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if version.Status != models.MenuVersionDraft && version.Status != models.MenuVersionScheduled {
+		return nil, fmt.Errorf("%w: version %d is %s and can no longer be changed", ErrConflict, version.Number, version.Status)
+	}
+	return version, nil
+}
+
+func toVersionItem(item *models.MenuItem) models.MenuVersionItem {
+	id := item.ID
+	return models.MenuVersionItem{
+		MenuItemID:   &id,
+		SKU:          item.SKU,
+		CategoryID:   item.CategoryID,
+		Name:         item.Name,
+		Description:  item.Description,
+		Translations: item.Translations,
+		Price:        item.Price,
+		Allergens:    item.Allergens,
+		DietaryTags:  item.DietaryTags,
+		Nutrition:    item.Nutrition,
+	}
+}
+
+// applyVersionItem copies the versioned content onto a menu item, leaving
+// stock, availability and the brand link alone.
+func applyVersionItem(item *models.MenuItem, entry *models.MenuVersionItem) {
+	item.SKU = entry.SKU
+	item.CategoryID = entry.CategoryID
+	item.Name = entry.Name
+	item.Description = entry.Description
+	item.Translations = entry.Translations
+	item.Price = entry.Price
+	item.Allergens = entry.Allergens
+	item.DietaryTags = entry.DietaryTags
+	item.Nutrition = entry.Nutrition
+}
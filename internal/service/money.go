@@ -0,0 +1,14 @@
+package service
+
+import "math"
+
+// Amounts are stored as DECIMAL(10,2) but handled as float64; arithmetic
+// that must add up exactly (splits, refunds) is done in integer cents.
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,26 @@
+package service
+
+import "testing"
+
+func TestToCents(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   int64
+	}{
+		{0, 0},
+		{0.01, 1},
+		{0.1 + 0.2, 30},
+		{1.005, 100},
+		{19.99, 1999},
+		{1234.565, 123457},
+		{-4.5, -450},
+	}
+	for _, tt := range tests {
+		if got := toCents(tt.amount); got != tt.want {
+			t.Errorf("toCents(%v) = %d, want %d", tt.amount, got, tt.want)
+		}
+		if got := toCents(fromCents(tt.want)); got != tt.want {
+			t.Errorf("toCents(fromCents(%d)) = %d", tt.want, got)
+		}
+	}
+}
@@ -2,24 +2,338 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
 
 	"github.com/KNLopez/restaurant-api/internal/models"
 	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/KNLopez/restaurant-api/internal/tenant"
 	"github.com/google/uuid"
 )
 
 type OrderService struct {
-	orderRepo repository.OrderRepository
+	orderRepo   repository.OrderRepository
+	checkRepo   repository.CheckRepository
+	menuRepo    repository.MenuRepository
+	variantRepo repository.VariantRepository
+	pricing     *PricingService
+	loyalty     *LoyaltyService
+	inventory   *InventoryService
+	schedules   *MenuScheduleService
+	dietary     *DietaryService
+	versions    *MenuVersionService
+	hours       *HoursService
+	settings    *SettingsService
 }
 
-func NewOrderService(orderRepo repository.OrderRepository) *OrderService {
+func NewOrderService(
+	orderRepo repository.OrderRepository,
+	checkRepo repository.CheckRepository,
+	menuRepo repository.MenuRepository,
+	variantRepo repository.VariantRepository,
+	pricing *PricingService,
+	loyalty *LoyaltyService,
+	inventory *InventoryService,
+	schedules *MenuScheduleService,
+	dietary *DietaryService,
+	versions *MenuVersionService,
+	hours *HoursService,
+	settings *SettingsService,
+) *OrderService {
 	return &OrderService{
-		orderRepo: orderRepo,
+		orderRepo:   orderRepo,
+		checkRepo:   checkRepo,
+		menuRepo:    menuRepo,
+		variantRepo: variantRepo,
+		pricing:     pricing,
+		loyalty:     loyalty,
+		inventory:   inventory,
+		schedules:   schedules,
+		dietary:     dietary,
+		versions:    versions,
+		hours:       hours,
+		settings:    settings,
 	}
 }
 
+// Create prices the order from the menu, pricing rules, redeemed loyalty
+// points and the restaurant's service charge and tax, and stores it
+// together with its applied discounts and the menu version it was priced
+// against. Items that clash with the guest's allergy profile are reported
+// as warnings. While the restaurant is closed an order must be scheduled
+// for a time it is open, and pickup orders must be collected while it is
+// open. Restaurants that auto-accept orders accept those due now straight
+// away; if that fails the order is still placed and waits for the
+// restaurant, with a warning saying so.
 func (s *OrderService) Create(ctx context.Context, order *models.Order) error {
-	return s.orderRepo.Create(ctx, order)
+	order.Status = models.OrderStatusPending
+	if err := validateOrderType(order); err != nil {
+		return err
+	}
+	if err := s.checkOpen(ctx, order); err != nil {
+		return err
+	}
+	if err := s.checkPickup(ctx, order); err != nil {
+		return err
+	}
+	if err := s.checkAvailability(ctx, order, nil); err != nil {
+		return err
+	}
+	var err error
+	if order.MenuVersionID, err = s.versions.Current(ctx, order.RestaurantID); err != nil {
+		return err
+	}
+	if err := s.price(ctx, order, true); err != nil {
+		return err
+	}
+	settings, err := s.settings.For(ctx, order.RestaurantID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.orderRepo.Create(ctx, order); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrUsageLimitReached):
+			return fmt.Errorf("%w: a discount on this order is no longer available", ErrConflict)
+		case errors.Is(err, repository.ErrInsufficientBalance):
+			return fmt.Errorf("%w: not enough loyalty points", ErrConflict)
+		case errors.Is(err, repository.ErrSoldOut):
+			return fmt.Errorf("%w: an item on this order has just sold out", ErrConflict)
+		}
+		return err
+	}
+
+	if settings.AutoAcceptOrders && order.ScheduledFor == nil {
+		s.autoAccept(ctx, order)
+	}
+	return nil
+}
+
+// autoAccept accepts a newly placed order for the restaurant. The order is
+// placed whether or not that works, so a failure is logged and reported as
+// a warning rather than returned; the order is then still pending, since
+// accepting changes nothing unless it all goes through.
+func (s *OrderService) autoAccept(ctx context.Context, order *models.Order) {
+	// The restaurant accepts the order, not the guest who placed it, so its
+	// stock is deducted whoever that is.
+	ctx = tenant.Unscoped(ctx)
+	err := s.UpdateStatus(ctx, order.ID, models.OrderStatusAccepted)
+	if err == nil {
+		order.Status = models.OrderStatusAccepted
+		return
+	}
+
+	log.Printf("orders: auto-accepting order %s: %v", order.ID, err)
+	order.Warnings = append(order.Warnings, models.OrderWarning{
+		Message: "the order could not be accepted automatically and is waiting for the restaurant",
+	})
+}
+
+// checkOpen rejects orders for right now while the restaurant is closed and
+// orders scheduled for the past or for a time it is closed.
+func (s *OrderService) checkOpen(ctx context.Context, order *models.Order) error {
+	now := time.Now()
+	if order.ScheduledFor != nil {
+		if !order.ScheduledFor.After(now) {
+			return fmt.Errorf("%w: scheduled_for must be in the future", ErrInvalidInput)
+		}
+		status, err := s.hours.Status(ctx, order.RestaurantID, *order.ScheduledFor)
+		if err != nil {
+			return err
+		}
+		if !status.IsOpen {
+			return fmt.Errorf("%w: the restaurant is closed at the scheduled time", ErrInvalidInput)
+		}
+		return nil
+	}
+
+	status, err := s.hours.Status(ctx, order.RestaurantID, now)
+	if err != nil {
+		return err
+	}
+	if status.IsOpen {
+		return nil
+	}
+	if status.NextOpenAt != nil {
+		return fmt.Errorf("%w: the restaurant is closed until %s; schedule the order for then or later", ErrConflict, status.NextOpenAt.Format(time.RFC3339))
+	}
+	return fmt.Errorf("%w: the restaurant is closed", ErrConflict)
+}
+
+// checkPickup rejects pickup times in the past, before the order is due or
+// when the restaurant is closed.
+func (s *OrderService) checkPickup(ctx context.Context, order *models.Order) error {
+	if order.Type != models.OrderTypePickup {
+		return nil
+	}
+	if !order.PickupAt.After(time.Now()) {
+		return fmt.Errorf("%w: pickup_at must be in the future", ErrInvalidInput)
+	}
+	if order.ScheduledFor != nil && order.PickupAt.Before(*order.ScheduledFor) {
+		return fmt.Errorf("%w: pickup_at must not be before scheduled_for", ErrInvalidInput)
+	}
+	status, err := s.hours.Status(ctx, order.RestaurantID, *order.PickupAt)
+	if err != nil {
+		return err
+	}
+	if !status.IsOpen {
+		return fmt.Errorf("%w: the restaurant is closed at the pickup time", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Quote prices an order without placing it.
+func (s *OrderService) Quote(ctx context.Context, order *models.Order) error {
+	if err := validateOrderType(order); err != nil {
+		return err
+	}
+	if err := s.checkAvailability(ctx, order, nil); err != nil {
+		return err
+	}
+	return s.price(ctx, order, true)
+}
+
+// checkAvailability rejects items, bundle components and variants that are
+// sold out, do not have enough portions left for the quantity ordered or
+// are not on a menu being served when the order is due. held are the
+// portions the order has claimed already, so only quantities beyond them
+// must still be left. Orders are checked when they are placed and again
+// whenever their items are edited.
+func (s *OrderService) checkAvailability(ctx context.Context, order *models.Order, held map[uuid.UUID]int) error {
+	schedule, err := s.schedules.scheduleAt(ctx, order.RestaurantID, dueAt(order, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	standalone := make(map[uuid.UUID]bool)
+	var variantIDs []uuid.UUID
+	for _, item := range order.Items {
+		standalone[item.MenuItemID] = true
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+		for _, component := range item.Components {
+			if component.VariantID != nil {
+				variantIDs = append(variantIDs, *component.VariantID)
+			}
+		}
+	}
+
+	for id, quantity := range portions(order.Items) {
+		menuItem, err := s.menuRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Items that are not on the menu at all are reported when pricing
+		if menuItem == nil {
+			continue
+		}
+		if quantity -= held[id]; quantity > 0 {
+			if !menuItem.Available {
+				return fmt.Errorf("%w: %s is sold out", ErrConflict, menuItem.Name)
+			}
+			if menuItem.PortionsRemaining != nil && *menuItem.PortionsRemaining < quantity {
+				return fmt.Errorf("%w: only %d portions of %s are left", ErrConflict, *menuItem.PortionsRemaining, menuItem.Name)
+			}
+		}
+		// Components are served with their bundle, whatever menu they are on
+		if standalone[id] && !schedule.orderable(menuItem) {
+			return fmt.Errorf("%w: %s is not being served at this time", ErrConflict, menuItem.Name)
+		}
+	}
+
+	for _, id := range variantIDs {
+		variant, err := s.variantRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Unknown variants are reported when pricing
+		if variant != nil && !variant.Available {
+			return fmt.Errorf("%w: %s is sold out", ErrConflict, variant.Name)
+		}
+	}
+
+	return nil
+}
+
+// dueAt returns when an order is made: at the time it is scheduled for, or
+// now if it is for right now or its time has passed while it was pending.
+func dueAt(order *models.Order, now time.Time) time.Time {
+	if order.ScheduledFor != nil && order.ScheduledFor.After(now) {
+		return *order.ScheduledFor
+	}
+	return now
+}
+
+// portions returns the portions items take of each menu item, counting
+// bundle components once per bundle ordered.
+func portions(items []models.OrderItem) map[uuid.UUID]int {
+	quantities := make(map[uuid.UUID]int)
+	for _, item := range items {
+		quantities[item.MenuItemID] += item.Quantity
+		for _, component := range item.Components {
+			quantities[component.MenuItemID] += component.Quantity * item.Quantity
+		}
+	}
+	return quantities
+}
+
+func (s *OrderService) price(ctx context.Context, order *models.Order, checkBalance bool) error {
+	if len(order.Items) == 0 {
+		return fmt.Errorf("%w: order must contain at least one item", ErrInvalidInput)
+	}
+	for _, item := range order.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: item quantity must be greater than 0", ErrInvalidInput)
+		}
+	}
+	// Happy hours and other timed offers apply when the order is made
+	if err := s.pricing.PriceOrder(ctx, order, dueAt(order, time.Now())); err != nil {
+		return err
+	}
+	if err := s.loyalty.ApplyRedemption(ctx, order, checkBalance); err != nil {
+		return err
+	}
+	settings, err := s.settings.For(ctx, order.RestaurantID)
+	if err != nil {
+		return err
+	}
+	applyCharges(order, settings)
+
+	order.Warnings, err = s.dietary.OrderWarnings(ctx, order)
+	return err
+}
+
+// applyCharges adds the restaurant's service charge to the discounted
+// total, and its delivery fee to delivery orders, then tax on all of them.
+// Inclusive tax is already in the prices and is only broken out.
+func applyCharges(order *models.Order, settings models.RestaurantSettings) {
+	net := toCents(order.TotalAmount)
+	serviceCharge := int64(math.Round(float64(net) * settings.ServiceChargeRate / 100))
+	var deliveryFee int64
+	if order.Type == models.OrderTypeDelivery {
+		deliveryFee = toCents(settings.DeliveryFee)
+	}
+	total := net + serviceCharge + deliveryFee
+
+	var tax int64
+	switch settings.TaxMode {
+	case models.TaxModeExclusive:
+		tax = int64(math.Round(float64(total) * settings.TaxRate / 100))
+		total += tax
+	case models.TaxModeInclusive:
+		tax = total - int64(math.Round(float64(total)/(1+settings.TaxRate/100)))
+	}
+
+	order.ServiceCharge = fromCents(serviceCharge)
+	order.DeliveryFee = fromCents(deliveryFee)
+	order.TaxTotal = fromCents(tax)
+	order.TotalAmount = fromCents(total)
+	order.Currency = settings.Currency
 }
 
 func (s *OrderService) GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
@@ -30,14 +344,258 @@ func (s *OrderService) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*mo
 	return s.orderRepo.GetByUserID(ctx, userID)
 }
 
+func (s *OrderService) ListByUser(ctx context.Context, userID uuid.UUID, filter models.OrderFilter) ([]*models.Order, error) {
+	return s.orderRepo.ListByUser(ctx, userID, filter)
+}
+
+// Reorder places a new order with the items of an earlier one at current
+// menu prices. Items that are no longer on the menu are left out, and
+// both they and price changes are reported as warnings. The new order is
+// of the same type, without the table; a pickup is due as long after
+// ordering as the earlier one was.
+func (s *OrderService) Reorder(ctx context.Context, id uuid.UUID, user *models.AuthUser) (*models.ReorderResult, error) {
+	previous, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		return nil, fmt.Errorf("%w: order %s", ErrNotFound, id)
+	}
+	if !user.IsStaff() && previous.UserID != user.ID {
+		return nil, fmt.Errorf("%w: order belongs to another user", ErrForbidden)
+	}
+
+	order := &models.Order{
+		UserID:          previous.UserID,
+		RestaurantID:    previous.RestaurantID,
+		Type:            previous.Type,
+		CustomerName:    previous.CustomerName,
+		DeliveryAddress: previous.DeliveryAddress,
+		ContactPhone:    previous.ContactPhone,
+	}
+	if previous.PickupAt != nil {
+		pickupAt := time.Now().Add(previous.PickupAt.Sub(previous.CreatedAt))
+		order.PickupAt = &pickupAt
+	}
+	warnings := []models.OrderWarning{}
+
+	schedule, err := s.schedules.scheduleAt(ctx, previous.RestaurantID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range previous.Items {
+		quantity := item.ActiveQuantity()
+		if quantity == 0 {
+			continue
+		}
+
+		menuItem, err := s.menuRepo.GetByID(ctx, item.MenuItemID)
+		if err != nil {
+			return nil, err
+		}
+		if menuItem == nil || menuItem.RestaurantID != previous.RestaurantID {
+			warnings = append(warnings, models.OrderWarning{
+				MenuItemID: &item.MenuItemID,
+				Message:    "item is no longer on the menu and was left out",
+			})
+			continue
+		}
+
+		if !menuItem.Available {
+			warnings = append(warnings, models.OrderWarning{
+				MenuItemID: &item.MenuItemID,
+				Message:    fmt.Sprintf("%s is sold out and was left out", menuItem.Name),
+			})
+			continue
+		}
+
+		if !schedule.orderable(menuItem) {
+			warnings = append(warnings, models.OrderWarning{
+				MenuItemID: &item.MenuItemID,
+				Message:    fmt.Sprintf("%s is not being served at this time and was left out", menuItem.Name),
+			})
+			continue
+		}
+
+		price := menuItem.Price
+		if item.VariantID != nil {
+			variant, err := s.variantRepo.GetByID(ctx, *item.VariantID)
+			if err != nil {
+				return nil, err
+			}
+			if variant == nil || !variant.Available {
+				warnings = append(warnings, models.OrderWarning{
+					MenuItemID: &item.MenuItemID,
+					Message:    fmt.Sprintf("%s (%s) is no longer available and was left out", menuItem.Name, item.VariantName),
+				})
+				continue
+			}
+			price = variant.Price
+		} else {
+			variants, err := s.variantRepo.ListByMenuItem(ctx, menuItem.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(variants) > 0 {
+				warnings = append(warnings, models.OrderWarning{
+					MenuItemID: &item.MenuItemID,
+					Message:    fmt.Sprintf("%s now comes in several sizes and was left out", menuItem.Name),
+				})
+				continue
+			}
+		}
+
+		if toCents(price) != toCents(item.Price) {
+			warnings = append(warnings, models.OrderWarning{
+				MenuItemID: &item.MenuItemID,
+				Message:    fmt.Sprintf("%s now costs %.2f instead of %.2f", menuItem.Name, price, item.Price),
+			})
+		}
+
+		components := make([]models.OrderItem, 0, len(item.Components))
+		for _, component := range item.Components {
+			components = append(components, models.OrderItem{
+				MenuItemID:    component.MenuItemID,
+				VariantID:     component.VariantID,
+				BundleGroupID: component.BundleGroupID,
+				Quantity:      component.Quantity,
+				Options:       component.Options,
+			})
+		}
+
+		order.Items = append(order.Items, models.OrderItem{
+			MenuItemID: item.MenuItemID,
+			VariantID:  item.VariantID,
+			Quantity:   quantity,
+			Options:    item.Options,
+			Components: components,
+		})
+	}
+
+	if len(order.Items) == 0 {
+		return nil, fmt.Errorf("%w: none of the items of order %s can be ordered anymore", ErrConflict, id)
+	}
+
+	if err := s.Create(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return &models.ReorderResult{Order: order, Warnings: warnings}, nil
+}
+
+// UpdateStatus moves an order to the next status of its type's flow. A
+// split order can only be completed once every one of its checks is paid.
+// Canceling an order gives its portions, stock and loyalty points back.
+// The stock and points move together with the status, and only if no one
+// else changed the status first.
 func (s *OrderService) UpdateStatus(ctx context.Context, id uuid.UUID, status models.OrderStatus) error {
-	return s.orderRepo.UpdateStatus(ctx, id, status)
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return fmt.Errorf("%w: order %s", ErrNotFound, id)
+	}
+	if err := checkTransition(order, status); err != nil {
+		return err
+	}
+
+	if status == models.OrderStatusComplete {
+		checks, err := s.checkRepo.GetByOrderID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !allPaid(checks) {
+			return fmt.Errorf("%w: order has unpaid checks", ErrConflict)
+		}
+	}
+
+	change := models.OrderStatusChange{From: order.Status, To: status}
+	switch status {
+	case models.OrderStatusAccepted:
+		change.StockMovements, err = s.inventory.saleMovements(ctx, order)
+	case models.OrderStatusComplete:
+		change.LoyaltyEntry, err = s.loyalty.earning(ctx, order)
+	case models.OrderStatusCanceled:
+		change.StockMovements, err = s.inventory.reversalMovements(ctx, order)
+		if err == nil {
+			change.LoyaltyEntry, err = s.loyalty.restoring(ctx, order)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.orderRepo.UpdateStatus(ctx, id, change); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: order is no longer %s", ErrConflict, order.Status)
+		}
+		return err
+	}
+
+	return nil
 }
 
+// Update replaces the items and the pickup or delivery details of a
+// pending order. Once an order has been accepted, changes must go through
+// voids and refunds so they are recorded. The type cannot change. The new
+// items must be available like those of a new order; the portions of the
+// old ones are given back as the new ones are claimed.
 func (s *OrderService) Update(ctx context.Context, order *models.Order) error {
-	return s.orderRepo.Update(ctx, order)
+	existing, err := s.orderRepo.GetByID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("%w: order %s", ErrNotFound, order.ID)
+	}
+	if existing.Status != models.OrderStatusPending {
+		return fmt.Errorf("%w: only pending orders can be edited, use voids and refunds instead", ErrConflict)
+	}
+
+	// The promo code and loyalty points were redeemed when the order was
+	// placed, so they cannot be swapped here; the discounts are
+	// recalculated for the new items.
+	order.UserID = existing.UserID
+	order.RestaurantID = existing.RestaurantID
+	order.Type = existing.Type
+	order.TableID = existing.TableID
+	order.Status = existing.Status
+	order.PaymentStatus = existing.PaymentStatus
+	order.PromoCode = existing.PromoCode
+	order.LoyaltyPoints = existing.LoyaltyPoints
+	order.ScheduledFor = existing.ScheduledFor
+	order.CreatedAt = existing.CreatedAt
+	if err := validateOrderType(order); err != nil {
+		return err
+	}
+	if order.Type == models.OrderTypePickup && (existing.PickupAt == nil || !order.PickupAt.Equal(*existing.PickupAt)) {
+		if err := s.checkPickup(ctx, order); err != nil {
+			return err
+		}
+	}
+	if err := s.checkAvailability(ctx, order, portions(existing.Items)); err != nil {
+		return err
+	}
+	if err := s.price(ctx, order, false); err != nil {
+		return err
+	}
+
+	err = s.orderRepo.Update(ctx, order)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%w: the order is no longer pending", ErrConflict)
+	case errors.Is(err, repository.ErrSoldOut):
+		return fmt.Errorf("%w: an item on this order has just sold out", ErrConflict)
+	case errors.Is(err, repository.ErrUsageLimitReached):
+		return fmt.Errorf("%w: a discount on this order is no longer available", ErrConflict)
+	}
+	return err
 }
 
+// Delete cancels an order rather than removing it, so that its payments,
+// adjustments and history are kept and what it claimed is given back.
 func (s *OrderService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.orderRepo.Delete(ctx, id)
+	return s.UpdateStatus(ctx, id, models.OrderStatusCanceled)
 }
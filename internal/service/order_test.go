@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestApplyCharges(t *testing.T) {
+	tests := []struct {
+		name          string
+		orderType     models.OrderType
+		total         float64
+		settings      models.RestaurantSettings
+		serviceCharge float64
+		deliveryFee   float64
+		tax           float64
+		want          float64
+	}{
+		{
+			name:      "no charges",
+			orderType: models.OrderTypeDineIn,
+			total:     100,
+			settings:  models.RestaurantSettings{TaxMode: models.TaxModeNone},
+			want:      100,
+		},
+		{
+			name:          "service charge without tax",
+			orderType:     models.OrderTypeDineIn,
+			total:         100,
+			settings:      models.RestaurantSettings{TaxMode: models.TaxModeNone, ServiceChargeRate: 10},
+			serviceCharge: 10,
+			want:          110,
+		},
+		{
+			name:      "exclusive tax",
+			orderType: models.OrderTypePickup,
+			total:     100,
+			settings:  models.RestaurantSettings{TaxMode: models.TaxModeExclusive, TaxRate: 8},
+			tax:       8,
+			want:      108,
+		},
+		{
+			name:      "exclusive tax rounds to the cent",
+			orderType: models.OrderTypePickup,
+			total:     10.01,
+			settings:  models.RestaurantSettings{TaxMode: models.TaxModeExclusive, TaxRate: 8.25},
+			tax:       0.83,
+			want:      10.84,
+		},
+		{
+			name:      "inclusive tax is broken out",
+			orderType: models.OrderTypeDineIn,
+			total:     120,
+			settings:  models.RestaurantSettings{TaxMode: models.TaxModeInclusive, TaxRate: 20},
+			tax:       20,
+			want:      120,
+		},
+		{
+			name:          "delivery fee and service charge are taxed",
+			orderType:     models.OrderTypeDelivery,
+			total:         100,
+			settings:      models.RestaurantSettings{TaxMode: models.TaxModeExclusive, TaxRate: 10, ServiceChargeRate: 10, DeliveryFee: 5},
+			serviceCharge: 10,
+			deliveryFee:   5,
+			tax:           11.5,
+			want:          126.5,
+		},
+		{
+			name:      "no delivery fee off delivery",
+			orderType: models.OrderTypeDineIn,
+			total:     100,
+			settings:  models.RestaurantSettings{TaxMode: models.TaxModeNone, DeliveryFee: 5},
+			want:      100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.settings.Currency = "USD"
+			order := &models.Order{Type: tt.orderType, TotalAmount: tt.total}
+			applyCharges(order, tt.settings)
+			if order.ServiceCharge != tt.serviceCharge {
+				t.Errorf("service charge = %v, want %v", order.ServiceCharge, tt.serviceCharge)
+			}
+			if order.DeliveryFee != tt.deliveryFee {
+				t.Errorf("delivery fee = %v, want %v", order.DeliveryFee, tt.deliveryFee)
+			}
+			if order.TaxTotal != tt.tax {
+				t.Errorf("tax = %v, want %v", order.TaxTotal, tt.tax)
+			}
+			if order.TotalAmount != tt.want {
+				t.Errorf("total = %v, want %v", order.TotalAmount, tt.want)
+			}
+			if order.Currency != "USD" {
+				t.Errorf("currency = %q, want USD", order.Currency)
+			}
+		})
+	}
+}
+
+func TestUpdateStatusBooksOnce(t *testing.T) {
+	burger, bun := uuid.New(), uuid.New()
+	clientID, redeemed := uuid.New(), uuid.New()
+	inventory := NewInventoryService(&fakeInventoryRepo{
+		recipes: map[uuid.UUID][]models.RecipeLine{burger: {{IngredientID: bun, Quantity: 1}}},
+		movements: []*models.StockMovement{
+			{IngredientID: bun, Type: models.StockSale, Quantity: -1, OrderID: &redeemed},
+		},
+	}, nil, nil)
+	loyalty := NewLoyaltyService(&fakeLoyaltyRepo{entries: []*models.LoyaltyTransaction{
+		{UserID: clientID, OrderID: &redeemed, Type: models.LoyaltyRedeem, Points: -100},
+	}}, &fakeUserRepo{users: map[uuid.UUID]*models.User{clientID: {ID: clientID, Role: models.RoleClient}}})
+
+	tests := []struct {
+		name      string
+		id        uuid.UUID
+		from      models.OrderStatus
+		to        models.OrderStatus
+		stock     float64
+		points    int
+		moveFirst models.OrderStatus
+		err       error
+	}{
+		{name: "accepting books the stock out", from: models.OrderStatusPending, to: models.OrderStatusAccepted, stock: -2},
+		{name: "completing earns points", from: models.OrderStatusAccepted, to: models.OrderStatusComplete, points: 20},
+		{name: "canceling gives stock and points back", id: redeemed, from: models.OrderStatusAccepted, to: models.OrderStatusCanceled, stock: 1, points: 100},
+		{name: "accepted meanwhile", from: models.OrderStatusPending, to: models.OrderStatusAccepted, moveFirst: models.OrderStatusAccepted, err: ErrConflict},
+		{name: "canceled meanwhile", id: redeemed, from: models.OrderStatusAccepted, to: models.OrderStatusCanceled, moveFirst: models.OrderStatusCanceled, err: ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := tt.id
+			if id == uuid.Nil {
+				id = uuid.New()
+			}
+			order := &models.Order{
+				ID: id, UserID: clientID, RestaurantID: uuid.New(), Type: models.OrderTypeDineIn, Status: tt.from, Subtotal: 20,
+				Items: []models.OrderItem{{MenuItemID: burger, Quantity: 2, Price: 10}},
+			}
+			orders := newFakeOrderRepo(order)
+			checks := &fakeCheckRepo{checks: []*models.Check{{OrderIDs: []uuid.UUID{id}, Status: models.CheckStatusPaid}}}
+			s := NewOrderService(orders, checks, nil, nil, nil, loyalty, inventory, nil, nil, nil, nil, nil)
+			if tt.moveFirst != "" {
+				orders.beforeUpdate = func() { order.Status = tt.moveFirst }
+			}
+
+			err := s.UpdateStatus(context.Background(), id, tt.to)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("UpdateStatus() error = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				if len(orders.changes) != 0 {
+					t.Errorf("UpdateStatus() booked %+v, want nothing", orders.changes)
+				}
+				return
+			}
+
+			change := orders.changes[0]
+			var stock float64
+			for _, movement := range change.StockMovements {
+				stock += movement.Quantity
+			}
+			if stock != tt.stock {
+				t.Errorf("stock moved %v, want %v", stock, tt.stock)
+			}
+			points := 0
+			if change.LoyaltyEntry != nil {
+				points = change.LoyaltyEntry.Points
+			}
+			if points != tt.points {
+				t.Errorf("points credited %d, want %d", points, tt.points)
+			}
+		})
+	}
+}
+
+func TestAutoAccept(t *testing.T) {
+	tests := []struct {
+		name     string
+		meantime models.OrderStatus
+		want     models.OrderStatus
+		warnings int
+	}{
+		{name: "accepted", want: models.OrderStatusAccepted},
+		{name: "canceled before it could be accepted", meantime: models.OrderStatusCanceled, want: models.OrderStatusPending, warnings: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{ID: uuid.New(), Type: models.OrderTypeDineIn, Status: models.OrderStatusPending}
+			stored := *order
+			orders := newFakeOrderRepo(&stored)
+			if tt.meantime != "" {
+				orders.beforeUpdate = func() { stored.Status = tt.meantime }
+			}
+			inventory := NewInventoryService(&fakeInventoryRepo{}, nil, nil)
+			s := NewOrderService(orders, nil, nil, nil, nil, nil, inventory, nil, nil, nil, nil, nil)
+
+			s.autoAccept(context.Background(), order)
+			if order.Status != tt.want {
+				t.Errorf("status = %s, want %s", order.Status, tt.want)
+			}
+			if len(order.Warnings) != tt.warnings {
+				t.Errorf("warnings = %v, want %d", order.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestDeleteCancels(t *testing.T) {
+	tests := []struct {
+		name   string
+		status models.OrderStatus
+		want   error
+	}{
+		{"pending order is canceled", models.OrderStatusPending, nil},
+		{"completed order is kept", models.OrderStatusComplete, ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{ID: uuid.New(), Type: models.OrderTypeDineIn, Status: tt.status}
+			orders := newFakeOrderRepo(order)
+			s := NewOrderService(orders, nil, nil, nil, nil, NewLoyaltyService(&fakeLoyaltyRepo{}, nil), NewInventoryService(&fakeInventoryRepo{}, nil, nil), nil, nil, nil, nil, nil)
+
+			if err := s.Delete(context.Background(), order.ID); !errors.Is(err, tt.want) {
+				t.Fatalf("Delete() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && order.Status != models.OrderStatusCanceled {
+				t.Errorf("status = %s, want %s", order.Status, models.OrderStatusCanceled)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// orderFlows lists, for each order type, the statuses an order can move to
+// from each status. Dine-in orders can be completed before they are marked
+// ready, as paying the bill closes them; only delivery orders go out for
+// delivery, and they are complete once delivered.
+var orderFlows = map[models.OrderType]map[models.OrderStatus][]models.OrderStatus{
+	models.OrderTypeDineIn: {
+		models.OrderStatusPending:  {models.OrderStatusAccepted, models.OrderStatusCanceled},
+		models.OrderStatusAccepted: {models.OrderStatusReady, models.OrderStatusComplete, models.OrderStatusCanceled},
+		models.OrderStatusReady:    {models.OrderStatusComplete, models.OrderStatusCanceled},
+	},
+	models.OrderTypePickup: {
+		models.OrderStatusPending:  {models.OrderStatusAccepted, models.OrderStatusCanceled},
+		models.OrderStatusAccepted: {models.OrderStatusReady, models.OrderStatusCanceled},
+		models.OrderStatusReady:    {models.OrderStatusComplete, models.OrderStatusCanceled},
+	},
+	models.OrderTypeDelivery: {
+		models.OrderStatusPending:        {models.OrderStatusAccepted, models.OrderStatusCanceled},
+		models.OrderStatusAccepted:       {models.OrderStatusReady, models.OrderStatusCanceled},
+		models.OrderStatusReady:          {models.OrderStatusOutForDelivery, models.OrderStatusCanceled},
+		models.OrderStatusOutForDelivery: {models.OrderStatusComplete, models.OrderStatusCanceled},
+	},
+}
+
+// canMoveTo reports whether an order can go from its status to the given
+// one.
+func canMoveTo(order *models.Order, status models.OrderStatus) bool {
+	for _, next := range orderFlows[order.Type][order.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTransition explains why an order cannot move to a status.
+func checkTransition(order *models.Order, status models.OrderStatus) error {
+	switch status {
+	case models.OrderStatusPending, models.OrderStatusAccepted, models.OrderStatusReady,
+		models.OrderStatusOutForDelivery, models.OrderStatusComplete, models.OrderStatusCanceled:
+	default:
+		return fmt.Errorf("%w: unknown order status %q", ErrInvalidInput, status)
+	}
+	if status == models.OrderStatusOutForDelivery && order.Type != models.OrderTypeDelivery {
+		return fmt.Errorf("%w: only delivery orders go out for delivery", ErrConflict)
+	}
+	if !canMoveTo(order, status) {
+		return fmt.Errorf("%w: a %s order cannot go from %s to %s", ErrConflict, order.Type, order.Status, status)
+	}
+	return nil
+}
+
+// validateOrderType defaults the type to dine-in and checks the order has
+// the details its type needs and none that belong to another type. Pickup
+// times are checked against the opening hours by checkPickup.
+func validateOrderType(order *models.Order) error {
+	order.CustomerName = strings.TrimSpace(order.CustomerName)
+	order.DeliveryAddress = strings.TrimSpace(order.DeliveryAddress)
+	order.ContactPhone = strings.TrimSpace(order.ContactPhone)
+
+	if order.Type == "" {
+		order.Type = models.OrderTypeDineIn
+	}
+	switch order.Type {
+	case models.OrderTypeDineIn, models.OrderTypePickup, models.OrderTypeDelivery:
+	default:
+		return fmt.Errorf("%w: type must be dine_in, pickup or delivery", ErrInvalidInput)
+	}
+
+	if order.Type != models.OrderTypeDineIn && order.TableID != uuid.Nil {
+		return fmt.Errorf("%w: table_id only applies to dine-in orders", ErrInvalidInput)
+	}
+
+	if order.Type == models.OrderTypePickup {
+		if order.PickupAt == nil {
+			return fmt.Errorf("%w: pickup orders need a pickup_at time", ErrInvalidInput)
+		}
+		if order.CustomerName == "" {
+			return fmt.Errorf("%w: pickup orders need a customer_name", ErrInvalidInput)
+		}
+	} else if order.PickupAt != nil {
+		return fmt.Errorf("%w: pickup_at only applies to pickup orders", ErrInvalidInput)
+	}
+
+	if order.Type == models.OrderTypeDelivery {
+		if order.DeliveryAddress == "" {
+			return fmt.Errorf("%w: delivery orders need a delivery_address", ErrInvalidInput)
+		}
+		if order.ContactPhone == "" {
+			return fmt.Errorf("%w: delivery orders need a contact_phone", ErrInvalidInput)
+		}
+	} else if order.DeliveryAddress != "" {
+		return fmt.Errorf("%w: delivery_address only applies to delivery orders", ErrInvalidInput)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		name      string
+		orderType models.OrderType
+		from      models.OrderStatus
+		to        models.OrderStatus
+		want      error
+	}{
+		{"dine-in accepted", models.OrderTypeDineIn, models.OrderStatusPending, models.OrderStatusAccepted, nil},
+		{"dine-in paid before ready", models.OrderTypeDineIn, models.OrderStatusAccepted, models.OrderStatusComplete, nil},
+		{"pickup not complete before ready", models.OrderTypePickup, models.OrderStatusAccepted, models.OrderStatusComplete, ErrConflict},
+		{"pickup collected", models.OrderTypePickup, models.OrderStatusReady, models.OrderStatusComplete, nil},
+		{"delivery goes out", models.OrderTypeDelivery, models.OrderStatusReady, models.OrderStatusOutForDelivery, nil},
+		{"delivery not complete before going out", models.OrderTypeDelivery, models.OrderStatusReady, models.OrderStatusComplete, ErrConflict},
+		{"delivery delivered", models.OrderTypeDelivery, models.OrderStatusOutForDelivery, models.OrderStatusComplete, nil},
+		{"only delivery goes out", models.OrderTypePickup, models.OrderStatusReady, models.OrderStatusOutForDelivery, ErrConflict},
+		{"canceled before complete", models.OrderTypePickup, models.OrderStatusReady, models.OrderStatusCanceled, nil},
+		{"complete is final", models.OrderTypeDineIn, models.OrderStatusComplete, models.OrderStatusCanceled, ErrConflict},
+		{"canceled is final", models.OrderTypeDineIn, models.OrderStatusCanceled, models.OrderStatusAccepted, ErrConflict},
+		{"no going back", models.OrderTypeDineIn, models.OrderStatusAccepted, models.OrderStatusPending, ErrConflict},
+		{"unknown status", models.OrderTypeDineIn, models.OrderStatusPending, "served", ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{Type: tt.orderType, Status: tt.from}
+			err := checkTransition(order, tt.to)
+			if tt.want == nil && err != nil {
+				t.Fatalf("checkTransition(%s -> %s) = %v, want nil", tt.from, tt.to, err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("checkTransition(%s -> %s) = %v, want %v", tt.from, tt.to, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOrderType(t *testing.T) {
+	pickupAt := time.Now().Add(time.Hour)
+	tests := []struct {
+		name  string
+		order models.Order
+		want  error
+	}{
+		{"defaults to dine-in", models.Order{TableID: uuid.New()}, nil},
+		{"unknown type", models.Order{Type: "drive_through"}, ErrInvalidInput},
+		{"table only for dine-in", models.Order{Type: models.OrderTypePickup, TableID: uuid.New(), PickupAt: &pickupAt, CustomerName: "Ana"}, ErrInvalidInput},
+		{"pickup", models.Order{Type: models.OrderTypePickup, PickupAt: &pickupAt, CustomerName: "Ana"}, nil},
+		{"pickup without time", models.Order{Type: models.OrderTypePickup, CustomerName: "Ana"}, ErrInvalidInput},
+		{"pickup without name", models.Order{Type: models.OrderTypePickup, PickupAt: &pickupAt, CustomerName: "  "}, ErrInvalidInput},
+		{"pickup time only for pickup", models.Order{PickupAt: &pickupAt}, ErrInvalidInput},
+		{"delivery", models.Order{Type: models.OrderTypeDelivery, DeliveryAddress: "1 Main St", ContactPhone: "555-0100"}, nil},
+		{"delivery without address", models.Order{Type: models.OrderTypeDelivery, ContactPhone: "555-0100"}, ErrInvalidInput},
+		{"delivery without phone", models.Order{Type: models.OrderTypeDelivery, DeliveryAddress: "1 Main St"}, ErrInvalidInput},
+		{"address only for delivery", models.Order{DeliveryAddress: "1 Main St"}, ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := tt.order
+			err := validateOrderType(&order)
+			if tt.want == nil && err != nil {
+				t.Fatalf("validateOrderType() = %v, want nil", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("validateOrderType() = %v, want %v", err, tt.want)
+			}
+			if err == nil && order.Type == "" {
+				t.Errorf("validateOrderType() left the type empty")
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type OrganizationService struct {
+	organizationRepo repository.OrganizationRepository
+	restaurantRepo   repository.RestaurantRepository
+	userRepo         repository.UserRepository
+}
+
+func NewOrganizationService(
+	organizationRepo repository.OrganizationRepository,
+	restaurantRepo repository.RestaurantRepository,
+	userRepo repository.UserRepository,
+) *OrganizationService {
+	return &OrganizationService{
+		organizationRepo: organizationRepo,
+		restaurantRepo:   restaurantRepo,
+		userRepo:         userRepo,
+	}
+}
+
+// Memberships returns the organizations a user is a member of. It is what
+// requests are scoped by.
+func (s *OrganizationService) Memberships(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return s.organizationRepo.ListMemberships(ctx, userID)
+}
+
+// Create registers an organization with the calling manager as its owner.
+func (s *OrganizationService) Create(ctx context.Context, user *models.AuthUser, organization *models.Organization) error {
+	if user.Role != models.RoleAdmin && user.Role != models.RoleManager {
+		return fmt.Errorf("%w: only managers can create organizations", ErrForbidden)
+	}
+	organization.Name = strings.TrimSpace(organization.Name)
+	if organization.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	organization.CreatedBy = user.ID
+	return s.organizationRepo.Create(ctx, organization)
+}
+
+// List returns every organization to admins and their own organizations,
+// with their role, to everyone else.
+func (s *OrganizationService) List(ctx context.Context, user *models.AuthUser) ([]*models.Organization, error) {
+	if user.Role == models.RoleAdmin {
+		return s.organizationRepo.List(ctx)
+	}
+	return s.organizationRepo.ListByMember(ctx, user.ID)
+}
+
+// Get returns an organization the user is a member of.
+func (s *OrganizationService) Get(ctx context.Context, user *models.AuthUser, id uuid.UUID) (*models.Organization, error) {
+	organization, err := s.organizationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if organization == nil {
+		return nil, fmt.Errorf("%w: organization %s", ErrNotFound, id)
+	}
+	if user.Role == models.RoleAdmin {
+		return organization, nil
+	}
+
+	member, err := s.organizationRepo.GetMember(ctx, id, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, fmt.Errorf("%w: organization %s", ErrNotFound, id)
+	}
+	organization.Role = member.Role
+	return organization, nil
+}
+
+func (s *OrganizationService) Update(ctx context.Context, user *models.AuthUser, organization *models.Organization) error {
+	existing, err := s.getAs(ctx, user, organization.ID, models.OrganizationRoleOwner)
+	if err != nil {
+		return err
+	}
+	organization.Name = strings.TrimSpace(organization.Name)
+	if organization.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	organization.CreatedBy = existing.CreatedBy
+	organization.CreatedAt = existing.CreatedAt
+	organization.Role = existing.Role
+	return s.organizationRepo.Update(ctx, organization)
+}
+
+// Delete removes an organization that no longer runs any restaurants.
+func (s *OrganizationService) Delete(ctx context.Context, user *models.AuthUser, id uuid.UUID) error {
+	if _, err := s.getAs(ctx, user, id, models.OrganizationRoleOwner); err != nil {
+		return err
+	}
+	count, err := s.organizationRepo.CountRestaurants(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: the organization still runs %d restaurants", ErrConflict, count)
+	}
+	if err := s.organizationRepo.Delete(ctx, id); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+func (s *OrganizationService) ListMembers(ctx context.Context, user *models.AuthUser, organizationID uuid.UUID) ([]*models.OrganizationMember, error) {
+	if _, err := s.Get(ctx, user, organizationID); err != nil {
+		return nil, err
+	}
+	return s.organizationRepo.ListMembers(ctx, organizationID)
+}
+
+// SaveMember adds a user to an organization or changes their role. Only
+// owners manage members, and an organization always keeps an owner.
+func (s *OrganizationService) SaveMember(ctx context.Context, user *models.AuthUser, member *models.OrganizationMember) error {
+	if _, err := s.getAs(ctx, user, member.OrganizationID, models.OrganizationRoleOwner); err != nil {
+		return err
+	}
+	switch member.Role {
+	case models.OrganizationRoleOwner, models.OrganizationRoleManager, models.OrganizationRoleStaff:
+	default:
+		return fmt.Errorf("%w: role must be owner, manager or staff", ErrInvalidInput)
+	}
+
+	target, err := s.userRepo.GetByID(ctx, member.UserID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return fmt.Errorf("%w: user %s", ErrNotFound, member.UserID)
+	}
+	if target.Role != models.RoleAdmin && target.Role != models.RoleManager && target.Role != models.RoleEmployee {
+		return fmt.Errorf("%w: only staff accounts can be members", ErrInvalidInput)
+	}
+
+	if member.Role != models.OrganizationRoleOwner {
+		if err := s.keepOwner(ctx, member.OrganizationID, member.UserID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.organizationRepo.SaveMember(ctx, member); err != nil {
+		return err
+	}
+	member.Email = target.Email
+	return nil
+}
+
+func (s *OrganizationService) RemoveMember(ctx context.Context, user *models.AuthUser, organizationID, userID uuid.UUID) error {
+	if _, err := s.getAs(ctx, user, organizationID, models.OrganizationRoleOwner); err != nil {
+		return err
+	}
+	if err := s.keepOwner(ctx, organizationID, userID); err != nil {
+		return err
+	}
+	err := s.organizationRepo.RemoveMember(ctx, organizationID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: user %s is not a member", ErrNotFound, userID)
+	}
+	return err
+}
+
+// ListRestaurants returns the restaurants an organization runs.
+func (s *OrganizationService) ListRestaurants(ctx context.Context, user *models.AuthUser, organizationID uuid.UUID) ([]*models.Restaurant, error) {
+	if _, err := s.Get(ctx, user, organizationID); err != nil {
+		return nil, err
+	}
+	return s.restaurantRepo.List(ctx, models.RestaurantFilter{OrganizationID: &organizationID})
+}
+
+// AssignRestaurant moves a restaurant into an organization. The user must
+// own both the organization and the one the restaurant is in. Restaurants
+// in no organization are only visible to admins, who assign them.
+func (s *OrganizationService) AssignRestaurant(ctx context.Context, user *models.AuthUser, organizationID, restaurantID uuid.UUID) error {
+	if _, err := s.getAs(ctx, user, organizationID, models.OrganizationRoleOwner); err != nil {
+		return err
+	}
+
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+	if restaurant == nil {
+		return fmt.Errorf("%w: restaurant %s", ErrNotFound, restaurantID)
+	}
+	if restaurant.OrganizationID != nil && *restaurant.OrganizationID == organizationID {
+		return nil
+	}
+
+	if restaurant.OrganizationID != nil {
+		ok, err := hasOrganizationRole(ctx, s.organizationRepo, *restaurant.OrganizationID, user, models.OrganizationRoleOwner)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: only the owners of the restaurant's organization can move it", ErrForbidden)
+		}
+	}
+
+	return s.restaurantRepo.SetOrganization(ctx, restaurantID, organizationID)
+}
+
+// getAs loads an organization the user has one of the roles in.
+func (s *OrganizationService) getAs(ctx context.Context, user *models.AuthUser, id uuid.UUID, roles ...models.OrganizationRole) (*models.Organization, error) {
+	organization, err := s.Get(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := hasOrganizationRole(ctx, s.organizationRepo, id, user, roles...)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: only the organization's owners can do this", ErrForbidden)
+	}
+	return organization, nil
+}
+
+// keepOwner refuses to demote or remove the last owner of an organization.
+func (s *OrganizationService) keepOwner(ctx context.Context, organizationID, userID uuid.UUID) error {
+	member, err := s.organizationRepo.GetMember(ctx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Role != models.OrganizationRoleOwner {
+		return nil
+	}
+	owners, err := s.organizationRepo.CountOwners(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return fmt.Errorf("%w: an organization must keep at least one owner", ErrConflict)
+	}
+	return nil
+}
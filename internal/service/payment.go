@@ -0,0 +1,472 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/payment"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/KNLopez/restaurant-api/internal/tenant"
+	"github.com/google/uuid"
+)
+
+type PaymentService struct {
+	paymentRepo  repository.PaymentRepository
+	orderRepo    repository.OrderRepository
+	checkService *CheckService
+	providers    *payment.Registry
+}
+
+func NewPaymentService(
+	paymentRepo repository.PaymentRepository,
+	orderRepo repository.OrderRepository,
+	checkService *CheckService,
+	providers *payment.Registry,
+) *PaymentService {
+	return &PaymentService{
+		paymentRepo:  paymentRepo,
+		orderRepo:    orderRepo,
+		checkService: checkService,
+		providers:    providers,
+	}
+}
+
+// Create starts a payment against an order, or one of its checks, through
+// the requested provider. Guests can only pay their own orders, and only
+// staff can record payments taken outside the system. The amount defaults
+// to, and may not exceed, what is left to pay once the payments still
+// pending or authorized are counted.
+func (s *PaymentService) Create(ctx context.Context, orderID uuid.UUID, req models.PaymentRequest, user *models.AuthUser) (*models.Payment, error) {
+	order, err := s.getOrder(ctx, orderID, user)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == models.OrderStatusCanceled {
+		return nil, fmt.Errorf("%w: order is canceled", ErrConflict)
+	}
+
+	provider, err := s.providers.Get(req.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if payment.IsManual(provider.Name()) && !user.IsStaff() {
+		return nil, fmt.Errorf("%w: only staff can record %s payments", ErrForbidden, provider.Name())
+	}
+
+	payments, err := s.paymentRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	owed := toCents(order.TotalAmount) - outstanding(payments, nil)
+	if req.CheckID != nil {
+		check, err := s.checkService.GetByID(ctx, *req.CheckID)
+		if err != nil {
+			return nil, err
+		}
+		if check == nil || !containsID(check.OrderIDs, orderID) {
+			return nil, fmt.Errorf("%w: check %s does not belong to the order", ErrInvalidInput, *req.CheckID)
+		}
+		if check.Status == models.CheckStatusPaid {
+			return nil, fmt.Errorf("%w: check is already paid", ErrConflict)
+		}
+		owed = min(owed, toCents(check.Amount)-outstanding(payments, req.CheckID))
+	}
+	if owed <= 0 {
+		return nil, fmt.Errorf("%w: nothing left to pay", ErrInvalidInput)
+	}
+
+	amount := toCents(req.Amount)
+	if amount == 0 {
+		amount = owed
+	}
+	if amount < 0 || amount > owed {
+		return nil, fmt.Errorf("%w: amount must be between 0 and %.2f", ErrInvalidInput, fromCents(owed))
+	}
+
+	p := &models.Payment{
+		OrderID:  orderID,
+		CheckID:  req.CheckID,
+		Provider: provider.Name(),
+		Amount:   fromCents(amount),
+		Status:   models.PaymentStatusPending,
+	}
+	if err := s.paymentRepo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	pending := *p
+
+	result, err := provider.Authorize(ctx, payment.AuthorizeRequest{
+		PaymentID: p.ID,
+		OrderID:   orderID,
+		Amount:    p.Amount,
+		Token:     req.Token,
+		Capture:   req.Capture,
+	})
+	if err != nil {
+		result = &payment.Result{Status: models.PaymentStatusDeclined, Message: err.Error()}
+	}
+	applyResult(p, result)
+
+	if err := s.update(ctx, p, pending); err != nil {
+		return nil, err
+	}
+
+	return p, s.settle(ctx, p)
+}
+
+// GetByID returns a payment made against one of the user's orders, or any
+// order for staff.
+func (s *PaymentService) GetByID(ctx context.Context, id uuid.UUID, user *models.AuthUser) (*models.Payment, error) {
+	p, err := s.paymentRepo.GetByID(ctx, id)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	if _, err := s.getOrder(ctx, p.OrderID, user); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListByOrder returns the payments of one of the user's orders, or any
+// order for staff.
+func (s *PaymentService) ListByOrder(ctx context.Context, orderID uuid.UUID, user *models.AuthUser) ([]*models.Payment, error) {
+	if _, err := s.getOrder(ctx, orderID, user); err != nil {
+		return nil, err
+	}
+	return s.paymentRepo.GetByOrderID(ctx, orderID)
+}
+
+// orderPayments returns the payments of an order without checking who is
+// asking, for services that have already done so.
+func (s *PaymentService) orderPayments(ctx context.Context, orderID uuid.UUID) ([]*models.Payment, error) {
+	return s.paymentRepo.GetByOrderID(ctx, orderID)
+}
+
+// getOrder loads an order the user may pay or see the payments of.
+func (s *PaymentService) getOrder(ctx context.Context, orderID uuid.UUID, user *models.AuthUser) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, fmt.Errorf("%w: order %s", ErrNotFound, orderID)
+	}
+	if !user.IsStaff() && order.UserID != user.ID {
+		return nil, fmt.Errorf("%w: order belongs to another user", ErrForbidden)
+	}
+	return order, nil
+}
+
+// Capture captures an authorized payment. A zero amount captures the full
+// authorized amount; the remainder of a partial capture is released.
+func (s *PaymentService) Capture(ctx context.Context, id uuid.UUID, amount float64) (*models.Payment, error) {
+	p, provider, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != models.PaymentStatusAuthorized {
+		return nil, fmt.Errorf("%w: payment is %s", ErrConflict, p.Status)
+	}
+
+	cents := toCents(amount)
+	if cents == 0 {
+		cents = toCents(p.Amount)
+	}
+	if cents <= 0 || cents > toCents(p.Amount) {
+		return nil, fmt.Errorf("%w: capture amount must be between 0 and %.2f", ErrInvalidInput, p.Amount)
+	}
+
+	authorized := *p
+	result, err := provider.Capture(ctx, p.ProviderRef, fromCents(cents))
+	if err != nil {
+		return nil, err
+	}
+	p.CapturedAmount = result.Amount
+	p.Status = models.PaymentStatusCaptured
+
+	if err := s.update(ctx, p, authorized); err != nil {
+		return nil, err
+	}
+
+	return p, s.settle(ctx, p)
+}
+
+// Refund refunds part or all of a captured payment. A zero amount refunds
+// everything that has not been refunded yet. Refunds are requested through
+// AdjustmentService, which applies the approval threshold.
+func (s *PaymentService) Refund(ctx context.Context, id uuid.UUID, amount float64) (*models.Payment, error) {
+	p, provider, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != models.PaymentStatusCaptured && p.Status != models.PaymentStatusPartiallyRefunded {
+		return nil, fmt.Errorf("%w: payment is %s", ErrConflict, p.Status)
+	}
+
+	refundable := toCents(p.CapturedAmount) - toCents(p.RefundedAmount)
+	cents := toCents(amount)
+	if cents == 0 {
+		cents = refundable
+	}
+	if cents <= 0 || cents > refundable {
+		return nil, fmt.Errorf("%w: refund amount must be between 0 and %.2f", ErrInvalidInput, fromCents(refundable))
+	}
+
+	// Book the refund before moving money, so that two refunds read from
+	// the same state cannot both go out; it is taken back if the provider
+	// fails.
+	before := *p
+	addRefund(p, cents)
+	if err := s.update(ctx, p, before); err != nil {
+		return nil, err
+	}
+
+	result, err := provider.Refund(ctx, p.ProviderRef, fromCents(cents))
+	if err != nil {
+		claimed := *p
+		*p = before
+		if undoErr := s.update(ctx, p, claimed); undoErr != nil {
+			return nil, fmt.Errorf("%v; releasing the refund: %w", err, undoErr)
+		}
+		return nil, err
+	}
+	if refunded := toCents(result.Amount); refunded != cents {
+		claimed := *p
+		*p = before
+		addRefund(p, refunded)
+		if err := s.update(ctx, p, claimed); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, s.settle(ctx, p)
+}
+
+// addRefund adds cents to the payment's refunded amount.
+func addRefund(p *models.Payment, cents int64) {
+	p.RefundedAmount = fromCents(toCents(p.RefundedAmount) + cents)
+	p.Status = models.PaymentStatusPartiallyRefunded
+	if toCents(p.RefundedAmount) >= toCents(p.CapturedAmount) {
+		p.Status = models.PaymentStatusRefunded
+	}
+}
+
+// Void cancels a payment that has not been captured.
+func (s *PaymentService) Void(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
+	p, provider, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != models.PaymentStatusAuthorized && p.Status != models.PaymentStatusPending {
+		return nil, fmt.Errorf("%w: payment is %s", ErrConflict, p.Status)
+	}
+
+	before := *p
+	if _, err := provider.Void(ctx, p.ProviderRef); err != nil {
+		return nil, err
+	}
+	p.Status = models.PaymentStatusVoided
+
+	if err := s.update(ctx, p, before); err != nil {
+		return nil, err
+	}
+
+	return p, s.settle(ctx, p)
+}
+
+// HandleWebhook verifies and applies an asynchronous status update. Events
+// for payments that are no longer pending are ignored so that redelivery is
+// harmless.
+func (s *PaymentService) HandleWebhook(ctx context.Context, providerName string, payload []byte, signature string) error {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+
+	event, err := provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) || errors.Is(err, payment.ErrUnsupported) {
+			return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		return err
+	}
+
+	// The provider calls without a user; a verified event may update the
+	// payment of any organization.
+	ctx = tenant.Unscoped(ctx)
+
+	p, err := s.paymentRepo.GetByProviderRef(ctx, providerName, event.Reference)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("%w: payment %s", ErrNotFound, event.Reference)
+	}
+	if p.Status != models.PaymentStatusPending {
+		return nil
+	}
+
+	pending := *p
+	applyResult(p, &payment.Result{
+		Reference: event.Reference,
+		Status:    event.Status,
+		Amount:    event.Amount,
+		Message:   event.Message,
+	})
+
+	if err := s.update(ctx, p, pending); err != nil {
+		return err
+	}
+
+	return s.settle(ctx, p)
+}
+
+func (s *PaymentService) load(ctx context.Context, id uuid.UUID) (*models.Payment, payment.PaymentProvider, error) {
+	p, err := s.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p == nil {
+		return nil, nil, fmt.Errorf("%w: payment %s", ErrNotFound, id)
+	}
+
+	provider, err := s.providers.Get(p.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return p, provider, nil
+}
+
+// update stores p if the payment is still as it was read in from, and
+// reports a conflict if it changed in the meantime.
+func (s *PaymentService) update(ctx context.Context, p *models.Payment, from models.Payment) error {
+	err := s.paymentRepo.Update(ctx, p, from.Status, from.RefundedAmount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: payment %s was changed by another request", ErrConflict, p.ID)
+	}
+	return err
+}
+
+// settle recomputes the order's payment status and pays the check the
+// payment belongs to once it is covered.
+func (s *PaymentService) settle(ctx context.Context, p *models.Payment) error {
+	order, err := s.orderRepo.GetByID(ctx, p.OrderID)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return fmt.Errorf("%w: order %s", ErrNotFound, p.OrderID)
+	}
+
+	payments, err := s.paymentRepo.GetByOrderID(ctx, p.OrderID)
+	if err != nil {
+		return err
+	}
+
+	net := netPaid(payments, nil)
+	status := models.OrderPaymentUnpaid
+	switch {
+	case net > 0 && net >= toCents(order.TotalAmount):
+		status = models.OrderPaymentPaid
+	case net > 0:
+		status = models.OrderPaymentPartiallyPaid
+	case anyRefunded(payments):
+		status = models.OrderPaymentRefunded
+	}
+	if status != order.PaymentStatus {
+		if err := s.orderRepo.UpdatePaymentStatus(ctx, order.ID, status); err != nil {
+			return err
+		}
+	}
+
+	if p.CheckID == nil {
+		return nil
+	}
+	check, err := s.checkService.GetByID(ctx, *p.CheckID)
+	if err != nil {
+		return err
+	}
+	if check == nil || check.Status == models.CheckStatusPaid {
+		return nil
+	}
+	if netPaid(payments, p.CheckID) >= toCents(check.Amount) {
+		// Another payment settling at the same time may have paid it first
+		if _, err = s.checkService.Pay(ctx, check.ID); errors.Is(err, ErrConflict) {
+			return nil
+		}
+	}
+	return err
+}
+
+func applyResult(p *models.Payment, result *payment.Result) {
+	if result.Reference != "" {
+		p.ProviderRef = result.Reference
+	}
+	p.Status = result.Status
+
+	switch result.Status {
+	case models.PaymentStatusAuthorized:
+		p.Amount = result.Amount
+	case models.PaymentStatusCaptured:
+		p.Amount = result.Amount
+		p.CapturedAmount = result.Amount
+	case models.PaymentStatusDeclined:
+		p.FailureReason = result.Message
+	}
+}
+
+// netPaid returns captured minus refunded cents, optionally limited to the
+// payments made against one check.
+func netPaid(payments []*models.Payment, checkID *uuid.UUID) int64 {
+	var net int64
+	for _, p := range payments {
+		if checkID != nil && (p.CheckID == nil || *p.CheckID != *checkID) {
+			continue
+		}
+		net += toCents(p.CapturedAmount) - toCents(p.RefundedAmount)
+	}
+	return net
+}
+
+// outstanding returns the cents a guest has paid or is about to pay:
+// captured minus refunded, plus what pending and authorized payments may
+// still capture. It is optionally limited to the payments made against one
+// check.
+func outstanding(payments []*models.Payment, checkID *uuid.UUID) int64 {
+	var total int64
+	for _, p := range payments {
+		if checkID != nil && (p.CheckID == nil || *p.CheckID != *checkID) {
+			continue
+		}
+		switch p.Status {
+		case models.PaymentStatusPending, models.PaymentStatusAuthorized:
+			total += toCents(p.Amount)
+		default:
+			total += toCents(p.CapturedAmount) - toCents(p.RefundedAmount)
+		}
+	}
+	return total
+}
+
+func anyRefunded(payments []*models.Payment) bool {
+	for _, p := range payments {
+		if p.RefundedAmount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
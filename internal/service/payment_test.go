@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/payment"
+	"github.com/google/uuid"
+)
+
+// fakePaymentRepo keeps payments in memory. beforeUpdate, if set, runs
+// before every update, as a concurrent request would.
+type fakePaymentRepo struct {
+	payments     map[uuid.UUID]*models.Payment
+	order        []uuid.UUID
+	beforeUpdate func(stored *models.Payment)
+}
+
+func newFakePaymentRepo(payments ...*models.Payment) *fakePaymentRepo {
+	r := &fakePaymentRepo{payments: make(map[uuid.UUID]*models.Payment)}
+	for _, p := range payments {
+		r.store(p)
+	}
+	return r
+}
+
+func (r *fakePaymentRepo) store(p *models.Payment) {
+	if _, ok := r.payments[p.ID]; !ok {
+		r.order = append(r.order, p.ID)
+	}
+	copied := *p
+	r.payments[p.ID] = &copied
+}
+
+func (r *fakePaymentRepo) Create(ctx context.Context, p *models.Payment) error {
+	p.ID = uuid.New()
+	r.store(p)
+	return nil
+}
+
+func (r *fakePaymentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error) {
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (r *fakePaymentRepo) GetByProviderRef(ctx context.Context, provider, ref string) (*models.Payment, error) {
+	for _, p := range r.payments {
+		if p.Provider == provider && p.ProviderRef == ref {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakePaymentRepo) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*models.Payment, error) {
+	var payments []*models.Payment
+	for _, id := range r.order {
+		if p := r.payments[id]; p.OrderID == orderID {
+			copied := *p
+			payments = append(payments, &copied)
+		}
+	}
+	return payments, nil
+}
+
+func (r *fakePaymentRepo) Update(ctx context.Context, p *models.Payment, from models.PaymentStatus, refunded float64) error {
+	stored := r.payments[p.ID]
+	if r.beforeUpdate != nil {
+		r.beforeUpdate(stored)
+	}
+	if stored.Status != from || stored.RefundedAmount != refunded {
+		return sql.ErrNoRows
+	}
+	r.store(p)
+	return nil
+}
+
+// countingProvider records payments without charging anyone and counts the
+// refunds it is asked for. refundErr makes refunds fail.
+type countingProvider struct {
+	*payment.ManualProvider
+	refunds   int
+	refundErr error
+}
+
+func (p *countingProvider) Refund(ctx context.Context, ref string, amount float64) (*payment.Result, error) {
+	p.refunds++
+	if p.refundErr != nil {
+		return nil, p.refundErr
+	}
+	return p.ManualProvider.Refund(ctx, ref, amount)
+}
+
+var staff = &models.AuthUser{ID: uuid.New(), Role: models.RoleEmployee}
+
+func newTestPaymentService(orders *fakeOrderRepo, payments *fakePaymentRepo, providers ...payment.PaymentProvider) *PaymentService {
+	return NewPaymentService(payments, orders, nil, payment.NewRegistry(providers...))
+}
+
+func capturedPayment(orderID uuid.UUID, captured float64) *models.Payment {
+	return &models.Payment{
+		ID:             uuid.New(),
+		OrderID:        orderID,
+		Provider:       payment.ProviderCash,
+		ProviderRef:    uuid.NewString(),
+		Amount:         captured,
+		CapturedAmount: captured,
+		Status:         models.PaymentStatusCaptured,
+	}
+}
+
+func TestPaymentCreateCapsAmount(t *testing.T) {
+	order := &models.Order{ID: uuid.New(), TotalAmount: 50}
+	authorized := &models.Payment{
+		ID:       uuid.New(),
+		OrderID:  order.ID,
+		Provider: payment.ProviderCash,
+		Amount:   30,
+		Status:   models.PaymentStatusAuthorized,
+	}
+	declined := &models.Payment{ID: uuid.New(), OrderID: order.ID, Provider: payment.ProviderCash, Amount: 50, Status: models.PaymentStatusDeclined}
+
+	tests := []struct {
+		name   string
+		amount float64
+		want   float64
+		err    error
+	}{
+		{"defaults to the rest", 0, 20, nil},
+		{"part of the rest", 5, 5, nil},
+		{"more than the rest", 25, 0, ErrInvalidInput},
+		{"negative", -1, 0, ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments := newFakePaymentRepo(authorized, declined)
+			s := newTestPaymentService(newFakeOrderRepo(order), payments, payment.NewManualProvider(payment.ProviderCash))
+
+			p, err := s.Create(context.Background(), order.ID, models.PaymentRequest{Provider: payment.ProviderCash, Amount: tt.amount}, staff)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && p.Amount != tt.want {
+				t.Errorf("Create() amount = %v, want %v", p.Amount, tt.want)
+			}
+		})
+	}
+
+	t.Run("nothing left", func(t *testing.T) {
+		paid := capturedPayment(order.ID, 20)
+		s := newTestPaymentService(newFakeOrderRepo(order), newFakePaymentRepo(authorized, paid), payment.NewManualProvider(payment.ProviderCash))
+
+		_, err := s.Create(context.Background(), order.ID, models.PaymentRequest{Provider: payment.ProviderCash}, staff)
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("Create() error = %v, want %v", err, ErrInvalidInput)
+		}
+	})
+}
+
+func TestPaymentRefund(t *testing.T) {
+	order := &models.Order{ID: uuid.New(), TotalAmount: 40}
+
+	t.Run("partial then the rest", func(t *testing.T) {
+		p := capturedPayment(order.ID, 40)
+		payments := newFakePaymentRepo(p)
+		provider := &countingProvider{ManualProvider: payment.NewManualProvider(payment.ProviderCash)}
+		s := newTestPaymentService(newFakeOrderRepo(order), payments, provider)
+
+		refunded, err := s.Refund(context.Background(), p.ID, 15)
+		if err != nil {
+			t.Fatalf("Refund() error = %v", err)
+		}
+		if refunded.RefundedAmount != 15 || refunded.Status != models.PaymentStatusPartiallyRefunded {
+			t.Errorf("after a partial refund: refunded %v, status %s", refunded.RefundedAmount, refunded.Status)
+		}
+
+		refunded, err = s.Refund(context.Background(), p.ID, 0)
+		if err != nil {
+			t.Fatalf("Refund() error = %v", err)
+		}
+		if refunded.RefundedAmount != 40 || refunded.Status != models.PaymentStatusRefunded {
+			t.Errorf("after refunding the rest: refunded %v, status %s", refunded.RefundedAmount, refunded.Status)
+		}
+		if order := s.orderRepo.(*fakeOrderRepo).orders[order.ID]; order.PaymentStatus != models.OrderPaymentRefunded {
+			t.Errorf("order payment status = %s, want %s", order.PaymentStatus, models.OrderPaymentRefunded)
+		}
+
+		if _, err := s.Refund(context.Background(), p.ID, 1); !errors.Is(err, ErrConflict) {
+			t.Errorf("refunding a refunded payment: error = %v, want %v", err, ErrConflict)
+		}
+		if provider.refunds != 2 {
+			t.Errorf("provider refunded %d times, want 2", provider.refunds)
+		}
+	})
+
+	t.Run("concurrent refund moves no money", func(t *testing.T) {
+		p := capturedPayment(order.ID, 40)
+		payments := newFakePaymentRepo(p)
+		provider := &countingProvider{ManualProvider: payment.NewManualProvider(payment.ProviderCash)}
+		s := newTestPaymentService(newFakeOrderRepo(order), payments, provider)
+
+		// Another refund of the whole payment is booked after this one read it
+		payments.beforeUpdate = func(stored *models.Payment) {
+			stored.RefundedAmount = 40
+			stored.Status = models.PaymentStatusRefunded
+			payments.beforeUpdate = nil
+		}
+
+		if _, err := s.Refund(context.Background(), p.ID, 30); !errors.Is(err, ErrConflict) {
+			t.Fatalf("Refund() error = %v, want %v", err, ErrConflict)
+		}
+		if provider.refunds != 0 {
+			t.Errorf("provider refunded %d times, want 0", provider.refunds)
+		}
+		if stored := payments.payments[p.ID]; stored.RefundedAmount != 40 {
+			t.Errorf("refunded amount = %v, want the other refund's 40", stored.RefundedAmount)
+		}
+	})
+
+	t.Run("provider failure releases the refund", func(t *testing.T) {
+		p := capturedPayment(order.ID, 40)
+		payments := newFakePaymentRepo(p)
+		provider := &countingProvider{ManualProvider: payment.NewManualProvider(payment.ProviderCash), refundErr: errors.New("gateway down")}
+		s := newTestPaymentService(newFakeOrderRepo(order), payments, provider)
+
+		if _, err := s.Refund(context.Background(), p.ID, 10); err == nil {
+			t.Fatal("Refund() error = nil, want the provider's error")
+		}
+		stored := payments.payments[p.ID]
+		if stored.RefundedAmount != 0 || stored.Status != models.PaymentStatusCaptured {
+			t.Errorf("after a failed refund: refunded %v, status %s, want 0 and captured", stored.RefundedAmount, stored.Status)
+		}
+	})
+}
+
+func TestPaymentCapture(t *testing.T) {
+	order := &models.Order{ID: uuid.New(), TotalAmount: 40}
+	authorized := func() *models.Payment {
+		return &models.Payment{
+			ID:          uuid.New(),
+			OrderID:     order.ID,
+			Provider:    payment.ProviderCash,
+			ProviderRef: uuid.NewString(),
+			Amount:      40,
+			Status:      models.PaymentStatusAuthorized,
+		}
+	}
+
+	t.Run("partial capture", func(t *testing.T) {
+		p := authorized()
+		s := newTestPaymentService(newFakeOrderRepo(order), newFakePaymentRepo(p), payment.NewManualProvider(payment.ProviderCash))
+
+		captured, err := s.Capture(context.Background(), p.ID, 25)
+		if err != nil {
+			t.Fatalf("Capture() error = %v", err)
+		}
+		if captured.CapturedAmount != 25 || captured.Status != models.PaymentStatusCaptured {
+			t.Errorf("captured %v, status %s", captured.CapturedAmount, captured.Status)
+		}
+		if order := s.orderRepo.(*fakeOrderRepo).orders[order.ID]; order.PaymentStatus != models.OrderPaymentPartiallyPaid {
+			t.Errorf("order payment status = %s, want %s", order.PaymentStatus, models.OrderPaymentPartiallyPaid)
+		}
+	})
+
+	t.Run("more than authorized", func(t *testing.T) {
+		p := authorized()
+		s := newTestPaymentService(newFakeOrderRepo(order), newFakePaymentRepo(p), payment.NewManualProvider(payment.ProviderCash))
+
+		if _, err := s.Capture(context.Background(), p.ID, 41); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Capture() error = %v, want %v", err, ErrInvalidInput)
+		}
+	})
+
+	t.Run("voided meanwhile", func(t *testing.T) {
+		p := authorized()
+		payments := newFakePaymentRepo(p)
+		payments.beforeUpdate = func(stored *models.Payment) { stored.Status = models.PaymentStatusVoided }
+		s := newTestPaymentService(newFakeOrderRepo(order), payments, payment.NewManualProvider(payment.ProviderCash))
+
+		if _, err := s.Capture(context.Background(), p.ID, 0); !errors.Is(err, ErrConflict) {
+			t.Errorf("Capture() error = %v, want %v", err, ErrConflict)
+		}
+		if stored := payments.payments[p.ID]; stored.Status != models.PaymentStatusVoided {
+			t.Errorf("status = %s, want the void to stand", stored.Status)
+		}
+	})
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	const secret = "test-secret"
+	order := &models.Order{ID: uuid.New(), TotalAmount: 40}
+	pending := func() *models.Payment {
+		return &models.Payment{
+			ID:          uuid.New(),
+			OrderID:     order.ID,
+			Provider:    payment.ProviderFake,
+			ProviderRef: "fake_" + uuid.NewString(),
+			Amount:      40,
+			Status:      models.PaymentStatusPending,
+		}
+	}
+	sign := func(t *testing.T, event payment.WebhookEvent) ([]byte, string) {
+		t.Helper()
+		payload, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		return payload, hex.EncodeToString(mac.Sum(nil))
+	}
+
+	t.Run("captures a pending payment", func(t *testing.T) {
+		p := pending()
+		payments := newFakePaymentRepo(p)
+		s := newTestPaymentService(newFakeOrderRepo(order), payments, payment.NewFakeProvider(secret, 0))
+
+		payload, signature := sign(t, payment.WebhookEvent{Reference: p.ProviderRef, Status: models.PaymentStatusCaptured, Amount: 40})
+		if err := s.HandleWebhook(context.Background(), payment.ProviderFake, payload, signature); err != nil {
+			t.Fatalf("HandleWebhook() error = %v", err)
+		}
+		if stored := payments.payments[p.ID]; stored.Status != models.PaymentStatusCaptured || stored.CapturedAmount != 40 {
+			t.Errorf("status %s, captured %v", stored.Status, stored.CapturedAmount)
+		}
+		if order := s.orderRepo.(*fakeOrderRepo).orders[order.ID]; order.PaymentStatus != models.OrderPaymentPaid {
+			t.Errorf("order payment status = %s, want %s", order.PaymentStatus, models.OrderPaymentPaid)
+		}
+
+		// Redelivery changes nothing
+		if err := s.HandleWebhook(context.Background(), payment.ProviderFake, payload, signature); err != nil {
+			t.Errorf("redelivered HandleWebhook() error = %v", err)
+		}
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		p := pending()
+		s := newTestPaymentService(newFakeOrderRepo(order), newFakePaymentRepo(p), payment.NewFakeProvider(secret, 0))
+
+		payload, _ := sign(t, payment.WebhookEvent{Reference: p.ProviderRef, Status: models.PaymentStatusCaptured, Amount: 40})
+		if err := s.HandleWebhook(context.Background(), payment.ProviderFake, payload, hex.EncodeToString([]byte("forged"))); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("HandleWebhook() error = %v, want %v", err, ErrInvalidInput)
+		}
+	})
+
+	t.Run("voided meanwhile", func(t *testing.T) {
+		p := pending()
+		payments := newFakePaymentRepo(p)
+		payments.beforeUpdate = func(stored *models.Payment) { stored.Status = models.PaymentStatusVoided }
+		s := newTestPaymentService(newFakeOrderRepo(order), payments, payment.NewFakeProvider(secret, 0))
+
+		payload, signature := sign(t, payment.WebhookEvent{Reference: p.ProviderRef, Status: models.PaymentStatusCaptured, Amount: 40})
+		if err := s.HandleWebhook(context.Background(), payment.ProviderFake, payload, signature); !errors.Is(err, ErrConflict) {
+			t.Errorf("HandleWebhook() error = %v, want %v", err, ErrConflict)
+		}
+		if stored := payments.payments[p.ID]; stored.Status != models.PaymentStatusVoided {
+			t.Errorf("status = %s, want the void to stand", stored.Status)
+		}
+	})
+}
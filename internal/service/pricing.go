@@ -0,0 +1,637 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type PricingService struct {
+	ruleRepo          repository.PricingRuleRepository
+	menuRepo          repository.MenuRepository
+	customizationRepo repository.CustomizationRepository
+	variantRepo       repository.VariantRepository
+	bundleRepo        repository.BundleRepository
+	restaurantRepo    repository.RestaurantRepository
+}
+
+func NewPricingService(
+	ruleRepo repository.PricingRuleRepository,
+	menuRepo repository.MenuRepository,
+	customizationRepo repository.CustomizationRepository,
+	variantRepo repository.VariantRepository,
+	bundleRepo repository.BundleRepository,
+	restaurantRepo repository.RestaurantRepository,
+) *PricingService {
+	return &PricingService{
+		ruleRepo:          ruleRepo,
+		menuRepo:          menuRepo,
+		customizationRepo: customizationRepo,
+		variantRepo:       variantRepo,
+		bundleRepo:        bundleRepo,
+		restaurantRepo:    restaurantRepo,
+	}
+}
+
+func (s *PricingService) CreateRule(ctx context.Context, rule *models.PricingRule) error {
+	if err := s.validate(ctx, rule); err != nil {
+		return err
+	}
+	return s.ruleRepo.Create(ctx, rule)
+}
+
+// GetRule returns a pricing rule of the restaurant.
+func (s *PricingService) GetRule(ctx context.Context, restaurantID, id uuid.UUID) (*models.PricingRule, error) {
+	rule, err := s.ruleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil || rule.RestaurantID != restaurantID {
+		return nil, fmt.Errorf("%w: pricing rule %s", ErrNotFound, id)
+	}
+	return rule, nil
+}
+
+func (s *PricingService) ListRules(ctx context.Context, restaurantID uuid.UUID) ([]*models.PricingRule, error) {
+	return s.ruleRepo.ListByRestaurant(ctx, restaurantID)
+}
+
+func (s *PricingService) UpdateRule(ctx context.Context, rule *models.PricingRule) error {
+	if err := s.validate(ctx, rule); err != nil {
+		return err
+	}
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: pricing rule %s", ErrNotFound, rule.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *PricingService) DeleteRule(ctx context.Context, restaurantID, id uuid.UUID) error {
+	if _, err := s.GetRule(ctx, restaurantID, id); err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(ctx, id)
+}
+
+// validate checks the rule itself and that its promo code is not already
+// used by another rule of the restaurant.
+func (s *PricingService) validate(ctx context.Context, rule *models.PricingRule) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	if rule.PromoCode == nil {
+		return nil
+	}
+
+	rules, err := s.ruleRepo.ListByRestaurant(ctx, rule.RestaurantID)
+	if err != nil {
+		return err
+	}
+	for _, other := range rules {
+		if other.ID != rule.ID && other.PromoCode != nil && *other.PromoCode == *rule.PromoCode {
+			return fmt.Errorf("%w: promo code %s is already in use", ErrConflict, *rule.PromoCode)
+		}
+	}
+	return nil
+}
+
+// pricedLine is an order item priced from the menu.
+type pricedLine struct {
+	menuItemID uuid.UUID
+	categoryID uuid.UUID
+	unit       int64
+	quantity   int
+}
+
+type discountCandidate struct {
+	rule   *models.PricingRule
+	amount int64
+}
+
+// PriceOrder sets item prices from the current menu, using the price of the
+// chosen variant where the item has variants and adding the upcharges of
+// bundle components, and applies the
+// restaurant's pricing rules at the given time. Rule days and hours are in
+// the restaurant's time zone, whatever zone at is in.
+//
+// Every applicable rule is evaluated against the undiscounted prices.
+// Stackable discounts add up; a non-stackable discount is applied on its
+// own, and only if it beats the stackable total. A promo code on the order
+// must end up applied, otherwise the order is rejected.
+func (s *PricingService) PriceOrder(ctx context.Context, order *models.Order, at time.Time) error {
+	var (
+		lines    []pricedLine
+		subtotal int64
+	)
+	for i := range order.Items {
+		item := &order.Items[i]
+
+		menuItem, err := s.menuRepo.GetByID(ctx, item.MenuItemID)
+		if err != nil {
+			return err
+		}
+		if menuItem == nil || menuItem.RestaurantID != order.RestaurantID {
+			return fmt.Errorf("%w: menu item %s is not on the menu", ErrInvalidInput, item.MenuItemID)
+		}
+
+		base, err := s.priceVariant(ctx, menuItem, item)
+		if err != nil {
+			return err
+		}
+		extra, err := s.priceOptions(ctx, menuItem, item.Options)
+		if err != nil {
+			return err
+		}
+		upcharges, err := s.priceBundle(ctx, order.RestaurantID, menuItem, item)
+		if err != nil {
+			return err
+		}
+
+		unit := base + extra + upcharges
+		item.Price = fromCents(unit)
+		line := pricedLine{
+			menuItemID: menuItem.ID,
+			categoryID: menuItem.CategoryID,
+			unit:       unit,
+			quantity:   item.ActiveQuantity(),
+		}
+		lines = append(lines, line)
+		subtotal += line.unit * int64(line.quantity)
+	}
+
+	rules, err := s.ruleRepo.ListByRestaurant(ctx, order.RestaurantID)
+	if err != nil {
+		return err
+	}
+	restaurant, err := s.restaurantRepo.GetByID(ctx, order.RestaurantID)
+	if err != nil {
+		return err
+	}
+	at = at.In(restaurantLocation(restaurant))
+
+	code := normalizePromoCode(order.PromoCode)
+	order.PromoCode = code
+	codeReason := "is not valid"
+
+	var (
+		stackable []discountCandidate
+		exclusive *discountCandidate
+	)
+	for _, rule := range rules {
+		isCode := rule.PromoCode != nil
+		if isCode && *rule.PromoCode != code {
+			continue
+		}
+
+		if ok, reason := ruleApplies(rule, at, subtotal); !ok {
+			if isCode {
+				codeReason = reason
+			}
+			continue
+		}
+
+		amount := ruleDiscount(rule, lines, subtotal)
+		if amount <= 0 {
+			if isCode {
+				codeReason = "does not apply to any item on the order"
+			}
+			continue
+		}
+
+		candidate := discountCandidate{rule: rule, amount: amount}
+		if rule.Stackable {
+			stackable = append(stackable, candidate)
+			continue
+		}
+		// Prefer the promo code the guest entered when amounts tie
+		if exclusive == nil || amount > exclusive.amount || (amount == exclusive.amount && isCode) {
+			exclusive = &candidate
+		}
+	}
+
+	applied := stackable
+	var stackTotal int64
+	for _, c := range stackable {
+		stackTotal += c.amount
+	}
+	if exclusive != nil && exclusive.amount > stackTotal {
+		applied = []discountCandidate{*exclusive}
+	}
+
+	order.Discounts = nil
+	var discountTotal int64
+	codeApplied := false
+	for _, c := range applied {
+		amount := min(c.amount, subtotal-discountTotal)
+		if amount <= 0 {
+			break
+		}
+		discountTotal += amount
+
+		discount := models.OrderDiscount{
+			RuleID:      &c.rule.ID,
+			Description: c.rule.Name,
+			Amount:      fromCents(amount),
+		}
+		if c.rule.PromoCode != nil {
+			discount.Code = *c.rule.PromoCode
+			codeApplied = true
+		}
+		order.Discounts = append(order.Discounts, discount)
+	}
+
+	if code != "" && !codeApplied {
+		if exclusive != nil || len(stackable) > 0 {
+			codeReason = "cannot be combined with the current offers"
+		}
+		return fmt.Errorf("%w: promo code %s %s", ErrInvalidInput, code, codeReason)
+	}
+
+	order.Subtotal = fromCents(subtotal)
+	order.DiscountTotal = fromCents(discountTotal)
+	order.TotalAmount = fromCents(subtotal - discountTotal)
+
+	return nil
+}
+
+// priceVariant returns the base price of an item in cents: the price of the
+// chosen variant, or the menu price for items without variants. It records
+// the variant name on the item.
+func (s *PricingService) priceVariant(ctx context.Context, menuItem *models.MenuItem, item *models.OrderItem) (int64, error) {
+	variants, err := s.variantRepo.ListByMenuItem(ctx, menuItem.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	if item.VariantID == nil {
+		if len(variants) > 0 {
+			return 0, fmt.Errorf("%w: choose a variant of %s", ErrInvalidInput, menuItem.Name)
+		}
+		item.VariantName = ""
+		return toCents(menuItem.Price), nil
+	}
+
+	for _, variant := range variants {
+		if variant.ID == *item.VariantID {
+			item.VariantName = variant.Name
+			return toCents(variant.Price), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: variant %s is not a variant of %s", ErrInvalidInput, *item.VariantID, menuItem.Name)
+}
+
+// priceBundle checks the components chosen for a bundle against its choice
+// groups, prices each component at its upcharge plus its options and
+// returns the total per bundle in cents. Only bundles have components.
+func (s *PricingService) priceBundle(ctx context.Context, restaurantID uuid.UUID, menuItem *models.MenuItem, item *models.OrderItem) (int64, error) {
+	groups, err := s.bundleRepo.ListByMenuItem(ctx, menuItem.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(groups) == 0 {
+		if len(item.Components) > 0 {
+			return 0, fmt.Errorf("%w: %s is not a bundle", ErrInvalidInput, menuItem.Name)
+		}
+		return 0, nil
+	}
+
+	var total int64
+	selections := make(map[uuid.UUID]int)
+	for i := range item.Components {
+		component := &item.Components[i]
+		if component.Quantity == 0 {
+			component.Quantity = 1
+		}
+		if component.Quantity < 0 {
+			return 0, fmt.Errorf("%w: component quantity must be greater than 0", ErrInvalidInput)
+		}
+		if len(component.Components) > 0 {
+			return 0, fmt.Errorf("%w: bundle components cannot have components", ErrInvalidInput)
+		}
+
+		var group *models.BundleGroup
+		for j := range groups {
+			if component.BundleGroupID != nil && groups[j].ID == *component.BundleGroupID {
+				group = &groups[j]
+			}
+		}
+		if group == nil {
+			return 0, fmt.Errorf("%w: every component of %s must name one of its choice groups", ErrInvalidInput, menuItem.Name)
+		}
+
+		choice, ok := group.Choice(component.MenuItemID, component.VariantID)
+		if !ok {
+			return 0, fmt.Errorf("%w: menu item %s is not a choice for %s", ErrInvalidInput, component.MenuItemID, group.Name)
+		}
+		if choice.VariantID != nil {
+			component.VariantID = choice.VariantID
+		}
+
+		componentItem, err := s.menuRepo.GetByID(ctx, component.MenuItemID)
+		if err != nil {
+			return 0, err
+		}
+		if componentItem == nil || componentItem.RestaurantID != restaurantID {
+			return 0, fmt.Errorf("%w: menu item %s is not on the menu", ErrInvalidInput, component.MenuItemID)
+		}
+		// The bundle price covers the component, only its variant name is kept
+		if _, err := s.priceVariant(ctx, componentItem, component); err != nil {
+			return 0, err
+		}
+		extra, err := s.priceOptions(ctx, componentItem, component.Options)
+		if err != nil {
+			return 0, err
+		}
+
+		component.Price = fromCents(toCents(choice.Upcharge) + extra)
+		selections[group.ID] += component.Quantity
+		total += toCents(component.Price) * int64(component.Quantity)
+	}
+
+	for _, group := range groups {
+		count := selections[group.ID]
+		if count < group.MinSelections {
+			return 0, fmt.Errorf("%w: choose at least %d for %s", ErrInvalidInput, group.MinSelections, group.Name)
+		}
+		if count > group.MaxSelections {
+			return 0, fmt.Errorf("%w: choose at most %d for %s", ErrInvalidInput, group.MaxSelections, group.Name)
+		}
+	}
+
+	return total, nil
+}
+
+// priceOptions checks the options chosen for an item against its
+// customizations and returns their added price in cents.
+func (s *PricingService) priceOptions(ctx context.Context, menuItem *models.MenuItem, options []models.OrderItemOption) (int64, error) {
+	customizations, err := s.customizationRepo.ListByMenuItem(ctx, menuItem.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	byID := make(map[uuid.UUID]*models.MenuItemCustomization, len(customizations))
+	for _, c := range customizations {
+		byID[c.ID] = c
+	}
+
+	var extra int64
+	chosen := make(map[uuid.UUID]int)
+	for _, option := range options {
+		c, ok := byID[option.CustomizationID]
+		if !ok {
+			return 0, fmt.Errorf("%w: customization %s is not available for %s", ErrInvalidInput, option.CustomizationID, menuItem.Name)
+		}
+		chosen[c.ID]++
+
+		switch c.FieldType {
+		case models.CustomizationSingleSelect, models.CustomizationMultiSelect:
+			choice, ok := c.Option(option.Value)
+			if !ok {
+				return 0, fmt.Errorf("%w: %q is not an option of %s", ErrInvalidInput, option.Value, c.Name)
+			}
+			if c.FieldType == models.CustomizationSingleSelect && chosen[c.ID] > 1 {
+				return 0, fmt.Errorf("%w: only one option of %s can be chosen", ErrInvalidInput, c.Name)
+			}
+			extra += toCents(choice.Price)
+		case models.CustomizationBoolean:
+			if option.Value != "true" && option.Value != "false" {
+				return 0, fmt.Errorf("%w: %s must be true or false", ErrInvalidInput, c.Name)
+			}
+		}
+	}
+
+	for _, c := range customizations {
+		if c.Required && chosen[c.ID] == 0 {
+			return 0, fmt.Errorf("%w: %s is required for %s", ErrInvalidInput, c.Name, menuItem.Name)
+		}
+	}
+
+	return extra, nil
+}
+
+// ruleApplies checks the rule's validity window, schedule, usage limit and
+// minimum subtotal, returning why it does not apply. The schedule is read
+// in at's time zone, which must be the restaurant's.
+func ruleApplies(rule *models.PricingRule, at time.Time, subtotal int64) (bool, string) {
+	switch {
+	case !rule.Active:
+		return false, "is not active"
+	case rule.ValidFrom != nil && at.Before(*rule.ValidFrom):
+		return false, "is not valid yet"
+	case rule.ValidUntil != nil && !at.Before(*rule.ValidUntil):
+		return false, "has expired"
+	case rule.UsageLimit != nil && rule.UsageCount >= *rule.UsageLimit:
+		return false, "has reached its usage limit"
+	case subtotal < toCents(rule.MinSubtotal):
+		return false, fmt.Sprintf("requires a minimum order of %.2f", rule.MinSubtotal)
+	}
+
+	if len(rule.DaysOfWeek) > 0 && !containsInt(rule.DaysOfWeek, int(at.Weekday())) {
+		return false, "is not valid today"
+	}
+
+	if rule.StartTime != "" && rule.EndTime != "" {
+		start, _ := parseClock(rule.StartTime)
+		end, _ := parseClock(rule.EndTime)
+		now := at.Hour()*60 + at.Minute()
+
+		// A window such as 22:00-02:00 runs past midnight
+		inWindow := now >= start && now < end
+		if start > end {
+			inWindow = now >= start || now < end
+		}
+		if !inWindow {
+			return false, "is not valid at this time"
+		}
+	}
+
+	return true, ""
+}
+
+// ruleDiscount returns the discount in cents the rule gives on the lines.
+func ruleDiscount(rule *models.PricingRule, lines []pricedLine, subtotal int64) int64 {
+	matches := func(line pricedLine) bool {
+		switch rule.Scope {
+		case models.PricingScopeCategory:
+			return rule.ScopeID != nil && line.categoryID == *rule.ScopeID
+		case models.PricingScopeMenuItem:
+			return rule.ScopeID != nil && line.menuItemID == *rule.ScopeID
+		}
+		return true
+	}
+
+	value := toCents(rule.Value)
+	var amount int64
+
+	switch rule.Type {
+	case models.PricingPercentOff:
+		base := subtotal
+		if rule.Scope != models.PricingScopeOrder {
+			base = 0
+			for _, line := range lines {
+				if matches(line) {
+					base += line.unit * int64(line.quantity)
+				}
+			}
+		}
+		amount = int64(math.Round(float64(base) * rule.Value / 100))
+
+	case models.PricingAmountOff:
+		if rule.Scope == models.PricingScopeOrder {
+			amount = min(value, subtotal)
+			break
+		}
+		for _, line := range lines {
+			if matches(line) {
+				amount += min(value, line.unit) * int64(line.quantity)
+			}
+		}
+
+	case models.PricingFixedPrice:
+		for _, line := range lines {
+			if matches(line) && line.unit > value {
+				amount += (line.unit - value) * int64(line.quantity)
+			}
+		}
+
+	case models.PricingBuyXGetY:
+		var units []int64
+		for _, line := range lines {
+			if !matches(line) {
+				continue
+			}
+			for i := 0; i < line.quantity; i++ {
+				units = append(units, line.unit)
+			}
+		}
+		sort.Slice(units, func(i, j int) bool { return units[i] > units[j] })
+
+		percent := rule.Value
+		if percent == 0 {
+			percent = 100
+		}
+		group := rule.BuyQuantity + rule.GetQuantity
+		full := len(units) / group * group
+		for i := 0; i < full; i++ {
+			if i%group >= rule.BuyQuantity {
+				amount += int64(math.Round(float64(units[i]) * percent / 100))
+			}
+		}
+	}
+
+	return amount
+}
+
+func validateRule(rule *models.PricingRule) error {
+	if strings.TrimSpace(rule.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	switch rule.Scope {
+	case models.PricingScopeOrder:
+		rule.ScopeID = nil
+	case models.PricingScopeCategory, models.PricingScopeMenuItem:
+		if rule.ScopeID == nil {
+			return fmt.Errorf("%w: scope_id is required for %s scope", ErrInvalidInput, rule.Scope)
+		}
+	default:
+		return fmt.Errorf("%w: unknown scope %q", ErrInvalidInput, rule.Scope)
+	}
+
+	switch rule.Type {
+	case models.PricingPercentOff:
+		if rule.Value <= 0 || rule.Value > 100 {
+			return fmt.Errorf("%w: percentage must be between 0 and 100", ErrInvalidInput)
+		}
+	case models.PricingAmountOff:
+		if rule.Value <= 0 {
+			return fmt.Errorf("%w: value must be greater than 0", ErrInvalidInput)
+		}
+	case models.PricingFixedPrice:
+		if rule.Value < 0 || rule.Scope == models.PricingScopeOrder {
+			return fmt.Errorf("%w: fixed prices need a non-negative value and an item or category scope", ErrInvalidInput)
+		}
+	case models.PricingBuyXGetY:
+		if rule.BuyQuantity <= 0 || rule.GetQuantity <= 0 {
+			return fmt.Errorf("%w: buy_quantity and get_quantity must be greater than 0", ErrInvalidInput)
+		}
+		if rule.Value < 0 || rule.Value > 100 {
+			return fmt.Errorf("%w: percentage must be between 0 and 100", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown rule type %q", ErrInvalidInput, rule.Type)
+	}
+
+	if rule.PromoCode != nil {
+		code := normalizePromoCode(*rule.PromoCode)
+		if code == "" {
+			return fmt.Errorf("%w: promo code cannot be empty", ErrInvalidInput)
+		}
+		rule.PromoCode = &code
+	}
+
+	if rule.ValidFrom != nil && rule.ValidUntil != nil && !rule.ValidFrom.Before(*rule.ValidUntil) {
+		return fmt.Errorf("%w: valid_from must be before valid_until", ErrInvalidInput)
+	}
+
+	for _, day := range rule.DaysOfWeek {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("%w: days_of_week must be between 0 (Sunday) and 6", ErrInvalidInput)
+		}
+	}
+
+	if (rule.StartTime == "") != (rule.EndTime == "") {
+		return fmt.Errorf("%w: start_time and end_time must be set together", ErrInvalidInput)
+	}
+	if rule.StartTime != "" {
+		if _, err := parseClock(rule.StartTime); err != nil {
+			return err
+		}
+		if _, err := parseClock(rule.EndTime); err != nil {
+			return err
+		}
+	}
+
+	if rule.UsageLimit != nil && *rule.UsageLimit <= 0 {
+		return fmt.Errorf("%w: usage_limit must be greater than 0", ErrInvalidInput)
+	}
+	if rule.MinSubtotal < 0 {
+		return fmt.Errorf("%w: min_subtotal cannot be negative", ErrInvalidInput)
+	}
+
+	return nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid time %q, expected HH:MM", ErrInvalidInput, value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
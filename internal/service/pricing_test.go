@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestRuleAppliesInRestaurantTime(t *testing.T) {
+	newYork := time.FixedZone("UTC-5", -5*60*60)
+	happyHour := &models.PricingRule{
+		Active:     true,
+		DaysOfWeek: []int{1, 2, 3, 4, 5},
+		StartTime:  "17:00",
+		EndTime:    "20:00",
+	}
+	lateNight := &models.PricingRule{
+		Active:    true,
+		StartTime: "22:00",
+		EndTime:   "02:00",
+	}
+
+	tests := []struct {
+		name string
+		rule *models.PricingRule
+		at   time.Time
+		want bool
+	}{
+		{"happy hour in restaurant time", happyHour, time.Date(2024, 3, 4, 22, 30, 0, 0, time.UTC).In(newYork), true},
+		{"same instant in server time", happyHour, time.Date(2024, 3, 4, 22, 30, 0, 0, time.UTC), false},
+		{"friday evening locally", happyHour, time.Date(2024, 3, 9, 0, 30, 0, 0, time.UTC).In(newYork), true},
+		{"friday evening is saturday in server time", happyHour, time.Date(2024, 3, 9, 0, 30, 0, 0, time.UTC), false},
+		{"window past midnight", lateNight, time.Date(2024, 3, 5, 1, 0, 0, 0, newYork), true},
+		{"before window past midnight", lateNight, time.Date(2024, 3, 5, 21, 0, 0, 0, newYork), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := ruleApplies(tt.rule, tt.at, 0)
+			if got != tt.want {
+				t.Errorf("ruleApplies at %s = %v (%s), want %v", tt.at, got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduledOrdersArePricedWhenDue(t *testing.T) {
+	restaurantID := uuid.New()
+	burger := &models.MenuItem{ID: uuid.New(), RestaurantID: restaurantID, Price: 10}
+	happyHour := &models.PricingRule{
+		ID: uuid.New(), RestaurantID: restaurantID, Name: "Happy hour", Type: models.PricingPercentOff,
+		Scope: models.PricingScopeOrder, Value: 50, StartTime: "17:00", EndTime: "20:00", Active: true,
+	}
+	s := newPricingOrderService(newFakeOrderRepo(), []*models.MenuItem{burger}, []*models.PricingRule{happyHour}, models.RestaurantSettings{TaxMode: models.TaxModeNone})
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	at := func(hour int) *time.Time {
+		due := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), hour, 0, 0, 0, time.UTC)
+		return &due
+	}
+	tests := []struct {
+		name string
+		due  *time.Time
+		want float64
+	}{
+		{"due in happy hour", at(18), 5},
+		{"due before happy hour", at(12), 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{RestaurantID: restaurantID, ScheduledFor: tt.due, Items: []models.OrderItem{{MenuItemID: burger.ID, Quantity: 1}}}
+			if err := s.price(context.Background(), order, false); err != nil {
+				t.Fatalf("price() error = %v", err)
+			}
+			if order.TotalAmount != tt.want {
+				t.Errorf("total = %v, want %v", order.TotalAmount, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,510 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type PurchasingService struct {
+	supplierRepo      repository.SupplierRepository
+	purchaseOrderRepo repository.PurchaseOrderRepository
+	inventoryRepo     repository.InventoryRepository
+}
+
+func NewPurchasingService(
+	supplierRepo repository.SupplierRepository,
+	purchaseOrderRepo repository.PurchaseOrderRepository,
+	inventoryRepo repository.InventoryRepository,
+) *PurchasingService {
+	return &PurchasingService{
+		supplierRepo:      supplierRepo,
+		purchaseOrderRepo: purchaseOrderRepo,
+		inventoryRepo:     inventoryRepo,
+	}
+}
+
+func (s *PurchasingService) CreateSupplier(ctx context.Context, supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+	return s.supplierRepo.Create(ctx, supplier)
+}
+
+// GetSupplier returns a supplier of the restaurant.
+func (s *PurchasingService) GetSupplier(ctx context.Context, restaurantID, id uuid.UUID) (*models.Supplier, error) {
+	supplier, err := s.supplierRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil || supplier.RestaurantID != restaurantID {
+		return nil, fmt.Errorf("%w: supplier %s", ErrNotFound, id)
+	}
+	return supplier, nil
+}
+
+func (s *PurchasingService) ListSuppliers(ctx context.Context, restaurantID uuid.UUID) ([]*models.Supplier, error) {
+	return s.supplierRepo.ListByRestaurant(ctx, restaurantID)
+}
+
+func (s *PurchasingService) UpdateSupplier(ctx context.Context, supplier *models.Supplier) error {
+	if _, err := s.GetSupplier(ctx, supplier.RestaurantID, supplier.ID); err != nil {
+		return err
+	}
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+	return s.supplierRepo.Update(ctx, supplier)
+}
+
+// DeleteSupplier removes a supplier and its catalog. Suppliers with
+// purchase orders are kept for the record.
+func (s *PurchasingService) DeleteSupplier(ctx context.Context, restaurantID, id uuid.UUID) error {
+	if _, err := s.GetSupplier(ctx, restaurantID, id); err != nil {
+		return err
+	}
+
+	orders, err := s.purchaseOrderRepo.ListByRestaurant(ctx, restaurantID, models.PurchaseOrderFilter{SupplierID: &id})
+	if err != nil {
+		return err
+	}
+	if len(orders) > 0 {
+		return fmt.Errorf("%w: supplier has purchase orders", ErrConflict)
+	}
+
+	return s.supplierRepo.Delete(ctx, id)
+}
+
+// ListCatalog returns the ingredients a supplier sells with their current
+// on-hand quantities.
+func (s *PurchasingService) ListCatalog(ctx context.Context, restaurantID, supplierID uuid.UUID) ([]*models.SupplierItem, error) {
+	if _, err := s.GetSupplier(ctx, restaurantID, supplierID); err != nil {
+		return nil, err
+	}
+	return s.supplierRepo.ListItems(ctx, supplierID)
+}
+
+func (s *PurchasingService) SaveCatalogItem(ctx context.Context, restaurantID uuid.UUID, item *models.SupplierItem) error {
+	if _, err := s.GetSupplier(ctx, restaurantID, item.SupplierID); err != nil {
+		return err
+	}
+	if err := s.checkIngredient(ctx, restaurantID, item.IngredientID); err != nil {
+		return err
+	}
+	if item.UnitCost < 0 {
+		return fmt.Errorf("%w: unit cost cannot be negative", ErrInvalidInput)
+	}
+	item.SKU = strings.TrimSpace(item.SKU)
+
+	return s.supplierRepo.SaveItem(ctx, item)
+}
+
+func (s *PurchasingService) DeleteCatalogItem(ctx context.Context, restaurantID, supplierID, id uuid.UUID) error {
+	if _, err := s.GetSupplier(ctx, restaurantID, supplierID); err != nil {
+		return err
+	}
+
+	item, err := s.supplierRepo.GetItem(ctx, id)
+	if err != nil {
+		return err
+	}
+	if item == nil || item.SupplierID != supplierID {
+		return fmt.Errorf("%w: catalog item %s", ErrNotFound, id)
+	}
+
+	return s.supplierRepo.DeleteItem(ctx, id)
+}
+
+// CreatePurchaseOrder stores a draft purchase order. Lines without a unit
+// cost take the cost from the supplier's catalog.
+func (s *PurchasingService) CreatePurchaseOrder(ctx context.Context, order *models.PurchaseOrder, user *models.AuthUser) error {
+	if err := s.prepare(ctx, order); err != nil {
+		return err
+	}
+	order.CreatedBy = &user.ID
+	order.SentAt = nil
+	return s.purchaseOrderRepo.Create(ctx, order)
+}
+
+// GetPurchaseOrder returns a purchase order of the restaurant.
+func (s *PurchasingService) GetPurchaseOrder(ctx context.Context, restaurantID, id uuid.UUID) (*models.PurchaseOrder, error) {
+	order, err := s.purchaseOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || order.RestaurantID != restaurantID {
+		return nil, fmt.Errorf("%w: purchase order %s", ErrNotFound, id)
+	}
+	return order, nil
+}
+
+func (s *PurchasingService) ListPurchaseOrders(ctx context.Context, restaurantID uuid.UUID, filter models.PurchaseOrderFilter) ([]*models.PurchaseOrder, error) {
+	return s.purchaseOrderRepo.ListByRestaurant(ctx, restaurantID, filter)
+}
+
+// UpdatePurchaseOrder replaces the lines and details of a draft. Once an
+// order has been sent to the supplier it can no longer be changed.
+func (s *PurchasingService) UpdatePurchaseOrder(ctx context.Context, order *models.PurchaseOrder) error {
+	existing, err := s.GetPurchaseOrder(ctx, order.RestaurantID, order.ID)
+	if err != nil {
+		return err
+	}
+	if existing.Status != models.PurchaseOrderDraft {
+		return fmt.Errorf("%w: only draft purchase orders can be edited", ErrConflict)
+	}
+
+	order.SupplierID = existing.SupplierID
+	if err := s.prepare(ctx, order); err != nil {
+		return err
+	}
+	order.Status = existing.Status
+	order.CreatedBy = existing.CreatedBy
+	order.CreatedAt = existing.CreatedAt
+
+	if err := s.purchaseOrderRepo.Update(ctx, order); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: purchase order was sent in the meantime", ErrConflict)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *PurchasingService) DeletePurchaseOrder(ctx context.Context, restaurantID, id uuid.UUID) error {
+	order, err := s.GetPurchaseOrder(ctx, restaurantID, id)
+	if err != nil {
+		return err
+	}
+	if order.Status != models.PurchaseOrderDraft {
+		return fmt.Errorf("%w: only draft purchase orders can be deleted", ErrConflict)
+	}
+
+	if err := s.purchaseOrderRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: purchase order was sent in the meantime", ErrConflict)
+		}
+		return err
+	}
+	return nil
+}
+
+// SendPurchaseOrder marks a draft as sent to the supplier.
+func (s *PurchasingService) SendPurchaseOrder(ctx context.Context, restaurantID, id uuid.UUID) (*models.PurchaseOrder, error) {
+	order, err := s.GetPurchaseOrder(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.PurchaseOrderDraft {
+		return nil, fmt.Errorf("%w: purchase order has already been sent", ErrConflict)
+	}
+
+	if err := s.purchaseOrderRepo.MarkSent(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: purchase order has already been sent", ErrConflict)
+		}
+		return nil, err
+	}
+
+	return s.purchaseOrderRepo.GetByID(ctx, id)
+}
+
+// ReceivePurchaseOrder books a delivery against a sent purchase order. The
+// received quantities are added to stock and the order becomes received
+// once every line has been delivered in full.
+func (s *PurchasingService) ReceivePurchaseOrder(ctx context.Context, restaurantID, id uuid.UUID, req models.ReceiveRequest, user *models.AuthUser) (*models.PurchaseOrder, error) {
+	order, err := s.GetPurchaseOrder(ctx, restaurantID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch order.Status {
+	case models.PurchaseOrderDraft:
+		return nil, fmt.Errorf("%w: purchase order has not been sent yet", ErrConflict)
+	case models.PurchaseOrderReceived:
+		return nil, fmt.Errorf("%w: purchase order has already been received", ErrConflict)
+	}
+	if len(req.Lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one line is required", ErrInvalidInput)
+	}
+
+	lines := make(map[uuid.UUID]models.PurchaseOrderLine, len(order.Lines))
+	for _, line := range order.Lines {
+		lines[line.ID] = line
+	}
+
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		note = fmt.Sprintf("purchase order %s", order.ID)
+	}
+
+	received := make(map[uuid.UUID]float64, len(req.Lines))
+	var movements []*models.StockMovement
+	for _, r := range req.Lines {
+		line, ok := lines[r.LineID]
+		if !ok {
+			return nil, fmt.Errorf("%w: line %s is not on the purchase order", ErrInvalidInput, r.LineID)
+		}
+		if _, ok := received[line.ID]; ok {
+			return nil, fmt.Errorf("%w: line %s is listed twice", ErrInvalidInput, line.ID)
+		}
+
+		quantity := roundQuantity(r.Quantity)
+		if quantity <= 0 {
+			return nil, fmt.Errorf("%w: received quantities must be greater than 0", ErrInvalidInput)
+		}
+		if quantity > roundQuantity(line.Outstanding()) {
+			return nil, fmt.Errorf("%w: only %.3f of line %s is outstanding", ErrInvalidInput, line.Outstanding(), line.ID)
+		}
+
+		received[line.ID] = quantity
+		movements = append(movements, &models.StockMovement{
+			IngredientID: line.IngredientID,
+			RestaurantID: order.RestaurantID,
+			Type:         models.StockDelivery,
+			Quantity:     quantity,
+			Note:         note,
+			CreatedBy:    &user.ID,
+		})
+	}
+
+	if err := s.purchaseOrderRepo.Receive(ctx, order, received, movements); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: purchase order was received in the meantime", ErrConflict)
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// SuggestPurchaseOrders proposes orders for every ingredient at or below
+// its low stock threshold. The quantity tops stock up to the reorder
+// target, counting what is already on order, and each ingredient goes to
+// the supplier that sells it cheapest.
+func (s *PurchasingService) SuggestPurchaseOrders(ctx context.Context, restaurantID uuid.UUID) ([]*models.SuggestedPurchaseOrder, error) {
+	ingredients, err := s.inventoryRepo.ListIngredients(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.purchaseOrderRepo.ListByRestaurant(ctx, restaurantID, models.PurchaseOrderFilter{})
+	if err != nil {
+		return nil, err
+	}
+	onOrder := make(map[uuid.UUID]float64)
+	for _, order := range orders {
+		if !order.Open() {
+			continue
+		}
+		for _, line := range order.Lines {
+			onOrder[line.IngredientID] += line.Outstanding()
+		}
+	}
+
+	items, err := s.supplierRepo.ListItemsByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	cheapest := make(map[uuid.UUID]*models.SupplierItem)
+	for _, item := range items {
+		if best, ok := cheapest[item.IngredientID]; !ok || item.UnitCost < best.UnitCost {
+			cheapest[item.IngredientID] = item
+		}
+	}
+
+	suppliers, err := s.supplierRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uuid.UUID]string, len(suppliers))
+	for _, supplier := range suppliers {
+		names[supplier.ID] = supplier.Name
+	}
+
+	bySupplier := make(map[uuid.UUID]*models.SuggestedPurchaseOrder)
+	var suggestions []*models.SuggestedPurchaseOrder
+	for _, ingredient := range ingredients {
+		if !ingredient.LowStock() {
+			continue
+		}
+		quantity := roundQuantity(ingredient.ReorderTarget() - ingredient.Stock - onOrder[ingredient.ID])
+		if quantity <= 0 {
+			continue
+		}
+
+		line := models.SuggestedLine{
+			IngredientID:      ingredient.ID,
+			Name:              ingredient.Name,
+			Unit:              ingredient.Unit,
+			Stock:             ingredient.Stock,
+			LowStockThreshold: ingredient.LowStockThreshold,
+			ParLevel:          ingredient.ParLevel,
+			OnOrder:           roundQuantity(onOrder[ingredient.ID]),
+			Quantity:          quantity,
+		}
+
+		// Ingredients no supplier sells are grouped under uuid.Nil
+		var supplierID uuid.UUID
+		if item, ok := cheapest[ingredient.ID]; ok {
+			supplierID = item.SupplierID
+			line.UnitCost = item.UnitCost
+		}
+
+		suggestion, ok := bySupplier[supplierID]
+		if !ok {
+			suggestion = &models.SuggestedPurchaseOrder{}
+			if supplierID != uuid.Nil {
+				id := supplierID
+				suggestion.SupplierID = &id
+				suggestion.SupplierName = names[supplierID]
+			}
+			bySupplier[supplierID] = suggestion
+			suggestions = append(suggestions, suggestion)
+		}
+		suggestion.Lines = append(suggestion.Lines, line)
+	}
+
+	for _, suggestion := range suggestions {
+		var total int64
+		for _, line := range suggestion.Lines {
+			total += lineCost(line.Quantity, line.UnitCost)
+		}
+		suggestion.Total = fromCents(total)
+	}
+
+	// Orders that can be placed come first, by supplier name
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if (suggestions[i].SupplierID == nil) != (suggestions[j].SupplierID == nil) {
+			return suggestions[j].SupplierID == nil
+		}
+		return suggestions[i].SupplierName < suggestions[j].SupplierName
+	})
+
+	return suggestions, nil
+}
+
+// CreateSuggestedPurchaseOrders turns the current suggestions into draft
+// purchase orders, skipping ingredients that no supplier sells.
+func (s *PurchasingService) CreateSuggestedPurchaseOrders(ctx context.Context, restaurantID uuid.UUID, user *models.AuthUser) ([]*models.PurchaseOrder, error) {
+	suggestions, err := s.SuggestPurchaseOrders(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := []*models.PurchaseOrder{}
+	for _, suggestion := range suggestions {
+		if suggestion.SupplierID == nil {
+			continue
+		}
+
+		order := &models.PurchaseOrder{
+			RestaurantID: restaurantID,
+			SupplierID:   *suggestion.SupplierID,
+			Notes:        "suggested from low stock",
+		}
+		for _, line := range suggestion.Lines {
+			order.Lines = append(order.Lines, models.PurchaseOrderLine{
+				IngredientID: line.IngredientID,
+				Quantity:     line.Quantity,
+				UnitCost:     line.UnitCost,
+			})
+		}
+
+		if err := s.CreatePurchaseOrder(ctx, order, user); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// prepare checks the supplier and lines of a purchase order, fills in
+// catalog costs and computes the total.
+func (s *PurchasingService) prepare(ctx context.Context, order *models.PurchaseOrder) error {
+	if _, err := s.GetSupplier(ctx, order.RestaurantID, order.SupplierID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: supplier %s does not supply the restaurant", ErrInvalidInput, order.SupplierID)
+		}
+		return err
+	}
+	if len(order.Lines) == 0 {
+		return fmt.Errorf("%w: purchase order must contain at least one line", ErrInvalidInput)
+	}
+
+	catalog, err := s.supplierRepo.ListItems(ctx, order.SupplierID)
+	if err != nil {
+		return err
+	}
+	costs := make(map[uuid.UUID]float64, len(catalog))
+	for _, item := range catalog {
+		costs[item.IngredientID] = item.UnitCost
+	}
+
+	seen := make(map[uuid.UUID]bool, len(order.Lines))
+	var total int64
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		if seen[line.IngredientID] {
+			return fmt.Errorf("%w: ingredient %s is listed twice", ErrInvalidInput, line.IngredientID)
+		}
+		seen[line.IngredientID] = true
+
+		if err := s.checkIngredient(ctx, order.RestaurantID, line.IngredientID); err != nil {
+			return err
+		}
+
+		line.Quantity = roundQuantity(line.Quantity)
+		if line.Quantity <= 0 {
+			return fmt.Errorf("%w: quantities must be greater than 0", ErrInvalidInput)
+		}
+		if line.UnitCost < 0 {
+			return fmt.Errorf("%w: unit cost cannot be negative", ErrInvalidInput)
+		}
+		if line.UnitCost == 0 {
+			line.UnitCost = costs[line.IngredientID]
+		}
+		line.ReceivedQuantity = 0
+
+		total += lineCost(line.Quantity, line.UnitCost)
+	}
+	order.Total = fromCents(total)
+	order.Notes = strings.TrimSpace(order.Notes)
+
+	return nil
+}
+
+func (s *PurchasingService) checkIngredient(ctx context.Context, restaurantID, id uuid.UUID) error {
+	ingredient, err := s.inventoryRepo.GetIngredient(ctx, id)
+	if err != nil {
+		return err
+	}
+	if ingredient == nil || ingredient.RestaurantID != restaurantID {
+		return fmt.Errorf("%w: ingredient %s is not stocked by the restaurant", ErrInvalidInput, id)
+	}
+	return nil
+}
+
+func validateSupplier(supplier *models.Supplier) error {
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	if supplier.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	supplier.Email = strings.TrimSpace(supplier.Email)
+	if supplier.Email != "" && !strings.Contains(supplier.Email, "@") {
+		return fmt.Errorf("%w: invalid email address", ErrInvalidInput)
+	}
+	return nil
+}
+
+// lineCost is the cost of a line in cents.
+func lineCost(quantity, unitCost float64) int64 {
+	return int64(math.Round(quantity * unitCost * 100))
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ReportService struct {
+	orderRepo repository.OrderRepository
+}
+
+func NewReportService(orderRepo repository.OrderRepository) *ReportService {
+	return &ReportService{
+		orderRepo: orderRepo,
+	}
+}
+
+// Sales reports what a restaurant sold on completed orders, broken down by
+// menu item and variant.
+func (s *ReportService) Sales(ctx context.Context, restaurantID uuid.UUID, from, to time.Time) (*models.SalesReport, error) {
+	sales, err := s.orderRepo.SalesByItem(ctx, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SalesReport{
+		RestaurantID: restaurantID,
+		From:         from,
+		To:           to,
+		Items:        []models.ItemSales{},
+	}
+	var revenue int64
+	for _, line := range sales {
+		report.Quantity += line.Quantity
+		revenue += toCents(line.Revenue)
+		report.Items = append(report.Items, line)
+	}
+	report.Revenue = fromCents(revenue)
+
+	return report, nil
+}
@@ -2,34 +2,237 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/KNLopez/restaurant-api/internal/geocode"
 	"github.com/KNLopez/restaurant-api/internal/models"
 	"github.com/KNLopez/restaurant-api/internal/repository"
 	"github.com/google/uuid"
 )
 
+const (
+	defaultSearchRadiusKm = 5
+	maxSearchRadiusKm     = 50
+)
+
 type RestaurantService struct {
-	restaurantRepo repository.RestaurantRepository
+	restaurantRepo   repository.RestaurantRepository
+	geocoder         geocode.Geocoder
+	hours            *HoursService
+	organizationRepo repository.OrganizationRepository
 }
 
-func NewRestaurantService(restaurantRepo repository.RestaurantRepository) *RestaurantService {
+func NewRestaurantService(
+	restaurantRepo repository.RestaurantRepository,
+	geocoder geocode.Geocoder,
+	hours *HoursService,
+	organizationRepo repository.OrganizationRepository,
+) *RestaurantService {
 	return &RestaurantService{
-		restaurantRepo: restaurantRepo,
+		restaurantRepo:   restaurantRepo,
+		geocoder:         geocoder,
+		hours:            hours,
+		organizationRepo: organizationRepo,
 	}
 }
 
-func (s *RestaurantService) Create(ctx context.Context, restaurant *models.Restaurant) error {
+// Create registers a restaurant. Everyone but admins opens it in an
+// organization they own or manage.
+func (s *RestaurantService) Create(ctx context.Context, user *models.AuthUser, restaurant *models.Restaurant) error {
+	restaurant.BrandID = nil
+	if user.Role != models.RoleAdmin {
+		if restaurant.OrganizationID == nil {
+			return fmt.Errorf("%w: organization_id is required", ErrInvalidInput)
+		}
+		ok, err := hasOrganizationRole(ctx, s.organizationRepo, *restaurant.OrganizationID, user, models.OrganizationRoleOwner, models.OrganizationRoleManager)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: only the organization's owners and managers can open restaurants in it", ErrForbidden)
+		}
+	}
+	if err := s.validate(ctx, restaurant, nil); err != nil {
+		return err
+	}
 	return s.restaurantRepo.Create(ctx, restaurant)
 }
 
+// GetByID returns a restaurant with whether it is open now.
 func (s *RestaurantService) GetByID(ctx context.Context, id uuid.UUID) (*models.Restaurant, error) {
-	return s.restaurantRepo.GetByID(ctx, id)
+	restaurant, err := s.restaurantRepo.GetByID(ctx, id)
+	if err != nil || restaurant == nil {
+		return restaurant, err
+	}
+	if err := s.hours.Annotate(ctx, []*models.Restaurant{restaurant}, time.Now()); err != nil {
+		return nil, err
+	}
+	return restaurant, nil
 }
 
-func (s *RestaurantService) Update(ctx context.Context, restaurant *models.Restaurant) error {
+// List finds restaurants for guests to browse. Around a point it returns
+// those within the radius, nearest first.
+func (s *RestaurantService) List(ctx context.Context, filter models.RestaurantFilter) ([]*models.Restaurant, error) {
+	if (filter.Latitude == nil) != (filter.Longitude == nil) {
+		return nil, fmt.Errorf("%w: near needs both a latitude and a longitude", ErrInvalidInput)
+	}
+	if filter.Latitude != nil {
+		if !(geocode.Point{Latitude: *filter.Latitude, Longitude: *filter.Longitude}).Valid() {
+			return nil, fmt.Errorf("%w: near is not a valid position", ErrInvalidInput)
+		}
+		if filter.RadiusKm == 0 {
+			filter.RadiusKm = defaultSearchRadiusKm
+		}
+		if filter.RadiusKm < 0 || filter.RadiusKm > maxSearchRadiusKm {
+			return nil, fmt.Errorf("%w: radius must be between 0 and %d km", ErrInvalidInput, maxSearchRadiusKm)
+		}
+	}
+	if filter.MinRating < 0 || filter.MinRating > 5 {
+		return nil, fmt.Errorf("%w: min_rating must be between 0 and 5", ErrInvalidInput)
+	}
+	filter.CuisineTags = normalizeCuisineTags(filter.CuisineTags)
+
+	now := time.Now()
+	if !filter.OpenNow {
+		restaurants, err := s.restaurantRepo.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.hours.Annotate(ctx, restaurants, now); err != nil {
+			return nil, err
+		}
+		return restaurants, nil
+	}
+
+	// Whether a restaurant is open depends on its own hours and timezone, so
+	// the page is cut after filtering
+	limit, offset := filter.Limit, filter.Offset
+	filter.Limit, filter.Offset = 0, 0
+	restaurants, err := s.restaurantRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.hours.Annotate(ctx, restaurants, now); err != nil {
+		return nil, err
+	}
+
+	open := []*models.Restaurant{}
+	for _, restaurant := range restaurants {
+		if *restaurant.IsOpen {
+			open = append(open, restaurant)
+		}
+	}
+
+	start := min(offset, len(open))
+	end := len(open)
+	if limit > 0 {
+		end = min(start+limit, end)
+	}
+	return open[start:end], nil
+}
+
+func (s *RestaurantService) Update(ctx context.Context, user *models.AuthUser, restaurant *models.Restaurant) error {
+	existing, err := s.getManaged(ctx, user, restaurant.ID)
+	if err != nil {
+		return err
+	}
+	// The timezone is part of the restaurant's settings and changed there,
+	// and the organization only through the organization
+	restaurant.Timezone = existing.Timezone
+	restaurant.OrganizationID = existing.OrganizationID
+	if err := s.validate(ctx, restaurant, existing); err != nil {
+		return err
+	}
 	return s.restaurantRepo.Update(ctx, restaurant)
 }
 
-func (s *RestaurantService) Delete(ctx context.Context, id uuid.UUID) error {
+func (s *RestaurantService) Delete(ctx context.Context, user *models.AuthUser, id uuid.UUID) error {
+	if _, err := s.getManaged(ctx, user, id); err != nil {
+		return err
+	}
 	return s.restaurantRepo.Delete(ctx, id)
 }
+
+// getManaged loads a restaurant the user manages.
+func (s *RestaurantService) getManaged(ctx context.Context, user *models.AuthUser, id uuid.UUID) (*models.Restaurant, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if restaurant == nil {
+		return nil, fmt.Errorf("%w: restaurant %s", ErrNotFound, id)
+	}
+	ok, err := managesRestaurant(ctx, s.restaurantRepo, s.organizationRepo, id, user)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: only the restaurant's managers can change it", ErrForbidden)
+	}
+	return restaurant, nil
+}
+
+func (s *RestaurantService) validate(ctx context.Context, restaurant, existing *models.Restaurant) error {
+	if err := validateTimezone(restaurant); err != nil {
+		return err
+	}
+	var err error
+	if restaurant.Translations, err = normalizeTranslations(restaurant.Translations, false); err != nil {
+		return err
+	}
+	restaurant.CuisineTags = normalizeCuisineTags(restaurant.CuisineTags)
+	return s.locate(ctx, restaurant, existing)
+}
+
+// locate fills in the coordinates of a restaurant from its address unless
+// they were given. A restaurant whose address has not changed keeps its
+// coordinates.
+func (s *RestaurantService) locate(ctx context.Context, restaurant, existing *models.Restaurant) error {
+	if (restaurant.Latitude == nil) != (restaurant.Longitude == nil) {
+		return fmt.Errorf("%w: latitude and longitude must be given together", ErrInvalidInput)
+	}
+	if restaurant.Latitude != nil {
+		if !(geocode.Point{Latitude: *restaurant.Latitude, Longitude: *restaurant.Longitude}).Valid() {
+			return fmt.Errorf("%w: latitude or longitude out of range", ErrInvalidInput)
+		}
+		return nil
+	}
+
+	restaurant.Address = strings.TrimSpace(restaurant.Address)
+	if existing != nil && existing.Address == restaurant.Address {
+		restaurant.Latitude, restaurant.Longitude = existing.Latitude, existing.Longitude
+		return nil
+	}
+	if restaurant.Address == "" {
+		return nil
+	}
+
+	point, err := s.geocoder.Geocode(ctx, restaurant.Address)
+	if errors.Is(err, geocode.ErrNotFound) {
+		return fmt.Errorf("%w: address %q could not be located", ErrInvalidInput, restaurant.Address)
+	}
+	if err != nil {
+		return err
+	}
+	restaurant.Latitude, restaurant.Longitude = &point.Latitude, &point.Longitude
+	return nil
+}
+
+// normalizeCuisineTags lowercases and trims cuisine tags and drops
+// duplicates, keeping their order.
+func normalizeCuisineTags(tags []string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ReviewService struct {
+	reviewRepo       repository.ReviewRepository
+	orderRepo        repository.OrderRepository
+	restaurantRepo   repository.RestaurantRepository
+	organizationRepo repository.OrganizationRepository
+}
+
+func NewReviewService(
+	reviewRepo repository.ReviewRepository,
+	orderRepo repository.OrderRepository,
+	restaurantRepo repository.RestaurantRepository,
+	organizationRepo repository.OrganizationRepository,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo:       reviewRepo,
+		orderRepo:        orderRepo,
+		restaurantRepo:   restaurantRepo,
+		organizationRepo: organizationRepo,
+	}
+}
+
+// Create records the guest's review of a completed order. Each order can
+// be reviewed once, and item ratings must be for items on the order.
+func (s *ReviewService) Create(ctx context.Context, orderID uuid.UUID, review *models.Review, user *models.AuthUser) error {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return fmt.Errorf("%w: order %s", ErrNotFound, orderID)
+	}
+	if order.UserID != user.ID {
+		return fmt.Errorf("%w: only the guest who placed the order can review it", ErrForbidden)
+	}
+	if order.Status != models.OrderStatusComplete {
+		return fmt.Errorf("%w: only completed orders can be reviewed", ErrConflict)
+	}
+
+	existing, err := s.reviewRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%w: order has already been reviewed", ErrConflict)
+	}
+
+	if !validRating(review.Rating) {
+		return fmt.Errorf("%w: rating must be between %d and %d", ErrInvalidInput, models.MinRating, models.MaxRating)
+	}
+
+	ordered := make(map[uuid.UUID]bool)
+	for _, item := range order.Items {
+		ordered[item.MenuItemID] = true
+	}
+	rated := make(map[uuid.UUID]bool)
+	for _, item := range review.Items {
+		if !ordered[item.MenuItemID] {
+			return fmt.Errorf("%w: menu item %s is not on the order", ErrInvalidInput, item.MenuItemID)
+		}
+		if rated[item.MenuItemID] {
+			return fmt.Errorf("%w: menu item %s is rated twice", ErrInvalidInput, item.MenuItemID)
+		}
+		if !validRating(item.Rating) {
+			return fmt.Errorf("%w: rating must be between %d and %d", ErrInvalidInput, models.MinRating, models.MaxRating)
+		}
+		rated[item.MenuItemID] = true
+	}
+
+	review.OrderID = order.ID
+	review.RestaurantID = order.RestaurantID
+	review.UserID = user.ID
+	review.Comment = strings.TrimSpace(review.Comment)
+	review.Reply = ""
+	review.RepliedBy = nil
+	review.RepliedAt = nil
+	review.Hidden = false
+
+	return s.reviewRepo.Create(ctx, review)
+}
+
+func (s *ReviewService) GetByOrder(ctx context.Context, orderID uuid.UUID) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil || review.Hidden {
+		return nil, fmt.Errorf("%w: order %s has no review", ErrNotFound, orderID)
+	}
+	return review, nil
+}
+
+func (s *ReviewService) ListByRestaurant(ctx context.Context, restaurantID uuid.UUID, filter models.ReviewFilter) ([]*models.Review, error) {
+	return s.reviewRepo.ListByRestaurant(ctx, restaurantID, filter)
+}
+
+// Reply sets the restaurant's public answer to a review.
+func (s *ReviewService) Reply(ctx context.Context, id uuid.UUID, reply string, user *models.AuthUser) (*models.Review, error) {
+	review, err := s.getManaged(ctx, id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	reply = strings.TrimSpace(reply)
+	if reply == "" {
+		return nil, fmt.Errorf("%w: reply cannot be empty", ErrInvalidInput)
+	}
+
+	review.Reply = reply
+	review.RepliedBy = &user.ID
+	if err := s.reviewRepo.Reply(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// SetHidden hides a review from listings and ratings, or shows it again.
+func (s *ReviewService) SetHidden(ctx context.Context, id uuid.UUID, hidden bool, user *models.AuthUser) (*models.Review, error) {
+	review, err := s.getManaged(ctx, id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reviewRepo.SetHidden(ctx, id, hidden); err != nil {
+		return nil, err
+	}
+	review.Hidden = hidden
+
+	return review, nil
+}
+
+// getManaged loads a review the user manages the restaurant of.
+func (s *ReviewService) getManaged(ctx context.Context, id uuid.UUID, user *models.AuthUser) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, fmt.Errorf("%w: review %s", ErrNotFound, id)
+	}
+
+	ok, err := managesRestaurant(ctx, s.restaurantRepo, s.organizationRepo, review.RestaurantID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: only the restaurant's manager can moderate reviews", ErrForbidden)
+	}
+
+	return review, nil
+}
+
+func validRating(rating int) bool {
+	return rating >= models.MinRating && rating <= models.MaxRating
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultCurrency     = "USD"
+	maxReceiptFooterLen = 500
+)
+
+type SettingsService struct {
+	settingsRepo   repository.SettingsRepository
+	restaurantRepo repository.RestaurantRepository
+	cacheTTL       time.Duration
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]cachedSettings
+}
+
+type cachedSettings struct {
+	settings  models.RestaurantSettings
+	expiresAt time.Time
+}
+
+// NewSettingsService creates the service. Settings read through For are
+// cached for cacheTTL, so a save made by another instance takes up to that
+// long to be seen.
+func NewSettingsService(
+	settingsRepo repository.SettingsRepository,
+	restaurantRepo repository.RestaurantRepository,
+	cacheTTL time.Duration,
+) *SettingsService {
+	return &SettingsService{
+		settingsRepo:   settingsRepo,
+		restaurantRepo: restaurantRepo,
+		cacheTTL:       cacheTTL,
+		cache:          make(map[uuid.UUID]cachedSettings),
+	}
+}
+
+// Get returns the current settings of a restaurant, or the defaults if it
+// has never saved any.
+func (s *SettingsService) Get(ctx context.Context, restaurantID uuid.UUID) (*models.RestaurantSettings, error) {
+	restaurant, err := s.restaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.current(ctx, restaurant)
+}
+
+// restaurant returns the restaurant if ctx may see it.
+func (s *SettingsService) restaurant(ctx context.Context, restaurantID uuid.UUID) (*models.Restaurant, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if restaurant == nil {
+		return nil, fmt.Errorf("%w: restaurant %s", ErrNotFound, restaurantID)
+	}
+	return restaurant, nil
+}
+
+func (s *SettingsService) current(ctx context.Context, restaurant *models.Restaurant) (*models.RestaurantSettings, error) {
+	settings, err := s.settingsRepo.Get(ctx, restaurant.ID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = defaultSettings(restaurant)
+	}
+	return settings, nil
+}
+
+// For returns the settings services act on, from the cache when it can.
+// The cache is shared by every tenant, so the restaurant is looked up in
+// the scope of ctx before it is used.
+func (s *SettingsService) For(ctx context.Context, restaurantID uuid.UUID) (models.RestaurantSettings, error) {
+	restaurant, err := s.restaurant(ctx, restaurantID)
+	if err != nil {
+		return models.RestaurantSettings{}, err
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	cached, ok := s.cache[restaurantID]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.settings, nil
+	}
+
+	settings, err := s.current(ctx, restaurant)
+	if err != nil {
+		return models.RestaurantSettings{}, err
+	}
+
+	s.mu.Lock()
+	s.cache[restaurantID] = cachedSettings{settings: *settings, expiresAt: now.Add(s.cacheTTL)}
+	s.mu.Unlock()
+	return *settings, nil
+}
+
+// Versions returns the saved versions of a restaurant's settings, newest
+// first.
+func (s *SettingsService) Versions(ctx context.Context, restaurantID uuid.UUID) ([]*models.RestaurantSettings, error) {
+	if _, err := s.Get(ctx, restaurantID); err != nil {
+		return nil, err
+	}
+	return s.settingsRepo.ListVersions(ctx, restaurantID)
+}
+
+// Save stores the settings as a new version. The settings must carry the
+// version they were based on.
+func (s *SettingsService) Save(ctx context.Context, user *models.AuthUser, settings *models.RestaurantSettings) error {
+	if _, err := s.Get(ctx, settings.RestaurantID); err != nil {
+		return err
+	}
+	if err := validateSettings(settings); err != nil {
+		return err
+	}
+	settings.UpdatedBy = &user.ID
+
+	err := s.settingsRepo.Save(ctx, settings)
+	if errors.Is(err, repository.ErrStaleVersion) {
+		return fmt.Errorf("%w: settings were changed since version %d; reload and try again", ErrConflict, settings.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.cache, settings.RestaurantID)
+	s.mu.Unlock()
+	return nil
+}
+
+func defaultSettings(restaurant *models.Restaurant) *models.RestaurantSettings {
+	timezone := restaurant.Timezone
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+	return &models.RestaurantSettings{
+		RestaurantID: restaurant.ID,
+		Currency:     defaultCurrency,
+		Timezone:     timezone,
+		TaxMode:      models.TaxModeNone,
+	}
+}
+
+func validateSettings(settings *models.RestaurantSettings) error {
+	if settings.Version < 0 {
+		return fmt.Errorf("%w: version must not be negative", ErrInvalidInput)
+	}
+
+	settings.Currency = strings.ToUpper(strings.TrimSpace(settings.Currency))
+	if settings.Currency == "" {
+		settings.Currency = defaultCurrency
+	}
+	if len(settings.Currency) != 3 || strings.Trim(settings.Currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("%w: currency must be a three-letter ISO 4217 code", ErrInvalidInput)
+	}
+
+	if settings.Timezone == "" {
+		settings.Timezone = defaultTimezone
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, settings.Timezone)
+	}
+
+	switch settings.TaxMode {
+	case "":
+		settings.TaxMode = models.TaxModeNone
+	case models.TaxModeNone, models.TaxModeExclusive, models.TaxModeInclusive:
+	default:
+		return fmt.Errorf("%w: tax_mode must be none, exclusive or inclusive", ErrInvalidInput)
+	}
+	if settings.TaxRate < 0 || settings.TaxRate > 100 {
+		return fmt.Errorf("%w: tax_rate must be between 0 and 100", ErrInvalidInput)
+	}
+	if settings.TaxMode == models.TaxModeNone && settings.TaxRate != 0 {
+		return fmt.Errorf("%w: tax_rate needs a tax_mode other than none", ErrInvalidInput)
+	}
+	if settings.ServiceChargeRate < 0 || settings.ServiceChargeRate > 100 {
+		return fmt.Errorf("%w: service_charge_rate must be between 0 and 100", ErrInvalidInput)
+	}
+	if settings.DeliveryFee < 0 {
+		return fmt.Errorf("%w: delivery_fee must not be negative", ErrInvalidInput)
+	}
+
+	settings.ReceiptFooter = strings.TrimSpace(settings.ReceiptFooter)
+	if utf8.RuneCountInString(settings.ReceiptFooter) > maxReceiptFooterLen {
+		return fmt.Errorf("%w: receipt_footer is longer than %d characters", ErrInvalidInput, maxReceiptFooterLen)
+	}
+
+	return nil
+}
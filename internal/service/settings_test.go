@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+)
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings models.RestaurantSettings
+		want     error
+	}{
+		{"defaults", models.RestaurantSettings{}, nil},
+		{"exclusive tax", models.RestaurantSettings{Currency: " eur ", TaxMode: models.TaxModeExclusive, TaxRate: 20}, nil},
+		{"negative version", models.RestaurantSettings{Version: -1}, ErrInvalidInput},
+		{"currency too long", models.RestaurantSettings{Currency: "EURO"}, ErrInvalidInput},
+		{"currency with digits", models.RestaurantSettings{Currency: "US1"}, ErrInvalidInput},
+		{"unknown timezone", models.RestaurantSettings{Timezone: "Mars/Olympus_Mons"}, ErrInvalidInput},
+		{"unknown tax mode", models.RestaurantSettings{TaxMode: "vat"}, ErrInvalidInput},
+		{"tax rate over 100", models.RestaurantSettings{TaxMode: models.TaxModeExclusive, TaxRate: 101}, ErrInvalidInput},
+		{"tax rate without tax mode", models.RestaurantSettings{TaxRate: 8}, ErrInvalidInput},
+		{"negative service charge", models.RestaurantSettings{ServiceChargeRate: -1}, ErrInvalidInput},
+		{"negative delivery fee", models.RestaurantSettings{DeliveryFee: -0.5}, ErrInvalidInput},
+		{"receipt footer too long", models.RestaurantSettings{ReceiptFooter: strings.Repeat("x", maxReceiptFooterLen+1)}, ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := tt.settings
+			err := validateSettings(&settings)
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("validateSettings() = %v, want %v", err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateSettings() = %v, want nil", err)
+			}
+			if len(settings.Currency) != 3 || settings.Currency != strings.ToUpper(settings.Currency) {
+				t.Errorf("currency = %q, want an upper-case ISO 4217 code", settings.Currency)
+			}
+			if settings.Timezone == "" || settings.TaxMode == "" {
+				t.Errorf("timezone %q and tax mode %q were not defaulted", settings.Timezone, settings.TaxMode)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+)
+
+const defaultTimezone = "UTC"
+
+// validateTimezone defaults an empty timezone to UTC and rejects names
+// that are not in the IANA time zone database.
+func validateTimezone(restaurant *models.Restaurant) error {
+	if restaurant.Timezone == "" {
+		restaurant.Timezone = defaultTimezone
+	}
+	if _, err := time.LoadLocation(restaurant.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, restaurant.Timezone)
+	}
+	return nil
+}
+
+// restaurantLocation returns the restaurant's time zone, falling back to
+// UTC for restaurants without a valid one.
+func restaurantLocation(restaurant *models.Restaurant) *time.Location {
+	if restaurant == nil || restaurant.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(restaurant.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
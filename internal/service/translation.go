@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KNLopez/restaurant-api/internal/models"
+	"github.com/KNLopez/restaurant-api/internal/repository"
+	"github.com/google/uuid"
+)
+
+// localePattern matches lowercase BCP 47 style tags such as "fr", "pt-br"
+// or "zh-hant-tw".
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+type TranslationService struct {
+	restaurantRepo repository.RestaurantRepository
+	categoryRepo   repository.CategoryRepository
+	menuRepo       repository.MenuRepository
+}
+
+func NewTranslationService(
+	restaurantRepo repository.RestaurantRepository,
+	categoryRepo repository.CategoryRepository,
+	menuRepo repository.MenuRepository,
+) *TranslationService {
+	return &TranslationService{
+		restaurantRepo: restaurantRepo,
+		categoryRepo:   categoryRepo,
+		menuRepo:       menuRepo,
+	}
+}
+
+// Missing lists the strings of a restaurant that have no translation in
+// the given locales. Without locales it checks every locale the
+// restaurant has translated anything into.
+func (s *TranslationService) Missing(ctx context.Context, restaurantID uuid.UUID, locales []string) (*models.TranslationReport, error) {
+	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if restaurant == nil {
+		return nil, fmt.Errorf("%w: restaurant %s", ErrNotFound, restaurantID)
+	}
+
+	categories, err := s.categoryRepo.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.menuRepo.List(ctx, restaurantID, models.MenuFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(locales) == 0 {
+		locales = usedLocales(restaurant, categories, items)
+	} else {
+		for i, locale := range locales {
+			if locales[i], err = normalizeLocale(locale); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	report := &models.TranslationReport{
+		RestaurantID: restaurantID,
+		Locales:      locales,
+		Missing:      []models.MissingTranslation{},
+		GeneratedAt:  time.Now(),
+	}
+	add := func(entityType string, id uuid.UUID, translations models.Translations, name, description string) {
+		for _, locale := range locales {
+			translation := translations[locale]
+			if name != "" && translation.Name == "" {
+				report.Missing = append(report.Missing, models.MissingTranslation{
+					EntityType: entityType, EntityID: id, Field: "name", Locale: locale, BaseText: name,
+				})
+			}
+			if description != "" && translation.Description == "" {
+				report.Missing = append(report.Missing, models.MissingTranslation{
+					EntityType: entityType, EntityID: id, Field: "description", Locale: locale, BaseText: description,
+				})
+			}
+		}
+	}
+
+	add("restaurant", restaurant.ID, restaurant.Translations, "", restaurant.Description)
+	for _, category := range categories {
+		add("category", category.ID, category.Translations, category.Name, category.Description)
+	}
+	for _, item := range items {
+		add("menu_item", item.ID, item.Translations, item.Name, item.Description)
+	}
+	report.Total = len(report.Missing)
+
+	return report, nil
+}
+
+// usedLocales returns the locales any of the restaurant's content is
+// translated into, sorted.
+func usedLocales(restaurant *models.Restaurant, categories []*models.Category, items []*models.MenuItem) []string {
+	seen := map[string]bool{}
+	for locale := range restaurant.Translations {
+		seen[locale] = true
+	}
+	for _, category := range categories {
+		for locale := range category.Translations {
+			seen[locale] = true
+		}
+	}
+	for _, item := range items {
+		for locale := range item.Translations {
+			seen[locale] = true
+		}
+	}
+
+	locales := make([]string, 0, len(seen))
+	for locale := range seen {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+func normalizeLocale(locale string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(strings.ReplaceAll(locale, "_", "-")))
+	if !localePattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w: invalid locale %q", ErrInvalidInput, locale)
+	}
+	return normalized, nil
+}
+
+// normalizeTranslations lowercases the locale tags, trims the text and
+// drops empty translations. Restaurant translations pass allowName false
+// as restaurant names are not translated.
+func normalizeTranslations(translations models.Translations, allowName bool) (models.Translations, error) {
+	normalized := models.Translations{}
+	for locale, translation := range translations {
+		key, err := normalizeLocale(locale)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := normalized[key]; ok {
+			return nil, fmt.Errorf("%w: locale %s is given twice", ErrInvalidInput, key)
+		}
+
+		translation.Name = strings.TrimSpace(translation.Name)
+		translation.Description = strings.TrimSpace(translation.Description)
+		if translation.Name != "" && !allowName {
+			return nil, fmt.Errorf("%w: only the description can be translated", ErrInvalidInput)
+		}
+		if translation == (models.Translation{}) {
+			continue
+		}
+		normalized[key] = translation
+	}
+	return normalized, nil
+}
@@ -0,0 +1,96 @@
+// Package tenant carries what restaurant data a request may see. Staff are
+// scoped to the organizations they are members of, so one organization can
+// never read another's data. Everyone else is a guest: guests may read the
+// public data of every restaurant, such as its menu, but only see the
+// orders they placed themselves and none of a restaurant's private data,
+// such as its stock. Only admins and background jobs are unscoped. Data a
+// customer owns, such as their account, favorites and loyalty points,
+// belongs to no organization and is not scoped.
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type contextKey struct{}
+
+type scope struct {
+	unscoped        bool
+	organizationIDs []uuid.UUID
+	customerID      uuid.UUID
+}
+
+func scopeOf(ctx context.Context) scope {
+	s, _ := ctx.Value(contextKey{}).(scope)
+	return s
+}
+
+// Unscoped lets ctx see the data of every organization. It is meant for
+// admins and background jobs, never for a request on behalf of a guest.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, scope{unscoped: true})
+}
+
+// WithOrganizations scopes ctx to the given organizations. A scope without
+// organizations sees no restaurant data at all.
+func WithOrganizations(ctx context.Context, organizationIDs []uuid.UUID) context.Context {
+	if organizationIDs == nil {
+		organizationIDs = []uuid.UUID{}
+	}
+	return context.WithValue(ctx, contextKey{}, scope{organizationIDs: organizationIDs})
+}
+
+// WithCustomer scopes ctx to a signed-in customer, who sees the orders they
+// placed. A context that is not scoped at all is an anonymous guest.
+func WithCustomer(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, contextKey{}, scope{customerID: userID})
+}
+
+// IsUnscoped reports whether ctx sees the data of every organization.
+func IsUnscoped(ctx context.Context) bool {
+	return scopeOf(ctx).unscoped
+}
+
+// Organizations returns the organizations ctx is scoped to. ok is false if
+// ctx is not scoped to organizations, as for admins, guests and background
+// jobs.
+func Organizations(ctx context.Context) (organizationIDs []uuid.UUID, ok bool) {
+	s := scopeOf(ctx)
+	return s.organizationIDs, s.organizationIDs != nil
+}
+
+// IsGuest reports whether ctx is neither unscoped nor scoped to
+// organizations. Guests only see public restaurant data and their own
+// orders.
+func IsGuest(ctx context.Context) bool {
+	s := scopeOf(ctx)
+	return !s.unscoped && s.organizationIDs == nil
+}
+
+// Customer returns the signed-in customer ctx is scoped to. ok is false for
+// anonymous guests and for contexts that are not guests.
+func Customer(ctx context.Context) (userID uuid.UUID, ok bool) {
+	s := scopeOf(ctx)
+	return s.customerID, s.customerID != uuid.Nil
+}
+
+// Allows reports whether ctx may write data of the organization. Data that
+// belongs to no organization may only be written by unscoped contexts, and
+// guests may write none.
+func Allows(ctx context.Context, organizationID *uuid.UUID) bool {
+	if IsUnscoped(ctx) {
+		return true
+	}
+	organizationIDs, ok := Organizations(ctx)
+	if !ok || organizationID == nil {
+		return false
+	}
+	for _, id := range organizationIDs {
+		if id == *organizationID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id UUID PRIMARY KEY,
+    user_id UUID,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    status VARCHAR(20) NOT NULL,
+    total_amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    menu_item_id UUID REFERENCES menu_items(id),
+    quantity INTEGER NOT NULL,
+    price DECIMAL(10,2) NOT NULL
+);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS table_id UUID REFERENCES tables(id);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS seat INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_orders_table_id ON orders(table_id);
+
+CREATE TABLE checks (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    table_id UUID REFERENCES tables(id),
+    split_type VARCHAR(20) NOT NULL, -- item, seat, even, amount
+    label VARCHAR(255) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    status VARCHAR(20) NOT NULL, -- open, paid
+    paid_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- A check covers one order, or several when a table's orders are split together.
+CREATE TABLE check_orders (
+    check_id UUID REFERENCES checks(id) ON DELETE CASCADE,
+    order_id UUID REFERENCES orders(id) ON DELETE CASCADE,
+    PRIMARY KEY (check_id, order_id)
+);
+
+CREATE TABLE check_items (
+    check_id UUID REFERENCES checks(id) ON DELETE CASCADE,
+    order_item_id UUID REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    PRIMARY KEY (check_id, order_item_id)
+);
+
+CREATE INDEX idx_check_orders_order_id ON check_orders(order_id);
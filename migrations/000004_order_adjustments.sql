@@ -0,0 +1,40 @@
+ALTER TABLE order_items ADD COLUMN sent_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE order_items ADD COLUMN voided_quantity INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN refunded_quantity INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE order_adjustments (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id),
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    order_item_id UUID REFERENCES order_items(id) ON DELETE SET NULL,
+    type VARCHAR(20) NOT NULL, -- void, refund
+    quantity INTEGER NOT NULL DEFAULT 0,
+    amount DECIMAL(10,2) NOT NULL,
+    refunded_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    reason_code VARCHAR(50) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL, -- pending_approval, processing, failed, completed, rejected
+    requested_by UUID NOT NULL REFERENCES users(id),
+    approved_by UUID REFERENCES users(id),
+    approved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_order_adjustments_order_id ON order_adjustments(order_id);
+CREATE INDEX idx_order_adjustments_restaurant_created ON order_adjustments(restaurant_id, created_at);
+
+-- Refunds are claimed before any money moves and record the result of
+-- refunding each payment, so a refund is never paid out twice and one that
+-- fails part way can be seen and retried.
+CREATE TABLE adjustment_refunds (
+    id UUID PRIMARY KEY,
+    adjustment_id UUID NOT NULL REFERENCES order_adjustments(id) ON DELETE CASCADE,
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    amount DECIMAL(10,2) NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_adjustment_refunds_adjustment_id ON adjustment_refunds(adjustment_id);
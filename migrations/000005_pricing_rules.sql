@@ -0,0 +1,42 @@
+CREATE TABLE pricing_rules (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL, -- percent_off, amount_off, buy_x_get_y, fixed_price
+    scope VARCHAR(20) NOT NULL, -- order, category, menu_item
+    scope_id UUID,
+    value DECIMAL(10,2) NOT NULL DEFAULT 0,
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    get_quantity INTEGER NOT NULL DEFAULT 0,
+    promo_code VARCHAR(50),
+    min_subtotal DECIMAL(10,2) NOT NULL DEFAULT 0,
+    valid_from TIMESTAMP WITH TIME ZONE,
+    valid_until TIMESTAMP WITH TIME ZONE,
+    days_of_week INTEGER[] NOT NULL DEFAULT '{}',
+    start_time VARCHAR(5) NOT NULL DEFAULT '', -- HH:MM, restaurant local time
+    end_time VARCHAR(5) NOT NULL DEFAULT '',
+    usage_limit INTEGER,
+    usage_count INTEGER NOT NULL DEFAULT 0,
+    stackable BOOLEAN NOT NULL DEFAULT false,
+    priority INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_pricing_rules_promo_code ON pricing_rules(restaurant_id, promo_code) WHERE promo_code IS NOT NULL;
+
+ALTER TABLE orders ADD COLUMN subtotal DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN discount_total DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN promo_code VARCHAR(50) NOT NULL DEFAULT '';
+
+CREATE TABLE order_discounts (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    rule_id UUID REFERENCES pricing_rules(id) ON DELETE SET NULL,
+    code VARCHAR(50) NOT NULL DEFAULT '',
+    description VARCHAR(255) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL
+);
+
+CREATE INDEX idx_order_discounts_order_id ON order_discounts(order_id);
@@ -0,0 +1,37 @@
+CREATE TABLE gift_cards (
+    id UUID PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    initial_balance DECIMAL(10,2) NOT NULL,
+    balance DECIMAL(10,2) NOT NULL CHECK (balance >= 0),
+    restaurant_id UUID REFERENCES restaurants(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE gift_card_transactions (
+    id UUID PRIMARY KEY,
+    gift_card_id UUID NOT NULL REFERENCES gift_cards(id),
+    type VARCHAR(20) NOT NULL, -- issue, load, redeem, refund
+    amount DECIMAL(10,2) NOT NULL,
+    balance_after DECIMAL(10,2) NOT NULL CHECK (balance_after >= 0),
+    order_id UUID REFERENCES orders(id),
+    payment_id UUID REFERENCES payments(id),
+    restaurant_id UUID REFERENCES restaurants(id),
+    note TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_gift_card_transactions_card_id ON gift_card_transactions(gift_card_id, created_at);
+CREATE INDEX idx_gift_card_transactions_payment_id ON gift_card_transactions(payment_id);
+
+-- The ledger is append-only: entries can never be changed or removed.
+CREATE FUNCTION gift_card_transactions_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'gift card transactions are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER gift_card_transactions_no_update
+    BEFORE UPDATE OR DELETE ON gift_card_transactions
+    FOR EACH ROW EXECUTE FUNCTION gift_card_transactions_append_only();
@@ -0,0 +1,43 @@
+CREATE TABLE loyalty_programs (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID UNIQUE REFERENCES restaurants(id) ON DELETE CASCADE, -- NULL for the chain-wide program
+    points_per_unit DECIMAL(10,2) NOT NULL,
+    point_value DECIMAL(10,4) NOT NULL,
+    expiry_days INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_loyalty_programs_chain ON loyalty_programs((restaurant_id IS NULL)) WHERE restaurant_id IS NULL;
+
+CREATE TABLE loyalty_accounts (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    balance INTEGER NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    lifetime_points INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE loyalty_transactions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    restaurant_id UUID REFERENCES restaurants(id) ON DELETE SET NULL,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    adjustment_id UUID REFERENCES order_adjustments(id) ON DELETE SET NULL,
+    type VARCHAR(20) NOT NULL, -- earn, redeem, restore, expire, reverse
+    points INTEGER NOT NULL,
+    remaining INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_loyalty_transactions_user_id ON loyalty_transactions(user_id, created_at);
+-- An order earns, redeems and restores points at most once
+CREATE UNIQUE INDEX idx_loyalty_transactions_order ON loyalty_transactions(order_id, type)
+    WHERE order_id IS NOT NULL AND type IN ('earn', 'redeem', 'restore');
+-- A completed refund takes back its share of the points at most once
+CREATE UNIQUE INDEX idx_loyalty_transactions_adjustment ON loyalty_transactions(adjustment_id)
+    WHERE adjustment_id IS NOT NULL;
+
+ALTER TABLE orders ADD COLUMN loyalty_points INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+CREATE TABLE favorites (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, menu_item_id)
+);
+
+CREATE INDEX idx_orders_user_id_created_at ON orders(user_id, created_at DESC);
@@ -0,0 +1,26 @@
+CREATE TABLE reviews (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    user_id UUID NOT NULL REFERENCES users(id),
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment TEXT NOT NULL DEFAULT '',
+    reply TEXT NOT NULL DEFAULT '',
+    replied_by UUID REFERENCES users(id),
+    replied_at TIMESTAMP WITH TIME ZONE,
+    hidden BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_reviews_restaurant_id ON reviews(restaurant_id, created_at DESC);
+
+CREATE TABLE review_items (
+    review_id UUID NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (review_id, menu_item_id)
+);
+
+CREATE INDEX idx_review_items_menu_item_id ON review_items(menu_item_id);
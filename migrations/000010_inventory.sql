@@ -0,0 +1,54 @@
+ALTER TABLE menu_item_customizations ALTER COLUMN options SET DEFAULT '[]';
+UPDATE menu_item_customizations SET options = '[]' WHERE options IS NULL;
+ALTER TABLE menu_item_customizations ALTER COLUMN options SET NOT NULL;
+UPDATE menu_item_customizations SET required = false WHERE required IS NULL;
+ALTER TABLE menu_item_customizations ALTER COLUMN required SET NOT NULL;
+ALTER TABLE menu_item_customizations DROP CONSTRAINT menu_item_customizations_menu_item_id_fkey;
+ALTER TABLE menu_item_customizations ADD CONSTRAINT menu_item_customizations_menu_item_id_fkey
+    FOREIGN KEY (menu_item_id) REFERENCES menu_items(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_menu_item_customizations_menu_item_id ON menu_item_customizations(menu_item_id);
+
+ALTER TABLE order_items ADD COLUMN options JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE ingredients (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(255) NOT NULL,
+    unit VARCHAR(10) NOT NULL, -- g, kg, ml, l, pcs
+    stock DECIMAL(12,3) NOT NULL DEFAULT 0,
+    low_stock_threshold DECIMAL(12,3) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE(restaurant_id, name)
+);
+
+CREATE TABLE recipe_lines (
+    id UUID PRIMARY KEY,
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    ingredient_id UUID NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+    quantity DECIMAL(12,3) NOT NULL CHECK (quantity > 0),
+    customization_id UUID REFERENCES menu_item_customizations(id) ON DELETE CASCADE,
+    option VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_recipe_lines_menu_item_id ON recipe_lines(menu_item_id);
+
+CREATE TABLE stock_movements (
+    id UUID PRIMARY KEY,
+    ingredient_id UUID NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    type VARCHAR(20) NOT NULL, -- delivery, waste, count, sale, sale_reversal
+    quantity DECIMAL(12,3) NOT NULL,
+    stock_after DECIMAL(12,3) NOT NULL,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_stock_movements_ingredient_id ON stock_movements(ingredient_id, created_at DESC);
+CREATE INDEX idx_stock_movements_order_id ON stock_movements(order_id);
+-- An order's stock is booked out and put back at most once per ingredient
+CREATE UNIQUE INDEX idx_stock_movements_order_once ON stock_movements(order_id, ingredient_id, type)
+    WHERE type IN ('sale', 'sale_reversal');
@@ -0,0 +1,2 @@
+ALTER TABLE menu_items ADD COLUMN available BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE menu_items ADD COLUMN portions_remaining INTEGER CHECK (portions_remaining >= 0);
@@ -0,0 +1,51 @@
+ALTER TABLE ingredients ADD COLUMN par_level DECIMAL(12,3) NOT NULL DEFAULT 0;
+
+CREATE TABLE suppliers (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(255) NOT NULL,
+    contact_name VARCHAR(255) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_suppliers_restaurant_id ON suppliers(restaurant_id);
+
+CREATE TABLE supplier_items (
+    id UUID PRIMARY KEY,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    ingredient_id UUID NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+    sku VARCHAR(100) NOT NULL DEFAULT '',
+    unit_cost DECIMAL(10,4) NOT NULL DEFAULT 0,
+    UNIQUE(supplier_id, ingredient_id)
+);
+
+CREATE TABLE purchase_orders (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    supplier_id UUID NOT NULL REFERENCES suppliers(id),
+    status VARCHAR(20) NOT NULL, -- draft, sent, partially_received, received
+    expected_at TIMESTAMP WITH TIME ZONE,
+    notes TEXT NOT NULL DEFAULT '',
+    total DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(id),
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_purchase_orders_restaurant_id ON purchase_orders(restaurant_id, created_at DESC);
+
+CREATE TABLE purchase_order_lines (
+    id UUID PRIMARY KEY,
+    purchase_order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    ingredient_id UUID NOT NULL REFERENCES ingredients(id),
+    quantity DECIMAL(12,3) NOT NULL CHECK (quantity > 0),
+    received_quantity DECIMAL(12,3) NOT NULL DEFAULT 0,
+    unit_cost DECIMAL(10,4) NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_purchase_order_lines_purchase_order_id ON purchase_order_lines(purchase_order_id);
@@ -0,0 +1,32 @@
+ALTER TABLE restaurants ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE menus (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id),
+    name VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE(restaurant_id, name)
+);
+
+CREATE TABLE menu_windows (
+    menu_id UUID NOT NULL REFERENCES menus(id) ON DELETE CASCADE,
+    days_of_week INTEGER[] NOT NULL DEFAULT '{}',
+    start_time VARCHAR(5) NOT NULL, -- HH:MM, restaurant local time
+    end_time VARCHAR(5) NOT NULL
+);
+
+CREATE INDEX idx_menu_windows_menu_id ON menu_windows(menu_id);
+
+CREATE TABLE menu_categories (
+    menu_id UUID NOT NULL REFERENCES menus(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES food_categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (menu_id, category_id)
+);
+
+CREATE TABLE menu_menu_items (
+    menu_id UUID NOT NULL REFERENCES menus(id) ON DELETE CASCADE,
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    PRIMARY KEY (menu_id, menu_item_id)
+);
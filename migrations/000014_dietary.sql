@@ -0,0 +1,10 @@
+ALTER TABLE menu_items ADD COLUMN allergens TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE menu_items ADD COLUMN dietary_tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE menu_items ADD COLUMN nutrition JSONB;
+
+CREATE TABLE allergy_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    allergens TEXT[] NOT NULL DEFAULT '{}',
+    dietary_tags TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
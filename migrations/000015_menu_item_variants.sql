@@ -0,0 +1,19 @@
+CREATE TABLE menu_item_variants (
+    id UUID PRIMARY KEY,
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    sku VARCHAR(100) NOT NULL DEFAULT '',
+    price DECIMAL(10,2) NOT NULL,
+    available BOOLEAN NOT NULL DEFAULT true,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (menu_item_id, name)
+);
+
+CREATE INDEX idx_menu_item_variants_menu_item_id ON menu_item_variants(menu_item_id);
+
+-- The variant name is kept on the order item so tickets and reports still
+-- read correctly after the variant is renamed or deleted.
+ALTER TABLE order_items ADD COLUMN variant_id UUID REFERENCES menu_item_variants(id) ON DELETE SET NULL;
+ALTER TABLE order_items ADD COLUMN variant_name VARCHAR(100) NOT NULL DEFAULT '';
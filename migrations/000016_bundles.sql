@@ -0,0 +1,25 @@
+CREATE TABLE bundle_groups (
+    id UUID PRIMARY KEY,
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    min_selections INTEGER NOT NULL DEFAULT 0 CHECK (min_selections >= 0),
+    max_selections INTEGER NOT NULL CHECK (max_selections >= min_selections),
+    position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_bundle_groups_menu_item_id ON bundle_groups(menu_item_id);
+
+CREATE TABLE bundle_choices (
+    group_id UUID NOT NULL REFERENCES bundle_groups(id) ON DELETE CASCADE,
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    variant_id UUID REFERENCES menu_item_variants(id) ON DELETE CASCADE,
+    upcharge DECIMAL(10,2) NOT NULL DEFAULT 0,
+    position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_bundle_choices_group_id ON bundle_choices(group_id);
+
+ALTER TABLE order_items ADD COLUMN parent_item_id UUID REFERENCES order_items(id) ON DELETE CASCADE;
+ALTER TABLE order_items ADD COLUMN bundle_group_id UUID REFERENCES bundle_groups(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_order_items_parent_item_id ON order_items(parent_item_id);
@@ -0,0 +1,12 @@
+-- Translations are stored next to the base text as a JSON object keyed by
+-- lowercase locale tag, e.g. {"fr": {"name": "...", "description": "..."}}.
+ALTER TABLE menu_items
+    ADD COLUMN translations JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE food_categories
+    ADD COLUMN translations JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE restaurants
+    ADD COLUMN translations JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_food_categories_restaurant ON food_categories(restaurant_id);
@@ -0,0 +1,5 @@
+ALTER TABLE menu_items
+    ADD COLUMN sku VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Bulk imports upsert items by SKU, so it must be unique per restaurant.
+CREATE UNIQUE INDEX idx_menu_items_restaurant_sku ON menu_items(restaurant_id, sku) WHERE sku <> '';
@@ -0,0 +1,48 @@
+CREATE TABLE brands (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    owner_id UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_brands_owner ON brands(owner_id);
+
+ALTER TABLE restaurants
+    ADD COLUMN brand_id UUID REFERENCES brands(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_restaurants_brand ON restaurants(brand_id);
+
+-- Deleted master items are kept until the next publish has taken them off
+-- every location's menu.
+CREATE TABLE brand_menu_items (
+    id UUID PRIMARY KEY,
+    brand_id UUID NOT NULL REFERENCES brands(id) ON DELETE CASCADE,
+    sku VARCHAR(100) NOT NULL DEFAULT '',
+    category VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    translations JSONB NOT NULL DEFAULT '{}',
+    price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
+    allergens TEXT[] NOT NULL DEFAULT '{}',
+    dietary_tags TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_brand_menu_items_brand ON brand_menu_items(brand_id);
+
+ALTER TABLE menu_items
+    ADD COLUMN brand_item_id UUID REFERENCES brand_menu_items(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_menu_items_brand_item ON menu_items(brand_item_id);
+
+CREATE TABLE brand_menu_overrides (
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    brand_item_id UUID NOT NULL REFERENCES brand_menu_items(id) ON DELETE CASCADE,
+    price DECIMAL(10,2) CHECK (price >= 0),
+    available BOOLEAN,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (restaurant_id, brand_item_id)
+);
@@ -0,0 +1,27 @@
+CREATE TABLE menu_versions (
+    id UUID PRIMARY KEY,
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('draft', 'scheduled', 'published', 'archived')),
+    note TEXT NOT NULL DEFAULT '',
+    items JSONB NOT NULL DEFAULT '[]',
+    publish_at TIMESTAMP WITH TIME ZONE,
+    published_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (restaurant_id, number)
+);
+
+-- A restaurant has at most one live version and one open draft.
+CREATE UNIQUE INDEX idx_menu_versions_published ON menu_versions(restaurant_id) WHERE status = 'published';
+CREATE UNIQUE INDEX idx_menu_versions_open ON menu_versions(restaurant_id) WHERE status IN ('draft', 'scheduled');
+CREATE INDEX idx_menu_versions_due ON menu_versions(publish_at) WHERE status = 'scheduled';
+
+ALTER TABLE orders
+    ADD COLUMN menu_version_id UUID REFERENCES menu_versions(id) ON DELETE SET NULL;
+
+-- Items removed by a publish that have been ordered are retired rather
+-- than deleted, so a rollback can bring them back.
+ALTER TABLE menu_items
+    ADD COLUMN retired_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,28 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Full-text search weighs the name over dietary tags over the description.
+-- Tags are stored as e.g. gluten_free and indexed as separate words.
+ALTER TABLE menu_items
+    ADD COLUMN search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector;
+
+CREATE FUNCTION menu_items_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', replace(array_to_string(NEW.dietary_tags, ' '), '_', ' ')), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.description, '')), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER menu_items_search_vector_update
+    BEFORE INSERT OR UPDATE OF name, description, dietary_tags ON menu_items
+    FOR EACH ROW EXECUTE FUNCTION menu_items_search_vector();
+
+UPDATE menu_items SET name = name;
+
+CREATE INDEX idx_menu_items_search ON menu_items USING GIN (search_vector);
+
+-- Trigram indexes back the fuzzy fallback for misspelled queries.
+CREATE INDEX idx_menu_items_name_trgm ON menu_items USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_menu_items_description_trgm ON menu_items USING GIN (description gin_trgm_ops);
@@ -0,0 +1,8 @@
+ALTER TABLE restaurants
+    ADD COLUMN latitude DOUBLE PRECISION,
+    ADD COLUMN longitude DOUBLE PRECISION,
+    ADD COLUMN cuisine_tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Nearby searches narrow by a bounding box before computing distances.
+CREATE INDEX idx_restaurants_location ON restaurants(latitude, longitude);
+CREATE INDEX idx_restaurants_cuisine_tags ON restaurants USING GIN (cuisine_tags);
@@ -0,0 +1,13 @@
+-- Weekly intervals and date exceptions are kept as one document per
+-- restaurant, in the restaurant's timezone.
+CREATE TABLE restaurant_hours (
+    restaurant_id UUID PRIMARY KEY REFERENCES restaurants(id) ON DELETE CASCADE,
+    weekly JSONB NOT NULL DEFAULT '[]',
+    exceptions JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Orders placed while a restaurant is closed must be scheduled for a time
+-- it is open.
+ALTER TABLE orders
+    ADD COLUMN scheduled_for TIMESTAMP WITH TIME ZONE;
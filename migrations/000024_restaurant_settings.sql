@@ -0,0 +1,22 @@
+-- Every save of a restaurant's settings is kept as a new version; the
+-- highest version is current. The timezone is mirrored on restaurants.
+CREATE TABLE restaurant_settings (
+    restaurant_id UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    tax_mode VARCHAR(20) NOT NULL,
+    tax_rate DECIMAL(6,3) NOT NULL DEFAULT 0,
+    service_charge_rate DECIMAL(6,3) NOT NULL DEFAULT 0,
+    auto_accept_orders BOOLEAN NOT NULL DEFAULT false,
+    receipt_footer TEXT NOT NULL DEFAULT '',
+    updated_by UUID REFERENCES users(id),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (restaurant_id, version)
+);
+
+-- Orders keep the charges and currency they were priced with.
+ALTER TABLE orders
+    ADD COLUMN service_charge DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN tax_total DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
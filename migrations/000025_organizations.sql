@@ -0,0 +1,54 @@
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE organization_members (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX idx_organization_members_user ON organization_members(user_id);
+
+ALTER TABLE restaurants
+    ADD COLUMN organization_id UUID REFERENCES organizations(id);
+
+CREATE INDEX idx_restaurants_organization ON restaurants(organization_id);
+
+ALTER TABLE brands
+    ADD COLUMN organization_id UUID REFERENCES organizations(id);
+
+CREATE INDEX idx_brands_organization ON brands(organization_id);
+
+-- Every manager and brand owner gets an organization they own, with their
+-- restaurants and brands and the restaurants' employees in it.
+INSERT INTO organizations (id, name, created_by)
+SELECT gen_random_uuid(), u.email, u.id
+FROM users u
+WHERE u.id IN (SELECT manager_id FROM restaurants UNION SELECT owner_id FROM brands);
+
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT id, created_by, 'owner' FROM organizations;
+
+UPDATE restaurants r
+SET organization_id = o.id
+FROM organizations o
+WHERE o.created_by = r.manager_id;
+
+UPDATE brands b
+SET organization_id = o.id
+FROM organizations o
+WHERE o.created_by = b.owner_id;
+
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT DISTINCT r.organization_id, e.user_id, 'staff'
+FROM restaurant_employees e
+JOIN restaurants r ON r.id = e.restaurant_id
+WHERE r.organization_id IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,15 @@
+-- Orders are eaten in, picked up or delivered; existing orders were all
+-- dine-in.
+ALTER TABLE orders
+    ADD COLUMN order_type VARCHAR(20) NOT NULL DEFAULT 'dine_in' CHECK (order_type IN ('dine_in', 'pickup', 'delivery')),
+    ADD COLUMN pickup_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN customer_name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN delivery_address TEXT NOT NULL DEFAULT '',
+    ADD COLUMN contact_phone VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN delivery_fee DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (delivery_fee >= 0);
+
+CREATE INDEX idx_orders_restaurant_type ON orders(restaurant_id, order_type);
+
+-- The flat fee a restaurant charges on delivery orders.
+ALTER TABLE restaurant_settings
+    ADD COLUMN delivery_fee DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (delivery_fee >= 0);